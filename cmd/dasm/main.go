@@ -4,22 +4,34 @@
 //
 //	dasm run program.dasm          # Execute assembly file
 //	dasm run program.dasm -v       # Execute with verbose output
+//	dasm run -watch program.dasm   # Re-execute whenever the file changes
 //	dasm compile program.dasm      # Compile to bytecode (.dfbc)
 //	dasm exec program.dfbc         # Execute compiled bytecode
 //	dasm disasm program.dfbc       # Disassemble bytecode
+//	dasm check program.dasm        # Compile and validate without running
+//	dasm explain program.dfx       # Describe a DSL program's plan
+//	dasm bench -n 100 program.dasm # Execute repeatedly and report stats
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 
 	"github.com/akhildatla/dasm/pkg/compiler"
-	"github.com/akhildatla/dasm/pkg/embed"
+	"github.com/akhildatla/dasm/pkg/dsl"
+	"github.com/akhildatla/dasm/pkg/loader"
 	"github.com/akhildatla/dasm/pkg/optimizer"
 	"github.com/akhildatla/dasm/pkg/repl"
 	"github.com/akhildatla/dasm/pkg/vm"
@@ -55,6 +67,12 @@ func run() error {
 		return execCommand(os.Args[2:])
 	case "disasm":
 		return disasmCommand(os.Args[2:])
+	case "check":
+		return checkCommand(os.Args[2:])
+	case "explain":
+		return explainCommand(os.Args[2:])
+	case "bench":
+		return benchCommand(os.Args[2:])
 	case "repl":
 		return replCommand(os.Args[2:])
 	case "version":
@@ -77,13 +95,24 @@ func runCommand(args []string) error {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 	verbose := fs.Bool("v", false, "verbose output")
 	useExampleFrames := fs.Bool("example-frames", false, "load built-in example frames (sales, people)")
+	format := fs.String("format", "text", "result format: text, json or csv")
+	watch := fs.Bool("watch", false, "re-execute whenever the file changes")
+	interval := fs.Duration("interval", 500*time.Millisecond, "polling interval for -watch")
+	optLevel := optimizationFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("usage: dasm run <file.dasm>")
+		return fmt.Errorf("usage: dasm run [-watch] [-O0|-O1|-O2] <file.dasm>")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	level, err := optLevel()
+	if err != nil {
+		return err
 	}
 
 	path := fs.Arg(0)
@@ -100,44 +129,151 @@ func runCommand(args []string) error {
 		frames = loadExampleFrames()
 	}
 
-	var result any
-	var err error
+	if *watch {
+		watchFile(path, *interval, nil, func() {
+			if err := runFile(path, frames, *format, level); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+		})
+		return nil
+	}
+	return runFile(path, frames, *format, level)
+}
 
-	if frames != nil {
-		data, readErr := os.ReadFile(path)
-		if readErr != nil {
-			return readErr
-		}
-		result, err = embed.ExecuteWithFrames(string(data), frames)
-	} else {
-		result, err = embed.ExecuteFile(path)
+// runFile executes the assembly file at path, optimized at level, and
+// prints its result. PRINT output goes to stdout ahead of it.
+func runFile(path string, frames map[string]*dataframe.DataFrame, format string, level int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
 	}
+	program, err := vm.Assemble(string(data))
+	if err != nil {
+		return err
+	}
+	result, err := executeProgram(optimize(program, level), frames)
 	if err != nil {
 		return err
 	}
 
-	// Print result
-	switch v := result.(type) {
-	case int64:
-		fmt.Printf("%d\n", v)
-	case float64:
-		fmt.Printf("%.6g\n", v)
-	case string:
-		fmt.Printf("%s\n", v)
-	case bool:
-		fmt.Printf("%v\n", v)
-	default:
-		fmt.Printf("%v\n", result)
+	return printResult(os.Stdout, result, format)
+}
+
+// executeProgram runs program on a fresh VM with the given predeclared
+// frames, writing PRINT output to stdout.
+func executeProgram(program *vm.Program, frames map[string]*dataframe.DataFrame) (any, error) {
+	v := vm.NewVM()
+	v.SetOutput(os.Stdout)
+	if frames != nil {
+		v.SetPredeclaredFrames(frames)
 	}
 
-	return nil
+	if err := v.Load(program); err != nil {
+		return nil, fmt.Errorf("loading program: %w", err)
+	}
+	result, err := v.Execute()
+	if err != nil {
+		return nil, fmt.Errorf("executing: %w", err)
+	}
+	return result, nil
+}
+
+// optimizationFlags registers -O, -O0, -O1 and -O2 on fs. The returned
+// function reports the requested tier after fs is parsed, and rejects
+// flags that ask for different tiers, such as -O0 -O2. -O is the same
+// as -O2.
+func optimizationFlags(fs *flag.FlagSet) func() (int, error) {
+	o := fs.Bool("O", false, "all optimizations (same as -O2)")
+	o0 := fs.Bool("O0", false, "disable optimizations")
+	o1 := fs.Bool("O1", false, "safe optimizations (constant folding, dead code elimination)")
+	o2 := fs.Bool("O2", false, "all optimizations (adds predicate pushdown, projection pruning, common subexpression elimination, peephole)")
+
+	return func() (int, error) {
+		tiers := []struct {
+			set   bool
+			level int
+		}{{*o0, 0}, {*o1, 1}, {*o2, 2}, {*o, 2}}
+
+		level, chosen := 0, false
+		for _, tier := range tiers {
+			if !tier.set {
+				continue
+			}
+			if chosen && tier.level != level {
+				return 0, fmt.Errorf("conflicting optimization flags: -O%d and -O%d", level, tier.level)
+			}
+			level, chosen = tier.level, true
+		}
+		return level, nil
+	}
+}
+
+// optimize applies the optimizations of tier level to program; level 0
+// returns it unchanged.
+func optimize(program *vm.Program, level int) *vm.Program {
+	if level == 0 {
+		return program
+	}
+	return optimizer.New(optimizer.WithLevel(level)).Optimize(program)
+}
+
+// watchFile calls run once, then polls path every interval and calls run
+// again each time its contents change. It returns when stop is closed; a nil
+// stop watches until the process is interrupted.
+func watchFile(path string, interval time.Duration, stop <-chan struct{}, run func()) {
+	digest, _ := fileDigest(path)
+	run()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		next, changed, err := fileChanged(path, digest)
+		if err != nil || !changed {
+			// A missing file is usually an editor mid-save; keep waiting.
+			continue
+		}
+		digest = next
+		fmt.Fprintf(os.Stderr, "--- %s changed, re-running ---\n", path)
+		run()
+	}
+}
+
+// fileChanged reports whether the contents of path differ from the digest
+// recorded by an earlier call, returning the current digest. Contents are
+// compared rather than modification times, so saves that leave the file
+// unchanged do not trigger a re-run and rewrites within the filesystem's
+// timestamp resolution are still noticed.
+func fileChanged(path string, prev uint64) (uint64, bool, error) {
+	digest, err := fileDigest(path)
+	if err != nil {
+		return prev, false, err
+	}
+	return digest, digest != prev, nil
+}
+
+// fileDigest returns an FNV-1a hash of the file at path.
+func fileDigest(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64(), nil
 }
 
 func compileCommand(args []string) error {
 	fs := flag.NewFlagSet("compile", flag.ExitOnError)
 	output := fs.String("o", "", "output file (default: input with .dfbc extension)")
 	verbose := fs.Bool("v", false, "verbose output")
-	optimize := fs.Bool("O", false, "enable optimizations (constant folding, dead code elimination)")
+	optLevel := optimizationFlags(fs)
+	emitAsm := fs.Bool("emit-asm", false, "also write the disassembly of the final program")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -147,6 +283,11 @@ func compileCommand(args []string) error {
 		return fmt.Errorf("usage: dasm compile <file.dasm> [-o output.dfbc]")
 	}
 
+	level, err := optLevel()
+	if err != nil {
+		return err
+	}
+
 	inputPath := fs.Arg(0)
 	outputPath := *output
 
@@ -167,18 +308,17 @@ func compileCommand(args []string) error {
 	}
 
 	// Compile to program
-	program, err := compiler.Compile(string(source))
+	program, err := vm.Assemble(string(source))
 	if err != nil {
 		return fmt.Errorf("compiling: %w", err)
 	}
 
 	// Apply optimizations if requested
-	if *optimize {
+	if level > 0 {
 		if *verbose {
-			fmt.Printf("Applying optimizations (before: %d instructions)\n", len(program.Code))
+			fmt.Printf("Applying -O%d optimizations (before: %d instructions)\n", level, len(program.Code))
 		}
-		opt := optimizer.New(optimizer.WithAllOptimizations())
-		program = opt.Optimize(program)
+		program = optimize(program, level)
 		if *verbose {
 			fmt.Printf("After optimization: %d instructions\n", len(program.Code))
 		}
@@ -203,6 +343,18 @@ func compileCommand(args []string) error {
 		fmt.Printf("Compiled: %s\n", outputPath)
 	}
 
+	if *emitAsm {
+		// Write next to the bytecode, without clobbering the source
+		asmPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".dasm"
+		if filepath.Clean(asmPath) == filepath.Clean(inputPath) {
+			asmPath = strings.TrimSuffix(asmPath, ".dasm") + ".out.dasm"
+		}
+		if err := os.WriteFile(asmPath, []byte(vm.Disassemble(program)), 0644); err != nil {
+			return fmt.Errorf("writing assembly: %w", err)
+		}
+		fmt.Printf("Assembly: %s\n", asmPath)
+	}
+
 	return nil
 }
 
@@ -210,13 +362,22 @@ func execCommand(args []string) error {
 	fs := flag.NewFlagSet("exec", flag.ExitOnError)
 	verbose := fs.Bool("v", false, "verbose output")
 	useExampleFrames := fs.Bool("example-frames", false, "load built-in example frames (sales, people)")
+	format := fs.String("format", "text", "result format: text, json or csv")
+	optLevel := optimizationFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("usage: dasm exec <file.dfbc>")
+		return fmt.Errorf("usage: dasm exec [-O0|-O1|-O2] <file.dfbc>")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	level, err := optLevel()
+	if err != nil {
+		return err
 	}
 
 	path := fs.Arg(0)
@@ -242,49 +403,37 @@ func execCommand(args []string) error {
 			len(program.Code), len(program.Constants), len(program.FloatConstants))
 	}
 
-	// Create and run VM
-	v := vm.NewVM()
-
-	if *useExampleFrames {
-		v.SetPredeclaredFrames(loadExampleFrames())
+	if level > 0 {
+		program = optimize(program, level)
+		if *verbose {
+			fmt.Printf("After -O%d optimizations: %d instructions\n", level, len(program.Code))
+		}
 	}
 
-	if err := v.Load(program); err != nil {
-		return fmt.Errorf("loading program: %w", err)
+	var frames map[string]*dataframe.DataFrame
+	if *useExampleFrames {
+		frames = loadExampleFrames()
 	}
 
-	result, err := v.Execute()
+	result, err := executeProgram(program, frames)
 	if err != nil {
-		return fmt.Errorf("executing: %w", err)
-	}
-
-	// Print result
-	switch val := result.(type) {
-	case int64:
-		fmt.Printf("%d\n", val)
-	case float64:
-		fmt.Printf("%.6g\n", val)
-	case string:
-		fmt.Printf("%s\n", val)
-	case bool:
-		fmt.Printf("%v\n", val)
-	default:
-		fmt.Printf("%v\n", result)
+		return err
 	}
 
-	return nil
+	return printResult(os.Stdout, result, *format)
 }
 
 func disasmCommand(args []string) error {
 	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
 	output := fs.String("o", "", "output file (default: stdout)")
+	verbose := fs.Bool("v", false, "annotate instructions with constant slots and registers")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("usage: dasm disasm <file.dfbc> [-o output.dasm]")
+		return fmt.Errorf("usage: dasm disasm [-v] <file.dfbc> [-o output.dasm]")
 	}
 
 	path := fs.Arg(0)
@@ -303,6 +452,9 @@ func disasmCommand(args []string) error {
 
 	// Disassemble
 	asm := vm.Disassemble(program)
+	if *verbose {
+		asm = vm.DisassembleAnnotated(program)
+	}
 
 	// Output
 	if *output != "" {
@@ -317,6 +469,186 @@ func disasmCommand(args []string) error {
 	return nil
 }
 
+func checkCommand(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	optLevel := optimizationFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("usage: dasm check [-O0|-O1|-O2] <file.dasm|file.dfx|file.dfbc>")
+	}
+	level, err := optLevel()
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	program, err := loadProgram(path, level)
+	if err != nil {
+		return err
+	}
+
+	if err := vm.ValidateProgram(program); err != nil {
+		return fmt.Errorf("validating: %w", err)
+	}
+
+	fmt.Printf("OK: %s (%d instructions, %d constants, %d float constants)\n",
+		path, len(program.Code), len(program.Constants), len(program.FloatConstants))
+	return nil
+}
+
+func explainCommand(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: dasm explain <file.dfx>")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[0], err)
+	}
+
+	plan, err := compiler.Explain(string(data))
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	return nil
+}
+
+// loadProgram reads path as bytecode (.dfbc), DSL (.dfx) or assembly and
+// returns the compiled program, optimized at level.
+func loadProgram(path string, level int) (*vm.Program, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var program *vm.Program
+	switch filepath.Ext(path) {
+	case ".dfbc":
+		program, err = vm.DeserializeProgram(data)
+		if err != nil {
+			return nil, fmt.Errorf("deserializing: %w", err)
+		}
+
+	case ".dfx":
+		tokens := dsl.NewLexer(string(data)).Tokenize()
+		ast, err := dsl.NewParser(tokens).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("parsing DSL: %w", err)
+		}
+		if program, err = compiler.CompileDSL(ast); err != nil {
+			return nil, fmt.Errorf("compiling DSL: %w", err)
+		}
+
+	default:
+		if program, err = vm.Assemble(string(data)); err != nil {
+			return nil, fmt.Errorf("compiling: %w", err)
+		}
+	}
+
+	return optimize(program, level), nil
+}
+
+func benchCommand(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	iterations := fs.Int("n", 10, "number of executions")
+	useExampleFrames := fs.Bool("example-frames", false, "load built-in example frames (sales, people)")
+	optLevel := optimizationFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("usage: dasm bench [-n N] [-example-frames] [-O0|-O1|-O2] <file>")
+	}
+	if *iterations < 1 {
+		return fmt.Errorf("iteration count must be positive, got %d", *iterations)
+	}
+	level, err := optLevel()
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	program, err := loadProgram(path, level)
+	if err != nil {
+		return err
+	}
+
+	var frames map[string]*dataframe.DataFrame
+	if *useExampleFrames {
+		frames = loadExampleFrames()
+	}
+
+	result, err := runBench(program, frames, *iterations)
+	if err != nil {
+		return err
+	}
+
+	n := int64(result.Iterations)
+	fmt.Printf("%s: %d iterations\n", path, result.Iterations)
+	fmt.Printf("  avg steps: %d\n", result.TotalSteps/n)
+	fmt.Printf("  avg time:  %s (min %s, max %s)\n",
+		time.Duration(result.TotalNs/n), time.Duration(result.MinNs), time.Duration(result.MaxNs))
+
+	fmt.Println("  opcode counts (per run):")
+	totals := vm.ExecutionStats{OpCounts: result.OpCounts}
+	for _, h := range totals.Hotspots(0) {
+		fmt.Printf("    %-18s %d\n", h.Op, h.Count/result.Iterations)
+	}
+
+	return nil
+}
+
+// benchResult aggregates ExecutionStats over repeated runs of one program.
+type benchResult struct {
+	Iterations int
+	TotalSteps int64
+	TotalNs    int64
+	MinNs      int64
+	MaxNs      int64
+	OpCounts   map[string]int // Summed over all iterations
+}
+
+// runBench executes program n times on a fresh VM with stats enabled.
+func runBench(program *vm.Program, frames map[string]*dataframe.DataFrame, n int) (*benchResult, error) {
+	result := &benchResult{Iterations: n, OpCounts: make(map[string]int)}
+
+	for i := 0; i < n; i++ {
+		v := vm.NewVM()
+		if frames != nil {
+			v.SetPredeclaredFrames(frames)
+		}
+		v.EnableStats()
+		if err := v.Load(program); err != nil {
+			return nil, fmt.Errorf("loading program: %w", err)
+		}
+		if _, err := v.Execute(); err != nil {
+			return nil, fmt.Errorf("executing (iteration %d): %w", i+1, err)
+		}
+
+		stats := v.Stats()
+		result.TotalSteps += stats.StepsExecuted
+		result.TotalNs += stats.ExecutionTimeNs
+		if i == 0 || stats.ExecutionTimeNs < result.MinNs {
+			result.MinNs = stats.ExecutionTimeNs
+		}
+		if stats.ExecutionTimeNs > result.MaxNs {
+			result.MaxNs = stats.ExecutionTimeNs
+		}
+		for op, count := range stats.OpCounts {
+			result.OpCounts[op] += count
+		}
+	}
+
+	return result, nil
+}
+
 func replCommand(args []string) error {
 	fs := flag.NewFlagSet("repl", flag.ExitOnError)
 	useExampleFrames := fs.Bool("example-frames", false, "load built-in example frames")
@@ -351,25 +683,48 @@ Commands:
   compile <file.dasm>   Compile assembly to bytecode (.dfbc)
   exec <file.dfbc>      Execute compiled bytecode
   disasm <file.dfbc>    Disassemble bytecode to assembly
+  check <file>          Compile and validate without executing (.dasm, .dfx, .dfbc)
+  explain <file.dfx>    Describe a DSL program's plan without compiling it
+  bench <file>          Execute repeatedly and report steps, timing and opcode counts
   repl                  Start interactive REPL
   version               Print version information
   help                  Show this help message
 
 Run Options:
   -v                    Verbose output
+  -watch                Re-execute whenever the file changes (Ctrl-C to stop)
+  -interval <dur>       Polling interval for -watch (default: 500ms)
   -example-frames       Load built-in example frames (sales, people, orders, customers, products)
+  -format <fmt>         Result format: text (default), json or csv
+  -O0, -O1, -O2, -O     Optimization tier, as for compile
 
 Compile Options:
   -o <file>             Output file (default: input with .dfbc extension)
-  -O                    Enable optimizations (constant folding, dead code elimination)
+  -O0                   No optimizations (default)
+  -O1                   Safe optimizations (constant folding, dead code elimination)
+  -O2, -O               All optimizations (adds predicate pushdown, projection pruning,
+                        common subexpression elimination, peephole); tiers may not be mixed
+  -emit-asm             Also write the final program's disassembly (output with .dasm,
+                        or .out.dasm if that would overwrite the input)
   -v                    Verbose output
 
 Exec Options:
   -v                    Verbose output
   -example-frames       Load built-in example frames
+  -format <fmt>         Result format: text (default), json or csv
+  -O0, -O1, -O2, -O     Optimize the loaded bytecode at this tier before running
 
 Disasm Options:
   -o <file>             Output file (default: stdout)
+  -v                    Annotate instructions with constant slots and registers
+
+Check Options:
+  -O0, -O1, -O2, -O     Validate the program optimized at this tier
+
+Bench Options:
+  -n <count>            Number of executions (default: 10)
+  -example-frames       Load built-in example frames
+  -O0, -O1, -O2, -O     Benchmark the program optimized at this tier
 
 REPL Options:
   -example-frames       Load built-in example frames
@@ -378,57 +733,165 @@ REPL Options:
 Examples:
   dasm run program.dasm
   dasm run -example-frames examples/groupby_aggregate.dasm
+  dasm run -watch program.dasm
   dasm compile program.dasm -o program.dfbc
   dasm exec program.dfbc
   dasm disasm program.dfbc
+  dasm check program.dfx
+  dasm explain program.dfx
+  dasm bench -n 100 -example-frames examples/groupby_aggregate.dasm
   dasm repl
   dasm repl -example-frames -asm`)
 	return nil
 }
 
+// checkFormat rejects result formats printResult does not support.
+func checkFormat(format string) error {
+	switch format {
+	case "text", "json", "csv":
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (want text, json or csv)", format)
+}
+
+// printResult writes a program result in the given format. Scalars print as a
+// single value, vectors as one value per row, and frames as a table (text),
+// array of row objects (json) or header plus rows (csv).
+func printResult(w io.Writer, result any, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		switch v := result.(type) {
+		case dataframe.Series:
+			return enc.Encode(seriesValues(v))
+		case *dataframe.DataFrame:
+			names := v.Names()
+			rows := make([]map[string]any, v.NRows())
+			for i := range rows {
+				rows[i] = make(map[string]any, len(names))
+				for j, s := range v.Series {
+					rows[i][names[j]] = jsonValue(s.Value(i))
+				}
+			}
+			return enc.Encode(rows)
+		default:
+			return enc.Encode(jsonValue(result))
+		}
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		switch v := result.(type) {
+		case dataframe.Series:
+			cw.Write([]string{v.Name()})
+			for _, val := range seriesValues(v) {
+				cw.Write([]string{csvValue(val)})
+			}
+		case *dataframe.DataFrame:
+			cw.Write(v.Names())
+			for i := 0; i < v.NRows(); i++ {
+				record := make([]string, len(v.Series))
+				for j, s := range v.Series {
+					record[j] = csvValue(s.Value(i))
+				}
+				cw.Write(record)
+			}
+		default:
+			cw.Write([]string{csvValue(result)})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "text":
+		switch v := result.(type) {
+		case int64:
+			fmt.Fprintf(w, "%d\n", v)
+		case float64:
+			fmt.Fprintf(w, "%.6g\n", v)
+		case string:
+			fmt.Fprintf(w, "%s\n", v)
+		case bool:
+			fmt.Fprintf(w, "%v\n", v)
+		case *dataframe.DataFrame:
+			fmt.Fprint(w, v.Table())
+		default:
+			fmt.Fprintf(w, "%v\n", result)
+		}
+		return nil
+	}
+
+	return checkFormat(format)
+}
+
+// seriesValues returns the values of s with nulls as nil.
+func seriesValues(s dataframe.Series) []any {
+	values := make([]any, s.NRows())
+	for i := range values {
+		values[i] = jsonValue(s.Value(i))
+	}
+	return values
+}
+
+// jsonValue maps NaN and infinities to nil, which encoding/json rejects.
+func jsonValue(v any) any {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil
+	}
+	return v
+}
+
+func csvValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
 // loadExampleFrames constructs the built-in frames referenced by example programs.
 func loadExampleFrames() map[string]*dataframe.DataFrame {
-	frames := make(map[string]*dataframe.DataFrame)
-
-	// "sales" frame used by examples/groupby_aggregate.dasm
-	sales := dataframe.NewDataFrame(
-		dataframe.NewSeriesString("category", nil, "A", "B", "A", "C"),
-		dataframe.NewSeriesFloat64("amount", nil, 10.0, 25.0, 7.5, 40.0),
-	)
-	frames["sales"] = sales
-
-	// "people" frame used by examples/string_operations.dasm
-	people := dataframe.NewDataFrame(
-		dataframe.NewSeriesString("name", nil, "Johnson", "Anderson", "Lee", "Jackson", "Kim"),
-		dataframe.NewSeriesInt64("age", nil, 34, 29, 41, 22, 37),
-	)
-	frames["people"] = people
-
-	// "orders" frame used by examples/join_example.dasm
-	orders := dataframe.NewDataFrame(
-		dataframe.NewSeriesInt64("order_id", nil, 1, 2, 3, 4, 5),
-		dataframe.NewSeriesInt64("customer_id", nil, 101, 102, 101, 103, 102),
-		dataframe.NewSeriesFloat64("amount", nil, 150.0, 200.0, 75.0, 300.0, 125.0),
-	)
-	frames["orders"] = orders
-
-	// "customers" frame used by examples/join_example.dasm
-	customers := dataframe.NewDataFrame(
-		dataframe.NewSeriesInt64("customer_id", nil, 101, 102, 103),
-		dataframe.NewSeriesString("name", nil, "Alice", "Bob", "Charlie"),
-	)
-	frames["customers"] = customers
-
-	// "products" frame used by examples/multi_filter.dasm
-	// Use SeriesGeneric for bool values (second param must be zero value)
-	inStockVals := []interface{}{true, true, false, true, true}
-	products := dataframe.NewDataFrame(
-		dataframe.NewSeriesString("name", nil, "Widget", "Gadget", "Gizmo", "Thing", "Stuff"),
-		dataframe.NewSeriesFloat64("price", nil, 25.0, 75.0, 100.0, 45.0, 60.0),
-		dataframe.NewSeriesString("category", nil, "A", "B", "A", "C", "B"),
-		dataframe.NewSeriesGeneric("in_stock", false, nil, inStockVals...),
-	)
-	frames["products"] = products
-
-	return frames
+	return map[string]*dataframe.DataFrame{
+		// "sales" frame used by examples/groupby_aggregate.dasm
+		"sales": mustFrame(loader.FromColumns(map[string][]any{
+			"category": {"A", "B", "A", "C"},
+			"amount":   {10.0, 25.0, 7.5, 40.0},
+		}, "category", "amount")),
+
+		// "people" frame used by examples/string_operations.dasm
+		"people": mustFrame(loader.FromColumns(map[string][]any{
+			"name": {"Johnson", "Anderson", "Lee", "Jackson", "Kim"},
+			"age":  {34, 29, 41, 22, 37},
+		}, "name", "age")),
+
+		// "orders" frame used by examples/join_example.dasm
+		"orders": mustFrame(loader.FromColumns(map[string][]any{
+			"order_id":    {1, 2, 3, 4, 5},
+			"customer_id": {101, 102, 101, 103, 102},
+			"amount":      {150.0, 200.0, 75.0, 300.0, 125.0},
+		}, "order_id", "customer_id", "amount")),
+
+		// "customers" frame used by examples/join_example.dasm
+		"customers": mustFrame(loader.FromColumns(map[string][]any{
+			"customer_id": {101, 102, 103},
+			"name":        {"Alice", "Bob", "Charlie"},
+		}, "customer_id", "name")),
+
+		// "products" frame used by examples/multi_filter.dasm
+		"products": mustFrame(loader.FromColumns(map[string][]any{
+			"name":     {"Widget", "Gadget", "Gizmo", "Thing", "Stuff"},
+			"price":    {25.0, 75.0, 100.0, 45.0, 60.0},
+			"category": {"A", "B", "A", "C", "B"},
+			"in_stock": {true, true, false, true, true},
+		}, "name", "price", "category", "in_stock")),
+	}
+}
+
+// mustFrame panics if a built-in frame definition is invalid.
+func mustFrame(df *dataframe.DataFrame, err error) *dataframe.DataFrame {
+	if err != nil {
+		panic(fmt.Sprintf("invalid example frame: %v", err))
+	}
+	return df
 }
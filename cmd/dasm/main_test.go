@@ -1,11 +1,19 @@
 package main
 
 import (
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+
+	"github.com/akhildatla/dasm/pkg/compiler"
+	"github.com/akhildatla/dasm/pkg/vm"
 )
 
 // buildDasm builds the dasm binary for testing
@@ -83,6 +91,28 @@ HALT R0
 	}
 }
 
+func TestCLI_RunPrint(t *testing.T) {
+	binary := buildDasm(t)
+
+	dasmFile := filepath.Join(t.TempDir(), "print.dasm")
+	err := os.WriteFile(dasmFile, []byte(`
+LOAD_CONST R0, 42
+PRINT R0, "int"
+HALT R0
+`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	output, err := exec.Command(binary, "run", dasmFile).Output()
+	if err != nil {
+		t.Fatalf("run command failed: %v\n%s", err, output)
+	}
+	if got := string(output); got != "R0 = 42\n42\n" {
+		t.Errorf("expected PRINT output then the result, got: %q", got)
+	}
+}
+
 func TestCLI_CompileAndExec(t *testing.T) {
 	binary := buildDasm(t)
 	tmpDir := t.TempDir()
@@ -158,6 +188,39 @@ HALT R0
 	}
 }
 
+func TestCLI_DisasmVerbose(t *testing.T) {
+	binary := buildDasm(t)
+	tmpDir := t.TempDir()
+
+	dasmFile := filepath.Join(tmpDir, "test.dasm")
+	err := os.WriteFile(dasmFile, []byte(`
+LOAD_FRAME    R0, "sales"
+SELECT_COL    V0, R0, "amount"
+REDUCE_SUM    R1, V0
+HALT          R1
+`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	bytecodeFile := filepath.Join(tmpDir, "test.dfbc")
+	cmd := exec.Command(binary, "compile", dasmFile, "-o", bytecodeFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed: %v\n%s", err, output)
+	}
+
+	cmd = exec.Command(binary, "disasm", "-v", bytecodeFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("disasm failed: %v\n%s", err, output)
+	}
+
+	out := string(output)
+	if !strings.Contains(out, `"amount"`) || !strings.Contains(out, "; const[1]; dst V0; src R0") {
+		t.Errorf("expected annotated SELECT_COL, got: %s", out)
+	}
+}
+
 func TestCLI_RunWithCSV(t *testing.T) {
 	binary := buildDasm(t)
 	tmpDir := t.TempDir()
@@ -228,6 +291,506 @@ HALT          R2
 	}
 }
 
+func TestCheckCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		return path
+	}
+
+	program, err := compiler.Compile("LOAD_CONST R0, 7\nHALT R0")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	bytecode, err := vm.SerializeProgram(program)
+	if err != nil {
+		t.Fatalf("SerializeProgram failed: %v", err)
+	}
+
+	valid := map[string][]string{
+		"asm":       {write("ok.dasm", "LOAD_CONST R0, 42\nHALT R0")},
+		"optimized": {"-O", write("opt.dasm", "LOAD_CONST R0, 1\nLOAD_CONST R1, 2\nHALT R0")},
+		"dsl":       {write("ok.dfx", "data = frame(\"sales\")\nreturn sum(data.price)")},
+		"bytecode":  {write("ok.dfbc", string(bytecode))},
+	}
+	for name, args := range valid {
+		if err := checkCommand(args); err != nil {
+			t.Errorf("%s: expected success, got %v", name, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"unknown opcode": write("bad.dasm", "FROBNICATE R0\nHALT R0"),
+		"missing halt":   write("nohalt.dasm", "LOAD_CONST R0, 42"),
+		"bad register":   write("reg.dasm", "HALT_V V9"),
+		"dsl syntax":     write("bad.dfx", "data = frame(\"sales\"\nreturn"),
+		"bytecode":       write("bad.dfbc", "not bytecode"),
+		"missing file":   filepath.Join(tmpDir, "missing.dasm"),
+	}
+	for name, path := range invalid {
+		if err := checkCommand([]string{path}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExplainCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.dfx")
+	src := "big = frame(\"sales\") |> filter(quantity > 10)\nreturn sum(big.price)"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	binary := buildDasm(t)
+	out, err := exec.Command(binary, "explain", path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("explain failed: %v\n%s", err, out)
+	}
+	want := "big = Load frame 'sales' → Filter quantity > 10\nreturn Sum big.price\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	if err := explainCommand(nil); err == nil {
+		t.Error("expected usage error without a file")
+	}
+}
+
+func TestFileChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.dasm")
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	write("LOAD_CONST R0, 1\nHALT R0")
+	digest, err := fileDigest(path)
+	if err != nil {
+		t.Fatalf("fileDigest failed: %v", err)
+	}
+
+	if _, changed, err := fileChanged(path, digest); err != nil || changed {
+		t.Errorf("untouched file: changed=%v err=%v, want false, nil", changed, err)
+	}
+
+	// Same length, rewritten immediately: modification times may not differ.
+	write("LOAD_CONST R0, 2\nHALT R0")
+	next, changed, err := fileChanged(path, digest)
+	if err != nil || !changed {
+		t.Fatalf("rewritten file: changed=%v err=%v, want true, nil", changed, err)
+	}
+	if _, changed, _ := fileChanged(path, next); changed {
+		t.Error("expected no change after taking the new digest")
+	}
+
+	// Rewriting identical contents is not a change.
+	write("LOAD_CONST R0, 2\nHALT R0")
+	if _, changed, _ := fileChanged(path, next); changed {
+		t.Error("identical rewrite reported as a change")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	got, changed, err := fileChanged(path, next)
+	if err == nil || changed || got != next {
+		t.Errorf("missing file: digest=%d changed=%v err=%v, want %d, false, error", got, changed, err, next)
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.dasm")
+	if err := os.WriteFile(path, []byte("LOAD_CONST R0, 1\nHALT R0"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	runs := make(chan struct{}, 10)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchFile(path, 5*time.Millisecond, stop, func() { runs <- struct{}{} })
+		close(done)
+	}()
+
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+
+	wait("initial run")
+	if err := os.WriteFile(path, []byte("LOAD_CONST R0, 2\nHALT R0"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	wait("re-run after change")
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchFile did not return after stop")
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	program, err := compiler.Compile(`LOAD_FRAME R0, "sales"
+SELECT_COL V0, R0, "amount"
+REDUCE_SUM_F F0, V0
+HALT_F F0`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	frames := loadExampleFrames()
+
+	once, err := runBench(program, frames, 1)
+	if err != nil {
+		t.Fatalf("runBench failed: %v", err)
+	}
+	if once.TotalSteps != 4 {
+		t.Errorf("expected 4 steps for one run, got %d", once.TotalSteps)
+	}
+
+	five, err := runBench(program, frames, 5)
+	if err != nil {
+		t.Fatalf("runBench failed: %v", err)
+	}
+	if five.Iterations != 5 || five.TotalSteps != 5*once.TotalSteps {
+		t.Errorf("expected steps to scale with iterations, got %d over %d runs", five.TotalSteps, five.Iterations)
+	}
+	if five.OpCounts["SELECT_COL"] != 5 || five.OpCounts["HALT_F"] != 5 {
+		t.Errorf("unexpected opcode counts: %v", five.OpCounts)
+	}
+	if five.TotalNs <= 0 || five.MinNs > five.MaxNs {
+		t.Errorf("unexpected timing: total %d, min %d, max %d", five.TotalNs, five.MinNs, five.MaxNs)
+	}
+
+	if _, err := runBench(program, nil, 1); err == nil {
+		t.Error("expected error without the sales frame")
+	}
+}
+
+func TestLazyLogical_FewerRows(t *testing.T) {
+	n := 1000
+	qty := make([]interface{}, n)
+	names := make([]interface{}, n)
+	for i := range qty {
+		qty[i] = int64(i)
+		names[i] = "item" + strconv.Itoa(i)
+	}
+	frames := map[string]*dataframe.DataFrame{"data": dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("qty", nil, qty...),
+		dataframe.NewSeriesString("name", nil, names...),
+	)}
+
+	// The string tests only run on the rows where qty > 990 when written
+	// inline; binding them to variables first evaluates them on every row.
+	lazy := `data = frame("data")
+return data.qty > 990 && (contains(data.name, "7") || ends_with(data.name, "9"))
+`
+	eager := `data = frame("data")
+a = contains(data.name, "7")
+b = ends_with(data.name, "9")
+hit = a || b
+return data.qty > 990 && hit
+`
+	run := func(src string) (dataframe.Series, int64) {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "q.dfx")
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		program, err := loadProgram(path, 0)
+		if err != nil {
+			t.Fatalf("loadProgram failed: %v", err)
+		}
+		v := vm.NewVM()
+		v.SetPredeclaredFrames(frames)
+		v.EnableStats()
+		if err := v.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		result, err := v.Execute()
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return result.(dataframe.Series), v.Stats().RowsProcessed
+	}
+
+	lazyResult, lazyRows := run(lazy)
+	eagerResult, eagerRows := run(eager)
+
+	if lazyResult.NRows() != n || eagerResult.NRows() != n {
+		t.Fatalf("expected %d rows, got %d and %d", n, lazyResult.NRows(), eagerResult.NRows())
+	}
+	matches := 0
+	for i := 0; i < n; i++ {
+		if lazyResult.Value(i) != eagerResult.Value(i) {
+			t.Errorf("row %d: lazy %v, eager %v", i, lazyResult.Value(i), eagerResult.Value(i))
+		}
+		if lazyResult.Value(i) == true {
+			matches++
+		}
+	}
+	if matches != 2 { // item997, item999
+		t.Errorf("expected 2 matching rows, got %d", matches)
+	}
+	if lazyRows >= eagerRows {
+		t.Errorf("expected lazy evaluation to process fewer rows: lazy %d, eager %d", lazyRows, eagerRows)
+	}
+}
+
+func TestBenchCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bench.dasm")
+	if err := os.WriteFile(path, []byte("LOAD_CONST R0, 42\nHALT R0"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if err := benchCommand([]string{"-n", "3", path}); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+	if err := benchCommand([]string{"-n", "0", path}); err == nil {
+		t.Error("expected error for non-positive iteration count")
+	}
+	if err := benchCommand([]string{"-example-frames", filepath.Join(t.TempDir(), "missing.dasm")}); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	prices := dataframe.NewSeriesFloat64("price", nil, 1.5, nil, 3.0)
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", "b"),
+		dataframe.NewSeriesInt64("qty", nil, 2, nil),
+	)
+
+	tests := []struct {
+		name   string
+		result any
+		format string
+		want   string
+	}{
+		{"int text", int64(42), "text", "42\n"},
+		{"int json", int64(42), "json", "42\n"},
+		{"int csv", int64(42), "csv", "42\n"},
+		{"float text", 2.5, "text", "2.5\n"},
+		{"float json", 2.5, "json", "2.5\n"},
+		{"nan json", math.NaN(), "json", "null\n"},
+		{"string json", "x,y", "json", "\"x,y\"\n"},
+		{"string csv", "x,y", "csv", "\"x,y\"\n"},
+		{"series json", prices, "json", "[1.5,null,3]\n"},
+		{"series csv", prices, "csv", "price\n1.5\n\n3\n"},
+		{"frame json", frame, "json", `[{"name":"a","qty":2},{"name":"b","qty":null}]` + "\n"},
+		{"frame csv", frame, "csv", "name,qty\na,2\nb,\n"},
+	}
+
+	for _, tt := range tests {
+		var buf strings.Builder
+		if err := printResult(&buf, tt.result, tt.format); err != nil {
+			t.Errorf("%s: printResult failed: %v", tt.name, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, buf.String())
+		}
+	}
+
+	var buf strings.Builder
+	if err := printResult(&buf, frame, "text"); err != nil || !strings.Contains(buf.String(), "QTY") {
+		t.Errorf("expected frame table, got %q (err %v)", buf.String(), err)
+	}
+	if err := printResult(&buf, int64(1), "yaml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestCLI_RunFormatJSON(t *testing.T) {
+	binary := buildDasm(t)
+	path := filepath.Join(t.TempDir(), "vec.dasm")
+	err := os.WriteFile(path, []byte(`LOAD_FRAME R0, "sales"
+SELECT_COL V0, R0, "amount"
+HALT_V V0`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	output, err := exec.Command(binary, "run", "-example-frames", "-format", "json", path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, output)
+	}
+	if out := strings.TrimSpace(string(output)); out != "[10,25,7.5,40]" {
+		t.Errorf("expected JSON array, got: %s", out)
+	}
+}
+
+func TestCompileCommand_OptimizationLevels(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "levels.dasm")
+	err := os.WriteFile(src, []byte(`LOAD_FRAME R0, "sales"
+SELECT_COL V0, R0, "amount"
+SELECT_COL V1, R0, "category" ; unused
+LOAD_CONST R1, 5
+LOAD_CONST R2, 10
+ADD_R R3, R1, R2              ; folds to a constant
+BROADCAST V2, R3, V0
+SELECT_COL V0, R0, "amount"   ; V0 already holds the column
+CMP_GT V3, V0, V2
+FILTER V4, V0, V3
+REDUCE_SUM_F F0, V4
+HALT_F F0`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, level := range []string{"-O0", "-O1", "-O2", "-O"} {
+		out := filepath.Join(tmpDir, strings.TrimPrefix(level, "-")+".dfbc")
+		if err := compileCommand([]string{level, "-o", out, src}); err != nil {
+			t.Fatalf("%s: compile failed: %v", level, err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("%s: reading bytecode: %v", level, err)
+		}
+		program, err := vm.DeserializeProgram(data)
+		if err != nil {
+			t.Fatalf("%s: deserializing: %v", level, err)
+		}
+		counts[level] = len(program.Code)
+	}
+
+	if counts["-O0"] != 12 {
+		t.Errorf("expected -O0 to keep all 12 instructions, got %d", counts["-O0"])
+	}
+	if counts["-O1"] >= counts["-O0"] {
+		t.Errorf("expected -O1 to remove instructions: %v", counts)
+	}
+	if counts["-O2"] >= counts["-O1"] {
+		t.Errorf("expected -O2 to remove the repeated SELECT_COL that -O1 keeps: %v", counts)
+	}
+	if counts["-O"] != counts["-O2"] {
+		t.Errorf("expected -O to match -O2: %v", counts)
+	}
+
+	out := filepath.Join(tmpDir, "mixed.dfbc")
+	if err := compileCommand([]string{"-O", "-O2", "-o", out, src}); err != nil {
+		t.Errorf("-O -O2: expected the same tier to be accepted, got %v", err)
+	}
+	for _, flags := range [][]string{{"-O0", "-O2"}, {"-O1", "-O"}, {"-O0", "-O1"}} {
+		args := append(append([]string{}, flags...), "-o", out, src)
+		if err := compileCommand(args); err == nil || !strings.Contains(err.Error(), "conflicting optimization flags") {
+			t.Errorf("%v: expected a conflicting flags error, got %v", flags, err)
+		}
+	}
+}
+
+func TestOptimizationTiers_RunExecCheckBench(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "tiers.dasm")
+	err := os.WriteFile(src, []byte(`LOAD_CONST R0, 2
+LOAD_CONST R1, 3
+MUL_R R2, R0, R1
+MUL_R R3, R0, R1   ; same product
+ADD_R R4, R2, R3
+HALT R4`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	bytecode := filepath.Join(tmpDir, "tiers.dfbc")
+	if err := compileCommand([]string{"-o", bytecode, src}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	for _, level := range []string{"-O0", "-O1", "-O2", "-O"} {
+		if err := runCommand([]string{level, src}); err != nil {
+			t.Errorf("run %s: %v", level, err)
+		}
+		if err := execCommand([]string{level, bytecode}); err != nil {
+			t.Errorf("exec %s: %v", level, err)
+		}
+		if err := checkCommand([]string{level, src}); err != nil {
+			t.Errorf("check %s: %v", level, err)
+		}
+		if err := benchCommand([]string{"-n", "1", level, src}); err != nil {
+			t.Errorf("bench %s: %v", level, err)
+		}
+	}
+
+	conflicting := map[string]func([]string) error{
+		"run":   runCommand,
+		"exec":  execCommand,
+		"check": checkCommand,
+		"bench": benchCommand,
+	}
+	for name, command := range conflicting {
+		target := src
+		if name == "exec" {
+			target = bytecode
+		}
+		if err := command([]string{"-O0", "-O2", target}); err == nil || !strings.Contains(err.Error(), "conflicting optimization flags") {
+			t.Errorf("%s -O0 -O2: expected a conflicting flags error, got %v", name, err)
+		}
+	}
+
+	for level, want := range map[int]int{0: 6, 1: 2, 2: 2} {
+		program, err := loadProgram(src, level)
+		if err != nil {
+			t.Fatalf("loadProgram -O%d: %v", level, err)
+		}
+		if len(program.Code) != want {
+			t.Errorf("loadProgram -O%d: expected %d instructions, got %d", level, want, len(program.Code))
+		}
+	}
+}
+
+func TestCompileCommand_EmitAsm(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "fold.dasm")
+	err := os.WriteFile(src, []byte(`LOAD_CONST R0, 10
+LOAD_CONST R1, 20
+LOAD_CONST R2, 5   ; dead
+ADD_R R3, R0, R1
+HALT R3`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Default output is fold.dfbc, so the assembly must not overwrite fold.dasm
+	if err := compileCommand([]string{"-O", "-emit-asm", src}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "fold.out.dasm"))
+	if err != nil {
+		t.Fatalf("reading emitted assembly: %v", err)
+	}
+	asm := string(data)
+	if !strings.Contains(asm, "LOAD_CONST     R3, 30") {
+		t.Errorf("expected folded constant in emitted assembly, got:\n%s", asm)
+	}
+	if strings.Contains(asm, "ADD_R") || strings.Contains(asm, ", 5\n") {
+		t.Errorf("expected ADD_R and dead load to be optimized away, got:\n%s", asm)
+	}
+	if source, _ := os.ReadFile(src); !strings.Contains(string(source), "ADD_R") {
+		t.Error("source file was overwritten")
+	}
+
+	// An explicit output name places the assembly next to it
+	out := filepath.Join(tmpDir, "plain.dfbc")
+	if err := compileCommand([]string{"-emit-asm", "-o", out, src}); err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(tmpDir, "plain.dasm")); err != nil || !strings.Contains(string(data), "ADD_R") {
+		t.Errorf("expected unoptimized assembly in plain.dasm, got %q (err %v)", data, err)
+	}
+}
+
 func TestCLI_UnknownCommand(t *testing.T) {
 	binary := buildDasm(t)
 
@@ -321,3 +884,39 @@ HALT R0
 		t.Errorf("expected 42, got: %s", result)
 	}
 }
+
+func TestTopN_ExampleProducts(t *testing.T) {
+	program, err := compiler.Compile(`
+LOAD_FRAME    R0, "products"
+TOP_N         R1, R0, "price", 2, "desc"
+SELECT_COL    V0, R1, "name"
+HALT_V        V0
+`)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	v := vm.NewVM()
+	v.SetPredeclaredFrames(loadExampleFrames())
+	if err := v.Load(program); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	result, err := v.Execute()
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	names, ok := result.(dataframe.Series)
+	if !ok {
+		t.Fatalf("expected a series, got %T", result)
+	}
+	want := []string{"Gizmo", "Gadget"}
+	if names.NRows() != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), names.NRows())
+	}
+	for i, w := range want {
+		if got := names.Value(i); got != w {
+			t.Errorf("row %d: expected %q, got %v", i, w, got)
+		}
+	}
+}
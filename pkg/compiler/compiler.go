@@ -2,12 +2,19 @@ package compiler
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/akhildatla/dasm/pkg/vm"
 )
 
-// Compile compiles DFL assembly source code to bytecode.
+func init() {
+	vm.RegisterAssembler(Compile)
+}
+
+// Compile compiles DFL assembly source code to bytecode. It is also what
+// vm.Assemble runs.
 func Compile(source string) (*vm.Program, error) {
 	parser := NewParser(source)
 	asmProgram, err := parser.Parse()
@@ -15,15 +22,17 @@ func Compile(source string) (*vm.Program, error) {
 		return nil, err
 	}
 
-	compiler := &Compiler{
+	return newCompiler().compile(asmProgram)
+}
+
+func newCompiler() *Compiler {
+	return &Compiler{
 		constants:      []any{},
 		floatConstants: []float64{},
 		code:           []vm.Instruction{},
 		constIndex:     make(map[any]uint16),
-		floatIndex:     make(map[float64]uint16),
+		floatIndex:     make(map[uint64]uint16),
 	}
-
-	return compiler.compile(asmProgram)
 }
 
 // Compiler compiles parsed assembly to bytecode.
@@ -31,16 +40,29 @@ type Compiler struct {
 	constants      []any
 	floatConstants []float64
 	code           []vm.Instruction
-	constIndex     map[any]uint16
-	floatIndex     map[float64]uint16
+	constIndex     map[any]uint16    // first slot of each value, keyed by constKey
+	floatIndex     map[uint64]uint16 // first slot of each value, keyed by math.Float64bits
+	wide           uint16            // WIDE prefix for the instruction being compiled
 }
 
 func (c *Compiler) compile(program *AsmProgram) (*vm.Program, error) {
+	// Directives seed the constant pools before any instruction adds to them,
+	// so explicitly declared constants get the lowest indices.
+	for _, dir := range program.Directives {
+		if err := c.compileDirective(dir); err != nil {
+			return nil, fmt.Errorf("line %d: %w", dir.Line, err)
+		}
+	}
+
 	for _, inst := range program.Instructions {
 		bytecode, err := c.compileInstruction(inst)
 		if err != nil {
 			return nil, fmt.Errorf("line %d: %w", inst.Line, err)
 		}
+		if c.wide != 0 {
+			c.code = append(c.code, vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, c.wide))
+			c.wide = 0
+		}
 		c.code = append(c.code, bytecode)
 	}
 
@@ -51,6 +73,40 @@ func (c *Compiler) compile(program *AsmProgram) (*vm.Program, error) {
 	}, nil
 }
 
+// compileDirective handles .float and .const, which declare float and general
+// constants in the order given, duplicates included, so the pools vm.Disassemble
+// declares reassemble slot for slot. Instructions using an equal value or run
+// of values share the first declared slot.
+func (c *Compiler) compileDirective(dir AsmInstruction) error {
+	switch strings.ToLower(dir.Opcode) {
+	case ".float":
+		for _, op := range dir.Operands {
+			if op.Type != OperandInt && op.Type != OperandFloat {
+				return fmt.Errorf(".float values must be numbers")
+			}
+			c.appendFloatConstants(operandFloat(op))
+		}
+
+	case ".const":
+		for _, op := range dir.Operands {
+			switch op.Type {
+			case OperandInt:
+				c.appendConstants(op.IntVal)
+			case OperandFloat:
+				c.appendConstants(op.FloatVal)
+			case OperandString:
+				c.appendConstants(op.StrVal)
+			default:
+				return fmt.Errorf(".const values must be literals")
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown directive: %s", dir.Opcode)
+	}
+	return nil
+}
+
 func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, error) {
 	opcode, ok := vm.OpcodeFromString(strings.ToUpper(inst.Opcode))
 	if !ok {
@@ -68,35 +124,54 @@ func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, erro
 	case vm.OpLoadConstF:
 		return c.compileLoadConstF(inst)
 
+	case vm.OpLoadConstStr:
+		return c.compileLoadConstStr(inst)
+
 	case vm.OpSelectCol:
 		return c.compileSelectCol(inst)
 
 	case vm.OpBroadcast, vm.OpBroadcastF:
 		return c.compileBroadcast(opcode, inst)
 
+	case vm.OpFillI, vm.OpFillF, vm.OpFillStr:
+		return c.compileFill(opcode, inst)
+
+	case vm.OpRange:
+		return c.compileRange(inst)
+
 	case vm.OpLoadFrame:
 		return c.compileRegStrOp(opcode, inst)
 
-	case vm.OpLoadJSON:
+	case vm.OpLoadJSON, vm.OpLoadJSONL, vm.OpLoadHTTP:
 		return c.compileRegStrOp(opcode, inst)
 
 	case vm.OpLoadParquet:
 		return c.compileRegStrOp(opcode, inst)
 
+	case vm.OpLoadCSVOpts:
+		return c.compileLoadCSVOpts(inst)
+
 	// ===== Vector Arithmetic =====
 	case vm.OpVecAddI, vm.OpVecSubI, vm.OpVecMulI, vm.OpVecDivI, vm.OpVecModI,
-		vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF:
+		vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF,
+		vm.OpVecMinI, vm.OpVecMaxI, vm.OpVecMinF, vm.OpVecMaxF:
 		return c.compileVecBinaryOp(opcode, inst)
 
 	// ===== Comparison =====
 	case vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE:
 		return c.compileVecBinaryOp(opcode, inst)
 
+	case vm.OpCmpEqEpsF:
+		return c.compileCmpEqEps(inst)
+
+	case vm.OpInSet:
+		return c.compileInSet(inst)
+
 	// ===== Logical =====
-	case vm.OpAnd, vm.OpOr:
+	case vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect:
 		return c.compileVecBinaryOp(opcode, inst)
 
-	case vm.OpNot:
+	case vm.OpNot, vm.OpVecNegF:
 		return c.compileVecUnaryOp(opcode, inst)
 
 	// ===== Filtering =====
@@ -105,16 +180,26 @@ func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, erro
 
 	// ===== Aggregations =====
 	case vm.OpReduceSum, vm.OpReduceSumF, vm.OpReduceCount,
-		vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceMinF, vm.OpReduceMaxF, vm.OpReduceMean:
+		vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceMinF, vm.OpReduceMaxF, vm.OpReduceMean,
+		vm.OpReduceAny, vm.OpReduceAll, vm.OpReduceProd, vm.OpReduceProdF:
 		return c.compileReduceOp(opcode, inst)
 
+	case vm.OpReduceQuantileF:
+		return c.compileReduceQuantile(inst)
+
+	case vm.OpCorrF, vm.OpCovF, vm.OpReduceWMeanF:
+		return c.compileVecBinaryOp(opcode, inst)
+
 	// ===== Scalar Operations =====
-	case vm.OpMoveR, vm.OpMoveF:
+	case vm.OpMoveR, vm.OpMoveF, vm.OpNegI, vm.OpNegF:
 		return c.compileScalarUnaryOp(opcode, inst)
 
 	case vm.OpAddR, vm.OpSubR, vm.OpMulR, vm.OpDivR:
 		return c.compileScalarBinaryOp(opcode, inst)
 
+	case vm.OpVecIndex, vm.OpVecIndexF:
+		return c.compileVecBinaryOp(opcode, inst)
+
 	// ===== Frame Operations =====
 	case vm.OpNewFrame:
 		return c.compileSingleRegOp(opcode, inst)
@@ -122,6 +207,18 @@ func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, erro
 	case vm.OpRowCount, vm.OpColCount:
 		return c.compileScalarUnaryOp(opcode, inst)
 
+	case vm.OpRowIndex:
+		return c.compileVecUnaryOp(opcode, inst)
+
+	case vm.OpTopN:
+		return c.compileTopN(inst)
+
+	case vm.OpSample:
+		return c.compileSample(inst)
+
+	case vm.OpShuffle:
+		return c.compileScalarUnaryOp(opcode, inst)
+
 	case vm.OpAddCol:
 		return c.compileAddCol(inst)
 
@@ -130,31 +227,109 @@ func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, erro
 		return c.compileGroupBy(inst)
 
 	case vm.OpGroupSum, vm.OpGroupSumF, vm.OpGroupMin, vm.OpGroupMax,
-		vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean:
+		vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean, vm.OpGroupFirst, vm.OpGroupLast, vm.OpGroupMedianF:
 		return c.compileGroupAgg(opcode, inst)
 
-	case vm.OpGroupCount, vm.OpGroupKeys:
+	case vm.OpGroupConcat:
+		return c.compileGroupConcat(inst)
+
+	case vm.OpGroupQuantileF:
+		return c.compileGroupQuantile(inst)
+
+	case vm.OpGroupCount, vm.OpGroupKeys, vm.OpGroupSizes:
 		return c.compileGroupUnary(opcode, inst)
 
 	// ===== Join Operations =====
-	case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter:
+	case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter, vm.OpJoinInnerSorted:
 		return c.compileJoin(opcode, inst)
 
+	case vm.OpJoinCross:
+		return c.compileScalarBinaryOp(opcode, inst)
+
 	// ===== String Operations =====
-	case vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTrim:
+	case vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTitle, vm.OpStrCapitalize,
+		vm.OpStrTrim, vm.OpStrTrimLeft, vm.OpStrTrimRight:
 		return c.compileVecUnaryOp(opcode, inst)
 
 	case vm.OpStrConcat:
 		return c.compileVecBinaryOp(opcode, inst)
 
-	case vm.OpStrContains, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace:
+	case vm.OpStrContains, vm.OpStrContainsCI, vm.OpStrRegexMatch, vm.OpStrRegexExtract, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace, vm.OpStrTrimChars:
+		return c.compileStrPatternOp(opcode, inst)
+
+	case vm.OpStrSubstr:
+		return c.compileStrSubstr(inst)
+
+	case vm.OpStrPadLeft, vm.OpStrPadRight:
+		return c.compileStrPad(opcode, inst)
+
+	case vm.OpFormatF:
+		return c.compileFormatF(inst)
+
+	// ===== Window Operations =====
+	case vm.OpShift:
+		return c.compileShift(inst)
+
+	case vm.OpBinF:
+		return c.compileBinF(inst)
+
+	case vm.OpRankF:
+		return c.compileRankF(inst)
+
+	case vm.OpClampF:
+		return c.compileClampF(inst)
+
+	// ===== Null Handling =====
+	case vm.OpFillNa:
+		return c.compileFillNa(inst)
+
+	case vm.OpCoalesce:
+		return c.compileVecBinaryOp(opcode, inst)
+
+	case vm.OpDropNa:
+		return c.compileDropNa(inst)
+
+	case vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF:
+		return c.compileVecUnaryOp(opcode, inst)
+
+	// ===== Hashing =====
+	case vm.OpHashStr:
+		return c.compileHashStr(inst)
+
+	// ===== Date Operations =====
+	case vm.OpParseDate:
 		return c.compileStrPatternOp(opcode, inst)
 
+	case vm.OpDatePart:
+		return c.compileDatePart(inst)
+
+	case vm.OpDateAddDays, vm.OpDateDiffDays:
+		return c.compileVecBinaryOp(opcode, inst)
+
+	// ===== Reshaping Operations =====
+	case vm.OpPivot:
+		return c.compilePivot(inst)
+
+	case vm.OpConcat:
+		return c.compileScalarBinaryOp(opcode, inst)
+
 	// ===== Control Flow =====
 	case vm.OpNop:
 		return vm.EncodeInstruction(opcode, 0, 0, 0, 0, 0), nil
 
-	case vm.OpHalt, vm.OpHaltF, vm.OpHaltV:
+	case vm.OpWide:
+		return 0, fmt.Errorf("WIDE is inserted by the assembler for constant indices above 255")
+
+	case vm.OpAssertEq:
+		return c.compileAssertEq(inst)
+
+	case vm.OpPrint:
+		return c.compilePrint(inst)
+
+	case vm.OpSetResult:
+		return c.compileSetResult(inst)
+
+	case vm.OpHalt, vm.OpHaltF, vm.OpHaltV, vm.OpHaltStr, vm.OpHaltFrame:
 		return c.compileSingleRegOp(opcode, inst)
 
 	default:
@@ -165,22 +340,107 @@ func (c *Compiler) compileInstruction(inst AsmInstruction) (vm.Instruction, erro
 // ===== Compile helpers =====
 
 func (c *Compiler) addConstant(value any) uint16 {
-	if idx, ok := c.constIndex[value]; ok {
+	if idx, ok := c.constIndex[constKey(value)]; ok {
 		return idx
 	}
+	return c.appendConstants(value)
+}
+
+// appendConstants appends values to the pool as they are, recording the
+// first slot of each for addConstant, and returns the index of the first.
+func (c *Compiler) appendConstants(values ...any) uint16 {
 	idx := uint16(len(c.constants))
-	c.constants = append(c.constants, value)
-	c.constIndex[value] = idx
+	for i, v := range values {
+		if _, ok := c.constIndex[constKey(v)]; !ok {
+			c.constIndex[constKey(v)] = idx + uint16(i)
+		}
+	}
+	c.constants = append(c.constants, values...)
 	return idx
 }
 
+// floatBits is the constKey of a float64: its bit pattern, so 0 and -0 get
+// separate slots and NaNs can share one.
+type floatBits uint64
+
+// constKey returns the key addConstant deduplicates value by. Keys differ in
+// type as well as value, so int64(2), 2.0 and "2" never share a slot.
+func constKey(value any) any {
+	if f, ok := value.(float64); ok {
+		return floatBits(math.Float64bits(f))
+	}
+	return value
+}
+
+// imm8Index returns the low byte of a constant index the VM reads from
+// imm8. Higher bits are left in c.wide for compile to emit as a WIDE prefix.
+func (c *Compiler) imm8Index(idx uint16) uint16 {
+	c.wide = idx >> 8
+	return idx & 0xFF
+}
+
+// addConstantRun returns the index of a contiguous block holding values, for
+// instructions that address several constants from a single base index. The
+// block is the first place in the pool the values fit, appending whatever
+// part of them runs past its end.
+func (c *Compiler) addConstantRun(values ...any) uint16 {
+	n := len(c.constants)
+	idx := runStart(n, len(values), func(i, j int) bool {
+		return constKey(c.constants[i]) == constKey(values[j])
+	})
+	if idx+len(values) > n {
+		c.appendConstants(values[n-idx:]...)
+	}
+	return uint16(idx)
+}
+
+// addFloatConstantRun is the float constant counterpart of addConstantRun.
+func (c *Compiler) addFloatConstantRun(values ...float64) uint16 {
+	n := len(c.floatConstants)
+	idx := runStart(n, len(values), func(i, j int) bool {
+		return math.Float64bits(c.floatConstants[i]) == math.Float64bits(values[j])
+	})
+	if idx+len(values) > n {
+		c.appendFloatConstants(values[n-idx:]...)
+	}
+	return uint16(idx)
+}
+
+// runStart returns the first index at which a run of m values fits a pool of
+// n entries, where eq compares pool entry i with value j: either wholly within
+// the pool or with a prefix matching the pool's tail. It returns n when the
+// run must be appended whole. Taking the first fit makes a run resolve to the
+// same slot whether the pool holds only what came before it or, as when a
+// disassembled program is reassembled, everything.
+func runStart(n, m int, eq func(i, j int) bool) int {
+	for idx := 0; idx < n; idx++ {
+		j := 0
+		for j < m && idx+j < n && eq(idx+j, j) {
+			j++
+		}
+		if j == m || idx+j == n {
+			return idx
+		}
+	}
+	return n
+}
+
 func (c *Compiler) addFloatConstant(value float64) uint16 {
-	if idx, ok := c.floatIndex[value]; ok {
+	if idx, ok := c.floatIndex[math.Float64bits(value)]; ok {
 		return idx
 	}
+	return c.appendFloatConstants(value)
+}
+
+// appendFloatConstants is the float constant counterpart of appendConstants.
+func (c *Compiler) appendFloatConstants(values ...float64) uint16 {
 	idx := uint16(len(c.floatConstants))
-	c.floatConstants = append(c.floatConstants, value)
-	c.floatIndex[value] = idx
+	for i, f := range values {
+		if _, ok := c.floatIndex[math.Float64bits(f)]; !ok {
+			c.floatIndex[math.Float64bits(f)] = idx + uint16(i)
+		}
+	}
+	c.floatConstants = append(c.floatConstants, values...)
 	return idx
 }
 
@@ -196,6 +456,20 @@ func (c *Compiler) compileRegStrOp(opcode vm.Opcode, inst AsmInstruction) (vm.In
 	return vm.EncodeInstruction(opcode, 0, dst, 0, 0, constIdx), nil
 }
 
+// LOAD_CSV_OPTS R[dst], "path", "delim=; header=false"
+func (c *Compiler) compileLoadCSVOpts(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum
+	path := inst.Operands[1].StrVal
+	spec := inst.Operands[2].StrVal
+	constIdx := c.addConstantRun(path, spec)
+
+	return vm.EncodeInstruction(vm.OpLoadCSVOpts, 0, dst, 0, 0, constIdx), nil
+}
+
 func (c *Compiler) compileLoadConst(inst AsmInstruction) (vm.Instruction, error) {
 	if len(inst.Operands) < 2 {
 		return 0, fmt.Errorf("expected 2 operands, got %d", len(inst.Operands))
@@ -208,13 +482,28 @@ func (c *Compiler) compileLoadConst(inst AsmInstruction) (vm.Instruction, error)
 	return vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx), nil
 }
 
+// LOAD_CONST_STR R[dst], "text"
+func (c *Compiler) compileLoadConstStr(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected 2 operands, got %d", len(inst.Operands))
+	}
+	if inst.Operands[1].Type != OperandString {
+		return 0, fmt.Errorf("LOAD_CONST_STR requires a string literal")
+	}
+
+	dst := inst.Operands[0].RegNum
+	constIdx := c.addConstant(inst.Operands[1].StrVal)
+
+	return vm.EncodeInstruction(vm.OpLoadConstStr, 0, dst, 0, 0, constIdx), nil
+}
+
 func (c *Compiler) compileLoadConstF(inst AsmInstruction) (vm.Instruction, error) {
 	if len(inst.Operands) < 2 {
 		return 0, fmt.Errorf("expected 2 operands, got %d", len(inst.Operands))
 	}
 
 	dst := inst.Operands[0].RegNum
-	floatVal := inst.Operands[1].FloatVal
+	floatVal := operandFloat(inst.Operands[1])
 	constIdx := c.addFloatConstant(floatVal)
 
 	return vm.EncodeInstruction(vm.OpLoadConstF, 0, dst, 0, 0, constIdx), nil
@@ -228,7 +517,7 @@ func (c *Compiler) compileSelectCol(inst AsmInstruction) (vm.Instruction, error)
 	dst := inst.Operands[0].RegNum // V register
 	src := inst.Operands[1].RegNum // R register (frame)
 	colName := inst.Operands[2].StrVal
-	constIdx := c.addConstant(colName)
+	constIdx := c.imm8Index(c.addConstant(colName)) // Use Imm8 since Src1 is used
 
 	return vm.EncodeInstruction(vm.OpSelectCol, 0, dst, src, 0, constIdx), nil
 }
@@ -245,6 +534,77 @@ func (c *Compiler) compileBroadcast(opcode vm.Opcode, inst AsmInstruction) (vm.I
 	return vm.EncodeInstruction(opcode, 0, dst, src, lenSrc, 0), nil
 }
 
+// compileFill compiles FILL_I V1, 42, 5 and FILL_I V1, 42, V0. The value
+// and an immediate length go in a constant run addressed by imm8; a vector
+// length sets vm.FillLikeVector and reads its row count from Src1.
+func (c *Compiler) compileFill(opcode vm.Opcode, inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+
+	var value any
+	switch op := inst.Operands[1]; {
+	case opcode == vm.OpFillI && op.Type == OperandInt:
+		value = op.IntVal
+	case opcode == vm.OpFillF && op.Type == OperandFloat:
+		value = op.FloatVal
+	case opcode == vm.OpFillF && op.Type == OperandInt:
+		value = float64(op.IntVal)
+	case opcode == vm.OpFillStr && op.Type == OperandString:
+		value = op.StrVal
+	default:
+		return 0, fmt.Errorf("invalid fill value for %s", inst.Opcode)
+	}
+
+	var modifier, src uint8
+	var constIdx uint16
+	switch op := inst.Operands[2]; op.Type {
+	case OperandInt:
+		if op.IntVal < 0 {
+			return 0, fmt.Errorf("fill length must be non-negative, got %d", op.IntVal)
+		}
+		constIdx = c.addConstantRun(value, op.IntVal)
+	case OperandRegV:
+		modifier, src = vm.FillLikeVector, op.RegNum
+		constIdx = c.addConstant(value)
+	default:
+		return 0, fmt.Errorf("fill length must be an integer or a vector register")
+	}
+
+	// Use Imm8 encoding since Src1 may be used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(opcode, modifier, dst, src, 0, constIdx), nil
+}
+
+// compileRange compiles RANGE V1, 0, 10 and RANGE V1, 0, 10, 2. The step
+// defaults to 1.
+func (c *Compiler) compileRange(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected at least 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	bounds := []any{int64(0), int64(0), int64(1)}
+	for i, op := range inst.Operands[1:min(len(inst.Operands), 4)] {
+		if op.Type != OperandInt {
+			return 0, fmt.Errorf("range bounds and step must be integers")
+		}
+		bounds[i] = op.IntVal
+	}
+	if bounds[2] == int64(0) {
+		return 0, fmt.Errorf("range step must be non-zero")
+	}
+	constIdx := c.addConstantRun(bounds...)
+
+	// The VM reads the index from Imm8
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpRange, 0, dst, 0, 0, constIdx), nil
+}
+
 func (c *Compiler) compileVecBinaryOp(opcode vm.Opcode, inst AsmInstruction) (vm.Instruction, error) {
 	if len(inst.Operands) < 3 {
 		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
@@ -279,6 +639,25 @@ func (c *Compiler) compileReduceOp(opcode vm.Opcode, inst AsmInstruction) (vm.In
 	return vm.EncodeInstruction(opcode, 0, dst, src, 0, 0), nil
 }
 
+func (c *Compiler) compileReduceQuantile(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // F register
+	src := inst.Operands[1].RegNum // V register
+	q := operandFloat(inst.Operands[2])
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile %g out of range [0, 1]", q)
+	}
+	constIdx := c.addFloatConstant(q)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpReduceQuantileF, 0, dst, src, 0, constIdx), nil
+}
+
 func (c *Compiler) compileScalarUnaryOp(opcode vm.Opcode, inst AsmInstruction) (vm.Instruction, error) {
 	if len(inst.Operands) < 2 {
 		return 0, fmt.Errorf("expected 2 operands, got %d", len(inst.Operands))
@@ -324,9 +703,7 @@ func (c *Compiler) compileAddCol(inst AsmInstruction) (vm.Instruction, error) {
 	constIdx := c.addConstant(colName)
 
 	// Use Imm8 encoding since Src1 is used
-	if constIdx > 255 {
-		return 0, fmt.Errorf("constant index %d exceeds 8-bit limit", constIdx)
-	}
+	constIdx = c.imm8Index(constIdx)
 
 	return vm.EncodeInstruction(vm.OpAddCol, 0, dst, src, 0, constIdx), nil
 }
@@ -356,7 +733,7 @@ func (c *Compiler) compileGroupAgg(opcode vm.Opcode, inst AsmInstruction) (vm.In
 	return vm.EncodeInstruction(opcode, 0, dst, gbSrc, valSrc, 0), nil
 }
 
-// GROUP_COUNT V[dst], R[src] or GROUP_KEYS V[dst], R[src]
+// GROUP_COUNT V[dst], R[src], GROUP_KEYS V[dst], R[src] or GROUP_SIZES R[dst], R[src]
 func (c *Compiler) compileGroupUnary(opcode vm.Opcode, inst AsmInstruction) (vm.Instruction, error) {
 	if len(inst.Operands) < 2 {
 		return 0, fmt.Errorf("expected 2 operands, got %d", len(inst.Operands))
@@ -381,9 +758,7 @@ func (c *Compiler) compileJoin(opcode vm.Opcode, inst AsmInstruction) (vm.Instru
 	constIdx := c.addConstant(keyName)
 
 	// Use Imm8 encoding since Src1 and Src2 are used
-	if constIdx > 255 {
-		return 0, fmt.Errorf("constant index %d exceeds 8-bit limit", constIdx)
-	}
+	constIdx = c.imm8Index(constIdx)
 
 	return vm.EncodeInstruction(opcode, 0, dst, src1, src2, constIdx), nil
 }
@@ -400,9 +775,529 @@ func (c *Compiler) compileStrPatternOp(opcode vm.Opcode, inst AsmInstruction) (v
 	constIdx := c.addConstant(pattern)
 
 	// Use Imm8 encoding since Src1 is used
-	if constIdx > 255 {
-		return 0, fmt.Errorf("constant index %d exceeds 8-bit limit", constIdx)
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(opcode, 0, dst, src, 0, constIdx), nil
+}
+
+// compileFormatF compiles FORMAT_F V1, V0, 2 (decimal places) and
+// FORMAT_F V1, V0, "%.2f" (printf format).
+func (c *Compiler) compileFormatF(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	var constIdx uint16
+	switch op := inst.Operands[2]; op.Type {
+	case OperandInt:
+		if op.IntVal < 0 {
+			return 0, fmt.Errorf("decimal places must be non-negative, got %d", op.IntVal)
+		}
+		constIdx = c.addConstant(op.IntVal)
+	case OperandString:
+		constIdx = c.addConstant(op.StrVal)
+	default:
+		return 0, fmt.Errorf("format must be a decimal count or a format string")
+	}
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpFormatF, 0, dst, src, 0, constIdx), nil
+}
+
+// compileHashStr compiles HASH_STR V1, V0 and HASH_STR V1, V0, buckets.
+func (c *Compiler) compileHashStr(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected at least 2 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	// Bucket count is stored in Imm8 since Src1 is used; 0 keeps the full hash
+	var buckets int64
+	if len(inst.Operands) > 2 {
+		buckets = inst.Operands[2].IntVal
+		if buckets < 1 || buckets > 255 {
+			return 0, fmt.Errorf("bucket count %d out of range [1, 255]", buckets)
+		}
+	}
+
+	return vm.EncodeInstruction(vm.OpHashStr, 0, dst, src, 0, uint16(buckets)), nil
+}
+
+// compileDatePart compiles DATE_PART V1, V0, "year" (or "month", "day",
+// "hour").
+func (c *Compiler) compileDatePart(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	var part uint8
+	switch inst.Operands[2].StrVal {
+	case "year":
+		part = vm.DatePartYear
+	case "month":
+		part = vm.DatePartMonth
+	case "day":
+		part = vm.DatePartDay
+	case "hour":
+		part = vm.DatePartHour
+	default:
+		return 0, fmt.Errorf("unknown date part: %q", inst.Operands[2].StrVal)
+	}
+
+	return vm.EncodeInstruction(vm.OpDatePart, 0, dst, src, 0, uint16(part)), nil
+}
+
+func (c *Compiler) compileShift(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+	n := inst.Operands[2].IntVal
+
+	// Offset is stored as a signed Imm8 since Src1 is used
+	if n < -128 || n > 127 {
+		return 0, fmt.Errorf("shift offset %d out of range [-128, 127]", n)
 	}
 
+	return vm.EncodeInstruction(vm.OpShift, 0, dst, src, 0, uint16(uint8(int8(n)))), nil
+}
+
+// compileBinF compiles BIN_F V1, V0, nbins [, min, max]. Without explicit
+// bounds the range is taken from the data at runtime (stored as NaN).
+func (c *Compiler) compileBinF(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) != 3 && len(inst.Operands) != 5 {
+		return 0, fmt.Errorf("expected 3 or 5 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+	nbins := inst.Operands[2].IntVal
+	if nbins < 1 {
+		return 0, fmt.Errorf("bin count must be positive, got %d", nbins)
+	}
+
+	lo, hi := math.NaN(), math.NaN()
+	if len(inst.Operands) == 5 {
+		lo, hi = operandFloat(inst.Operands[3]), operandFloat(inst.Operands[4])
+		if hi < lo {
+			return 0, fmt.Errorf("bin range max %g is less than min %g", hi, lo)
+		}
+	}
+
+	constIdx := c.addFloatConstantRun(float64(nbins), lo, hi)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpBinF, 0, dst, src, 0, constIdx), nil
+}
+
+// compileClampF compiles CLAMP_F V1, V0, lo, hi.
+func (c *Compiler) compileClampF(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+	lo, hi := operandFloat(inst.Operands[2]), operandFloat(inst.Operands[3])
+	if hi < lo {
+		return 0, fmt.Errorf("clamp upper bound %g is less than lower bound %g", hi, lo)
+	}
+
+	constIdx := c.addFloatConstantRun(lo, hi)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpClampF, 0, dst, src, 0, constIdx), nil
+}
+
+// operandFloat returns a numeric operand as float64, accepting int literals.
+func operandFloat(op Operand) float64 {
+	if op.Type == OperandInt {
+		return float64(op.IntVal)
+	}
+	return op.FloatVal
+}
+
+// compileStrSubstr compiles STR_SUBSTR V1, V0, start, length.
+func (c *Compiler) compileStrSubstr(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+	start, length := inst.Operands[2].IntVal, inst.Operands[3].IntVal
+	if length < 0 {
+		return 0, fmt.Errorf("substring length must be non-negative, got %d", length)
+	}
+	constIdx := c.addConstantRun(start, length)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpStrSubstr, 0, dst, src, 0, constIdx), nil
+}
+
+// compileStrPad compiles STR_PAD_LEFT/STR_PAD_RIGHT V1, V0, width, "c".
+func (c *Compiler) compileStrPad(opcode vm.Opcode, inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+	width, pad := inst.Operands[2].IntVal, inst.Operands[3].StrVal
+	if utf8.RuneCountInString(pad) != 1 {
+		return 0, fmt.Errorf("pad must be a single character, got %q", pad)
+	}
+	constIdx := c.addConstantRun(width, pad)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
 	return vm.EncodeInstruction(opcode, 0, dst, src, 0, constIdx), nil
 }
+
+// compileGroupConcat compiles GROUP_CONCAT V1, R1, V0, "sep".
+func (c *Compiler) compileGroupConcat(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum  // Result vector register
+	gb := inst.Operands[1].RegNum   // GroupBy result register
+	vals := inst.Operands[2].RegNum // Value column register
+	constIdx := c.addConstant(inst.Operands[3].StrVal)
+
+	// Use Imm8 encoding since Src1 and Src2 are used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpGroupConcat, 0, dst, gb, vals, constIdx), nil
+}
+
+// compileGroupQuantile compiles GROUP_QUANTILE_F V1, R1, V0, q.
+func (c *Compiler) compileGroupQuantile(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum  // Result vector register
+	gb := inst.Operands[1].RegNum   // GroupBy result register
+	vals := inst.Operands[2].RegNum // Value column register
+	q := operandFloat(inst.Operands[3])
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile %g out of range [0, 1]", q)
+	}
+	constIdx := c.addFloatConstant(q)
+
+	// Use Imm8 encoding since Src1 and Src2 are used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpGroupQuantileF, 0, dst, gb, vals, constIdx), nil
+}
+
+// compileInSet compiles IN_SET V1, V0, value, ... The values are stored as a
+// constant run prefixed with their count.
+func (c *Compiler) compileInSet(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected at least 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	run := []any{int64(len(inst.Operands) - 2)}
+	for _, op := range inst.Operands[2:] {
+		switch op.Type {
+		case OperandInt:
+			run = append(run, op.IntVal)
+		case OperandFloat:
+			run = append(run, op.FloatVal)
+		case OperandString:
+			run = append(run, op.StrVal)
+		default:
+			return 0, fmt.Errorf("set values must be literals")
+		}
+	}
+	constIdx := c.addConstantRun(run...)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpInSet, 0, dst, src, 0, constIdx), nil
+}
+
+// compileCmpEqEps compiles CMP_EQ_EPS_F V2, V0, V1, eps.
+func (c *Compiler) compileCmpEqEps(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum  // Result vector register
+	src1 := inst.Operands[1].RegNum // Left vector register
+	src2 := inst.Operands[2].RegNum // Right vector register
+	eps := operandFloat(inst.Operands[3])
+	if eps < 0 {
+		return 0, fmt.Errorf("epsilon %g must not be negative", eps)
+	}
+	constIdx := c.addFloatConstant(eps)
+
+	// Use Imm8 encoding since Src1 and Src2 are used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpCmpEqEpsF, 0, dst, src1, src2, constIdx), nil
+}
+
+// compileRankF compiles RANK_F V1, V0 [, "average"|"min"|"dense" [, "asc"|"desc"]].
+func (c *Compiler) compileRankF(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected at least 2 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	flags := vm.RankAverage
+	if len(inst.Operands) > 2 {
+		switch inst.Operands[2].StrVal {
+		case "average":
+			flags = vm.RankAverage
+		case "min":
+			flags = vm.RankMin
+		case "dense":
+			flags = vm.RankDense
+		default:
+			return 0, fmt.Errorf("unknown rank tie strategy: %q", inst.Operands[2].StrVal)
+		}
+	}
+	if len(inst.Operands) > 3 {
+		switch inst.Operands[3].StrVal {
+		case "asc":
+		case "desc":
+			flags |= vm.RankDescending
+		default:
+			return 0, fmt.Errorf("unknown rank order: %q", inst.Operands[3].StrVal)
+		}
+	}
+
+	return vm.EncodeInstruction(vm.OpRankF, 0, dst, src, 0, uint16(flags)), nil
+}
+
+// compileAssertEq compiles ASSERT_EQ R1, R2 (frames), ASSERT_EQ R1, R2, "int",
+// ASSERT_EQ F1, F2 or ASSERT_EQ V1, V2.
+func (c *Compiler) compileAssertEq(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected at least 2 operands, got %d", len(inst.Operands))
+	}
+
+	a, b := inst.Operands[0], inst.Operands[1]
+	if a.Type != b.Type {
+		return 0, fmt.Errorf("ASSERT_EQ operands must be the same register type")
+	}
+	kind, err := operandKind(a, inst.Operands[2:])
+	if err != nil {
+		return 0, err
+	}
+
+	return vm.EncodeInstruction(vm.OpAssertEq, 0, 0, a.RegNum, b.RegNum, uint16(kind)), nil
+}
+
+// compilePrint compiles PRINT R1 (frame), PRINT R1, "int", PRINT F1 or
+// PRINT V1.
+func (c *Compiler) compilePrint(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 1 {
+		return 0, fmt.Errorf("expected at least 1 operand, got %d", len(inst.Operands))
+	}
+
+	src := inst.Operands[0]
+	kind, err := operandKind(src, inst.Operands[1:])
+	if err != nil {
+		return 0, err
+	}
+
+	return vm.EncodeInstruction(vm.OpPrint, 0, 0, src.RegNum, 0, uint16(kind)), nil
+}
+
+// compileSetResult compiles SET_RESULT "name", R1 (frame),
+// SET_RESULT "name", R1, "int", SET_RESULT "name", F1 or
+// SET_RESULT "name", V1.
+func (c *Compiler) compileSetResult(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected at least 2 operands, got %d", len(inst.Operands))
+	}
+	if inst.Operands[0].Type != OperandString {
+		return 0, fmt.Errorf("result name must be a string")
+	}
+
+	src := inst.Operands[1]
+	kind, err := operandKind(src, inst.Operands[2:])
+	if err != nil {
+		return 0, err
+	}
+
+	constIdx := c.addConstant(inst.Operands[0].StrVal)
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpSetResult, kind, 0, src.RegNum, 0, constIdx), nil
+}
+
+// operandKind returns the vm.Reg* kind for a register operand. R registers
+// hold frames unless followed by the string "int".
+func operandKind(reg Operand, rest []Operand) (uint8, error) {
+	switch reg.Type {
+	case OperandRegR:
+		if len(rest) == 0 {
+			return vm.RegFrame, nil
+		}
+		if rest[0].StrVal != "int" {
+			return 0, fmt.Errorf("unknown register kind: %q", rest[0].StrVal)
+		}
+		return vm.RegInt, nil
+	case OperandRegF:
+		return vm.RegFloat, nil
+	case OperandRegV:
+		return vm.RegVector, nil
+	}
+	return 0, fmt.Errorf("operand must be a register")
+}
+
+// compileFillNa compiles FILL_NA V1, V0, value where value is an int, float
+// or string literal.
+func (c *Compiler) compileFillNa(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result vector register
+	src := inst.Operands[1].RegNum // Input vector register
+
+	var constIdx uint16
+	switch op := inst.Operands[2]; op.Type {
+	case OperandInt:
+		constIdx = c.addConstant(op.IntVal)
+	case OperandFloat:
+		constIdx = c.addConstant(op.FloatVal)
+	case OperandString:
+		constIdx = c.addConstant(op.StrVal)
+	default:
+		return 0, fmt.Errorf("fill value must be a literal")
+	}
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpFillNa, 0, dst, src, 0, constIdx), nil
+}
+
+// compileDropNa compiles DROP_NA R1, R0 [, "col", ...]. The column names are
+// stored as a constant run prefixed with their count.
+func (c *Compiler) compileDropNa(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 2 {
+		return 0, fmt.Errorf("expected at least 2 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result frame register
+	src := inst.Operands[1].RegNum // Source frame register
+
+	run := []any{int64(len(inst.Operands) - 2)}
+	for _, op := range inst.Operands[2:] {
+		if op.Type != OperandString {
+			return 0, fmt.Errorf("column names must be strings")
+		}
+		run = append(run, op.StrVal)
+	}
+	constIdx := c.addConstantRun(run...)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpDropNa, 0, dst, src, 0, constIdx), nil
+}
+
+// compileTopN compiles TOP_N R1, R0, "column", n [, "asc"|"desc"]. The order
+// defaults to "desc" so the largest values come first.
+func (c *Compiler) compileTopN(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 4 {
+		return 0, fmt.Errorf("expected at least 4 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result frame register
+	src := inst.Operands[1].RegNum // Source frame register
+	colName, n := inst.Operands[2].StrVal, inst.Operands[3].IntVal
+	if n < 0 {
+		return 0, fmt.Errorf("row count must be non-negative, got %d", n)
+	}
+	order := "desc"
+	if len(inst.Operands) > 4 {
+		order = inst.Operands[4].StrVal
+		if order != "asc" && order != "desc" {
+			return 0, fmt.Errorf("unknown sort order: %q", order)
+		}
+	}
+	constIdx := c.addConstantRun(colName, n, order)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpTopN, 0, dst, src, 0, constIdx), nil
+}
+
+// compileSample compiles SAMPLE R1, R0, 10 and SAMPLE R1, R0, 10, 42, where
+// the optional last operand fixes the seed for this instruction.
+func (c *Compiler) compileSample(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 3 {
+		return 0, fmt.Errorf("expected at least 3 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result frame register
+	src := inst.Operands[1].RegNum // Source frame register
+	n := inst.Operands[2].IntVal
+	if n < 0 {
+		return 0, fmt.Errorf("row count must be non-negative, got %d", n)
+	}
+
+	var modifier uint8
+	var constIdx uint16
+	if len(inst.Operands) > 3 {
+		modifier = vm.SampleSeeded
+		constIdx = c.addConstantRun(n, inst.Operands[3].IntVal)
+	} else {
+		constIdx = c.addConstant(n)
+	}
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpSample, modifier, dst, src, 0, constIdx), nil
+}
+
+// compilePivot compiles PIVOT R1, R0, "index", "key", "value".
+func (c *Compiler) compilePivot(inst AsmInstruction) (vm.Instruction, error) {
+	if len(inst.Operands) < 5 {
+		return 0, fmt.Errorf("expected 5 operands, got %d", len(inst.Operands))
+	}
+
+	dst := inst.Operands[0].RegNum // Result frame register
+	src := inst.Operands[1].RegNum // Source frame register
+	constIdx := c.addConstantRun(inst.Operands[2].StrVal, inst.Operands[3].StrVal, inst.Operands[4].StrVal)
+
+	// Use Imm8 encoding since Src1 is used
+	constIdx = c.imm8Index(constIdx)
+
+	return vm.EncodeInstruction(vm.OpPivot, 0, dst, src, 0, constIdx), nil
+}
@@ -1,8 +1,14 @@
 package compiler
 
 import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+
 	"github.com/akhildatla/dasm/pkg/vm"
 )
 
@@ -431,6 +437,130 @@ func TestCompiler_WhitespaceHandling(t *testing.T) {
 	}
 }
 
+func TestCompiler_DeduplicatesConstants(t *testing.T) {
+	input := `LOAD_CSV R0, "sales.csv"
+SELECT_COL V0, R0, "price"
+SELECT_COL V1, R0, "price"
+SELECT_COL V2, R0, "price"
+HALT_V V2`
+
+	program, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	idx := -1
+	for i, c := range program.Constants {
+		if c == "price" {
+			if idx >= 0 {
+				t.Fatalf("\"price\" appears more than once in %v", program.Constants)
+			}
+			idx = i
+		}
+	}
+	if idx < 0 {
+		t.Fatalf("\"price\" not found in %v", program.Constants)
+	}
+	for _, inst := range program.Code[1:4] {
+		if int(inst.Imm8()) != idx {
+			t.Errorf("%v references constant %d, want %d", inst, inst.Imm8(), idx)
+		}
+	}
+}
+
+func TestCompiler_DeduplicatesByTypeAndValue(t *testing.T) {
+	input := `LOAD_CONST R0, 2
+LOAD_CONST_STR R1, "2"
+LOAD_CONST_F F0, 0.0
+LOAD_CONST_F F1, -0.0
+LOAD_CONST_F F2, 0.0
+HALT R0`
+
+	program, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if len(program.Constants) != 2 {
+		t.Errorf("expected int64 2 and \"2\" in separate slots, got %v", program.Constants)
+	}
+	if len(program.FloatConstants) != 2 {
+		t.Fatalf("expected 0 and -0 in separate slots, got %v", program.FloatConstants)
+	}
+	if !math.Signbit(program.FloatConstants[1]) {
+		t.Errorf("expected float constant 1 = -0, got %v", program.FloatConstants[1])
+	}
+	if program.Code[4].Imm16() != program.Code[2].Imm16() {
+		t.Errorf("expected repeated 0.0 to reuse slot %d, got %d", program.Code[2].Imm16(), program.Code[4].Imm16())
+	}
+}
+
+func TestCompiler_WideConstantIndex(t *testing.T) {
+	// Fill slots 0-298 of both pools so the column name lands in slot 300
+	var src strings.Builder
+	for i := 0; i < 299; i++ {
+		fmt.Fprintf(&src, ".const %d\n.float %d.25\n", i, i)
+	}
+	src.WriteString(`LOAD_FRAME R0, "sales"
+SELECT_COL V0, R0, "price"
+REDUCE_QUANTILE_F F0, V0, 0.5
+HALT_F F0`)
+
+	program, err := Compile(src.String())
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if program.Constants[300] != "price" {
+		t.Fatalf("expected \"price\" in slot 300, got %v", program.Constants[300])
+	}
+	if program.FloatConstants[299] != 0.5 {
+		t.Fatalf("expected 0.5 in float slot 299, got %v", program.FloatConstants[299])
+	}
+
+	want := []vm.Instruction{
+		vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 299),
+		vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, 1),
+		vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 300&0xFF),
+		vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, 1),
+		vm.EncodeInstruction(vm.OpReduceQuantileF, 0, 0, 0, 0, 299&0xFF),
+		vm.EncodeInstruction(vm.OpHaltF, 0, 0, 0, 0, 0),
+	}
+	if !reflect.DeepEqual(program.Code, want) {
+		t.Errorf("unexpected code:\n%s", vm.Disassemble(program))
+	}
+	if err := vm.ValidateProgram(program); err != nil {
+		t.Fatalf("ValidateProgram failed: %v", err)
+	}
+
+	frames := map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5, 4.0)),
+	}
+	if got := runProgram(t, program, frames); got != 2.5 {
+		t.Errorf("expected median 2.5, got %v", got)
+	}
+
+	// Disassembly resolves wide indices and leaves WIDE as a comment
+	asm := vm.DisassembleAnnotated(program)
+	for _, want := range []string{"; WIDE", `SELECT_COL     V0, R0, "price"`, "const[300]", "fconst[299]"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in disassembly:\n%s", want, asm)
+		}
+	}
+	again, err := Compile(asm)
+	if err != nil {
+		t.Fatalf("reassembly failed: %v\n%s", err, asm)
+	}
+	if got := runProgram(t, again, frames); got != 2.5 {
+		t.Errorf("reassembled program: expected median 2.5, got %v", got)
+	}
+}
+
+func TestCompiler_WideRejected(t *testing.T) {
+	if _, err := Compile("WIDE 1\nHALT R0"); err == nil {
+		t.Error("expected an error for WIDE in source")
+	}
+}
+
 func TestCompiler_NegativeConstants(t *testing.T) {
 	input := `LOAD_CONST R0, -42
 HALT R0`
@@ -623,3 +753,513 @@ HALT_F F1`},
 		})
 	}
 }
+
+func TestCompiler_Shift(t *testing.T) {
+	program, err := Compile(`SHIFT V1, V0, -1
+HALT_V V1`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	inst := program.Code[0]
+	if inst.Opcode() != vm.OpShift {
+		t.Errorf("expected SHIFT, got %s", inst.Opcode())
+	}
+	if got := int8(inst.Imm8()); got != -1 {
+		t.Errorf("expected offset -1, got %d", got)
+	}
+
+	if _, err := Compile(`SHIFT V1, V0, 200`); err == nil {
+		t.Error("expected error for out-of-range offset")
+	}
+}
+
+func TestCompiler_ReduceQuantile(t *testing.T) {
+	program, err := Compile(`REDUCE_QUANTILE_F F0, V1, 0.95
+HALT_F F0`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	inst := program.Code[0]
+	if inst.Opcode() != vm.OpReduceQuantileF {
+		t.Errorf("expected REDUCE_QUANTILE_F, got %s", inst.Opcode())
+	}
+	if idx := inst.Imm8(); program.FloatConstants[idx] != 0.95 {
+		t.Errorf("expected quantile 0.95, got %v", program.FloatConstants[idx])
+	}
+
+	if _, err := Compile(`REDUCE_QUANTILE_F F0, V1, 1.5`); err == nil {
+		t.Error("expected error for quantile outside [0, 1]")
+	}
+}
+
+func TestCompiler_BinF(t *testing.T) {
+	program, err := Compile(`BIN_F V1, V0, 5, 0, 10.0
+HALT_V V1`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	base := program.Code[0].Imm8()
+	got := program.FloatConstants[base : base+3]
+	if got[0] != 5 || got[1] != 0 || got[2] != 10 {
+		t.Errorf("expected bin constants [5 0 10], got %v", got)
+	}
+
+	if _, err := Compile(`BIN_F V1, V0, 0`); err == nil {
+		t.Error("expected error for zero bin count")
+	}
+}
+
+func TestCompiler_FillNa(t *testing.T) {
+	program, err := Compile(`FILL_NA V1, V0, 0.5
+FILL_NA V2, V0, 7
+FILL_NA V3, V0, ""
+HALT_V V3`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	for i, want := range []any{0.5, int64(7), ""} {
+		inst := program.Code[i]
+		if inst.Opcode() != vm.OpFillNa {
+			t.Errorf("instruction %d: expected FILL_NA, got %s", i, inst.Opcode())
+		}
+		if got := program.Constants[inst.Imm8()]; got != want {
+			t.Errorf("instruction %d: expected fill value %#v, got %#v", i, want, got)
+		}
+	}
+}
+
+func TestCompiler_DropNa(t *testing.T) {
+	program, err := Compile(`DROP_NA R1, R0
+DROP_NA R2, R0, "price", "qty"
+HALT R2`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	all := program.Code[0].Imm8()
+	if got := program.Constants[all]; got != int64(0) {
+		t.Errorf("expected column count 0, got %v", got)
+	}
+
+	base := program.Code[1].Imm8()
+	got := program.Constants[base : base+3]
+	if got[0] != int64(2) || got[1] != "price" || got[2] != "qty" {
+		t.Errorf("expected [2 price qty], got %v", got)
+	}
+}
+
+func TestCompiler_InSet(t *testing.T) {
+	program, err := Compile(`IN_SET V1, V0, "A", "C", 3
+HALT_V V1`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	base := program.Code[0].Imm8()
+	got := program.Constants[base : base+4]
+	for i, want := range []any{int64(3), "A", "C", int64(3)} {
+		if got[i] != want {
+			t.Errorf("constant %d: expected %#v, got %#v", i, want, got[i])
+		}
+	}
+
+	if _, err := Compile(`IN_SET V1, V0`); err == nil {
+		t.Error("expected error for empty set")
+	}
+}
+
+func TestCompiler_StrPad(t *testing.T) {
+	program, err := Compile(`STR_PAD_LEFT V1, V0, 5, "0"
+HALT_V V1`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	base := program.Code[0].Imm8()
+	if program.Constants[base] != int64(5) || program.Constants[base+1] != "0" {
+		t.Errorf("expected pad constants [5 0], got %v", program.Constants[base:base+2])
+	}
+
+	if _, err := Compile(`STR_PAD_RIGHT V1, V0, 5, "ab"`); err == nil {
+		t.Error("expected error for multi-character pad")
+	}
+}
+
+func TestCompiler_RankF(t *testing.T) {
+	program, err := Compile(`RANK_F V1, V0
+RANK_F V2, V0, "dense", "desc"
+HALT_V V2`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if flags := program.Code[0].Imm8(); flags != vm.RankAverage {
+		t.Errorf("expected default flags %#x, got %#x", vm.RankAverage, flags)
+	}
+	if flags := program.Code[1].Imm8(); flags != vm.RankDense|vm.RankDescending {
+		t.Errorf("expected dense descending flags, got %#x", flags)
+	}
+
+	if _, err := Compile(`RANK_F V1, V0, "first"`); err == nil {
+		t.Error("expected error for unknown tie strategy")
+	}
+}
+
+func TestCompiler_TopN(t *testing.T) {
+	program, err := Compile(`TOP_N R1, R0, "price", 2
+TOP_N R2, R0, "price", 5, "asc"
+HALT R2`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	base := program.Code[0].Imm8()
+	if program.Constants[base] != "price" || program.Constants[base+1] != int64(2) || program.Constants[base+2] != "desc" {
+		t.Errorf("expected default desc constants, got %v", program.Constants[base:base+3])
+	}
+	base = program.Code[1].Imm8()
+	if program.Constants[base+2] != "asc" {
+		t.Errorf("expected asc order, got %v", program.Constants[base+2])
+	}
+
+	if _, err := Compile(`TOP_N R1, R0, "price", 2, "up"`); err == nil {
+		t.Error("expected error for unknown sort order")
+	}
+}
+
+func TestCompiler_GroupQuantileF(t *testing.T) {
+	program, err := Compile(`GROUP_QUANTILE_F V2, R1, V1, 0.9
+HALT_V V2`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if q := program.FloatConstants[program.Code[0].Imm8()]; q != 0.9 {
+		t.Errorf("expected quantile constant 0.9, got %v", q)
+	}
+
+	if _, err := Compile(`GROUP_QUANTILE_F V2, R1, V1, 1.5`); err == nil {
+		t.Error("expected error for quantile out of range")
+	}
+}
+
+func TestDisassemble_RoundTrip(t *testing.T) {
+	programs := map[string]string{
+		"filter_reduce": `LOAD_CSV      R0, "sales.csv"
+SELECT_COL    V0, R0, "price"
+SELECT_COL    V1, R0, "quantity"
+LOAD_CONST    R1, 10
+BROADCAST     V2, R1, V1
+CMP_GT        V3, V1, V2
+FILTER        V4, V0, V3
+REDUCE_SUM_F  F0, V4
+HALT_F        F0`,
+		"floats": `LOAD_FRAME R0, "data"
+SELECT_COL V0, R0, "x"
+LOAD_CONST_F F0, 2.5
+LOAD_CONST_F F1, 0.0000001
+LOAD_CONST_F F2, 123456789012345678901234.5
+LOAD_CONST_F F3, -3
+CLAMP_F V1, V0, -1.5, 1000
+BIN_F V2, V1, 5
+BIN_F V3, V1, 4, 0, 100
+REDUCE_QUANTILE_F F4, V1, 0.95
+FILL_NA V4, V0, 2.0
+HALT_F F4`,
+		"strings": `LOAD_CSV_OPTS R0, "C:\data\file.tsv", "delim=tab header=false"
+SELECT_COL V0, R0, "name"
+STR_REGEX_MATCH V1, V0, "^\d+\.\d*$"
+STR_REPLACE V2, V0, "old", "new"
+STR_PAD_RIGHT V3, V0, 4, "é"
+STR_SUBSTR V4, V0, 0, 4
+IN_SET V5, V0, "A", 2.0, 3
+FILL_NA V6, V0, ""
+LOAD_CONST_STR R1, "done"
+HALT_STR R1`,
+		"frames": `LOAD_FRAME R0, "left"
+LOAD_FRAME R1, "right"
+JOIN_INNER R2, R0, R1, "id"
+JOIN_LEFT R3, R0, R1, "id"
+DROP_NA R4, R3, "price", "qty"
+TOP_N R5, R4, "price", 3, "asc"
+PIVOT R6, R5, "region", "month", "sales"
+SAMPLE R7, R6, 2, 42
+SAMPLE R8, R7, 1
+SHUFFLE R9, R8
+SELECT_COL V0, R6, "price"
+RANK_F V1, V0, "dense", "desc"
+REDUCE_SUM_F F0, V0
+SET_RESULT "top", R5
+SET_RESULT "rows", R4, "int"
+SET_RESULT "total", F0
+SET_RESULT "ranks", V1
+HALT R6`,
+		"constructors": `RANGE V0, 10, 0, -2
+FILL_I V1, 7, V0
+FILL_F V2, 0.5, 3
+FILL_STR V3, "n/a", V0
+LOAD_CONST R1, 2
+VEC_INDEX R2, V0, R1
+VEC_INDEX_F F0, V2, R1
+NEG_I R3, R2
+NEG_F F1, F0
+VEC_NEG_F V4, V2
+HALT_V V3`,
+		"directives": `.float 1.5, 2.5, 1.5, NaN, -Inf
+.const "unused", 4, 2, "price", 4
+LOAD_CONST_F F0, 2.5
+LOAD_FRAME R0, "data"
+SELECT_COL V0, R0, "price"
+STR_SUBSTR V1, V0, 4, 2
+CLAMP_F V2, V0, 1.5, 2.5
+LOAD_CONST R1, 4
+HALT_F F0`,
+		"groups": `LOAD_FRAME R0, "data"
+SELECT_COL V0, R0, "category"
+SELECT_COL V1, R0, "amount"
+GROUP_BY R1, V0
+GROUP_CONCAT V2, R1, V0, ", "
+GROUP_QUANTILE_F V3, R1, V1, 0.9
+GROUP_KEYS V4, R1
+HALT_V V3`,
+	}
+
+	for name, src := range programs {
+		t.Run(name, func(t *testing.T) {
+			program, err := Compile(src)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+
+			asm := vm.Disassemble(program)
+			again, err := Compile(asm)
+			if err != nil {
+				t.Fatalf("reassembly failed: %v\n%s", err, asm)
+			}
+
+			if !reflect.DeepEqual(program.Code, again.Code) {
+				t.Errorf("code mismatch:\n%s", asm)
+			}
+			if !reflect.DeepEqual(program.Constants, again.Constants) {
+				t.Errorf("constants mismatch: %#v vs %#v", program.Constants, again.Constants)
+			}
+			if len(program.FloatConstants) != len(again.FloatConstants) {
+				t.Fatalf("float constants mismatch: %v vs %v", program.FloatConstants, again.FloatConstants)
+			}
+			for i, f := range program.FloatConstants {
+				if math.Float64bits(f) != math.Float64bits(again.FloatConstants[i]) {
+					t.Errorf("float constant %d: %v vs %v", i, f, again.FloatConstants[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompiler_OpcodeForms(t *testing.T) {
+	enc := vm.EncodeInstruction
+	nan := math.NaN()
+
+	tests := []struct {
+		src    string
+		want   vm.Instruction
+		consts []any
+		floats []float64
+	}{
+		// Data loading
+		{`LOAD_CSV R1, "a.csv"`, enc(vm.OpLoadCSV, 0, 1, 0, 0, 0), []any{"a.csv"}, nil},
+		{`LOAD_JSON R1, "a.json"`, enc(vm.OpLoadJSON, 0, 1, 0, 0, 0), []any{"a.json"}, nil},
+		{`LOAD_JSONL R1, "a.jsonl"`, enc(vm.OpLoadJSONL, 0, 1, 0, 0, 0), []any{"a.jsonl"}, nil},
+		{`LOAD_HTTP R1, "https://h/a.csv"`, enc(vm.OpLoadHTTP, 0, 1, 0, 0, 0), []any{"https://h/a.csv"}, nil},
+		{`LOAD_PARQUET R1, "a.parquet"`, enc(vm.OpLoadParquet, 0, 1, 0, 0, 0), []any{"a.parquet"}, nil},
+		{`LOAD_CSV_OPTS R1, "a.tsv", "delim=tab"`, enc(vm.OpLoadCSVOpts, 0, 1, 0, 0, 0), []any{"a.tsv", "delim=tab"}, nil},
+		{`LOAD_FRAME R2, "data"`, enc(vm.OpLoadFrame, 0, 2, 0, 0, 0), []any{"data"}, nil},
+		{`LOAD_CONST R3, -7`, enc(vm.OpLoadConst, 0, 3, 0, 0, 0), []any{int64(-7)}, nil},
+		{`LOAD_CONST_F F3, 1.5`, enc(vm.OpLoadConstF, 0, 3, 0, 0, 0), nil, []float64{1.5}},
+		{`SELECT_COL V1, R2, "price"`, enc(vm.OpSelectCol, 0, 1, 2, 0, 0), []any{"price"}, nil},
+		{`BROADCAST V2, R1, V3`, enc(vm.OpBroadcast, 0, 2, 1, 3, 0), nil, nil},
+		{`BROADCAST_F V2, F1, V3`, enc(vm.OpBroadcastF, 0, 2, 1, 3, 0), nil, nil},
+		{`RANGE V1, 0, 5`, enc(vm.OpRange, 0, 1, 0, 0, 0), []any{int64(0), int64(5), int64(1)}, nil},
+		{`RANGE V1, 10, 0, -2`, enc(vm.OpRange, 0, 1, 0, 0, 0), []any{int64(10), int64(0), int64(-2)}, nil},
+		{`FILL_I V1, 7, 5`, enc(vm.OpFillI, 0, 1, 0, 0, 0), []any{int64(7), int64(5)}, nil},
+		{`FILL_F V1, 2, V3`, enc(vm.OpFillF, vm.FillLikeVector, 1, 3, 0, 0), []any{float64(2)}, nil},
+		{`FILL_STR V1, "n/a", V0`, enc(vm.OpFillStr, vm.FillLikeVector, 1, 0, 0, 0), []any{"n/a"}, nil},
+
+		// Vector arithmetic, comparison and logic
+		{`VEC_ADD_I V2, V0, V1`, enc(vm.OpVecAddI, 0, 2, 0, 1, 0), nil, nil},
+		{`VEC_DIV_F V4, V5, V6`, enc(vm.OpVecDivF, 0, 4, 5, 6, 0), nil, nil},
+		{`VEC_MAX_F V1, V2, V3`, enc(vm.OpVecMaxF, 0, 1, 2, 3, 0), nil, nil},
+		{`CLAMP_F V1, V0, 0, 10`, enc(vm.OpClampF, 0, 1, 0, 0, 0), nil, []float64{0, 10}},
+		{`CMP_LE V3, V1, V2`, enc(vm.OpCmpLE, 0, 3, 1, 2, 0), nil, nil},
+		{`CMP_EQ_EPS_F V3, V1, V2, 0.001`, enc(vm.OpCmpEqEpsF, 0, 3, 1, 2, 0), nil, []float64{0.001}},
+		{`IN_SET V1, V0, "A", 2, 3.5`, enc(vm.OpInSet, 0, 1, 0, 0, 0), []any{int64(3), "A", int64(2), 3.5}, nil},
+		{`AND V3, V1, V2`, enc(vm.OpAnd, 0, 3, 1, 2, 0), nil, nil},
+		{`XOR V3, V1, V2`, enc(vm.OpXor, 0, 3, 1, 2, 0), nil, nil},
+		{`ASSERT_EQ R1, R2`, enc(vm.OpAssertEq, 0, 0, 1, 2, uint16(vm.RegFrame)), nil, nil},
+		{`ASSERT_EQ R1, R2, "int"`, enc(vm.OpAssertEq, 0, 0, 1, 2, uint16(vm.RegInt)), nil, nil},
+		{`ASSERT_EQ F0, F1`, enc(vm.OpAssertEq, 0, 0, 0, 1, uint16(vm.RegFloat)), nil, nil},
+		{`ASSERT_EQ V2, V3`, enc(vm.OpAssertEq, 0, 0, 2, 3, uint16(vm.RegVector)), nil, nil},
+		{`PRINT R1`, enc(vm.OpPrint, 0, 0, 1, 0, uint16(vm.RegFrame)), nil, nil},
+		{`PRINT R1, "int"`, enc(vm.OpPrint, 0, 0, 1, 0, uint16(vm.RegInt)), nil, nil},
+		{`PRINT F2`, enc(vm.OpPrint, 0, 0, 2, 0, uint16(vm.RegFloat)), nil, nil},
+		{`PRINT V3`, enc(vm.OpPrint, 0, 0, 3, 0, uint16(vm.RegVector)), nil, nil},
+		{`SET_RESULT "df", R1`, enc(vm.OpSetResult, vm.RegFrame, 0, 1, 0, 0), []any{"df"}, nil},
+		{`SET_RESULT "n", R1, "int"`, enc(vm.OpSetResult, vm.RegInt, 0, 1, 0, 0), []any{"n"}, nil},
+		{`SET_RESULT "total", F2`, enc(vm.OpSetResult, vm.RegFloat, 0, 2, 0, 0), []any{"total"}, nil},
+		{`SET_RESULT "col", V3`, enc(vm.OpSetResult, vm.RegVector, 0, 3, 0, 0), []any{"col"}, nil},
+		{`MASKED_SELECT V3, V1, V2`, enc(vm.OpMaskedSelect, 0, 3, 1, 2, 0), nil, nil},
+		{`NOT V3, V1`, enc(vm.OpNot, 0, 3, 1, 0, 0), nil, nil},
+		{`FILTER V3, V0, V2`, enc(vm.OpFilter, 0, 3, 0, 2, 0), nil, nil},
+		{`TAKE V3, V0, V2`, enc(vm.OpTake, 0, 3, 0, 2, 0), nil, nil},
+
+		// Aggregations
+		{`REDUCE_SUM R1, V2`, enc(vm.OpReduceSum, 0, 1, 2, 0, 0), nil, nil},
+		{`REDUCE_PROD_F F1, V2`, enc(vm.OpReduceProdF, 0, 1, 2, 0, 0), nil, nil},
+		{`REDUCE_QUANTILE_F F1, V2, 0.25`, enc(vm.OpReduceQuantileF, 0, 1, 2, 0, 0), nil, []float64{0.25}},
+		{`VEC_INDEX R1, V2, R3`, enc(vm.OpVecIndex, 0, 1, 2, 3, 0), nil, nil},
+		{`VEC_INDEX_F F1, V2, R3`, enc(vm.OpVecIndexF, 0, 1, 2, 3, 0), nil, nil},
+		{`NEG_I R1, R2`, enc(vm.OpNegI, 0, 1, 2, 0, 0), nil, nil},
+		{`NEG_F F1, F2`, enc(vm.OpNegF, 0, 1, 2, 0, 0), nil, nil},
+		{`VEC_NEG_F V1, V2`, enc(vm.OpVecNegF, 0, 1, 2, 0, 0), nil, nil},
+		{`LOAD_CONST_STR R1, "hi"`, enc(vm.OpLoadConstStr, 0, 1, 0, 0, 0), []any{"hi"}, nil},
+		{`HALT_STR R3`, enc(vm.OpHaltStr, 0, 3, 0, 0, 0), nil, nil},
+		{`CORR_F F1, V2, V3`, enc(vm.OpCorrF, 0, 1, 2, 3, 0), nil, nil},
+		{`REDUCE_WMEAN_F F1, V2, V3`, enc(vm.OpReduceWMeanF, 0, 1, 2, 3, 0), nil, nil},
+
+		// Scalar operations
+		{`MOVE_R R1, R0`, enc(vm.OpMoveR, 0, 1, 0, 0, 0), nil, nil},
+		{`ADD_R R2, R0, R1`, enc(vm.OpAddR, 0, 2, 0, 1, 0), nil, nil},
+
+		// Frame operations
+		{`NEW_FRAME R4`, enc(vm.OpNewFrame, 0, 4, 0, 0, 0), nil, nil},
+		{`ROW_COUNT R1, R0`, enc(vm.OpRowCount, 0, 1, 0, 0, 0), nil, nil},
+		{`ROW_INDEX V1, V0`, enc(vm.OpRowIndex, 0, 1, 0, 0, 0), nil, nil},
+		{`ADD_COL R0, V1, "total"`, enc(vm.OpAddCol, 0, 0, 1, 0, 0), []any{"total"}, nil},
+		{`TOP_N R1, R0, "price", 3`, enc(vm.OpTopN, 0, 1, 0, 0, 0), []any{"price", int64(3), "desc"}, nil},
+		{`SHUFFLE R1, R0`, enc(vm.OpShuffle, 0, 1, 0, 0, 0), nil, nil},
+		{`SAMPLE R1, R0, 10`, enc(vm.OpSample, 0, 1, 0, 0, 0), []any{int64(10)}, nil},
+		{`SAMPLE R1, R2, 10, 42`, enc(vm.OpSample, vm.SampleSeeded, 1, 2, 0, 0), []any{int64(10), int64(42)}, nil},
+
+		// GroupBy operations
+		{`GROUP_BY R1, V0`, enc(vm.OpGroupBy, 0, 1, 0, 0, 0), nil, nil},
+		{`GROUP_SUM_F V2, R1, V3`, enc(vm.OpGroupSumF, 0, 2, 1, 3, 0), nil, nil},
+		{`GROUP_COUNT V2, R1`, enc(vm.OpGroupCount, 0, 2, 1, 0, 0), nil, nil},
+		{`GROUP_CONCAT V2, R1, V3, ";"`, enc(vm.OpGroupConcat, 0, 2, 1, 3, 0), []any{";"}, nil},
+		{`GROUP_QUANTILE_F V2, R1, V3, 0.5`, enc(vm.OpGroupQuantileF, 0, 2, 1, 3, 0), nil, []float64{0.5}},
+
+		// Joins
+		{`JOIN_OUTER R2, R0, R1, "id"`, enc(vm.OpJoinOuter, 0, 2, 0, 1, 0), []any{"id"}, nil},
+		{`JOIN_CROSS R2, R0, R1`, enc(vm.OpJoinCross, 0, 2, 0, 1, 0), nil, nil},
+		{`JOIN_INNER_SORTED R2, R0, R1, "id"`, enc(vm.OpJoinInnerSorted, 0, 2, 0, 1, 0), []any{"id"}, nil},
+
+		// String operations
+		{`STR_UPPER V1, V0`, enc(vm.OpStrUpper, 0, 1, 0, 0, 0), nil, nil},
+		{`STR_TITLE V1, V0`, enc(vm.OpStrTitle, 0, 1, 0, 0, 0), nil, nil},
+		{`STR_CAPITALIZE V1, V0`, enc(vm.OpStrCapitalize, 0, 1, 0, 0, 0), nil, nil},
+		{`STR_TRIM_LEFT V1, V0`, enc(vm.OpStrTrimLeft, 0, 1, 0, 0, 0), nil, nil},
+		{`STR_TRIM_CHARS V1, V0, "x"`, enc(vm.OpStrTrimChars, 0, 1, 0, 0, 0), []any{"x"}, nil},
+		{`STR_CONCAT V2, V0, V1`, enc(vm.OpStrConcat, 0, 2, 0, 1, 0), nil, nil},
+		{`STR_REGEX_MATCH V1, V0, "^\d+$"`, enc(vm.OpStrRegexMatch, 0, 1, 0, 0, 0), []any{`^\d+$`}, nil},
+		{`STR_SUBSTR V1, V0, 2, 3`, enc(vm.OpStrSubstr, 0, 1, 0, 0, 0), []any{int64(2), int64(3)}, nil},
+		{`STR_PAD_LEFT V1, V0, 5, "0"`, enc(vm.OpStrPadLeft, 0, 1, 0, 0, 0), []any{int64(5), "0"}, nil},
+		{`FORMAT_F V1, V0, 2`, enc(vm.OpFormatF, 0, 1, 0, 0, 0), []any{int64(2)}, nil},
+		{`FORMAT_F V1, V0, "%.3e"`, enc(vm.OpFormatF, 0, 1, 0, 0, 0), []any{"%.3e"}, nil},
+
+		// Window operations
+		{`SHIFT V1, V0, -2`, enc(vm.OpShift, 0, 1, 0, 0, 0xFE), nil, nil},
+		{`BIN_F V1, V0, 4`, enc(vm.OpBinF, 0, 1, 0, 0, 0), nil, []float64{4, nan, nan}},
+		{`RANK_F V1, V0, "min", "desc"`, enc(vm.OpRankF, 0, 1, 0, 0, uint16(vm.RankMin|vm.RankDescending)), nil, nil},
+
+		// Null handling
+		{`FILL_NA V1, V0, 0.5`, enc(vm.OpFillNa, 0, 1, 0, 0, 0), []any{0.5}, nil},
+		{`DROP_NA R1, R0, "a", "b"`, enc(vm.OpDropNa, 0, 1, 0, 0, 0), []any{int64(2), "a", "b"}, nil},
+		{`IS_NULL V1, V0`, enc(vm.OpIsNull, 0, 1, 0, 0, 0), nil, nil},
+		{`IS_NAN_F V1, V0`, enc(vm.OpIsNaNF, 0, 1, 0, 0, 0), nil, nil},
+		{`COALESCE V2, V0, V1`, enc(vm.OpCoalesce, 0, 2, 0, 1, 0), nil, nil},
+		{`IS_INF_F V1, V0`, enc(vm.OpIsInfF, 0, 1, 0, 0, 0), nil, nil},
+
+		// Hashing
+		{`HASH_STR V1, V0`, enc(vm.OpHashStr, 0, 1, 0, 0, 0), nil, nil},
+		{`HASH_STR V1, V2, 16`, enc(vm.OpHashStr, 0, 1, 2, 0, 16), nil, nil},
+
+		// Dates
+		{`PARSE_DATE V1, V0, "2006-01-02"`, enc(vm.OpParseDate, 0, 1, 0, 0, 0), []any{"2006-01-02"}, nil},
+		{`DATE_PART V1, V2, "month"`, enc(vm.OpDatePart, 0, 1, 2, 0, uint16(vm.DatePartMonth)), nil, nil},
+		{`DATE_ADD_DAYS V2, V0, V1`, enc(vm.OpDateAddDays, 0, 2, 0, 1, 0), nil, nil},
+		{`DATE_DIFF_DAYS V2, V0, V1`, enc(vm.OpDateDiffDays, 0, 2, 0, 1, 0), nil, nil},
+
+		// Reshaping
+		{`PIVOT R1, R0, "i", "k", "v"`, enc(vm.OpPivot, 0, 1, 0, 0, 0), []any{"i", "k", "v"}, nil},
+		{`CONCAT R2, R0, R1`, enc(vm.OpConcat, 0, 2, 0, 1, 0), nil, nil},
+
+		// Control flow
+		{`NOP`, enc(vm.OpNop, 0, 0, 0, 0, 0), nil, nil},
+		{`HALT_V V7`, enc(vm.OpHaltV, 0, 7, 0, 0, 0), nil, nil},
+	}
+
+	// Through the public entry point the CLI and embed use
+	for _, tt := range tests {
+		program, err := vm.Assemble(tt.src)
+		if err != nil {
+			t.Errorf("%s: Assemble failed: %v", tt.src, err)
+			continue
+		}
+		if len(program.Code) != 1 || program.Code[0] != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.src, tt.want, program.Code)
+		}
+		if len(program.Constants) != len(tt.consts) || (len(tt.consts) > 0 && !reflect.DeepEqual(program.Constants, tt.consts)) {
+			t.Errorf("%s: expected constants %#v, got %#v", tt.src, tt.consts, program.Constants)
+		}
+		if len(program.FloatConstants) != len(tt.floats) {
+			t.Errorf("%s: expected float constants %v, got %v", tt.src, tt.floats, program.FloatConstants)
+			continue
+		}
+		for i, f := range tt.floats {
+			if math.Float64bits(f) != math.Float64bits(program.FloatConstants[i]) {
+				t.Errorf("%s: float constant %d: expected %v, got %v", tt.src, i, f, program.FloatConstants[i])
+			}
+		}
+	}
+}
+
+func TestCompiler_Directives(t *testing.T) {
+	program, err := Compile(`; Scale prices into a band
+.float 0.9, 1.5       ; declared first, so they own slots 0 and 1
+.const "price"
+
+LOAD_FRAME   R0, "data"      ; input frame
+SELECT_COL   V0, R0, "price"
+LOAD_CONST_F F0, 1.5         ; reuses the declared slot
+LOAD_CONST_F F1, 3.25
+REDUCE_QUANTILE_F F2, V0, 0.9
+HALT_F       F2              ; result`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if len(program.Code) != 6 {
+		t.Fatalf("expected 6 instructions, got %d", len(program.Code))
+	}
+	if !reflect.DeepEqual(program.FloatConstants, []float64{0.9, 1.5, 3.25}) {
+		t.Errorf("unexpected float constants: %v", program.FloatConstants)
+	}
+	if !reflect.DeepEqual(program.Constants, []any{"price", "data"}) {
+		t.Errorf("unexpected constants: %#v", program.Constants)
+	}
+	if idx := program.Code[2].Imm16(); idx != 1 {
+		t.Errorf("expected LOAD_CONST_F 1.5 to use slot 1, got %d", idx)
+	}
+	if idx := program.Code[4].Imm8(); idx != 0 {
+		t.Errorf("expected quantile 0.9 to use slot 0, got %d", idx)
+	}
+
+	if _, err := Compile(`.float "x"`); err == nil {
+		t.Error("expected error for non-numeric .float value")
+	}
+	if _, err := Compile(`.entry 0`); err == nil {
+		t.Error("expected error for unknown directive")
+	}
+}
@@ -0,0 +1,58 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/akhildatla/dasm/pkg/dsl"
+	"github.com/akhildatla/dasm/pkg/vm"
+)
+
+// CompileDSL compiles a parsed DSL program straight to bytecode. The DSL
+// compiler's structured instructions are encoded without a round trip
+// through assembly text, so string literals need no quoting, and identical
+// constants share one slot in the pool.
+func CompileDSL(program *dsl.Program) (*vm.Program, error) {
+	insts, err := dsl.NewCompiler().CompileInstructions(program)
+	if err != nil {
+		return nil, err
+	}
+
+	asm := &AsmProgram{Labels: make(map[string]int)}
+	for i, inst := range insts {
+		asmInst, err := dslInstruction(inst, i+1)
+		if err != nil {
+			return nil, err
+		}
+		asm.Instructions = append(asm.Instructions, asmInst)
+	}
+	return newCompiler().compile(asm)
+}
+
+// dslInstruction converts a DSL instruction to the form the parser produces
+// for the same line of assembly.
+func dslInstruction(inst dsl.Instruction, line int) (AsmInstruction, error) {
+	asm := AsmInstruction{Opcode: inst.Op, Line: line, Operands: make([]Operand, len(inst.Operands))}
+	for i, op := range inst.Operands {
+		switch op.Kind {
+		case dsl.OperandReg:
+			if op.Int < 0 || op.Int > 255 {
+				return AsmInstruction{}, fmt.Errorf("line %d: invalid register %c%d", line, op.Reg, op.Int)
+			}
+			switch op.Reg {
+			case 'R':
+				asm.Operands[i] = Operand{Type: OperandRegR, RegNum: uint8(op.Int)}
+			case 'F':
+				asm.Operands[i] = Operand{Type: OperandRegF, RegNum: uint8(op.Int)}
+			case 'V':
+				asm.Operands[i] = Operand{Type: OperandRegV, RegNum: uint8(op.Int)}
+			}
+		case dsl.OperandInt:
+			asm.Operands[i] = Operand{Type: OperandInt, IntVal: op.Int}
+		case dsl.OperandFloat:
+			asm.Operands[i] = Operand{Type: OperandFloat, FloatVal: op.Float}
+		case dsl.OperandString:
+			asm.Operands[i] = Operand{Type: OperandString, StrVal: op.Str}
+		}
+	}
+	return asm, nil
+}
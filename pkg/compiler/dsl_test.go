@@ -0,0 +1,110 @@
+package compiler
+
+import (
+	"reflect"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+
+	"github.com/akhildatla/dasm/pkg/dsl"
+	"github.com/akhildatla/dasm/pkg/vm"
+)
+
+func parseDSL(t *testing.T, src string) *dsl.Program {
+	t.Helper()
+	program, err := dsl.NewParser(dsl.NewLexer(src).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return program
+}
+
+func runProgram(t *testing.T, program *vm.Program, frames map[string]*dataframe.DataFrame) any {
+	t.Helper()
+	machine := vm.NewVM()
+	machine.SetPredeclaredFrames(frames)
+	if err := machine.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := machine.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return result
+}
+
+func TestCompileDSL_SharedConstants(t *testing.T) {
+	program, err := CompileDSL(parseDSL(t, `
+data = frame("sales")
+a = sum(data.price)
+b = max(data.price)
+c = mean(data.price)
+return sum(data.price * 2)
+`))
+	if err != nil {
+		t.Fatalf("CompileDSL failed: %v", err)
+	}
+
+	count := 0
+	for _, c := range program.Constants {
+		if c == "price" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected one \"price\" constant, got %d in %v", count, program.Constants)
+	}
+
+	frames := map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5, 4.0)),
+	}
+	if got := runProgram(t, program, frames); got != 16.0 {
+		t.Errorf("expected 16, got %v", got)
+	}
+}
+
+func TestCompileDSL_MatchesAssembly(t *testing.T) {
+	sources := []string{
+		`data = frame("sales")
+big = data |> filter(quantity > 10) |> select(price, quantity)
+return sum(big.price)`,
+		`data = frame("sales")
+flags = in(data.region, "EU", 2, 3.5)
+return sum(flags)`,
+		`data = frame("sales")
+return repeat("n/a", data.region)`,
+		`data = frame("sales")
+clean = dropna(data, "price", "region")
+return sample(clean, 2, seed = 7)`,
+		`return -(2.5)`,
+	}
+	for _, src := range sources {
+		ast := parseDSL(t, src)
+		asm, err := dsl.NewCompiler().Compile(ast)
+		if err != nil {
+			t.Fatalf("%q: Compile failed: %v", src, err)
+		}
+		want, err := Compile(asm)
+		if err != nil {
+			t.Fatalf("%q: assembling failed: %v\n%s", src, err, asm)
+		}
+		got, err := CompileDSL(ast)
+		if err != nil {
+			t.Fatalf("%q: CompileDSL failed: %v", src, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: CompileDSL differs from the assembled text:\n got %+v\nwant %+v", src, got, want)
+		}
+	}
+}
+
+func TestCompileDSL_QuotedString(t *testing.T) {
+	// Double quotes would end the literal in assembly text
+	program, err := CompileDSL(parseDSL(t, `return 'say "hi", then go'`))
+	if err != nil {
+		t.Fatalf("CompileDSL failed: %v", err)
+	}
+	if got := runProgram(t, program, nil); got != `say "hi", then go` {
+		t.Errorf("expected the string unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,261 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akhildatla/dasm/pkg/dsl"
+)
+
+// Explain parses DSL source and returns a human-readable plan with one line
+// per statement, e.g.
+//
+//	data = Load frame 'sales' → Filter quantity > 10
+//	return Sum data.price
+//
+// The plan is derived from the AST rather than the compiled bytecode, so it
+// reflects the program as written, before lowering and optimization.
+func Explain(src string) (string, error) {
+	tokens := dsl.NewLexer(src).Tokenize()
+	program, err := dsl.NewParser(tokens).Parse()
+	if err != nil {
+		return "", fmt.Errorf("parsing: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *dsl.AssignStmt:
+			fmt.Fprintf(&sb, "%s = %s\n", s.Name, explainPlan(s.Value))
+		case *dsl.ReturnStmt:
+			fmt.Fprintf(&sb, "return %s\n", explainPlan(s.Value))
+		case *dsl.ExprStmt:
+			fmt.Fprintf(&sb, "%s\n", explainPlan(s.Expr))
+		}
+	}
+	return sb.String(), nil
+}
+
+// explainPlan renders e as its stages joined by arrows.
+func explainPlan(e dsl.Expr) string {
+	return strings.Join(explainStages(e), " → ")
+}
+
+// explainStages flattens pipes, and calls whose first argument is itself a
+// frame pipeline, into an ordered list of stage descriptions.
+func explainStages(e dsl.Expr) []string {
+	switch e := e.(type) {
+	case *dsl.PipeExpr:
+		return append(explainStages(e.Left), explainStages(e.Right)...)
+	case *dsl.LoadExpr:
+		return []string{fmt.Sprintf("Load CSV '%s'", e.Path)}
+	case *dsl.LoadJSONExpr:
+		return []string{fmt.Sprintf("Load JSON '%s'", e.Path)}
+	case *dsl.LoadJSONLExpr:
+		return []string{fmt.Sprintf("Load JSONL '%s'", e.Path)}
+	case *dsl.LoadParquetExpr:
+		return []string{fmt.Sprintf("Load Parquet '%s'", e.Path)}
+	case *dsl.LoadURLExpr:
+		return []string{fmt.Sprintf("Load URL '%s'", e.URL)}
+	case *dsl.FrameExpr:
+		return []string{fmt.Sprintf("Load frame '%s'", e.Name)}
+	case *dsl.NewFrameExpr:
+		return []string{"New frame"}
+	case *dsl.SelectExpr:
+		return []string{"Select " + explainList(e.Columns)}
+	case *dsl.FilterExpr:
+		return []string{"Filter " + explainExpr(e.Condition)}
+	case *dsl.MutateExpr:
+		parts := make([]string, len(e.Assignments))
+		for i, a := range e.Assignments {
+			parts[i] = a.Name + " = " + explainExpr(a.Value)
+		}
+		return []string{"Mutate " + strings.Join(parts, ", ")}
+	case *dsl.GroupByExpr:
+		return []string{"Group by " + strings.Join(e.Keys, ", ")}
+	case *dsl.SummarizeExpr:
+		parts := make([]string, len(e.Aggregations))
+		for i, a := range e.Aggregations {
+			parts[i] = fmt.Sprintf("%s = %s(%s)", a.Name, a.Func, explainList(a.Args))
+		}
+		return []string{"Summarize " + strings.Join(parts, ", ")}
+	case *dsl.JoinExpr:
+		stage := fmt.Sprintf("%s join %s", explainTitle(e.JoinType), explainExpr(e.Right))
+		if e.JoinType != "cross" {
+			stage += " on " + e.On
+		}
+		return []string{stage}
+	case *dsl.TakeExpr:
+		if len(e.Indices) > 0 {
+			return []string{"Take rows " + explainList(e.Indices)}
+		}
+		return []string{"Take " + explainExpr(e.Count)}
+	case *dsl.PivotExpr:
+		var stages []string
+		if e.Frame != nil {
+			stages = explainStages(e.Frame)
+		}
+		return append(stages, fmt.Sprintf("Pivot index = %s, key = %s, value = %s", e.Index, e.Key, e.Value))
+	case *dsl.CallExpr:
+		return explainCall(e)
+	}
+	return []string{explainExpr(e)}
+}
+
+// explainCall renders a function call as a stage named after the function.
+// When the first argument is a frame pipeline it is expanded into the
+// preceding stages so nested calls read in execution order.
+func explainCall(e *dsl.CallExpr) []string {
+	var stages []string
+	args := e.Args
+	if len(args) > 0 && explainIsPipeline(args[0]) {
+		stages = explainStages(args[0])
+		args = args[1:]
+	}
+
+	stage := explainTitle(e.Func)
+	if rest := explainCallArgs(args, e.Named); rest != "" {
+		stage += " " + rest
+	}
+	return append(stages, stage)
+}
+
+// explainIsPipeline reports whether e produces a frame through stages worth
+// listing on their own, as opposed to a plain value or variable reference.
+func explainIsPipeline(e dsl.Expr) bool {
+	switch e.(type) {
+	case *dsl.PipeExpr, *dsl.LoadExpr, *dsl.LoadJSONExpr, *dsl.LoadJSONLExpr,
+		*dsl.LoadParquetExpr, *dsl.LoadURLExpr, *dsl.FrameExpr, *dsl.NewFrameExpr:
+		return true
+	}
+	return false
+}
+
+// explainExpr renders a scalar or vector expression in DSL-like syntax.
+func explainExpr(e dsl.Expr) string {
+	switch e := e.(type) {
+	case nil:
+		return ""
+	case *dsl.Ident:
+		return e.Name
+	case *dsl.IntLit:
+		return strconv.FormatInt(e.Value, 10)
+	case *dsl.FloatLit:
+		return strconv.FormatFloat(e.Value, 'g', -1, 64)
+	case *dsl.StringLit:
+		return strconv.Quote(e.Value)
+	case *dsl.BoolLit:
+		return strconv.FormatBool(e.Value)
+	case *dsl.BinaryExpr:
+		return fmt.Sprintf("%s %s %s", explainOperand(e.Left), explainOperator(e.Op), explainOperand(e.Right))
+	case *dsl.UnaryExpr:
+		if e.Op == dsl.TokenNot {
+			return "not " + explainOperand(e.Right)
+		}
+		return explainOperator(e.Op) + explainOperand(e.Right)
+	case *dsl.CallExpr:
+		return fmt.Sprintf("%s(%s)", e.Func, explainCallArgs(e.Args, e.Named))
+	case *dsl.MemberExpr:
+		return explainOperand(e.Object) + "." + e.Member
+	case *dsl.IndexExpr:
+		return fmt.Sprintf("%s[%s]", explainOperand(e.Object), explainExpr(e.Index))
+	case *dsl.ObjectLit:
+		parts := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			parts[i] = f.Key + ": " + explainPlan(f.Value)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *dsl.PipeExpr, *dsl.LoadExpr, *dsl.LoadJSONExpr, *dsl.LoadJSONLExpr, *dsl.LoadParquetExpr,
+		*dsl.LoadURLExpr, *dsl.FrameExpr, *dsl.NewFrameExpr, *dsl.SelectExpr, *dsl.FilterExpr,
+		*dsl.MutateExpr, *dsl.GroupByExpr, *dsl.SummarizeExpr, *dsl.JoinExpr, *dsl.TakeExpr, *dsl.PivotExpr:
+		// A pipeline used as a value, e.g. the right side of a join
+		return "(" + explainPlan(e) + ")"
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+// explainOperand parenthesizes nested binary expressions so the rendered
+// text keeps the parsed grouping.
+func explainOperand(e dsl.Expr) string {
+	if _, ok := e.(*dsl.BinaryExpr); ok {
+		return "(" + explainExpr(e) + ")"
+	}
+	return explainExpr(e)
+}
+
+// explainList renders expressions separated by commas.
+func explainList(exprs []dsl.Expr) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = explainExpr(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// explainCallArgs renders positional arguments followed by keyword
+// arguments in name order.
+func explainCallArgs(args []dsl.Expr, named map[string]dsl.Expr) string {
+	list := explainList(args)
+	if len(named) == 0 {
+		return list
+	}
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names)+1)
+	if list != "" {
+		parts = append(parts, list)
+	}
+	for _, name := range names {
+		parts = append(parts, name+" = "+explainExpr(named[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// explainTitle turns a DSL function name like "top_n" into "Top n".
+func explainTitle(name string) string {
+	name = strings.ReplaceAll(name, "_", " ")
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// explainOperator returns the source spelling of an operator token.
+func explainOperator(op dsl.TokenType) string {
+	switch op {
+	case dsl.TokenPlus:
+		return "+"
+	case dsl.TokenMinus:
+		return "-"
+	case dsl.TokenStar:
+		return "*"
+	case dsl.TokenSlash:
+		return "/"
+	case dsl.TokenPercent:
+		return "%"
+	case dsl.TokenEQ:
+		return "=="
+	case dsl.TokenNE:
+		return "!="
+	case dsl.TokenLT:
+		return "<"
+	case dsl.TokenLE:
+		return "<="
+	case dsl.TokenGT:
+		return ">"
+	case dsl.TokenGE:
+		return ">="
+	case dsl.TokenAnd:
+		return "and"
+	case dsl.TokenOr:
+		return "or"
+	case dsl.TokenNot:
+		return "not"
+	}
+	return op.String()
+}
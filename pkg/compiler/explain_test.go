@@ -0,0 +1,66 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain_FilterSummarize(t *testing.T) {
+	src := `result = frame("sales") |> filter(quantity > 10) |> group_by(region) |> summarize(total = sum(price))
+return result`
+
+	plan, err := Explain(src)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	want := "result = Load frame 'sales' → Filter quantity > 10 → Group by region → Summarize total = sum(price)\nreturn result\n"
+	if plan != want {
+		t.Errorf("plan = %q, want %q", plan, want)
+	}
+}
+
+func TestExplain_StageOrder(t *testing.T) {
+	src := `data = frame("sales")
+big = data |> filter(quantity > 10 and price >= 2.5) |> select(price, quantity)
+top = sample(frame("orders") |> mutate(total = price * (quantity + 1)), 5, seed = 7)
+return sum(big.price)`
+
+	plan, err := Explain(src)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	stages := []string{
+		"data = Load frame 'sales'",
+		"big = data → Filter (quantity > 10) and (price >= 2.5) → Select price, quantity",
+		"top = Load frame 'orders' → Mutate total = price * (quantity + 1) → Sample 5, seed = 7",
+		"return Sum big.price",
+	}
+	pos := 0
+	for _, stage := range stages {
+		i := strings.Index(plan[pos:], stage)
+		if i < 0 {
+			t.Fatalf("plan missing %q after offset %d:\n%s", stage, pos, plan)
+		}
+		pos += i + len(stage)
+	}
+}
+
+func TestExplain_ParseError(t *testing.T) {
+	if _, err := Explain("data = frame(\"sales\"\nreturn"); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestExplain_ReturnObject(t *testing.T) {
+	plan, err := Explain(`data = frame("sales")
+return { total: sum(data.price), n: count(data.price) }`)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	want := "return {total: Sum data.price, n: Count data.price}\n"
+	if !strings.HasSuffix(plan, want) {
+		t.Errorf("plan = %q, want suffix %q", plan, want)
+	}
+}
@@ -11,16 +11,17 @@ type TokenType uint8
 const (
 	TokenEOF TokenType = iota
 	TokenNewline
-	TokenIdent   // Opcode names
-	TokenInt     // Integer literals
-	TokenFloat   // Float literals
-	TokenString  // "quoted strings"
-	TokenComma   // ,
-	TokenColon   // : (for labels)
-	TokenComment // ; comment
-	TokenRegR    // R0-R15
-	TokenRegV    // V0-V7
-	TokenRegF    // F0-F15
+	TokenIdent     // Opcode names
+	TokenInt       // Integer literals
+	TokenFloat     // Float literals
+	TokenString    // "quoted strings"
+	TokenComma     // ,
+	TokenColon     // : (for labels)
+	TokenComment   // ; comment
+	TokenRegR      // R0-R15
+	TokenRegV      // V0-V7
+	TokenRegF      // F0-F15
+	TokenDirective // .float, .const
 )
 
 // String returns the string representation of a token type.
@@ -50,6 +51,8 @@ func (t TokenType) String() string {
 		return "REG_V"
 	case TokenRegF:
 		return "REG_F"
+	case TokenDirective:
+		return "DIRECTIVE"
 	default:
 		return "UNKNOWN"
 	}
@@ -116,12 +119,19 @@ func (l *Lexer) Tokenize() []Token {
 		case ch == '"':
 			l.scanString()
 
+		case ch == '-' && l.pos+1 < len(l.input) && unicode.IsLetter(rune(l.input[l.pos+1])):
+			// -Inf
+			l.scanIdentOrRegister()
+
 		case ch == '-' || unicode.IsDigit(rune(ch)):
 			l.scanNumber()
 
 		case unicode.IsLetter(rune(ch)) || ch == '_':
 			l.scanIdentOrRegister()
 
+		case ch == '.' && l.pos+1 < len(l.input) && unicode.IsLetter(rune(l.input[l.pos+1])):
+			l.scanDirective()
+
 		default:
 			// Unknown character, skip it
 			l.pos++
@@ -219,6 +229,22 @@ func (l *Lexer) scanIdentOrRegister() {
 	l.tokens = append(l.tokens, Token{Type: tokenType, Value: value, Line: l.line})
 }
 
+func (l *Lexer) scanDirective() {
+	start := l.pos
+	l.pos++ // Skip the dot
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+		if unicode.IsLetter(rune(ch)) || unicode.IsDigit(rune(ch)) || ch == '_' {
+			l.pos++
+		} else {
+			break
+		}
+	}
+
+	l.tokens = append(l.tokens, Token{Type: TokenDirective, Value: l.input[start:l.pos], Line: l.line})
+}
+
 func (l *Lexer) classifyIdentOrRegister(value string) TokenType {
 	upper := strings.ToUpper(value)
 
@@ -191,3 +191,21 @@ SELECT_COL V0, R0, "price"`
 		}
 	}
 }
+
+func TestLexer_Directive(t *testing.T) {
+	lexer := NewLexer(`.float 0.5, 2 ; declared up front`)
+	tokens := lexer.Tokenize()
+
+	expected := []TokenType{TokenDirective, TokenFloat, TokenComma, TokenInt, TokenEOF}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i, typ := range expected {
+		if tokens[i].Type != typ {
+			t.Errorf("token %d: expected %s, got %s", i, typ, tokens[i].Type)
+		}
+	}
+	if tokens[0].Value != ".float" {
+		t.Errorf("expected directive .float, got %q", tokens[0].Value)
+	}
+}
@@ -2,7 +2,9 @@ package compiler
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // OperandType represents the type of an operand.
@@ -36,7 +38,8 @@ type AsmInstruction struct {
 // AsmProgram represents a parsed assembly program.
 type AsmProgram struct {
 	Instructions []AsmInstruction
-	Labels       map[string]int // label -> instruction index
+	Directives   []AsmInstruction // .float, .const; Opcode holds the directive name
+	Labels       map[string]int   // label -> instruction index
 }
 
 // Parser parses DFL assembly source code.
@@ -80,6 +83,14 @@ func (p *Parser) Parse() (*AsmProgram, error) {
 			}
 			p.program.Instructions = append(p.program.Instructions, inst)
 
+		case TokenDirective:
+			// Directives share the instruction syntax: name followed by operands
+			dir, err := p.parseInstruction()
+			if err != nil {
+				return nil, err
+			}
+			p.program.Directives = append(p.program.Directives, dir)
+
 		default:
 			// Skip unknown tokens
 			p.pos++
@@ -168,6 +179,21 @@ func (p *Parser) parseOperand() (Operand, error) {
 		p.pos++
 		return Operand{Type: OperandString, StrVal: tok.Value}, nil
 
+	case TokenIdent:
+		// NaN and infinities, as vm.Disassemble writes them
+		switch strings.ToLower(tok.Value) {
+		case "nan":
+			p.pos++
+			return Operand{Type: OperandFloat, FloatVal: math.NaN()}, nil
+		case "inf":
+			p.pos++
+			return Operand{Type: OperandFloat, FloatVal: math.Inf(1)}, nil
+		case "-inf":
+			p.pos++
+			return Operand{Type: OperandFloat, FloatVal: math.Inf(-1)}, nil
+		}
+		return Operand{}, fmt.Errorf("line %d: unexpected token: %s", tok.Line, tok.Value)
+
 	default:
 		return Operand{}, fmt.Errorf("line %d: unexpected token: %s", tok.Line, tok.Value)
 	}
@@ -117,10 +117,11 @@ func (*UnaryExpr) node() {}
 func (*UnaryExpr) expr() {}
 
 // CallExpr represents a function call.
-// Example: sum(price), load("data.csv")
+// Example: sum(price), load("data.csv"), rank(price, desc = true)
 type CallExpr struct {
-	Func string
-	Args []Expr
+	Func  string
+	Args  []Expr
+	Named map[string]Expr // Keyword arguments (name = value)
 }
 
 func (*CallExpr) node() {}
@@ -156,6 +157,21 @@ type IndexExpr struct {
 func (*IndexExpr) node() {}
 func (*IndexExpr) expr() {}
 
+// ObjectLit represents a record of named values.
+// Example: return { total: sum(price), avg: mean(price) }
+type ObjectLit struct {
+	Fields []ObjectField
+}
+
+// ObjectField is one key: value pair of an ObjectLit.
+type ObjectField struct {
+	Key   string
+	Value Expr
+}
+
+func (*ObjectLit) node() {}
+func (*ObjectLit) expr() {}
+
 // ===== DataFrame Operations =====
 
 // LoadExpr represents loading data from a file.
@@ -235,9 +251,9 @@ func (*SummarizeExpr) expr() {}
 // JoinExpr represents a join operation.
 // Example: join(other, on: id) or left_join(other, on: id)
 type JoinExpr struct {
-	JoinType string // "inner", "left", "right"
+	JoinType string // "inner", "left", "right", "outer", "cross"
 	Right    Expr
-	On       string // join key column name
+	On       string // join key column name (unused for cross joins)
 }
 
 func (*JoinExpr) node() {}
@@ -252,6 +268,24 @@ type LoadJSONExpr struct {
 func (*LoadJSONExpr) node() {}
 func (*LoadJSONExpr) expr() {}
 
+// LoadJSONLExpr represents loading data from a newline-delimited JSON file.
+// Example: load_jsonl("events.jsonl")
+type LoadJSONLExpr struct {
+	Path string
+}
+
+func (*LoadJSONLExpr) node() {}
+func (*LoadJSONLExpr) expr() {}
+
+// LoadURLExpr represents loading data from an HTTP(S) URL.
+// Example: load_url("https://example.com/data.csv")
+type LoadURLExpr struct {
+	URL string
+}
+
+func (*LoadURLExpr) node() {}
+func (*LoadURLExpr) expr() {}
+
 // LoadParquetExpr represents loading data from a Parquet file.
 // Example: load_parquet("data.parquet")
 type LoadParquetExpr struct {
@@ -277,3 +311,16 @@ type TakeExpr struct {
 
 func (*TakeExpr) node() {}
 func (*TakeExpr) expr() {}
+
+// PivotExpr represents a long-to-wide reshape.
+// Example: pivot(sales, index = region, key = month, value = amount)
+// or sales |> pivot(index = region, key = month, value = amount)
+type PivotExpr struct {
+	Frame Expr   // Source frame; nil when used in a pipe
+	Index string // Column whose distinct values become rows
+	Key   string // Column whose distinct values become columns
+	Value string // Column summed into each cell
+}
+
+func (*PivotExpr) node() {}
+func (*PivotExpr) expr() {}
@@ -2,19 +2,22 @@ package dsl
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
 // Compiler compiles DSL AST to DFL assembly code.
 type Compiler struct {
-	output     strings.Builder
-	constants  []string
-	nextReg    int // Next available R register
-	nextVReg   int // Next available V register
-	nextFReg   int // Next available F register
+	insts      []Instruction // Emitted program, before register allocation
+	nextReg    int           // Next virtual R register; see allocateRegisters
+	nextVReg   int           // Next virtual V register
+	nextFReg   int           // Next virtual F register
 	variables  map[string]regInfo
 	masks      map[int]regInfo // Maps frame register to its filter mask
 	groupByReg int             // Register holding current groupby result
+	lanes      []regInfo       // Row masks of enclosing lazy && / || operands
+	frameVars  map[string]bool // Variables bound to frames rather than int scalars
+	strVars    map[string]bool // Variables bound to string constants
 }
 
 type regInfo struct {
@@ -30,19 +33,43 @@ func NewCompiler() *Compiler {
 		nextFReg:   0,
 		variables:  make(map[string]regInfo),
 		masks:      make(map[int]regInfo),
+		frameVars:  make(map[string]bool),
+		strVars:    make(map[string]bool),
 		groupByReg: -1,
 	}
 }
 
 // Compile compiles a DSL program to assembly code.
 func (c *Compiler) Compile(program *Program) (string, error) {
+	asm, _, err := c.compile(program)
+	return asm, err
+}
+
+// CompileInstructions compiles a DSL program to structured instructions, the
+// same program Compile renders as text. String operands hold their values
+// as-is, so nothing depends on assembly quoting.
+func (c *Compiler) CompileInstructions(program *Program) ([]Instruction, error) {
+	_, insts, err := c.compile(program)
+	return insts, err
+}
+
+func (c *Compiler) compile(program *Program) (string, []Instruction, error) {
 	for _, stmt := range program.Statements {
 		if err := c.compileStmt(stmt); err != nil {
-			return "", err
+			return "", nil, err
 		}
 	}
 
-	return c.output.String(), nil
+	insts, err := allocateRegisters(c.insts)
+	if err != nil {
+		return "", nil, err
+	}
+	var asm strings.Builder
+	for _, inst := range insts {
+		asm.WriteString(inst.String())
+		asm.WriteString("\n")
+	}
+	return asm.String(), insts, nil
 }
 
 func (c *Compiler) compileStmt(stmt Stmt) error {
@@ -65,10 +92,48 @@ func (c *Compiler) compileAssign(stmt *AssignStmt) error {
 		return err
 	}
 	c.variables[stmt.Name] = reg
+	c.frameVars[stmt.Name] = c.isFrameExpr(stmt.Value)
+	c.strVars[stmt.Name] = c.isStringExpr(stmt.Value)
 	return nil
 }
 
+// isStringExpr reports whether e evaluates to a string. Strings live in R
+// registers as constant pool indexes, so they must not be read as ints.
+func (c *Compiler) isStringExpr(e Expr) bool {
+	switch e := e.(type) {
+	case *StringLit:
+		return true
+	case *Ident:
+		return c.strVars[e.Name]
+	}
+	return false
+}
+
+// isFrameExpr reports whether e evaluates to a frame. Frames and int scalars
+// both live in R registers, so instructions that accept either need to know.
+func (c *Compiler) isFrameExpr(e Expr) bool {
+	switch e := e.(type) {
+	case *LoadExpr, *LoadJSONExpr, *LoadJSONLExpr, *LoadURLExpr, *LoadParquetExpr,
+		*FrameExpr, *NewFrameExpr, *PipeExpr, *JoinExpr, *PivotExpr:
+		return true
+	case *Ident:
+		return c.frameVars[e.Name]
+	case *CallExpr:
+		switch strings.ToLower(e.Func) {
+		case "dropna", "top_n", "sample", "shuffle", "add_col", "concat", "group_sizes":
+			return true
+		case "assert_eq", "print":
+			return len(e.Args) > 0 && c.isFrameExpr(e.Args[0])
+		}
+	}
+	return false
+}
+
 func (c *Compiler) compileReturn(stmt *ReturnStmt) error {
+	if obj, ok := stmt.Value.(*ObjectLit); ok {
+		return c.compileReturnObject(obj)
+	}
+
 	reg, err := c.compileExpr(stmt.Value)
 	if err != nil {
 		return err
@@ -76,14 +141,48 @@ func (c *Compiler) compileReturn(stmt *ReturnStmt) error {
 
 	switch reg.regType {
 	case "R":
-		c.emit("HALT          R%d", reg.regNum)
+		if c.isStringExpr(stmt.Value) {
+			c.emitOp("HALT_STR", rreg(reg.regNum))
+			break
+		}
+		if c.isFrameExpr(stmt.Value) {
+			c.emitOp("HALT_FRAME", rreg(reg.regNum))
+			break
+		}
+		c.emitOp("HALT", rreg(reg.regNum))
 	case "F":
-		c.emit("HALT_F        F%d", reg.regNum)
+		c.emitOp("HALT_F", freg(reg.regNum))
 	case "V":
 		// Return the vector directly using HALT_V
-		c.emit("HALT_V        V%d", reg.regNum)
+		c.emitOp("HALT_V", vreg(reg.regNum))
+	}
+
+	return nil
+}
+
+// compileReturnObject stores each field as a named result with SET_RESULT,
+// readable through VM.Results, and halts with the number of fields.
+func (c *Compiler) compileReturnObject(obj *ObjectLit) error {
+	for _, field := range obj.Fields {
+		reg, err := c.compileExpr(field.Value)
+		if err != nil {
+			return err
+		}
+		switch {
+		case reg.regType == "":
+			return fmt.Errorf("cannot return %q: value has no register", field.Key)
+		case c.isStringExpr(field.Value):
+			return fmt.Errorf("cannot return %q: strings can only be returned on their own", field.Key)
+		case reg.regType != "R", c.isFrameExpr(field.Value):
+			c.emitOp("SET_RESULT", strOperand(field.Key), regOf(reg))
+		default:
+			c.emitOp("SET_RESULT", strOperand(field.Key), rreg(reg.regNum), strOperand("int"))
+		}
 	}
 
+	reg := c.allocReg()
+	c.emitOp("LOAD_CONST", rreg(reg), intOperand(int64(len(obj.Fields))))
+	c.emitOp("HALT", rreg(reg))
 	return nil
 }
 
@@ -113,6 +212,10 @@ func (c *Compiler) compileExpr(expr Expr) (regInfo, error) {
 		return c.compileCall(e)
 	case *MemberExpr:
 		return c.compileMember(e)
+	case *IndexExpr:
+		return c.compileIndex(e)
+	case *ObjectLit:
+		return regInfo{}, fmt.Errorf("object literals can only be returned, as in return { total: sum(x) }")
 	case *FilterExpr:
 		return c.compileFilter(e, regInfo{})
 	case *SelectExpr:
@@ -127,12 +230,18 @@ func (c *Compiler) compileExpr(expr Expr) (regInfo, error) {
 		return c.compileJoin(e, regInfo{})
 	case *LoadJSONExpr:
 		return c.compileLoadJSON(e)
+	case *LoadJSONLExpr:
+		return c.compileLoadJSONL(e)
+	case *LoadURLExpr:
+		return c.compileLoadURL(e)
 	case *LoadParquetExpr:
 		return c.compileLoadParquet(e)
 	case *NewFrameExpr:
 		return c.compileNewFrame(e)
 	case *TakeExpr:
 		return c.compileTake(e, regInfo{})
+	case *PivotExpr:
+		return c.compilePivot(e, regInfo{})
 	default:
 		return regInfo{}, fmt.Errorf("unknown expression type: %T", expr)
 	}
@@ -140,28 +249,62 @@ func (c *Compiler) compileExpr(expr Expr) (regInfo, error) {
 
 func (c *Compiler) compileIntLit(e *IntLit) (regInfo, error) {
 	reg := c.allocReg()
-	c.emit("LOAD_CONST    R%d, %d", reg, e.Value)
+	c.emitOp("LOAD_CONST", rreg(reg), intOperand(e.Value))
 	return regInfo{"R", reg}, nil
 }
 
+// intLiteral returns the value of an integer literal, allowing a leading minus.
+func intLiteral(e Expr) (int64, bool) {
+	switch e := e.(type) {
+	case *IntLit:
+		return e.Value, true
+	case *UnaryExpr:
+		if e.Op == TokenMinus {
+			if v, ok := intLiteral(e.Right); ok {
+				return -v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// numberLiteral returns the value of an int or float literal as float64,
+// allowing a leading minus.
+func numberLiteral(e Expr) (float64, bool) {
+	switch e := e.(type) {
+	case *IntLit:
+		return float64(e.Value), true
+	case *FloatLit:
+		return e.Value, true
+	case *UnaryExpr:
+		if e.Op == TokenMinus {
+			if v, ok := numberLiteral(e.Right); ok {
+				return -v, true
+			}
+		}
+	}
+	return 0, false
+}
+
 func (c *Compiler) compileFloatLit(e *FloatLit) (regInfo, error) {
 	reg := c.allocFReg()
-	c.emit("LOAD_CONST_F  F%d, %g", reg, e.Value)
+	c.emitOp("LOAD_CONST_F", freg(reg), floatOperand(e.Value))
 	return regInfo{"F", reg}, nil
 }
 
 func (c *Compiler) compileStringLit(e *StringLit) (regInfo, error) {
-	// Strings are typically used as constants
-	return regInfo{}, nil
+	reg := c.allocReg()
+	c.emitOp("LOAD_CONST_STR", rreg(reg), strOperand(e.Value))
+	return regInfo{"R", reg}, nil
 }
 
 func (c *Compiler) compileBoolLit(e *BoolLit) (regInfo, error) {
 	reg := c.allocReg()
-	val := 0
+	val := int64(0)
 	if e.Value {
 		val = 1
 	}
-	c.emit("LOAD_CONST    R%d, %d", reg, val)
+	c.emitOp("LOAD_CONST", rreg(reg), intOperand(val))
 	return regInfo{"R", reg}, nil
 }
 
@@ -174,6 +317,14 @@ func (c *Compiler) compileIdent(e *Ident) (regInfo, error) {
 }
 
 func (c *Compiler) compileBinary(e *BinaryExpr) (regInfo, error) {
+	if (e.Op == TokenAnd || e.Op == TokenOr) && c.lazyOperand(e.Right, false) {
+		return c.compileLazyLogical(e, c.compileExpr)
+	}
+
+	if c.isStringExpr(e.Left) || c.isStringExpr(e.Right) {
+		return regInfo{}, fmt.Errorf("strings are not supported as operands")
+	}
+
 	left, err := c.compileExpr(e.Left)
 	if err != nil {
 		return regInfo{}, err
@@ -193,48 +344,55 @@ func (c *Compiler) compileBinary(e *BinaryExpr) (regInfo, error) {
 	return c.compileScalarBinary(e.Op, left, right)
 }
 
+// broadcast expands a scalar in an R or F register to the length of like.
+func (c *Compiler) broadcast(scalar, like regInfo) regInfo {
+	broadcastDst := c.allocVReg()
+	if scalar.regType == "F" {
+		c.emitOp("BROADCAST_F", vreg(broadcastDst), freg(scalar.regNum), vreg(like.regNum))
+	} else {
+		c.emitOp("BROADCAST", vreg(broadcastDst), rreg(scalar.regNum), vreg(like.regNum))
+	}
+	return regInfo{"V", broadcastDst}
+}
+
 func (c *Compiler) compileVectorBinary(op TokenType, left, right regInfo) (regInfo, error) {
 	dst := c.allocVReg()
 
 	// Ensure both are vectors (broadcast if needed)
 	if left.regType != "V" {
-		broadcastDst := c.allocVReg()
-		c.emit("BROADCAST     V%d, R%d, V%d", broadcastDst, left.regNum, right.regNum)
-		left = regInfo{"V", broadcastDst}
+		left = c.broadcast(left, right)
 	}
 	if right.regType != "V" {
-		broadcastDst := c.allocVReg()
-		c.emit("BROADCAST     V%d, R%d, V%d", broadcastDst, right.regNum, left.regNum)
-		right = regInfo{"V", broadcastDst}
+		right = c.broadcast(right, left)
 	}
 
 	switch op {
 	case TokenPlus:
-		c.emit("VEC_ADD_F     V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("VEC_ADD_F", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenMinus:
-		c.emit("VEC_SUB_F     V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("VEC_SUB_F", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenStar:
-		c.emit("VEC_MUL_F     V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("VEC_MUL_F", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenSlash:
-		c.emit("VEC_DIV_F     V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("VEC_DIV_F", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenPercent:
-		c.emit("VEC_MOD       V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("VEC_MOD", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenLT:
-		c.emit("CMP_LT        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_LT", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenLE:
-		c.emit("CMP_LE        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_LE", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenGT:
-		c.emit("CMP_GT        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_GT", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenGE:
-		c.emit("CMP_GE        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_GE", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenEQ:
-		c.emit("CMP_EQ        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_EQ", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenNE:
-		c.emit("CMP_NE        V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("CMP_NE", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenAnd:
-		c.emit("AND           V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("AND", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	case TokenOr:
-		c.emit("OR            V%d, V%d, V%d", dst, left.regNum, right.regNum)
+		c.emitOp("OR", vreg(dst), vreg(left.regNum), vreg(right.regNum))
 	default:
 		return regInfo{}, fmt.Errorf("unsupported vector operation: %v", op)
 	}
@@ -247,13 +405,13 @@ func (c *Compiler) compileScalarBinary(op TokenType, left, right regInfo) (regIn
 
 	switch op {
 	case TokenPlus:
-		c.emit("ADD_R         R%d, R%d, R%d", dst, left.regNum, right.regNum)
+		c.emitOp("ADD_R", rreg(dst), rreg(left.regNum), rreg(right.regNum))
 	case TokenMinus:
-		c.emit("SUB_R         R%d, R%d, R%d", dst, left.regNum, right.regNum)
+		c.emitOp("SUB_R", rreg(dst), rreg(left.regNum), rreg(right.regNum))
 	case TokenStar:
-		c.emit("MUL_R         R%d, R%d, R%d", dst, left.regNum, right.regNum)
+		c.emitOp("MUL_R", rreg(dst), rreg(left.regNum), rreg(right.regNum))
 	case TokenSlash:
-		c.emit("DIV_R         R%d, R%d, R%d", dst, left.regNum, right.regNum)
+		c.emitOp("DIV_R", rreg(dst), rreg(left.regNum), rreg(right.regNum))
 	default:
 		return regInfo{}, fmt.Errorf("unsupported scalar operation: %v", op)
 	}
@@ -269,24 +427,40 @@ func (c *Compiler) compileUnary(e *UnaryExpr) (regInfo, error) {
 
 	if e.Op == TokenNot && right.regType == "V" {
 		dst := c.allocVReg()
-		c.emit("NOT           V%d, V%d", dst, right.regNum)
+		c.emitOp("NOT", vreg(dst), vreg(right.regNum))
 		return regInfo{"V", dst}, nil
 	}
 
+	if e.Op == TokenMinus {
+		switch {
+		case right.regType == "V":
+			dst := c.allocVReg()
+			c.emitOp("VEC_NEG_F", vreg(dst), vreg(right.regNum))
+			return regInfo{"V", dst}, nil
+		case right.regType == "F":
+			dst := c.allocFReg()
+			c.emitOp("NEG_F", freg(dst), freg(right.regNum))
+			return regInfo{"F", dst}, nil
+		case right.regType == "R" && !c.isFrameExpr(e.Right):
+			dst := c.allocReg()
+			c.emitOp("NEG_I", rreg(dst), rreg(right.regNum))
+			return regInfo{"R", dst}, nil
+		}
+		return regInfo{}, fmt.Errorf("cannot negate a frame")
+	}
+
 	return regInfo{}, fmt.Errorf("unsupported unary operation")
 }
 
 func (c *Compiler) compileLoad(e *LoadExpr) (regInfo, error) {
 	reg := c.allocReg()
-	constIdx := c.addConstant(fmt.Sprintf("\"%s\"", e.Path))
-	c.emit("LOAD_CSV      R%d, \"%s\"", reg, e.Path)
-	_ = constIdx
+	c.emitOp("LOAD_CSV", rreg(reg), strOperand(e.Path))
 	return regInfo{"R", reg}, nil
 }
 
 func (c *Compiler) compileFrame(e *FrameExpr) (regInfo, error) {
 	reg := c.allocReg()
-	c.emit("LOAD_FRAME    R%d, \"%s\"", reg, e.Name)
+	c.emitOp("LOAD_FRAME", rreg(reg), strOperand(e.Name))
 	return regInfo{"R", reg}, nil
 }
 
@@ -317,6 +491,8 @@ func (c *Compiler) compilePipeRight(expr Expr, input regInfo) (regInfo, error) {
 		return c.compileJoin(e, input)
 	case *TakeExpr:
 		return c.compileTake(e, input)
+	case *PivotExpr:
+		return c.compilePivot(e, input)
 	case *CallExpr:
 		return c.compileCallWithInput(e, input)
 	default:
@@ -351,18 +527,26 @@ func (c *Compiler) compileCondition(expr Expr, frame regInfo) (regInfo, error) {
 	case *Ident:
 		// Column reference
 		vReg := c.allocVReg()
-		c.emit("SELECT_COL    V%d, R%d, \"%s\"", vReg, frame.regNum, e.Name)
-		return regInfo{"V", vReg}, nil
+		c.emitOp("SELECT_COL", vreg(vReg), rreg(frame.regNum), strOperand(e.Name))
+		return c.restrict(regInfo{"V", vReg}), nil
 	case *IntLit:
 		return c.compileIntLit(e)
 	case *FloatLit:
 		return c.compileFloatLit(e)
+	case *CallExpr:
+		return c.compileExprWithFrame(e, frame)
 	default:
 		return c.compileExpr(expr)
 	}
 }
 
 func (c *Compiler) compileConditionBinary(e *BinaryExpr, frame regInfo) (regInfo, error) {
+	if (e.Op == TokenAnd || e.Op == TokenOr) && c.lazyOperand(e.Right, true) {
+		return c.compileLazyLogical(e, func(x Expr) (regInfo, error) {
+			return c.compileCondition(x, frame)
+		})
+	}
+
 	left, err := c.compileCondition(e.Left, frame)
 	if err != nil {
 		return regInfo{}, err
@@ -376,13 +560,131 @@ func (c *Compiler) compileConditionBinary(e *BinaryExpr, frame regInfo) (regInfo
 	return c.compileVectorBinary(e.Op, left, right)
 }
 
+// lazyFuncs are the row-wise functions costly enough that a && or || is
+// worth evaluating lazily when its right operand calls one of them.
+var lazyFuncs = map[string]bool{
+	"contains": true, "icontains": true, "matches": true,
+	"starts_with": true, "ends_with": true,
+}
+
+// rowwiseFuncs are the functions whose result for a row depends on that row
+// alone, so they can run on a filtered subset of rows.
+var rowwiseFuncs = map[string]bool{
+	"contains": true, "icontains": true, "matches": true,
+	"starts_with": true, "ends_with": true,
+	"upper": true, "lower": true, "trim": true,
+	"is_null": true, "is_not_null": true, "is_nan": true, "is_inf": true,
+	"in": true, "between": true, "approx_eq": true, "xor": true,
+}
+
+// lazyOperand reports whether the right operand e of a && or || should only
+// be evaluated on the rows the left operand leaves undecided. It must call
+// at least one of lazyFuncs and read only literals and frame columns through
+// row-wise functions, so filtering its columns first changes no row's
+// result. columns reports whether bare identifiers name frame columns, as
+// they do in filter conditions.
+func (c *Compiler) lazyOperand(e Expr, columns bool) bool {
+	expensive, ok := c.rowwise(e, columns)
+	return ok && expensive
+}
+
+// rowwise reports whether e can be evaluated on a subset of rows (ok) and
+// whether it calls any of lazyFuncs (expensive).
+func (c *Compiler) rowwise(e Expr, columns bool) (expensive, ok bool) {
+	switch e := e.(type) {
+	case *IntLit, *FloatLit, *StringLit, *BoolLit:
+		return false, true
+	case *Ident:
+		_, known := c.variables[e.Name]
+		return false, columns && !known
+	case *MemberExpr:
+		obj, isIdent := e.Object.(*Ident)
+		if !isIdent {
+			return false, false
+		}
+		info, known := c.variables[obj.Name]
+		return false, known && info.regType == "R"
+	case *UnaryExpr:
+		return c.rowwise(e.Right, columns)
+	case *BinaryExpr:
+		le, lok := c.rowwise(e.Left, columns)
+		re, rok := c.rowwise(e.Right, columns)
+		return le || re, lok && rok
+	case *CallExpr:
+		name := strings.ToLower(e.Func)
+		if !rowwiseFuncs[name] || len(e.Named) > 0 {
+			return false, false
+		}
+		expensive = lazyFuncs[name]
+		for _, arg := range e.Args {
+			ae, aok := c.rowwise(arg, columns)
+			if !aok {
+				return false, false
+			}
+			expensive = expensive || ae
+		}
+		return expensive, true
+	}
+	return false, false
+}
+
+// compileLazyLogical compiles a && b or a || b so that b only sees the rows
+// a leaves undecided: those where a is true for &&, false for ||. Columns
+// read by b are filtered to those rows and MASKED_SELECT spreads b's result
+// back over the full length.
+func (c *Compiler) compileLazyLogical(e *BinaryExpr, compile func(Expr) (regInfo, error)) (regInfo, error) {
+	left, err := compile(e.Left)
+	if err != nil {
+		return regInfo{}, err
+	}
+	if left.regType != "V" {
+		right, err := compile(e.Right)
+		if err != nil {
+			return regInfo{}, err
+		}
+		return c.compileVectorBinary(e.Op, left, right)
+	}
+
+	lane := left
+	if e.Op == TokenOr {
+		lane = regInfo{"V", c.allocVReg()}
+		c.emitOp("NOT", vreg(lane.regNum), vreg(left.regNum))
+	}
+	c.lanes = append(c.lanes, lane)
+	right, err := compile(e.Right)
+	c.lanes = c.lanes[:len(c.lanes)-1]
+	if err != nil {
+		return regInfo{}, err
+	}
+	if right.regType != "V" {
+		return regInfo{}, fmt.Errorf("logical operand must be a vector")
+	}
+
+	// Reuse right's register for the result: V registers are scarce and
+	// the partial result is dead once spread.
+	c.emitOp("MASKED_SELECT", vreg(right.regNum), vreg(lane.regNum), vreg(right.regNum))
+	if e.Op == TokenOr {
+		c.emitOp("OR", vreg(right.regNum), vreg(left.regNum), vreg(right.regNum))
+	}
+	return right, nil
+}
+
+// restrict filters a freshly selected column, in place, down to the rows
+// kept by the enclosing lazy && / || operands, outermost first.
+func (c *Compiler) restrict(v regInfo) regInfo {
+	for _, lane := range c.lanes {
+		c.emitOp("FILTER", vreg(v.regNum), vreg(v.regNum), vreg(lane.regNum))
+	}
+	return v
+}
+
 func (c *Compiler) compileSelect(e *SelectExpr, input regInfo) (regInfo, error) {
 	// For select, we just store which columns are selected
 	// The actual selection happens when we need the columns
 	for _, col := range e.Columns {
 		if ident, ok := col.(*Ident); ok {
 			vReg := c.allocVReg()
-			c.emit("SELECT_COL    V%d, R%d, \"%s\"", vReg, input.regNum, ident.Name)
+			c.emitOp("SELECT_COL", vreg(vReg), rreg(input.regNum), strOperand(ident.Name))
 			c.variables[ident.Name] = regInfo{"V", vReg}
 		}
 	}
@@ -410,8 +712,8 @@ func (c *Compiler) compileExprWithFrame(expr Expr, frame regInfo) (regInfo, erro
 		}
 		// Otherwise, select from frame
 		vReg := c.allocVReg()
-		c.emit("SELECT_COL    V%d, R%d, \"%s\"", vReg, frame.regNum, e.Name)
-		return regInfo{"V", vReg}, nil
+		c.emitOp("SELECT_COL", vreg(vReg), rreg(frame.regNum), strOperand(e.Name))
+		return c.restrict(regInfo{"V", vReg}), nil
 	case *BinaryExpr:
 		left, err := c.compileExprWithFrame(e.Left, frame)
 		if err != nil {
@@ -425,6 +727,30 @@ func (c *Compiler) compileExprWithFrame(expr Expr, frame regInfo) (regInfo, erro
 			return c.compileVectorBinary(e.Op, left, right)
 		}
 		return c.compileScalarBinary(e.Op, left, right)
+	case *CallExpr:
+		// Bare column names passed to functions resolve against the frame
+		// for the duration of the call only.
+		var bound []string
+		for _, arg := range e.Args {
+			id, ok := arg.(*Ident)
+			if !ok {
+				continue
+			}
+			if _, known := c.variables[id.Name]; known {
+				continue
+			}
+			col, err := c.compileExprWithFrame(id, frame)
+			if err != nil {
+				return regInfo{}, err
+			}
+			c.variables[id.Name] = col
+			bound = append(bound, id.Name)
+		}
+		result, err := c.compileCall(e)
+		for _, name := range bound {
+			delete(c.variables, name)
+		}
+		return result, err
 	default:
 		return c.compileExpr(expr)
 	}
@@ -438,11 +764,11 @@ func (c *Compiler) compileGroupBy(e *GroupByExpr, input regInfo) (regInfo, error
 	// Select the key column
 	keyCol := e.Keys[0]
 	keyVReg := c.allocVReg()
-	c.emit("SELECT_COL    V%d, R%d, \"%s\"", keyVReg, input.regNum, keyCol)
+	c.emitOp("SELECT_COL", vreg(keyVReg), rreg(input.regNum), strOperand(keyCol))
 
 	// Create groupby result
 	gbReg := c.allocReg()
-	c.emit("GROUP_BY      R%d, V%d", gbReg, keyVReg)
+	c.emitOp("GROUP_BY", rreg(gbReg), vreg(keyVReg))
 	c.groupByReg = gbReg
 
 	return regInfo{"R", gbReg}, nil
@@ -457,7 +783,7 @@ func (c *Compiler) compileSummarize(e *SummarizeExpr, input regInfo) (regInfo, e
 		switch strings.ToLower(agg.Func) {
 		case "count":
 			vReg := c.allocVReg()
-			c.emit("GROUP_COUNT   V%d, R%d", vReg, c.groupByReg)
+			c.emitOp("GROUP_COUNT", vreg(vReg), rreg(c.groupByReg))
 			c.variables[agg.Name] = regInfo{"V", vReg}
 
 		case "sum":
@@ -468,7 +794,7 @@ func (c *Compiler) compileSummarize(e *SummarizeExpr, input regInfo) (regInfo, e
 					return regInfo{}, err
 				}
 				vReg := c.allocVReg()
-				c.emit("GROUP_SUM     V%d, R%d, V%d", vReg, c.groupByReg, colInfo.regNum)
+				c.emitOp("GROUP_SUM", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
 				c.variables[agg.Name] = regInfo{"V", vReg}
 			}
 
@@ -479,7 +805,36 @@ func (c *Compiler) compileSummarize(e *SummarizeExpr, input regInfo) (regInfo, e
 					return regInfo{}, err
 				}
 				vReg := c.allocVReg()
-				c.emit("GROUP_MEAN    V%d, R%d, V%d", vReg, c.groupByReg, colInfo.regNum)
+				c.emitOp("GROUP_MEAN", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
+				c.variables[agg.Name] = regInfo{"V", vReg}
+			}
+
+		case "median":
+			if len(agg.Args) > 0 {
+				colInfo, err := c.compileExpr(agg.Args[0])
+				if err != nil {
+					return regInfo{}, err
+				}
+				vReg := c.allocVReg()
+				c.emitOp("GROUP_MEDIAN_F", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
+				c.variables[agg.Name] = regInfo{"V", vReg}
+			}
+
+		case "quantile":
+			if len(agg.Args) >= 2 {
+				colInfo, err := c.compileExpr(agg.Args[0])
+				if err != nil {
+					return regInfo{}, err
+				}
+				q, ok := numberLiteral(agg.Args[1])
+				if !ok {
+					return regInfo{}, fmt.Errorf("quantile requires numeric literal between 0 and 1")
+				}
+				if q < 0 || q > 1 {
+					return regInfo{}, fmt.Errorf("quantile %g out of range [0, 1]", q)
+				}
+				vReg := c.allocVReg()
+				c.emitOp("GROUP_QUANTILE_F", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum), floatOperand(q))
 				c.variables[agg.Name] = regInfo{"V", vReg}
 			}
 
@@ -490,7 +845,7 @@ func (c *Compiler) compileSummarize(e *SummarizeExpr, input regInfo) (regInfo, e
 					return regInfo{}, err
 				}
 				vReg := c.allocVReg()
-				c.emit("GROUP_MIN     V%d, R%d, V%d", vReg, c.groupByReg, colInfo.regNum)
+				c.emitOp("GROUP_MIN", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
 				c.variables[agg.Name] = regInfo{"V", vReg}
 			}
 
@@ -501,7 +856,48 @@ func (c *Compiler) compileSummarize(e *SummarizeExpr, input regInfo) (regInfo, e
 					return regInfo{}, err
 				}
 				vReg := c.allocVReg()
-				c.emit("GROUP_MAX     V%d, R%d, V%d", vReg, c.groupByReg, colInfo.regNum)
+				c.emitOp("GROUP_MAX", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
+				c.variables[agg.Name] = regInfo{"V", vReg}
+			}
+
+		case "first":
+			if len(agg.Args) > 0 {
+				colInfo, err := c.compileExpr(agg.Args[0])
+				if err != nil {
+					return regInfo{}, err
+				}
+				vReg := c.allocVReg()
+				c.emitOp("GROUP_FIRST", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
+				c.variables[agg.Name] = regInfo{"V", vReg}
+			}
+
+		case "last":
+			if len(agg.Args) > 0 {
+				colInfo, err := c.compileExpr(agg.Args[0])
+				if err != nil {
+					return regInfo{}, err
+				}
+				vReg := c.allocVReg()
+				c.emitOp("GROUP_LAST", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum))
+				c.variables[agg.Name] = regInfo{"V", vReg}
+			}
+
+		case "group_concat":
+			if len(agg.Args) > 0 {
+				colInfo, err := c.compileExpr(agg.Args[0])
+				if err != nil {
+					return regInfo{}, err
+				}
+				sep := ","
+				if len(agg.Args) > 1 {
+					str, ok := agg.Args[1].(*StringLit)
+					if !ok {
+						return regInfo{}, fmt.Errorf("group_concat requires a string literal delimiter")
+					}
+					sep = str.Value
+				}
+				vReg := c.allocVReg()
+				c.emitOp("GROUP_CONCAT", vreg(vReg), rreg(c.groupByReg), vreg(colInfo.regNum), strOperand(sep))
 				c.variables[agg.Name] = regInfo{"V", vReg}
 			}
 		}
@@ -520,33 +916,67 @@ func (c *Compiler) compileJoin(e *JoinExpr, input regInfo) (regInfo, error) {
 
 	switch e.JoinType {
 	case "inner":
-		c.emit("JOIN_INNER    R%d, R%d, R%d, \"%s\"", resultReg, input.regNum, right.regNum, e.On)
+		c.emitOp("JOIN_INNER", rreg(resultReg), rreg(input.regNum), rreg(right.regNum), strOperand(e.On))
 	case "left":
-		c.emit("JOIN_LEFT     R%d, R%d, R%d, \"%s\"", resultReg, input.regNum, right.regNum, e.On)
+		c.emitOp("JOIN_LEFT", rreg(resultReg), rreg(input.regNum), rreg(right.regNum), strOperand(e.On))
 	case "right":
-		c.emit("JOIN_RIGHT    R%d, R%d, R%d, \"%s\"", resultReg, input.regNum, right.regNum, e.On)
+		c.emitOp("JOIN_RIGHT", rreg(resultReg), rreg(input.regNum), rreg(right.regNum), strOperand(e.On))
 	case "outer":
-		c.emit("JOIN_OUTER    R%d, R%d, R%d, \"%s\"", resultReg, input.regNum, right.regNum, e.On)
+		c.emitOp("JOIN_OUTER", rreg(resultReg), rreg(input.regNum), rreg(right.regNum), strOperand(e.On))
+	case "cross":
+		c.emitOp("JOIN_CROSS", rreg(resultReg), rreg(input.regNum), rreg(right.regNum))
 	}
 
 	return regInfo{"R", resultReg}, nil
 }
 
+func (c *Compiler) compilePivot(e *PivotExpr, input regInfo) (regInfo, error) {
+	if e.Frame != nil {
+		frame, err := c.compileExpr(e.Frame)
+		if err != nil {
+			return regInfo{}, err
+		}
+		input = frame
+	}
+	if input.regType != "R" {
+		return regInfo{}, fmt.Errorf("pivot requires a frame")
+	}
+	if e.Index == "" || e.Key == "" || e.Value == "" {
+		return regInfo{}, fmt.Errorf("pivot requires index, key and value columns")
+	}
+
+	resultReg := c.allocReg()
+	c.emitOp("PIVOT", rreg(resultReg), rreg(input.regNum), strOperand(e.Index), strOperand(e.Key), strOperand(e.Value))
+	return regInfo{"R", resultReg}, nil
+}
+
 func (c *Compiler) compileLoadJSON(e *LoadJSONExpr) (regInfo, error) {
 	reg := c.allocReg()
-	c.emit("LOAD_JSON     R%d, \"%s\"", reg, e.Path)
+	c.emitOp("LOAD_JSON", rreg(reg), strOperand(e.Path))
+	return regInfo{"R", reg}, nil
+}
+
+func (c *Compiler) compileLoadJSONL(e *LoadJSONLExpr) (regInfo, error) {
+	reg := c.allocReg()
+	c.emitOp("LOAD_JSONL", rreg(reg), strOperand(e.Path))
+	return regInfo{"R", reg}, nil
+}
+
+func (c *Compiler) compileLoadURL(e *LoadURLExpr) (regInfo, error) {
+	reg := c.allocReg()
+	c.emitOp("LOAD_HTTP", rreg(reg), strOperand(e.URL))
 	return regInfo{"R", reg}, nil
 }
 
 func (c *Compiler) compileLoadParquet(e *LoadParquetExpr) (regInfo, error) {
 	reg := c.allocReg()
-	c.emit("LOAD_PARQUET  R%d, \"%s\"", reg, e.Path)
+	c.emitOp("LOAD_PARQUET", rreg(reg), strOperand(e.Path))
 	return regInfo{"R", reg}, nil
 }
 
 func (c *Compiler) compileNewFrame(e *NewFrameExpr) (regInfo, error) {
 	reg := c.allocReg()
-	c.emit("NEW_FRAME     R%d", reg)
+	c.emitOp("NEW_FRAME", rreg(reg))
 	return regInfo{"R", reg}, nil
 }
 
@@ -560,9 +990,9 @@ func (c *Compiler) compileTake(e *TakeExpr, input regInfo) (regInfo, error) {
 		// Taking from a vector
 		vReg := c.allocVReg()
 		if count.regType == "R" {
-			c.emit("TAKE          V%d, V%d, R%d", vReg, input.regNum, count.regNum)
+			c.emitOp("TAKE", vreg(vReg), vreg(input.regNum), rreg(count.regNum))
 		} else if count.regType == "V" {
-			c.emit("TAKE          V%d, V%d, V%d", vReg, input.regNum, count.regNum)
+			c.emitOp("TAKE", vreg(vReg), vreg(input.regNum), vreg(count.regNum))
 		}
 		return regInfo{"V", vReg}, nil
 	}
@@ -580,7 +1010,20 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "V" {
 				fReg := c.allocFReg()
-				c.emit("REDUCE_SUM_F  F%d, V%d", fReg, arg.regNum)
+				c.emitOp("REDUCE_SUM_F", freg(fReg), vreg(arg.regNum))
+				return regInfo{"F", fReg}, nil
+			}
+		}
+
+	case "product":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				fReg := c.allocFReg()
+				c.emitOp("REDUCE_PROD_F", freg(fReg), vreg(arg.regNum))
 				return regInfo{"F", fReg}, nil
 			}
 		}
@@ -593,7 +1036,7 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "V" {
 				rReg := c.allocReg()
-				c.emit("REDUCE_COUNT  R%d, V%d", rReg, arg.regNum)
+				c.emitOp("REDUCE_COUNT", rreg(rReg), vreg(arg.regNum))
 				return regInfo{"R", rReg}, nil
 			}
 		}
@@ -606,12 +1049,16 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "V" {
 				fReg := c.allocFReg()
-				c.emit("REDUCE_MEAN   F%d, V%d", fReg, arg.regNum)
+				c.emitOp("REDUCE_MEAN", freg(fReg), vreg(arg.regNum))
 				return regInfo{"F", fReg}, nil
 			}
 		}
 
 	case "min":
+		if len(e.Args) == 2 {
+			// min(a, b) with two arguments is elementwise, like vmin
+			return c.compileVecMinMax(strings.ToLower(e.Func), "VEC_MIN_F", e.Args[0], e.Args[1])
+		}
 		if len(e.Args) > 0 {
 			arg, err := c.compileExpr(e.Args[0])
 			if err != nil {
@@ -619,12 +1066,15 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "V" {
 				fReg := c.allocFReg()
-				c.emit("REDUCE_MIN_F  F%d, V%d", fReg, arg.regNum)
+				c.emitOp("REDUCE_MIN_F", freg(fReg), vreg(arg.regNum))
 				return regInfo{"F", fReg}, nil
 			}
 		}
 
 	case "max":
+		if len(e.Args) == 2 {
+			return c.compileVecMinMax(strings.ToLower(e.Func), "VEC_MAX_F", e.Args[0], e.Args[1])
+		}
 		if len(e.Args) > 0 {
 			arg, err := c.compileExpr(e.Args[0])
 			if err != nil {
@@ -632,125 +1082,450 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "V" {
 				fReg := c.allocFReg()
-				c.emit("REDUCE_MAX_F  F%d, V%d", fReg, arg.regNum)
+				c.emitOp("REDUCE_MAX_F", freg(fReg), vreg(arg.regNum))
 				return regInfo{"F", fReg}, nil
 			}
 		}
 
-	case "upper":
-		if len(e.Args) > 0 {
-			arg, err := c.compileExpr(e.Args[0])
-			if err != nil {
-				return regInfo{}, err
-			}
-			if arg.regType == "V" {
-				vReg := c.allocVReg()
-				c.emit("STR_UPPER     V%d, V%d", vReg, arg.regNum)
-				return regInfo{"V", vReg}, nil
-			}
+	case "vmin":
+		if len(e.Args) == 2 {
+			return c.compileVecMinMax(strings.ToLower(e.Func), "VEC_MIN_F", e.Args[0], e.Args[1])
 		}
 
-	case "lower":
+	case "vmax":
+		if len(e.Args) == 2 {
+			return c.compileVecMinMax(strings.ToLower(e.Func), "VEC_MAX_F", e.Args[0], e.Args[1])
+		}
+
+	case "any":
 		if len(e.Args) > 0 {
 			arg, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
 			if arg.regType == "V" {
-				vReg := c.allocVReg()
-				c.emit("STR_LOWER     V%d, V%d", vReg, arg.regNum)
-				return regInfo{"V", vReg}, nil
+				rReg := c.allocReg()
+				c.emitOp("REDUCE_ANY", rreg(rReg), vreg(arg.regNum))
+				return regInfo{"R", rReg}, nil
 			}
 		}
 
-	case "trim":
+	case "all":
 		if len(e.Args) > 0 {
 			arg, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
 			if arg.regType == "V" {
-				vReg := c.allocVReg()
-				c.emit("STR_TRIM      V%d, V%d", vReg, arg.regNum)
-				return regInfo{"V", vReg}, nil
+				rReg := c.allocReg()
+				c.emitOp("REDUCE_ALL", rreg(rReg), vreg(arg.regNum))
+				return regInfo{"R", rReg}, nil
 			}
 		}
 
-	case "len", "length":
-		if len(e.Args) > 0 {
+	case "quantile":
+		if len(e.Args) >= 2 {
 			arg, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
-			if arg.regType == "V" {
-				vReg := c.allocVReg()
-				c.emit("STR_LEN       V%d, V%d", vReg, arg.regNum)
-				return regInfo{"V", vReg}, nil
+			if arg.regType != "V" {
+				return regInfo{}, fmt.Errorf("quantile requires vector input")
 			}
+			q, ok := numberLiteral(e.Args[1])
+			if !ok {
+				return regInfo{}, fmt.Errorf("quantile requires numeric literal between 0 and 1")
+			}
+			if q < 0 || q > 1 {
+				return regInfo{}, fmt.Errorf("quantile %g out of range [0, 1]", q)
+			}
+			fReg := c.allocFReg()
+			c.emitOp("REDUCE_QUANTILE_F", freg(fReg), vreg(arg.regNum), floatOperand(q))
+			return regInfo{"F", fReg}, nil
 		}
 
-	case "contains":
+	case "corr", "cov":
 		if len(e.Args) >= 2 {
-			col, err := c.compileExpr(e.Args[0])
+			left, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
-			if col.regType != "V" {
-				return regInfo{}, fmt.Errorf("contains requires vector input")
+			right, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
 			}
-			if str, ok := e.Args[1].(*StringLit); ok {
-				c.addConstant(fmt.Sprintf("\"%s\"", str.Value))
-				vReg := c.allocVReg()
-				c.emit("STR_CONTAINS  V%d, V%d, \"%s\"", vReg, col.regNum, str.Value)
-				return regInfo{"V", vReg}, nil
+			if left.regType != "V" || right.regType != "V" {
+				return regInfo{}, fmt.Errorf("%s requires two vector inputs", strings.ToLower(e.Func))
 			}
-			return regInfo{}, fmt.Errorf("contains requires string literal pattern")
+			fReg := c.allocFReg()
+			if strings.ToLower(e.Func) == "corr" {
+				c.emitOp("CORR_F", freg(fReg), vreg(left.regNum), vreg(right.regNum))
+			} else {
+				c.emitOp("COV_F", freg(fReg), vreg(left.regNum), vreg(right.regNum))
+			}
+			return regInfo{"F", fReg}, nil
 		}
 
-	case "starts_with":
-		if len(e.Args) >= 2 {
-			col, err := c.compileExpr(e.Args[0])
+	case "wmean":
+		if len(e.Args) == 2 {
+			values, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
-			if col.regType != "V" {
-				return regInfo{}, fmt.Errorf("starts_with requires vector input")
+			weights, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
 			}
-			if str, ok := e.Args[1].(*StringLit); ok {
-				vReg := c.allocVReg()
-				c.emit("STR_STARTS_WITH V%d, V%d, \"%s\"", vReg, col.regNum, str.Value)
-				return regInfo{"V", vReg}, nil
+			if values.regType != "V" || weights.regType != "V" {
+				return regInfo{}, fmt.Errorf("wmean requires two vector inputs")
 			}
+			fReg := c.allocFReg()
+			c.emitOp("REDUCE_WMEAN_F", freg(fReg), vreg(values.regNum), vreg(weights.regNum))
+			return regInfo{"F", fReg}, nil
 		}
 
-	case "ends_with":
+	case "in":
 		if len(e.Args) >= 2 {
 			col, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
 			if col.regType != "V" {
-				return regInfo{}, fmt.Errorf("ends_with requires vector input")
+				return regInfo{}, fmt.Errorf("in requires vector input")
 			}
-			if str, ok := e.Args[1].(*StringLit); ok {
-				vReg := c.allocVReg()
-				c.emit("STR_ENDS_WITH V%d, V%d, \"%s\"", vReg, col.regNum, str.Value)
-				return regInfo{"V", vReg}, nil
+			values := make([]Operand, 0, len(e.Args)-1)
+			for _, a := range e.Args[1:] {
+				if str, ok := a.(*StringLit); ok {
+					values = append(values, strOperand(str.Value))
+				} else if n, ok := intLiteral(a); ok {
+					values = append(values, intOperand(n))
+				} else if f, ok := numberLiteral(a); ok {
+					// Whole floats are read as integers, as in the assembler
+					if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+						values = append(values, intOperand(int64(f)))
+					} else {
+						values = append(values, floatOperand(f))
+					}
+				} else {
+					return regInfo{}, fmt.Errorf("in requires literal set values")
+				}
 			}
+			vReg := c.allocVReg()
+			c.emitOp("IN_SET", append([]Operand{vreg(vReg), vreg(col.regNum)}, values...)...)
+			return regInfo{"V", vReg}, nil
 		}
 
-	case "split":
-		if len(e.Args) >= 2 {
+	case "between":
+		if len(e.Args) == 3 {
 			col, err := c.compileExpr(e.Args[0])
 			if err != nil {
 				return regInfo{}, err
 			}
-			if col.regType != "V" {
+			lo, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			hi, err := c.compileExpr(e.Args[2])
+			if err != nil {
+				return regInfo{}, err
+			}
+			// Inclusive on both ends: col >= lo AND col <= hi
+			ge, err := c.compileVectorBinary(TokenGE, col, lo)
+			if err != nil {
+				return regInfo{}, err
+			}
+			le, err := c.compileVectorBinary(TokenLE, col, hi)
+			if err != nil {
+				return regInfo{}, err
+			}
+			return c.compileVectorBinary(TokenAnd, ge, le)
+		}
+
+	case "is_null", "is_not_null":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				c.emitOp(strings.ToUpper(e.Func), vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "is_nan", "is_inf":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "V" {
+				return regInfo{}, fmt.Errorf("%s requires vector input", strings.ToLower(e.Func))
+			}
+			vReg := c.allocVReg()
+			c.emitOp(strings.ToUpper(e.Func)+"_F", vreg(vReg), vreg(arg.regNum))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "xor":
+		if len(e.Args) == 2 {
+			left, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			right, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if left.regType != "V" || right.regType != "V" {
+				return regInfo{}, fmt.Errorf("xor requires two vector inputs")
+			}
+			vReg := c.allocVReg()
+			c.emitOp("XOR", vreg(vReg), vreg(left.regNum), vreg(right.regNum))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "upper":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				c.emitOp("STR_UPPER", vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "lower":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				c.emitOp("STR_LOWER", vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "title", "capitalize":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				c.emitOp("STR_"+strings.ToUpper(e.Func), vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "trim":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				if len(e.Args) >= 2 {
+					cutset, ok := e.Args[1].(*StringLit)
+					if !ok {
+						return regInfo{}, fmt.Errorf("trim requires string literal cutset")
+					}
+					c.emitOp("STR_TRIM_CHARS", vreg(vReg), vreg(arg.regNum), strOperand(cutset.Value))
+					return regInfo{"V", vReg}, nil
+				}
+				c.emitOp("STR_TRIM", vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "ltrim", "rtrim":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				op := "STR_TRIM_LEFT"
+				if strings.ToLower(e.Func) == "rtrim" {
+					op = "STR_TRIM_RIGHT"
+				}
+				vReg := c.allocVReg()
+				c.emitOp(op, vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "len", "length":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "V" {
+				vReg := c.allocVReg()
+				c.emitOp("STR_LEN", vreg(vReg), vreg(arg.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "contains":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("contains requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_CONTAINS", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("contains requires string literal pattern")
+		}
+
+	case "matches":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("matches requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_REGEX_MATCH", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("matches requires string literal pattern")
+		}
+
+	case "extract":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("extract requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_REGEX_EXTRACT", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("extract requires string literal pattern")
+		}
+
+	case "substr":
+		if len(e.Args) == 3 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("substr requires vector input")
+			}
+			start, okStart := intLiteral(e.Args[1])
+			length, okLen := intLiteral(e.Args[2])
+			if !okStart || !okLen {
+				return regInfo{}, fmt.Errorf("substr requires integer literal start and length")
+			}
+			vReg := c.allocVReg()
+			c.emitOp("STR_SUBSTR", vreg(vReg), vreg(col.regNum), intOperand(start), intOperand(length))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "pad_left", "pad_right":
+		if len(e.Args) == 3 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("%s requires vector input", e.Func)
+			}
+			width, okWidth := intLiteral(e.Args[1])
+			pad, okPad := e.Args[2].(*StringLit)
+			if !okWidth || !okPad {
+				return regInfo{}, fmt.Errorf("%s requires an integer width and a string pad character", e.Func)
+			}
+			vReg := c.allocVReg()
+			c.emitOp("STR_"+strings.ToUpper(e.Func), vreg(vReg), vreg(col.regNum), intOperand(width), strOperand(pad.Value))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "icontains":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("icontains requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_CONTAINS_CI", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("icontains requires string literal pattern")
+		}
+
+	case "starts_with":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("starts_with requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_STARTS_WITH", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "ends_with":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("ends_with requires vector input")
+			}
+			if str, ok := e.Args[1].(*StringLit); ok {
+				vReg := c.allocVReg()
+				c.emitOp("STR_ENDS_WITH", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+		}
+
+	case "split":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
 				return regInfo{}, fmt.Errorf("split requires vector input")
 			}
 			if str, ok := e.Args[1].(*StringLit); ok {
 				vReg := c.allocVReg()
-				c.emit("STR_SPLIT     V%d, V%d, \"%s\"", vReg, col.regNum, str.Value)
+				c.emitOp("STR_SPLIT", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
 				return regInfo{"V", vReg}, nil
 			}
 		}
@@ -768,7 +1543,7 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			new, okNew := e.Args[2].(*StringLit)
 			if okOld && okNew {
 				vReg := c.allocVReg()
-				c.emit("STR_REPLACE   V%d, V%d, \"%s\", \"%s\"", vReg, col.regNum, old.Value, new.Value)
+				c.emitOp("STR_REPLACE", vreg(vReg), vreg(col.regNum), strOperand(old.Value), strOperand(new.Value))
 				return regInfo{"V", vReg}, nil
 			}
 		}
@@ -785,7 +1560,143 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if left.regType == "V" && right.regType == "V" {
 				vReg := c.allocVReg()
-				c.emit("STR_CONCAT    V%d, V%d, V%d", vReg, left.regNum, right.regNum)
+				c.emitOp("STR_CONCAT", vreg(vReg), vreg(left.regNum), vreg(right.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+			if left.regType == "R" && right.regType == "R" {
+				// Two frames: stack rows vertically
+				rReg := c.allocReg()
+				c.emitOp("CONCAT", rreg(rReg), rreg(left.regNum), rreg(right.regNum))
+				return regInfo{"R", rReg}, nil
+			}
+		}
+
+	case "dropna":
+		if len(e.Args) >= 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "R" {
+				return regInfo{}, fmt.Errorf("dropna requires a frame")
+			}
+			var columns []Operand
+			for _, a := range e.Args[1:] {
+				str, ok := a.(*StringLit)
+				if !ok {
+					return regInfo{}, fmt.Errorf("dropna column names must be strings")
+				}
+				columns = append(columns, strOperand(str.Value))
+			}
+			rReg := c.allocReg()
+			if len(columns) == 0 {
+				c.emitOp("DROP_NA", rreg(rReg), rreg(arg.regNum))
+			} else {
+				c.emitOp("DROP_NA", append([]Operand{rreg(rReg), rreg(arg.regNum)}, columns...)...)
+			}
+			return regInfo{"R", rReg}, nil
+		}
+
+	case "top_n":
+		if len(e.Args) == 3 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "R" {
+				return regInfo{}, fmt.Errorf("top_n requires a frame")
+			}
+			var colName string
+			switch col := e.Args[1].(type) {
+			case *Ident:
+				colName = col.Name
+			case *StringLit:
+				colName = col.Value
+			default:
+				return regInfo{}, fmt.Errorf("top_n requires a column name")
+			}
+			n, ok := intLiteral(e.Args[2])
+			if !ok {
+				return regInfo{}, fmt.Errorf("top_n requires an integer literal row count")
+			}
+			order := "desc"
+			for name, v := range e.Named {
+				b, ok := v.(*BoolLit)
+				if name != "desc" || !ok {
+					return regInfo{}, fmt.Errorf("unknown top_n argument: %s", name)
+				}
+				if !b.Value {
+					order = "asc"
+				}
+			}
+			rReg := c.allocReg()
+			c.emitOp("TOP_N", rreg(rReg), rreg(arg.regNum), strOperand(colName), intOperand(n), strOperand(order))
+			return regInfo{"R", rReg}, nil
+		}
+
+	case "sample":
+		if len(e.Args) == 2 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "R" {
+				return regInfo{}, fmt.Errorf("sample requires a frame")
+			}
+			n, ok := intLiteral(e.Args[1])
+			if !ok || n < 0 {
+				return regInfo{}, fmt.Errorf("sample requires a non-negative integer literal row count")
+			}
+			rReg := c.allocReg()
+			operands := []Operand{rreg(rReg), rreg(arg.regNum), intOperand(n)}
+			for name, v := range e.Named {
+				s, ok := intLiteral(v)
+				if name != "seed" || !ok {
+					return regInfo{}, fmt.Errorf("unknown sample argument: %s", name)
+				}
+				operands = append(operands, intOperand(s))
+			}
+			c.emitOp("SAMPLE", operands...)
+			return regInfo{"R", rReg}, nil
+		}
+
+	case "shuffle":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "R" {
+				return regInfo{}, fmt.Errorf("shuffle requires a frame")
+			}
+			rReg := c.allocReg()
+			c.emitOp("SHUFFLE", rreg(rReg), rreg(arg.regNum))
+			return regInfo{"R", rReg}, nil
+		}
+
+	case "group_sizes":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType != "R" {
+				return regInfo{}, fmt.Errorf("group_sizes requires a group_by result")
+			}
+			rReg := c.allocReg()
+			c.emitOp("GROUP_SIZES", rreg(rReg), rreg(arg.regNum))
+			return regInfo{"R", rReg}, nil
+		}
+
+	case "row_index":
+		if len(e.Args) > 0 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if arg.regType == "R" {
+				vReg := c.allocVReg()
+				c.emitOp("ROW_INDEX", vreg(vReg), rreg(arg.regNum))
 				return regInfo{"V", vReg}, nil
 			}
 		}
@@ -798,7 +1709,7 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "R" {
 				rReg := c.allocReg()
-				c.emit("ROW_COUNT     R%d, R%d", rReg, arg.regNum)
+				c.emitOp("ROW_COUNT", rreg(rReg), rreg(arg.regNum))
 				return regInfo{"R", rReg}, nil
 			}
 		}
@@ -811,7 +1722,7 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			}
 			if arg.regType == "R" {
 				rReg := c.allocReg()
-				c.emit("COL_COUNT     R%d, R%d", rReg, arg.regNum)
+				c.emitOp("COL_COUNT", rreg(rReg), rreg(arg.regNum))
 				return regInfo{"R", rReg}, nil
 			}
 		}
@@ -836,14 +1747,407 @@ func (c *Compiler) compileCall(e *CallExpr) (regInfo, error) {
 			if col.regType != "V" {
 				return regInfo{}, fmt.Errorf("add_col requires vector as third argument")
 			}
-			c.emit("ADD_COL       R%d, V%d, \"%s\"", frame.regNum, col.regNum, name.Value)
+			c.emitOp("ADD_COL", rreg(frame.regNum), vreg(col.regNum), strOperand(name.Value))
 			return frame, nil
 		}
+
+	case "rank":
+		if len(e.Args) == 1 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("rank requires vector input")
+			}
+			ties, order := "average", "asc"
+			for name, arg := range e.Named {
+				switch name {
+				case "desc":
+					b, ok := arg.(*BoolLit)
+					if !ok {
+						return regInfo{}, fmt.Errorf("rank desc must be true or false")
+					}
+					if b.Value {
+						order = "desc"
+					}
+				case "ties":
+					str, ok := arg.(*StringLit)
+					if !ok {
+						return regInfo{}, fmt.Errorf("rank ties must be a string")
+					}
+					ties = str.Value
+				default:
+					return regInfo{}, fmt.Errorf("unknown rank argument: %s", name)
+				}
+			}
+			vReg := c.allocVReg()
+			c.emitOp("RANK_F", vreg(vReg), vreg(col.regNum), strOperand(ties), strOperand(order))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "fillna":
+		if len(e.Args) == 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("fillna requires vector input")
+			}
+			vReg := c.allocVReg()
+			if str, ok := e.Args[1].(*StringLit); ok {
+				c.emitOp("FILL_NA", vreg(vReg), vreg(col.regNum), strOperand(str.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			if n, ok := intLiteral(e.Args[1]); ok {
+				c.emitOp("FILL_NA", vreg(vReg), vreg(col.regNum), intOperand(n))
+				return regInfo{"V", vReg}, nil
+			}
+			if f, ok := numberLiteral(e.Args[1]); ok {
+				c.emitOp("FILL_NA", vreg(vReg), vreg(col.regNum), floatOperand(f))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("fillna requires a literal fill value")
+		}
+
+	case "assert_eq":
+		if len(e.Args) == 2 {
+			left, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			right, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if left.regType != right.regType {
+				return regInfo{}, fmt.Errorf("assert_eq arguments must both be frames, vectors, ints or floats")
+			}
+			switch {
+			case left.regType != "R":
+				c.emitOp("ASSERT_EQ", regOf(left), regOf(right))
+			case c.isFrameExpr(e.Args[0]) && c.isFrameExpr(e.Args[1]):
+				c.emitOp("ASSERT_EQ", rreg(left.regNum), rreg(right.regNum))
+			case !c.isFrameExpr(e.Args[0]) && !c.isFrameExpr(e.Args[1]):
+				c.emitOp("ASSERT_EQ", rreg(left.regNum), rreg(right.regNum), strOperand("int"))
+			default:
+				return regInfo{}, fmt.Errorf("assert_eq cannot compare a frame with a scalar")
+			}
+			return left, nil
+		}
+
+	case "print":
+		if len(e.Args) == 1 {
+			arg, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			switch {
+			case arg.regType != "R", c.isFrameExpr(e.Args[0]):
+				c.emitOp("PRINT", regOf(arg))
+			default:
+				c.emitOp("PRINT", rreg(arg.regNum), strOperand("int"))
+			}
+			return arg, nil
+		}
+
+	case "coalesce":
+		if len(e.Args) >= 2 {
+			result, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if result.regType != "V" {
+				return regInfo{}, fmt.Errorf("coalesce requires a vector first argument")
+			}
+			// Fold pairwise: coalesce(a, b, c) = COALESCE(COALESCE(a, b), c)
+			for _, arg := range e.Args[1:] {
+				next, err := c.compileExpr(arg)
+				if err != nil {
+					return regInfo{}, err
+				}
+				if next.regType != "V" {
+					next = c.broadcast(next, result)
+				}
+				vReg := c.allocVReg()
+				c.emitOp("COALESCE", vreg(vReg), vreg(result.regNum), vreg(next.regNum))
+				result = regInfo{"V", vReg}
+			}
+			return result, nil
+		}
+
+	case "clamp":
+		if len(e.Args) == 3 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("clamp requires vector input")
+			}
+			lo, okLo := numberLiteral(e.Args[1])
+			hi, okHi := numberLiteral(e.Args[2])
+			if !okLo || !okHi {
+				return regInfo{}, fmt.Errorf("clamp requires numeric literal bounds")
+			}
+			if hi < lo {
+				return regInfo{}, fmt.Errorf("clamp upper bound %g is less than lower bound %g", hi, lo)
+			}
+			vReg := c.allocVReg()
+			c.emitOp("CLAMP_F", vreg(vReg), vreg(col.regNum), floatOperand(lo), floatOperand(hi))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "approx_eq":
+		if len(e.Args) == 3 {
+			left, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			right, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if left.regType != "V" && right.regType != "V" {
+				return regInfo{}, fmt.Errorf("approx_eq requires at least one vector input")
+			}
+			if left.regType != "V" {
+				left = c.broadcast(left, right)
+			}
+			if right.regType != "V" {
+				right = c.broadcast(right, left)
+			}
+			eps, ok := numberLiteral(e.Args[2])
+			if !ok || eps < 0 {
+				return regInfo{}, fmt.Errorf("approx_eq requires a non-negative numeric literal epsilon")
+			}
+			// Plain decimal notation: the assembler has no exponent syntax.
+			vReg := c.allocVReg()
+			c.emitOp("CMP_EQ_EPS_F", vreg(vReg), vreg(left.regNum), vreg(right.regNum), floatOperand(eps))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "bin":
+		if len(e.Args) == 2 || len(e.Args) == 4 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("bin requires vector input")
+			}
+			nbins, ok := intLiteral(e.Args[1])
+			if !ok || nbins < 1 {
+				return regInfo{}, fmt.Errorf("bin requires positive integer literal bin count")
+			}
+			vReg := c.allocVReg()
+			if len(e.Args) == 2 {
+				c.emitOp("BIN_F", vreg(vReg), vreg(col.regNum), intOperand(nbins))
+				return regInfo{"V", vReg}, nil
+			}
+			lo, okLo := numberLiteral(e.Args[2])
+			hi, okHi := numberLiteral(e.Args[3])
+			if !okLo || !okHi {
+				return regInfo{}, fmt.Errorf("bin requires numeric literal min and max")
+			}
+			c.emitOp("BIN_F", vreg(vReg), vreg(col.regNum), intOperand(nbins), floatOperand(lo), floatOperand(hi))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "shift":
+		if len(e.Args) >= 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("shift requires vector input")
+			}
+			n, ok := intLiteral(e.Args[1])
+			if !ok {
+				return regInfo{}, fmt.Errorf("shift requires integer literal offset")
+			}
+			vReg := c.allocVReg()
+			c.emitOp("SHIFT", vreg(vReg), vreg(col.regNum), intOperand(n))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "format":
+		if len(e.Args) == 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("format requires vector input")
+			}
+			vReg := c.allocVReg()
+			if spec, ok := e.Args[1].(*StringLit); ok {
+				c.emitOp("FORMAT_F", vreg(vReg), vreg(col.regNum), strOperand(spec.Value))
+				return regInfo{"V", vReg}, nil
+			}
+			if n, ok := intLiteral(e.Args[1]); ok && n >= 0 {
+				c.emitOp("FORMAT_F", vreg(vReg), vreg(col.regNum), intOperand(n))
+				return regInfo{"V", vReg}, nil
+			}
+			return regInfo{}, fmt.Errorf("format requires a non-negative decimal count or a format string")
+		}
+
+	case "repeat":
+		if len(e.Args) == 2 {
+			var op string
+			var value Operand
+			if v, ok := intLiteral(e.Args[0]); ok {
+				op, value = "FILL_I", intOperand(v)
+			} else if v, ok := numberLiteral(e.Args[0]); ok {
+				op, value = "FILL_F", floatOperand(v)
+			} else if s, ok := e.Args[0].(*StringLit); ok {
+				op, value = "FILL_STR", strOperand(s.Value)
+			} else {
+				return regInfo{}, fmt.Errorf("repeat requires a literal value")
+			}
+
+			if n, ok := intLiteral(e.Args[1]); ok {
+				if n < 0 {
+					return regInfo{}, fmt.Errorf("repeat requires a non-negative count, got %d", n)
+				}
+				vReg := c.allocVReg()
+				c.emitOp(op, vreg(vReg), value, intOperand(n))
+				return regInfo{"V", vReg}, nil
+			}
+			like, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if like.regType != "V" {
+				return regInfo{}, fmt.Errorf("repeat requires an integer literal count or a vector to match")
+			}
+			vReg := c.allocVReg()
+			c.emitOp(op, vreg(vReg), value, vreg(like.regNum))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "range":
+		if len(e.Args) == 2 || len(e.Args) == 3 {
+			bounds := []int64{0, 0, 1}
+			for i, arg := range e.Args {
+				v, ok := intLiteral(arg)
+				if !ok {
+					return regInfo{}, fmt.Errorf("range requires integer literal arguments")
+				}
+				bounds[i] = v
+			}
+			if bounds[2] == 0 {
+				return regInfo{}, fmt.Errorf("range requires a non-zero step")
+			}
+			vReg := c.allocVReg()
+			c.emitOp("RANGE", vreg(vReg), intOperand(bounds[0]), intOperand(bounds[1]), intOperand(bounds[2]))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "hash":
+		if len(e.Args) == 1 || len(e.Args) == 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("hash requires vector input")
+			}
+			vReg := c.allocVReg()
+			if len(e.Args) == 1 {
+				c.emitOp("HASH_STR", vreg(vReg), vreg(col.regNum))
+				return regInfo{"V", vReg}, nil
+			}
+			buckets, ok := intLiteral(e.Args[1])
+			if !ok || buckets < 1 || buckets > 255 {
+				return regInfo{}, fmt.Errorf("hash requires an integer literal bucket count between 1 and 255")
+			}
+			c.emitOp("HASH_STR", vreg(vReg), vreg(col.regNum), intOperand(buckets))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "parse_date":
+		if len(e.Args) == 2 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("parse_date requires vector input")
+			}
+			layout, ok := e.Args[1].(*StringLit)
+			if !ok {
+				return regInfo{}, fmt.Errorf("parse_date requires a string literal layout")
+			}
+			vReg := c.allocVReg()
+			c.emitOp("PARSE_DATE", vreg(vReg), vreg(col.regNum), strOperand(layout.Value))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "year", "month", "day", "hour":
+		if len(e.Args) == 1 {
+			col, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if col.regType != "V" {
+				return regInfo{}, fmt.Errorf("%s requires vector input", e.Func)
+			}
+			vReg := c.allocVReg()
+			c.emitOp("DATE_PART", vreg(vReg), vreg(col.regNum), strOperand(strings.ToLower(e.Func)))
+			return regInfo{"V", vReg}, nil
+		}
+
+	case "date_add", "date_diff":
+		if len(e.Args) == 2 {
+			left, err := c.compileExpr(e.Args[0])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if left.regType != "V" {
+				return regInfo{}, fmt.Errorf("%s requires a date column first argument", e.Func)
+			}
+			right, err := c.compileExpr(e.Args[1])
+			if err != nil {
+				return regInfo{}, err
+			}
+			if right.regType != "V" {
+				right = c.broadcast(right, left)
+			}
+			mnemonic := "DATE_ADD_DAYS"
+			if strings.ToLower(e.Func) == "date_diff" {
+				mnemonic = "DATE_DIFF_DAYS"
+			}
+			vReg := c.allocVReg()
+			c.emitOp(mnemonic, vreg(vReg), vreg(left.regNum), vreg(right.regNum))
+			return regInfo{"V", vReg}, nil
+		}
 	}
 
 	return regInfo{}, fmt.Errorf("unknown function: %s", e.Func)
 }
 
+// compileVecMinMax emits an elementwise min/max of two vector expressions.
+func (c *Compiler) compileVecMinMax(name, mnemonic string, a, b Expr) (regInfo, error) {
+	left, err := c.compileExpr(a)
+	if err != nil {
+		return regInfo{}, err
+	}
+	right, err := c.compileExpr(b)
+	if err != nil {
+		return regInfo{}, err
+	}
+	if left.regType != "V" || right.regType != "V" {
+		return regInfo{}, fmt.Errorf("%s requires two vector arguments", name)
+	}
+
+	dst := c.allocVReg()
+	c.emitOp(mnemonic, vreg(dst), vreg(left.regNum), vreg(right.regNum))
+	return regInfo{"V", dst}, nil
+}
+
 func (c *Compiler) compileCallWithInput(e *CallExpr, input regInfo) (regInfo, error) {
 	// Same as compileCall but with frame context
 	return c.compileCall(e)
@@ -858,57 +2162,70 @@ func (c *Compiler) compileMember(e *MemberExpr) (regInfo, error) {
 	if obj.regType == "R" {
 		// Accessing column from frame
 		vReg := c.allocVReg()
-		c.emit("SELECT_COL    V%d, R%d, \"%s\"", vReg, obj.regNum, e.Member)
+		c.emitOp("SELECT_COL", vreg(vReg), rreg(obj.regNum), strOperand(e.Member))
 
 		// Check if this frame has a filter mask applied
 		if mask, ok := c.masks[obj.regNum]; ok {
 			// Apply the filter to the selected column
 			filteredReg := c.allocVReg()
-			c.emit("FILTER        V%d, V%d, V%d", filteredReg, vReg, mask.regNum)
-			return regInfo{"V", filteredReg}, nil
+			c.emitOp("FILTER", vreg(filteredReg), vreg(vReg), vreg(mask.regNum))
+			return c.restrict(regInfo{"V", filteredReg}), nil
 		}
 
-		return regInfo{"V", vReg}, nil
+		return c.restrict(regInfo{"V", vReg}), nil
 	}
 
 	return regInfo{}, fmt.Errorf("cannot access member on %s register", obj.regType)
 }
 
+// compileIndex compiles vec[i] to VEC_INDEX_F, reading one element of a
+// vector into a float register like the other column reductions.
+func (c *Compiler) compileIndex(e *IndexExpr) (regInfo, error) {
+	obj, err := c.compileExpr(e.Object)
+	if err != nil {
+		return regInfo{}, err
+	}
+	if obj.regType != "V" {
+		return regInfo{}, fmt.Errorf("only columns can be indexed, got %s register", obj.regType)
+	}
+
+	idx, err := c.compileExpr(e.Index)
+	if err != nil {
+		return regInfo{}, err
+	}
+	if idx.regType != "R" || c.isFrameExpr(e.Index) {
+		return regInfo{}, fmt.Errorf("index must be an integer")
+	}
+
+	fReg := c.allocFReg()
+	c.emitOp("VEC_INDEX_F", freg(fReg), vreg(obj.regNum), rreg(idx.regNum))
+	return regInfo{"F", fReg}, nil
+}
+
 // Helper methods
 
+// allocReg, allocVReg and allocFReg hand out fresh virtual registers.
+// Compile maps them onto physical registers once the program is emitted.
 func (c *Compiler) allocReg() int {
 	r := c.nextReg
 	c.nextReg++
-	if c.nextReg > 15 {
-		c.nextReg = 0 // Wrap around (in practice should error)
-	}
 	return r
 }
 
 func (c *Compiler) allocVReg() int {
 	r := c.nextVReg
 	c.nextVReg++
-	if c.nextVReg > 7 {
-		c.nextVReg = 0
-	}
 	return r
 }
 
 func (c *Compiler) allocFReg() int {
 	r := c.nextFReg
 	c.nextFReg++
-	if c.nextFReg > 15 {
-		c.nextFReg = 0
-	}
 	return r
 }
 
-func (c *Compiler) addConstant(val string) int {
-	c.constants = append(c.constants, val)
-	return len(c.constants) - 1
-}
-
-func (c *Compiler) emit(format string, args ...any) {
-	c.output.WriteString(fmt.Sprintf(format, args...))
-	c.output.WriteString("\n")
+// emitOp appends one instruction to the program. Compile renders the
+// assembly text from these instructions once registers are allocated.
+func (c *Compiler) emitOp(op string, operands ...Operand) {
+	c.insts = append(c.insts, Instruction{Op: op, Operands: operands})
 }
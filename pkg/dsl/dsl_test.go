@@ -1,6 +1,10 @@
 package dsl
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -97,6 +101,7 @@ func TestLexer_Keywords(t *testing.T) {
 		{"count", TokenCount},
 		{"mean", TokenMean},
 		{"return", TokenReturn},
+		{"pivot", TokenPivot},
 	}
 
 	for _, tt := range tests {
@@ -564,6 +569,21 @@ func TestParser_EmptyProgram(t *testing.T) {
 	}
 }
 
+func TestParser_ParseError(t *testing.T) {
+	_, err := NewParser(NewLexer("x = 1\ndata = ((((").Tokenize()).Parse()
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Line != 2 || parseErr.Col != 12 {
+		t.Errorf("expected line 2, col 12, got line %d, col %d", parseErr.Line, parseErr.Col)
+	}
+	if want := "line 2, col 12: " + parseErr.Msg; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
 func TestParser_MultipleStatements(t *testing.T) {
 	input := `
 x = 1
@@ -922,6 +942,8 @@ func TestCompiler_AggregationFunctions(t *testing.T) {
 		{"min", "REDUCE_MIN"},
 		{"max", "REDUCE_MAX"},
 		{"mean", "REDUCE_MEAN"},
+		{"any", "REDUCE_ANY"},
+		{"all", "REDUCE_ALL"},
 	}
 
 	for _, tt := range tests {
@@ -952,6 +974,96 @@ return ` + tt.func_ + `(col)
 	}
 }
 
+func TestCompiler_Product(t *testing.T) {
+	input := `
+data = frame("test")
+col = data.value
+return product(col)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "REDUCE_PROD_F F0, V0") {
+		t.Errorf("expected REDUCE_PROD_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_Quantile(t *testing.T) {
+	input := `
+data = frame("test")
+col = data.value
+return quantile(col, 0.95)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "REDUCE_QUANTILE_F F0, V0, 0.95") {
+		t.Errorf("expected REDUCE_QUANTILE_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_CorrCov(t *testing.T) {
+	tests := []struct {
+		func_    string
+		expected string
+	}{
+		{"corr", "CORR_F"},
+		{"cov", "COV_F"},
+		{"wmean", "REDUCE_WMEAN_F"},
+	}
+
+	for _, tt := range tests {
+		input := `
+data = frame("test")
+x = data.x
+y = data.y
+return ` + tt.func_ + `(x, y)
+`
+		lexer := NewLexer(input)
+		tokens := lexer.Tokenize()
+
+		parser := NewParser(tokens)
+		program, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("parse error for func %q: %v", tt.func_, err)
+		}
+
+		compiler := NewCompiler()
+		asm, err := compiler.Compile(program)
+		if err != nil {
+			t.Fatalf("compile error for func %q: %v", tt.func_, err)
+		}
+
+		if !strings.Contains(asm, tt.expected+" ") {
+			t.Errorf("expected %s in output for func %q, got: %s", tt.expected, tt.func_, asm)
+		}
+	}
+}
+
 func TestCompiler_FilterOperation(t *testing.T) {
 	input := `
 data = frame("test")
@@ -1350,6 +1462,67 @@ func TestParser_ParenExpression(t *testing.T) {
 	}
 }
 
+func TestParser_ObjectLiteral(t *testing.T) {
+	input := `x = { total: sum(price), "label": name }`
+
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	assign := program.Statements[0].(*AssignStmt)
+	obj, ok := assign.Value.(*ObjectLit)
+	if !ok {
+		t.Fatalf("expected ObjectLit, got %T", assign.Value)
+	}
+	if len(obj.Fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(obj.Fields))
+	}
+
+	if obj.Fields[0].Key != "total" {
+		t.Errorf("expected key 'total', got %q", obj.Fields[0].Key)
+	}
+	call, ok := obj.Fields[0].Value.(*CallExpr)
+	if !ok || call.Func != "sum" || len(call.Args) != 1 {
+		t.Errorf("expected sum(price), got %#v", obj.Fields[0].Value)
+	} else if arg, ok := call.Args[0].(*Ident); !ok || arg.Name != "price" {
+		t.Errorf("expected argument price, got %#v", call.Args[0])
+	}
+
+	if obj.Fields[1].Key != "label" {
+		t.Errorf("expected key 'label', got %q", obj.Fields[1].Key)
+	}
+	if ident, ok := obj.Fields[1].Value.(*Ident); !ok || ident.Name != "name" {
+		t.Errorf("expected Ident name, got %#v", obj.Fields[1].Value)
+	}
+}
+
+func TestParser_EmptyObjectLiteral(t *testing.T) {
+	for _, input := range []string{`return {}`, "return {\n}"} {
+		program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", input, err)
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: expected 1 statement, got %d", input, len(program.Statements))
+		}
+
+		ret := program.Statements[0].(*ReturnStmt)
+		obj, ok := ret.Value.(*ObjectLit)
+		if !ok {
+			t.Fatalf("%q: expected ObjectLit, got %T", input, ret.Value)
+		}
+		if len(obj.Fields) != 0 {
+			t.Errorf("%q: expected no fields, got %d", input, len(obj.Fields))
+		}
+	}
+}
+
 func TestParser_FunctionWithMultipleArgs(t *testing.T) {
 	input := `x = concat(a, b)`
 
@@ -1405,6 +1578,10 @@ func TestCompiler_StringFunctions(t *testing.T) {
 		{"lower", "STR_LOWER"},
 		{"len", "STR_LEN"},
 		{"trim", "STR_TRIM"},
+		{"ltrim", "STR_TRIM_LEFT"},
+		{"rtrim", "STR_TRIM_RIGHT"},
+		{"title", "STR_TITLE"},
+		{"capitalize", "STR_CAPITALIZE"},
 	}
 
 	for _, tt := range tests {
@@ -1619,6 +1796,43 @@ func TestParser_IndexExpression(t *testing.T) {
 	_ = index
 }
 
+func TestCompiler_IndexExpr(t *testing.T) {
+	input := `
+data = frame("test")
+i = 1 + 1
+first = data.price[i]
+return data.price[2]
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if n := strings.Count(asm, "VEC_INDEX_F   F"); n != 2 {
+		t.Errorf("expected 2 VEC_INDEX_F instructions, got %d: %s", n, asm)
+	}
+	if !strings.Contains(asm, "HALT_F") {
+		t.Errorf("expected a float result: %s", asm)
+	}
+
+	for _, bad := range []string{
+		"data = frame(\"test\")\nreturn data[0]",
+		"data = frame(\"test\")\nreturn data.price[1.5]",
+		"data = frame(\"test\")\nreturn data.price[data]",
+	} {
+		program, err := NewParser(NewLexer(bad).Tokenize()).Parse()
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", bad, err)
+		}
+		if _, err := NewCompiler().Compile(program); err == nil {
+			t.Errorf("%q: expected compile error", bad)
+		}
+	}
+}
+
 func TestParser_GroupByMultipleKeys(t *testing.T) {
 	input := `data = group_by(a, b, c)`
 
@@ -1817,10 +2031,28 @@ return result
 	}
 
 	compiler := NewCompiler()
-	_, err = compiler.Compile(program)
-	// String literals may not be fully supported, but we test the path
+	asm, err := compiler.Compile(program)
 	if err != nil {
-		// Expected - string literals aren't returned
+		t.Fatalf("compile error: %v", err)
+	}
+	if !strings.Contains(asm, "LOAD_CONST_STR R0, \"hello world\"") || !strings.Contains(asm, "HALT_STR      R0") {
+		t.Errorf("expected LOAD_CONST_STR and HALT_STR: %s", asm)
+	}
+}
+
+func TestCompiler_StringOperandRejected(t *testing.T) {
+	inputs := []string{
+		"return \"a\" + 1",
+		"name = \"x\"\nreturn { name: name }",
+	}
+	for _, input := range inputs {
+		program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", input, err)
+		}
+		if _, err := NewCompiler().Compile(program); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
 	}
 }
 
@@ -1839,9 +2071,13 @@ return result
 	}
 
 	compiler := NewCompiler()
-	_, err = compiler.Compile(program)
-	// Bool literals may not be fully supported, but we test the path
-	_ = err
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if !strings.Contains(asm, "LOAD_CONST    R0, 1\nHALT          R0") {
+		t.Errorf("expected true as 1 in an R register: %s", asm)
+	}
 }
 
 func TestCompiler_Select(t *testing.T) {
@@ -1922,12 +2158,12 @@ return result.total
 	}
 }
 
-func TestCompiler_Join(t *testing.T) {
+func TestCompiler_SummarizeFirstLast(t *testing.T) {
 	input := `
-left = frame("left")
-right = frame("right")
-result = left |> join(right, on = id)
-return result.value
+data = frame("test")
+amount = data.amount
+result = data |> group_by(category) |> summarize(opening = first(amount), closing = last(amount))
+return result.closing
 `
 	lexer := NewLexer(input)
 	tokens := lexer.Tokenize()
@@ -1944,106 +2180,46 @@ return result.value
 		t.Fatalf("compile error: %v", err)
 	}
 
-	if !strings.Contains(asm, "JOIN_INNER") {
-		t.Errorf("expected JOIN_INNER in output: %s", asm)
-	}
-}
-
-func TestTokenType_String(t *testing.T) {
-	// Test various token types to increase String() coverage
-	tokens := []TokenType{
-		TokenEOF,
-		TokenIdent,
-		TokenInt,
-		TokenFloat,
-		TokenString,
-		TokenPlus,
-		TokenMinus,
-		TokenStar,
-		TokenSlash,
-		TokenPercent,
-		TokenEQ,
-		TokenNE,
-		TokenLT,
-		TokenLE,
-		TokenGT,
-		TokenGE,
-		TokenAssign,
-		TokenLParen,
-		TokenRParen,
-		TokenLBracket,
-		TokenRBracket,
-		TokenComma,
-		TokenDot,
-		TokenPipe,
-		TokenAnd,
-		TokenOr,
-		TokenNot,
-		TokenTrue,
-		TokenFalse,
-		TokenLoad,
-		TokenFrame,
-		TokenFilter,
-		TokenSelect,
-		TokenMutate,
-		TokenGroupBy,
-		TokenSummarize,
-		TokenJoin,
-		TokenReturn,
-		TokenSum,
-		TokenMean,
-		TokenMin,
-		TokenMax,
-		TokenCount,
-	}
-
-	for _, tok := range tokens {
-		s := tok.String()
-		if s == "" {
-			t.Errorf("TokenType %d has empty string", tok)
+	for _, want := range []string{"GROUP_FIRST", "GROUP_LAST"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %s in output: %s", want, asm)
 		}
 	}
 }
 
-func TestParser_Error(t *testing.T) {
-	// Test with invalid syntax to trigger parser error
-	input := `data = ((((`
+func TestCompiler_SummarizeMedian(t *testing.T) {
+	input := `
+data = frame("test")
+amount = data.amount
+result = data |> group_by(category) |> summarize(mid = median(amount))
+return result.mid
+`
 	lexer := NewLexer(input)
 	tokens := lexer.Tokenize()
 
 	parser := NewParser(tokens)
-	_, err := parser.Parse()
-	if err == nil {
-		t.Error("expected parse error for invalid syntax")
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
 	}
-}
 
-func TestLexer_StringEscape(t *testing.T) {
-	// Test string with escape sequences
-	input := `data = "hello\nworld"`
-	lexer := NewLexer(input)
-	tokens := lexer.Tokenize()
-
-	// Check that string token is present
-	foundString := false
-	for _, tok := range tokens {
-		if tok.Type == TokenString {
-			foundString = true
-			break
-		}
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
 	}
 
-	if !foundString {
-		t.Error("expected string token")
+	if !strings.Contains(asm, "GROUP_MEDIAN_F") {
+		t.Errorf("expected GROUP_MEDIAN_F in output: %s", asm)
 	}
 }
 
-func TestCompiler_ScalarBinary(t *testing.T) {
+func TestCompiler_SummarizeQuantile(t *testing.T) {
 	input := `
-a = 10
-b = 20
-result = a + b
-return result
+data = frame("test")
+amount = data.amount
+result = data |> group_by(category) |> summarize(p90 = quantile(amount, 0.9))
+return result.p90
 `
 	lexer := NewLexer(input)
 	tokens := lexer.Tokenize()
@@ -2060,17 +2236,1242 @@ return result
 		t.Fatalf("compile error: %v", err)
 	}
 
-	// Should contain scalar add
-	if !strings.Contains(asm, "ADD_R") && !strings.Contains(asm, "LOAD_CONST") {
-		t.Errorf("expected scalar operations in output: %s", asm)
+	if !strings.Contains(asm, "GROUP_QUANTILE_F") || !strings.Contains(asm, ", 0.9") {
+		t.Errorf("expected GROUP_QUANTILE_F with q=0.9 in output: %s", asm)
 	}
 }
 
-func TestCompiler_UnaryNegate(t *testing.T) {
+func TestCompiler_SummarizeGroupConcat(t *testing.T) {
 	input := `
 data = frame("test")
-col = data.a
-result = -col
+name = data.name
+result = data |> group_by(category) |> summarize(names = group_concat(name, "; "))
+return result.names
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `GROUP_CONCAT  V2, R1, V0, "; "`) {
+		t.Errorf("expected GROUP_CONCAT in output: %s", asm)
+	}
+}
+
+func TestCompiler_Shift(t *testing.T) {
+	input := `
+data = frame("test") |> mutate(delta = value - shift(value, 1), next = shift(value, -1))
+return delta
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{", 1\n", ", -1\n"} {
+		if !strings.Contains(asm, "SHIFT") || !strings.Contains(asm, want) {
+			t.Errorf("expected SHIFT with offset %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_Bin(t *testing.T) {
+	input := `
+data = frame("test")
+data = add_col(data, "bucket", bin(data.value, 5))
+ranged = bin(data.value, 4, 0, 100)
+result = data |> group_by(bucket) |> summarize(n = count())
+return result.n
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"BIN_F         V1, V0, 5\n", ", 4, 0.0, 100.0\n", "\"bucket\"\nGROUP_BY"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_Pivot(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"call", `
+sales = frame("sales")
+wide = pivot(sales, index = region, key = month, value = amount)
+return wide
+`},
+		{"pipe", `
+wide = frame("sales") |> pivot(index: region, key: month, value: amount)
+return wide
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := NewLexer(tt.input)
+			tokens := lexer.Tokenize()
+
+			parser := NewParser(tokens)
+			program, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+
+			compiler := NewCompiler()
+			asm, err := compiler.Compile(program)
+			if err != nil {
+				t.Fatalf("compile error: %v", err)
+			}
+
+			if !strings.Contains(asm, `PIVOT         R1, R0, "region", "month", "amount"`) {
+				t.Errorf("expected PIVOT in output: %s", asm)
+			}
+		})
+	}
+}
+
+func TestCompiler_ConcatFrames(t *testing.T) {
+	input := `
+jan = frame("jan")
+feb = frame("feb")
+both = concat(jan, feb)
+return both
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "CONCAT        R2, R0, R1") {
+		t.Errorf("expected CONCAT in output: %s", asm)
+	}
+	if strings.Contains(asm, "STR_CONCAT") {
+		t.Errorf("frames should not compile to STR_CONCAT: %s", asm)
+	}
+}
+
+func TestCompiler_VecMinMax(t *testing.T) {
+	tests := []struct {
+		call     string
+		expected string
+	}{
+		{"vmin(a, b)", "VEC_MIN_F     V2, V0, V1"},
+		{"vmax(a, b)", "VEC_MAX_F     V2, V0, V1"},
+		{"min(a, b)", "VEC_MIN_F     V2, V0, V1"},
+		{"max(a, b)", "VEC_MAX_F     V2, V0, V1"},
+		{"min(a)", "REDUCE_MIN_F"},
+	}
+
+	for _, tt := range tests {
+		input := `
+data = frame("test")
+a = data.a
+b = data.b
+return ` + tt.call + `
+`
+		lexer := NewLexer(input)
+		tokens := lexer.Tokenize()
+
+		parser := NewParser(tokens)
+		program, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("parse error for %q: %v", tt.call, err)
+		}
+
+		compiler := NewCompiler()
+		asm, err := compiler.Compile(program)
+		if err != nil {
+			t.Fatalf("compile error for %q: %v", tt.call, err)
+		}
+
+		if !strings.Contains(asm, tt.expected) {
+			t.Errorf("expected %s in output for %q, got: %s", tt.expected, tt.call, asm)
+		}
+	}
+}
+
+func TestCompiler_Clamp(t *testing.T) {
+	input := `
+data = frame("test")
+col = data.value
+return clamp(col, -1, 2.5)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "CLAMP_F       V1, V0, -1.0, 2.5") {
+		t.Errorf("expected CLAMP_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_ApproxEq(t *testing.T) {
+	input := `
+data = frame("test")
+return approx_eq(data.a, 0.3, 0.000001)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "BROADCAST_F") || !strings.Contains(asm, ", 0.000001") {
+		t.Errorf("expected broadcast and decimal epsilon in output: %s", asm)
+	}
+	if !strings.Contains(asm, "CMP_EQ_EPS_F") {
+		t.Errorf("expected CMP_EQ_EPS_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_FillNa(t *testing.T) {
+	input := `
+data = frame("test")
+price = fillna(data.price, 0.5)
+return fillna(data.name, "")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "FILL_NA       V1, V0, 0.5") {
+		t.Errorf("expected numeric FILL_NA in output: %s", asm)
+	}
+	if !strings.Contains(asm, `FILL_NA       V3, V2, ""`) {
+		t.Errorf("expected string FILL_NA in output: %s", asm)
+	}
+}
+
+func TestCompiler_DropNa(t *testing.T) {
+	input := `
+data = frame("test")
+clean = dropna(data)
+return dropna(data, "price")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "DROP_NA       R1, R0\n") {
+		t.Errorf("expected DROP_NA over all columns in output: %s", asm)
+	}
+	if !strings.Contains(asm, `DROP_NA       R2, R0, "price"`) {
+		t.Errorf("expected DROP_NA on price in output: %s", asm)
+	}
+}
+
+func TestCompiler_LazyLogical(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		lazy  bool
+	}{
+		{"and with string test", `data = frame("test")
+return data.qty > 10 && contains(data.name, "x")`, true},
+		{"or with string test", `data = frame("test")
+return data.qty > 10 || starts_with(data.name, "x")`, true},
+		{"filter condition", `data = frame("test")
+return data |> filter(qty > 10 && matches(name, "^a"))`, true},
+		{"cheap right side", `data = frame("test")
+return data.qty > 10 && data.price < 5`, false},
+		{"precomputed variable", `data = frame("test")
+hit = contains(data.name, "x")
+return data.qty > 10 && hit`, false},
+		{"aggregate on right side", `data = frame("test")
+return data.qty > 10 && (contains(data.name, "x") || data.price > mean(data.price))`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := NewParser(NewLexer(tt.input).Tokenize()).Parse()
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			asm, err := NewCompiler().Compile(program)
+			if err != nil {
+				t.Fatalf("compile error: %v", err)
+			}
+			if got := strings.Contains(asm, "MASKED_SELECT"); got != tt.lazy {
+				t.Errorf("expected lazy=%v, got output: %s", tt.lazy, asm)
+			}
+		})
+	}
+}
+
+func TestCompiler_Xor(t *testing.T) {
+	input := `
+data = frame("test")
+return data |> filter(xor(data.a > 10, data.b > 100))
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "XOR ") {
+		t.Errorf("expected XOR in output: %s", asm)
+	}
+}
+
+func TestCompiler_AssertEq(t *testing.T) {
+	input := `
+data = frame("test")
+expected = frame("expected")
+assert_eq(data, expected)
+n = count(data.qty)
+assert_eq(n, 3)
+assert_eq(data.qty, expected.qty)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"ASSERT_EQ     R0, R1\n", `, "int"`, "ASSERT_EQ     V"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+
+	bad := `
+data = frame("test")
+assert_eq(data, 3)
+`
+	program, err = NewParser(NewLexer(bad).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := NewCompiler().Compile(program); err == nil {
+		t.Error("expected error comparing a frame with a scalar")
+	}
+}
+
+func TestCompiler_Print(t *testing.T) {
+	input := `
+data = frame("test")
+print(data)
+print(count(data.qty))
+print(data.price * 2.0)
+print(sum(data.price))
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"PRINT         R0\n", `, "int"`, "PRINT         V", "PRINT         F"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_ReturnObject(t *testing.T) {
+	input := `
+data = frame("test")
+return {
+	total: sum(data.price),
+	avg: mean(data.price),
+	"rows": count(data.qty),
+	frame: data,
+}
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	obj, ok := program.Statements[1].(*ReturnStmt).Value.(*ObjectLit)
+	if !ok || len(obj.Fields) != 4 || obj.Fields[1].Key != "avg" || obj.Fields[2].Key != "rows" {
+		t.Fatalf("expected a 4-field object literal, got %#v", program.Statements[1])
+	}
+
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	for _, want := range []string{
+		`SET_RESULT    "total", F`,
+		`SET_RESULT    "avg", F`,
+		`SET_RESULT    "rows", R`,
+		`SET_RESULT    "frame", R0` + "\n",
+		"LOAD_CONST    R", ", 4\nHALT          R",
+	} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+	if !strings.Contains(asm, `"rows", R1, "int"`) && !strings.Contains(asm, `"rows", R2, "int"`) {
+		t.Errorf("expected rows stored as an int: %s", asm)
+	}
+
+	for _, bad := range []string{
+		`return { a: 1, a: 2 }`,
+		`return { 1: 2 }`,
+		`return { a 1 }`,
+	} {
+		if _, err := NewParser(NewLexer(bad).Tokenize()).Parse(); err == nil {
+			t.Errorf("%s: expected parse error", bad)
+		}
+	}
+}
+
+func TestCompiler_ObjectOutsideReturn(t *testing.T) {
+	program, err := NewParser(NewLexer("x = { a: 1 }\nreturn x").Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := NewCompiler().Compile(program); err == nil || !strings.Contains(err.Error(), "object literals can only be returned") {
+		t.Errorf("expected object literal error, got %v", err)
+	}
+
+	program, err = NewParser(NewLexer("return {}").Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if strings.Contains(asm, "SET_RESULT") || !strings.Contains(asm, ", 0\nHALT") {
+		t.Errorf("expected an empty object to halt with 0 fields: %s", asm)
+	}
+}
+
+func TestCompiler_Coalesce(t *testing.T) {
+	input := `
+data = frame("test")
+return coalesce(data.a, data.b, data.c)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "COALESCE      V2, V0, V1") {
+		t.Errorf("expected COALESCE of the first two columns in output: %s", asm)
+	}
+	if !strings.Contains(asm, "COALESCE      V4, V2, V3") {
+		t.Errorf("expected COALESCE folding in the third column in output: %s", asm)
+	}
+}
+
+func TestCompiler_Format(t *testing.T) {
+	input := `
+data = frame("test")
+cents = format(data.price, 2)
+return format(data.price, "%.1e")
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"FORMAT_F      V1, V0, 2", `FORMAT_F      V3, V2, "%.1e"`} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_TrimCutset(t *testing.T) {
+	input := `
+data = frame("test")
+return trim(data.code, "x-")
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `STR_TRIM_CHARS V1, V0, "x-"`) {
+		t.Errorf("expected STR_TRIM_CHARS in output: %s", asm)
+	}
+}
+
+func TestCompiler_Repeat(t *testing.T) {
+	input := `
+data = frame("test")
+ones = repeat(1, 5)
+halves = repeat(0.5, data.price)
+return repeat("n/a", data.price)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"FILL_I        V0, 1, 5", "FILL_F        V2, 0.5, V1", `FILL_STR      V4, "n/a", V3`} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_Range(t *testing.T) {
+	input := `
+evens = range(0, 10, 2)
+return range(0, 5)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"RANGE         V0, 0, 10, 2", "RANGE         V1, 0, 5, 1"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_Hash(t *testing.T) {
+	input := `
+data = frame("test")
+shard = hash(data.user, 8)
+return hash(data.user)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "HASH_STR      V1, V0, 8") {
+		t.Errorf("expected bucketed HASH_STR in output: %s", asm)
+	}
+	if !strings.Contains(asm, "HASH_STR      V3, V2\n") {
+		t.Errorf("expected plain HASH_STR in output: %s", asm)
+	}
+
+	bad := `
+data = frame("test")
+return hash(data.user, 1000)
+`
+	program, err = NewParser(NewLexer(bad).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := NewCompiler().Compile(program); err == nil {
+		t.Error("expected error for bucket count above 255")
+	}
+}
+
+func TestCompiler_Dates(t *testing.T) {
+	input := `
+data = frame("test")
+ts = parse_date(data.created, "2006-01-02")
+return year(ts)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{`PARSE_DATE    V1, V0, "2006-01-02"`, `DATE_PART     V2, V1, "year"`} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_DateArithmetic(t *testing.T) {
+	input := `
+data = frame("test")
+start = parse_date(data.start, "2006-01-02")
+end = parse_date(data.end, "2006-01-02")
+due = date_add(start, 30)
+return date_diff(end, start)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"BROADCAST     V4, R1, V1", "DATE_ADD_DAYS V5, V1, V4", "DATE_DIFF_DAYS V6, V3, V1"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_IsNaNInf(t *testing.T) {
+	input := `
+data = frame("test")
+ratio = data.a / data.b
+bad = is_nan(ratio) || is_inf(ratio)
+return data |> filter(bad)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "IS_NAN_F      V3, V2") {
+		t.Errorf("expected IS_NAN_F in output: %s", asm)
+	}
+	if !strings.Contains(asm, "IS_INF_F      V4, V2") {
+		t.Errorf("expected IS_INF_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_IsNull(t *testing.T) {
+	input := `
+data = frame("test")
+present = is_not_null(data.name)
+return data |> filter(is_null(price))
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "IS_NULL       V3, V2") {
+		t.Errorf("expected IS_NULL in output: %s", asm)
+	}
+	if !strings.Contains(asm, "IS_NOT_NULL   V1, V0") {
+		t.Errorf("expected IS_NOT_NULL in output: %s", asm)
+	}
+}
+
+func TestCompiler_Between(t *testing.T) {
+	input := `
+data = frame("test")
+return between(data.price, 10, 20)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"CMP_GE", "CMP_LE", "AND"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %s in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_InSet(t *testing.T) {
+	input := `
+data = frame("test")
+picked = data |> filter(in(category, "A", "C"))
+return count(picked.category)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `IN_SET        V1, V0, "A", "C"`) {
+		t.Errorf("expected IN_SET in output: %s", asm)
+	}
+}
+
+func TestCompiler_IContains(t *testing.T) {
+	input := `
+data = frame("test")
+return icontains(data.name, "hello")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `STR_CONTAINS_CI V1, V0, "hello"`) {
+		t.Errorf("expected STR_CONTAINS_CI in output: %s", asm)
+	}
+}
+
+func TestCompiler_Matches(t *testing.T) {
+	input := `
+data = frame("test")
+return matches(data.email, "^\w+@\w+\.com$")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `STR_REGEX_MATCH V1, V0, "^\w+@\w+\.com$"`) {
+		t.Errorf("expected STR_REGEX_MATCH in output: %s", asm)
+	}
+}
+
+func TestCompiler_Extract(t *testing.T) {
+	input := `
+data = frame("test")
+return extract(data.id, "(\d+)")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `STR_REGEX_EXTRACT V1, V0, "(\d+)"`) {
+		t.Errorf("expected STR_REGEX_EXTRACT in output: %s", asm)
+	}
+}
+
+func TestCompiler_Substr(t *testing.T) {
+	input := `
+data = frame("test")
+return substr(data.name, 1, 3)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "STR_SUBSTR    V1, V0, 1, 3") {
+		t.Errorf("expected STR_SUBSTR in output: %s", asm)
+	}
+}
+
+func TestCompiler_Pad(t *testing.T) {
+	input := `
+data = frame("test")
+ids = pad_left(data.id, 5, "0")
+return pad_right(data.name, 10, " ")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `STR_PAD_LEFT  V1, V0, 5, "0"`) {
+		t.Errorf("expected STR_PAD_LEFT in output: %s", asm)
+	}
+	if !strings.Contains(asm, `STR_PAD_RIGHT V3, V2, 10, " "`) {
+		t.Errorf("expected STR_PAD_RIGHT in output: %s", asm)
+	}
+}
+
+func TestCompiler_RowIndex(t *testing.T) {
+	input := `
+data = frame("test")
+numbered = data |> mutate(idx = row_index(data))
+return idx
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "ROW_INDEX     V0, R0") {
+		t.Errorf("expected ROW_INDEX in output: %s", asm)
+	}
+	if !strings.Contains(asm, "HALT_V        V0") {
+		t.Errorf("expected mutate to bind idx to the row index: %s", asm)
+	}
+}
+
+func TestCompiler_TopN(t *testing.T) {
+	input := `
+data = frame("test")
+cheap = top_n(data, price, 2, desc = false)
+return top_n(data, "price", 3)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `TOP_N         R1, R0, "price", 2, "asc"`) {
+		t.Errorf("expected ascending TOP_N in output: %s", asm)
+	}
+	if !strings.Contains(asm, `TOP_N         R2, R0, "price", 3, "desc"`) {
+		t.Errorf("expected descending TOP_N in output: %s", asm)
+	}
+}
+
+func TestCompiler_Sample(t *testing.T) {
+	input := `
+data = frame("test")
+fixed = sample(data, 5, seed = 42)
+mixed = shuffle(data)
+return sample(data, 10)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"SAMPLE        R1, R0, 5, 42", "SHUFFLE       R2, R0", "SAMPLE        R3, R0, 10\n"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+}
+
+func TestCompiler_GroupSizes(t *testing.T) {
+	input := `
+data = frame("test")
+grouped = data |> group_by(category)
+return group_sizes(grouped)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, want := range []string{"GROUP_SIZES   R2, R1", "HALT_FRAME    R2"} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in output: %s", want, asm)
+		}
+	}
+
+	program, err = NewParser(NewLexer(`return group_sizes(1.5)`).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := NewCompiler().Compile(program); err == nil {
+		t.Error("expected error for group_sizes of a non-group_by value")
+	}
+}
+
+func TestCompiler_Rank(t *testing.T) {
+	input := `
+data = frame("test")
+asc = rank(data.score)
+return rank(data.score, desc = true, ties = "dense")
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `RANK_F        V1, V0, "average", "asc"`) {
+		t.Errorf("expected default RANK_F in output: %s", asm)
+	}
+	if !strings.Contains(asm, `RANK_F        V3, V2, "dense", "desc"`) {
+		t.Errorf("expected dense descending RANK_F in output: %s", asm)
+	}
+}
+
+func TestCompiler_Join(t *testing.T) {
+	input := `
+left = frame("left")
+right = frame("right")
+result = left |> join(right, on = id)
+return result.value
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "JOIN_INNER") {
+		t.Errorf("expected JOIN_INNER in output: %s", asm)
+	}
+}
+
+func TestTokenType_String(t *testing.T) {
+	// Test various token types to increase String() coverage
+	tokens := []TokenType{
+		TokenEOF,
+		TokenIdent,
+		TokenInt,
+		TokenFloat,
+		TokenString,
+		TokenPlus,
+		TokenMinus,
+		TokenStar,
+		TokenSlash,
+		TokenPercent,
+		TokenEQ,
+		TokenNE,
+		TokenLT,
+		TokenLE,
+		TokenGT,
+		TokenGE,
+		TokenAssign,
+		TokenLParen,
+		TokenRParen,
+		TokenLBracket,
+		TokenRBracket,
+		TokenComma,
+		TokenDot,
+		TokenPipe,
+		TokenAnd,
+		TokenOr,
+		TokenNot,
+		TokenTrue,
+		TokenFalse,
+		TokenLoad,
+		TokenFrame,
+		TokenFilter,
+		TokenSelect,
+		TokenMutate,
+		TokenGroupBy,
+		TokenSummarize,
+		TokenJoin,
+		TokenReturn,
+		TokenSum,
+		TokenMean,
+		TokenMin,
+		TokenMax,
+		TokenCount,
+	}
+
+	for _, tok := range tokens {
+		s := tok.String()
+		if s == "" {
+			t.Errorf("TokenType %d has empty string", tok)
+		}
+	}
+}
+
+func TestParser_Error(t *testing.T) {
+	// Test with invalid syntax to trigger parser error
+	input := `data = ((((`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	_, err := parser.Parse()
+	if err == nil {
+		t.Error("expected parse error for invalid syntax")
+	}
+}
+
+func TestLexer_StringEscape(t *testing.T) {
+	// Test string with escape sequences
+	input := `data = "hello\nworld"`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	// Check that string token is present
+	foundString := false
+	for _, tok := range tokens {
+		if tok.Type == TokenString {
+			foundString = true
+			break
+		}
+	}
+
+	if !foundString {
+		t.Error("expected string token")
+	}
+}
+
+func TestCompiler_ScalarBinary(t *testing.T) {
+	input := `
+a = 10
+b = 20
+result = a + b
+return result
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	// Should contain scalar add
+	if !strings.Contains(asm, "ADD_R") && !strings.Contains(asm, "LOAD_CONST") {
+		t.Errorf("expected scalar operations in output: %s", asm)
+	}
+}
+
+func TestCompiler_UnaryNegate(t *testing.T) {
+	input := `
+data = frame("test")
+col = data.a
+result = -col
 return sum(result)
 `
 	lexer := NewLexer(input)
@@ -2084,9 +3485,44 @@ return sum(result)
 
 	compiler := NewCompiler()
 	asm, err := compiler.Compile(program)
-	// Negation might not be fully supported
-	_ = asm
-	_ = err
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if !strings.Contains(asm, "VEC_NEG_F     V1, V0") {
+		t.Errorf("expected VEC_NEG_F for a column: %s", asm)
+	}
+}
+
+func TestCompiler_UnaryNegateScalar(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"x = 5\nreturn -x", "NEG_I         R1, R0\nHALT          R1"},
+		{"data = frame(\"test\")\nreturn -sum(data.a)", "NEG_F         F1, F0\nHALT_F        F1"},
+		{"return -(-2.5)", "NEG_F"},
+	}
+	for _, tt := range tests {
+		program, err := NewParser(NewLexer(tt.input).Tokenize()).Parse()
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", tt.input, err)
+		}
+		asm, err := NewCompiler().Compile(program)
+		if err != nil {
+			t.Fatalf("%q: compile error: %v", tt.input, err)
+		}
+		if !strings.Contains(asm, tt.want) {
+			t.Errorf("%q: expected %q in output: %s", tt.input, tt.want, asm)
+		}
+	}
+
+	program, err := NewParser(NewLexer("data = frame(\"test\")\nreturn -data").Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := NewCompiler().Compile(program); err == nil {
+		t.Error("expected an error negating a frame")
+	}
 }
 
 func TestCompiler_LoadJSON(t *testing.T) {
@@ -2114,6 +3550,56 @@ return sum(data.value)
 	}
 }
 
+func TestCompiler_LoadJSONL(t *testing.T) {
+	input := `
+data = load_jsonl("events.jsonl")
+return sum(data.value)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `LOAD_JSONL    R0, "events.jsonl"`) {
+		t.Errorf("expected LOAD_JSONL in output: %s", asm)
+	}
+}
+
+func TestCompiler_LoadURL(t *testing.T) {
+	input := `
+data = load_url("https://example.com/sales.csv")
+return sum(data.value)
+`
+	lexer := NewLexer(input)
+	tokens := lexer.Tokenize()
+
+	parser := NewParser(tokens)
+	program, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	compiler := NewCompiler()
+	asm, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, `LOAD_HTTP     R0, "https://example.com/sales.csv"`) {
+		t.Errorf("expected LOAD_HTTP in output: %s", asm)
+	}
+}
+
 func TestCompiler_LoadParquet(t *testing.T) {
 	input := `
 data = load_parquet("test.parquet")
@@ -2432,6 +3918,26 @@ return row_count(result)
 	}
 }
 
+func TestCompiler_CrossJoin(t *testing.T) {
+	input := `
+sizes = frame("sizes")
+colors = frame("colors")
+return sizes |> cross_join(colors)
+`
+	program, err := NewParser(NewLexer(input).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	if !strings.Contains(asm, "JOIN_CROSS    R2, R0, R1") {
+		t.Errorf("expected JOIN_CROSS in output: %s", asm)
+	}
+}
+
 func TestAST_MarkerMethods(t *testing.T) {
 	// Call marker methods to cover them
 	// These are interface satisfaction methods
@@ -2489,3 +3995,94 @@ func TestAST_MarkerMethods(t *testing.T) {
 	(&ExprStmt{}).stmt()
 	(&Program{}).node()
 }
+
+func TestCompiler_RegisterReuse(t *testing.T) {
+	// base stays live across many short-lived temporaries
+	var src strings.Builder
+	src.WriteString("data = frame(\"t\")\nbase = data.x\n")
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&src, "s%d = sum(data.y * %d)\n", i, i)
+	}
+	src.WriteString("return sum(base)\n")
+
+	program, err := NewParser(NewLexer(src.String()).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	asm, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	for _, m := range regexp.MustCompile(`\bV(\d+)\b`).FindAllStringSubmatch(asm, -1) {
+		if len(m[1]) > 1 || m[1][0] > '7' {
+			t.Fatalf("V register out of range: %s\n%s", m[0], asm)
+		}
+	}
+	if !strings.Contains(asm, "SELECT_COL    V0, R0, \"x\"") || !strings.Contains(asm, "REDUCE_SUM_F  F12, V0") {
+		t.Errorf("expected base to keep V0 until the final sum:\n%s", asm)
+	}
+}
+
+func TestCompiler_RegisterExhaustion(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("data = frame(\"t\")\n")
+	names := make([]string, 9)
+	for i := range names {
+		names[i] = fmt.Sprintf("c%d", i)
+		fmt.Fprintf(&src, "%s = data.%s\n", names[i], names[i])
+	}
+	src.WriteString("return sum(" + strings.Join(names, " + ") + ")\n")
+
+	program, err := NewParser(NewLexer(src.String()).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	_, err = NewCompiler().Compile(program)
+	if !errors.Is(err, ErrRegisterExhaustion) {
+		t.Fatalf("expected ErrRegisterExhaustion, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "more than 8 V registers") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestCompiler_CompileInstructions(t *testing.T) {
+	program, err := NewParser(NewLexer(`data = frame("t")
+return in(data.name, 'a "b"', 2)`).Tokenize()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	insts, err := NewCompiler().CompileInstructions(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	want := []Instruction{
+		{Op: "LOAD_FRAME", Operands: []Operand{{Kind: OperandReg, Reg: 'R', Int: 0}, {Kind: OperandString, Str: "t"}}},
+		{Op: "SELECT_COL", Operands: []Operand{{Kind: OperandReg, Reg: 'V', Int: 0}, {Kind: OperandReg, Reg: 'R', Int: 0}, {Kind: OperandString, Str: "name"}}},
+		{Op: "IN_SET", Operands: []Operand{{Kind: OperandReg, Reg: 'V', Int: 1}, {Kind: OperandReg, Reg: 'V', Int: 0}, {Kind: OperandString, Str: `a "b"`}, {Kind: OperandInt, Int: 2}}},
+		{Op: "HALT_V", Operands: []Operand{{Kind: OperandReg, Reg: 'V', Int: 1}}},
+	}
+	if !reflect.DeepEqual(insts, want) {
+		t.Errorf("instructions = %#v, want %#v", insts, want)
+	}
+}
+
+func TestInstruction_String(t *testing.T) {
+	tests := []struct {
+		inst Instruction
+		want string
+	}{
+		{Instruction{Op: "HALT_V", Operands: []Operand{vreg(1)}}, "HALT_V        V1"},
+		{Instruction{Op: "LOAD_CONST_F", Operands: []Operand{freg(0), floatOperand(2)}}, "LOAD_CONST_F  F0, 2.0"},
+		{Instruction{Op: "CLAMP_F", Operands: []Operand{vreg(1), vreg(0), floatOperand(-0.5), floatOperand(1e21)}}, "CLAMP_F       V1, V0, -0.5, 1000000000000000000000.0"},
+		{Instruction{Op: "STR_REGEX_MATCH", Operands: []Operand{vreg(2), vreg(0), strOperand("a, b")}}, `STR_REGEX_MATCH V2, V0, "a, b"`},
+		{Instruction{Op: "SET_RESULT", Operands: []Operand{strOperand("n"), rreg(3), strOperand("int")}}, `SET_RESULT    "n", R3, "int"`},
+	}
+	for _, tt := range tests {
+		if got := tt.inst.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
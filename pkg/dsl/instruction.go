@@ -0,0 +1,77 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// OperandKind identifies the type of an instruction operand.
+type OperandKind uint8
+
+const (
+	OperandReg    OperandKind = iota // Register; Reg names the file
+	OperandInt                       // Integer literal
+	OperandFloat                     // Float literal
+	OperandString                    // String literal, unquoted
+)
+
+// Operand is one operand of a compiled instruction.
+type Operand struct {
+	Kind  OperandKind
+	Reg   byte    // 'R', 'F' or 'V' for OperandReg
+	Int   int64   // Register number or integer value
+	Float float64 // Value of an OperandFloat
+	Str   string  // Value of an OperandString
+}
+
+// String renders the operand in assembly syntax. Floats always carry a
+// decimal point so the assembler reads them back as floats.
+func (o Operand) String() string {
+	switch o.Kind {
+	case OperandReg:
+		return fmt.Sprintf("%c%d", o.Reg, o.Int)
+	case OperandInt:
+		return strconv.FormatInt(o.Int, 10)
+	case OperandFloat:
+		text := strconv.FormatFloat(o.Float, 'f', -1, 64)
+		if math.IsInf(o.Float, 0) || math.IsNaN(o.Float) || strings.Contains(text, ".") {
+			return text
+		}
+		return text + ".0"
+	}
+	return fmt.Sprintf("\"%s\"", o.Str)
+}
+
+// Instruction is one compiled DSL instruction: the structured form of a line
+// of Compile's assembly output.
+type Instruction struct {
+	Op       string
+	Operands []Operand
+}
+
+// String renders the instruction as a line of assembly, with the mnemonic
+// padded so operands line up.
+func (inst Instruction) String() string {
+	if len(inst.Operands) == 0 {
+		return inst.Op
+	}
+	parts := make([]string, len(inst.Operands))
+	for i, op := range inst.Operands {
+		parts[i] = op.String()
+	}
+	return fmt.Sprintf("%-13s %s", inst.Op, strings.Join(parts, ", "))
+}
+
+func rreg(n int) Operand             { return Operand{Kind: OperandReg, Reg: 'R', Int: int64(n)} }
+func freg(n int) Operand             { return Operand{Kind: OperandReg, Reg: 'F', Int: int64(n)} }
+func vreg(n int) Operand             { return Operand{Kind: OperandReg, Reg: 'V', Int: int64(n)} }
+func strOperand(s string) Operand    { return Operand{Kind: OperandString, Str: s} }
+func intOperand(n int64) Operand     { return Operand{Kind: OperandInt, Int: n} }
+func floatOperand(f float64) Operand { return Operand{Kind: OperandFloat, Float: f} }
+
+// regOf returns the register operand for r.
+func regOf(r regInfo) Operand {
+	return Operand{Kind: OperandReg, Reg: r.regType[0], Int: int64(r.regNum)}
+}
@@ -12,6 +12,18 @@ type Parser struct {
 	errors []error
 }
 
+// ParseError is a syntax error at a position in the source. Line and Col
+// are 1-based; Col counts bytes, like Token.Col.
+type ParseError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, col %d: %s", e.Line, e.Col, e.Msg)
+}
+
 // NewParser creates a new parser for the given tokens.
 func NewParser(tokens []Token) *Parser {
 	return &Parser{
@@ -72,6 +84,41 @@ func (p *Parser) parseReturnStmt() *ReturnStmt {
 	return &ReturnStmt{Value: expr}
 }
 
+// parseObject parses { key: expr, ... }. Keys are identifiers (including
+// keywords such as avg or frame) or strings and must be unique.
+func (p *Parser) parseObject() *ObjectLit {
+	p.advance() // consume '{'
+	p.skipNewlines()
+
+	obj := &ObjectLit{Fields: []ObjectField{}}
+	seen := make(map[string]bool)
+	for !p.check(TokenRBrace) && !p.isAtEnd() {
+		keyTok := p.peek()
+		if keyTok.Type != TokenIdent && keyTok.Type != TokenString && LookupIdent(keyTok.Value) != keyTok.Type {
+			p.error(fmt.Sprintf("expected object key, got %v", keyTok.Type))
+			return obj
+		}
+		p.advance()
+		if seen[keyTok.Value] {
+			p.errors = append(p.errors, &ParseError{Line: keyTok.Line, Col: keyTok.Col, Msg: fmt.Sprintf("duplicate key %q", keyTok.Value)})
+		}
+		seen[keyTok.Value] = true
+
+		p.expect(TokenColon)
+		obj.Fields = append(obj.Fields, ObjectField{Key: keyTok.Value, Value: p.parseExpression()})
+
+		p.skipNewlines()
+		if !p.check(TokenComma) {
+			break
+		}
+		p.advance() // consume ','
+		p.skipNewlines()
+	}
+
+	p.expect(TokenRBrace)
+	return obj
+}
+
 func (p *Parser) parseAssignStmt() *AssignStmt {
 	name := p.advance().Value
 	p.advance() // consume '='
@@ -116,8 +163,12 @@ func (p *Parser) parsePipeOperation() Expr {
 		return p.parseJoin("right")
 	case p.check(TokenOuterJoin):
 		return p.parseJoin("outer")
+	case p.check(TokenCrossJoin):
+		return p.parseJoin("cross")
 	case p.check(TokenTake):
 		return p.parseTake()
+	case p.check(TokenPivot):
+		return p.parsePivot()
 	default:
 		return p.parseOr()
 	}
@@ -131,6 +182,50 @@ func (p *Parser) parseTake() Expr {
 	return &TakeExpr{Count: count}
 }
 
+func (p *Parser) parsePivot() Expr {
+	p.advance() // consume 'pivot'
+	p.expect(TokenLParen)
+
+	pivot := &PivotExpr{}
+
+	// Optional leading frame argument (omitted in pipes)
+	if !(p.check(TokenIdent) && (p.peekNext().Type == TokenAssign || p.peekNext().Type == TokenColon)) {
+		pivot.Frame = p.parseExpression()
+		if p.check(TokenComma) {
+			p.advance()
+		}
+	}
+
+	// Named arguments: index = col, key = col, value = col
+	for p.check(TokenIdent) {
+		name := p.advance().Value
+		if p.check(TokenColon) {
+			p.advance()
+		} else {
+			p.expect(TokenAssign)
+		}
+		col := p.expect(TokenIdent).Value
+		switch name {
+		case "index":
+			pivot.Index = col
+		case "key":
+			pivot.Key = col
+		case "value":
+			pivot.Value = col
+		default:
+			p.error(fmt.Sprintf("unknown pivot argument: %s", name))
+		}
+
+		if !p.check(TokenComma) {
+			break
+		}
+		p.advance()
+	}
+
+	p.expect(TokenRParen)
+	return pivot
+}
+
 func (p *Parser) parseFilter() Expr {
 	p.advance() // consume 'filter'
 	p.expect(TokenLParen)
@@ -247,7 +342,7 @@ func (p *Parser) parseSummarize() Expr {
 }
 
 func (p *Parser) parseJoin(joinType string) Expr {
-	p.advance() // consume 'join', 'left_join', or 'right_join'
+	p.advance() // consume 'join', 'left_join', 'right_join', 'outer_join' or 'cross_join'
 	p.expect(TokenLParen)
 
 	right := p.parseExpression()
@@ -386,9 +481,19 @@ func (p *Parser) parseCall(name string) Expr {
 	p.expect(TokenLParen)
 
 	args := []Expr{}
+	var named map[string]Expr
 	for !p.check(TokenRParen) && !p.isAtEnd() {
-		arg := p.parseExpression()
-		args = append(args, arg)
+		// Keyword argument: name = value (or name: value)
+		if p.check(TokenIdent) && (p.peekNext().Type == TokenAssign || p.peekNext().Type == TokenColon) {
+			key := p.advance().Value
+			p.advance() // consume '=' or ':'
+			if named == nil {
+				named = make(map[string]Expr)
+			}
+			named[key] = p.parseExpression()
+		} else {
+			args = append(args, p.parseExpression())
+		}
 
 		if !p.check(TokenComma) {
 			break
@@ -418,6 +523,18 @@ func (p *Parser) parseCall(name string) Expr {
 				return &LoadJSONExpr{Path: str.Value}
 			}
 		}
+	case "load_jsonl":
+		if len(args) == 1 {
+			if str, ok := args[0].(*StringLit); ok {
+				return &LoadJSONLExpr{Path: str.Value}
+			}
+		}
+	case "load_url":
+		if len(args) == 1 {
+			if str, ok := args[0].(*StringLit); ok {
+				return &LoadURLExpr{URL: str.Value}
+			}
+		}
 	case "load_parquet":
 		if len(args) == 1 {
 			if str, ok := args[0].(*StringLit); ok {
@@ -428,7 +545,7 @@ func (p *Parser) parseCall(name string) Expr {
 		return &NewFrameExpr{}
 	}
 
-	return &CallExpr{Func: name, Args: args}
+	return &CallExpr{Func: name, Args: args, Named: named}
 }
 
 func (p *Parser) parsePrimary() Expr {
@@ -469,6 +586,9 @@ func (p *Parser) parsePrimary() Expr {
 		p.expect(TokenRParen)
 		return expr
 
+	case p.check(TokenLBrace):
+		return p.parseObject()
+
 	// Aggregation functions
 	case p.check(TokenSum), p.check(TokenCount), p.check(TokenMean),
 		p.check(TokenMin), p.check(TokenMax):
@@ -510,10 +630,21 @@ func (p *Parser) parsePrimary() Expr {
 	case p.check(TokenOuterJoin):
 		return p.parseJoin("outer")
 
+	case p.check(TokenCrossJoin):
+		return p.parseJoin("cross")
+
 	case p.check(TokenLoadJSON):
 		p.advance()
 		return p.parseCall("load_json")
 
+	case p.check(TokenLoadJSONL):
+		p.advance()
+		return p.parseCall("load_jsonl")
+
+	case p.check(TokenLoadURL):
+		p.advance()
+		return p.parseCall("load_url")
+
 	case p.check(TokenLoadParquet):
 		p.advance()
 		return p.parseCall("load_parquet")
@@ -525,6 +656,9 @@ func (p *Parser) parsePrimary() Expr {
 	case p.check(TokenTake):
 		return p.parseTake()
 
+	case p.check(TokenPivot):
+		return p.parsePivot()
+
 	case p.check(TokenRowCount), p.check(TokenColCount),
 		p.check(TokenSplit), p.check(TokenReplace), p.check(TokenAddCol):
 		name := p.advance().Value
@@ -583,7 +717,6 @@ func (p *Parser) skipNewlines() {
 
 func (p *Parser) error(msg string) {
 	tok := p.peek()
-	err := fmt.Errorf("line %d, col %d: %s", tok.Line, tok.Col, msg)
-	p.errors = append(p.errors, err)
+	p.errors = append(p.errors, &ParseError{Line: tok.Line, Col: tok.Col, Msg: msg})
 	p.advance() // Skip problematic token
 }
@@ -0,0 +1,93 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRegisterExhaustion is returned when a program needs more simultaneously
+// live registers of one kind than the VM provides.
+var ErrRegisterExhaustion = errors.New("out of registers")
+
+// Register file sizes, matching vm.NumScalarRegs and vm.NumVectorRegs.
+var registerLimits = map[byte]int{'R': 16, 'F': 16, 'V': 8}
+
+// virtualReg identifies a register before allocation.
+type virtualReg struct {
+	file byte
+	num  int64
+}
+
+// allocateRegisters rewrites a program that uses an unbounded number of
+// virtual registers to use the VM's physical registers. DSL output has no
+// jumps, so a register is live from its first mention to its last. A
+// physical register is reused once the value in it is dead; a register whose
+// own number is free keeps it, so programs that already fit are left
+// unchanged.
+func allocateRegisters(insts []Instruction) ([]Instruction, error) {
+	if len(insts) == 0 {
+		return insts, nil
+	}
+
+	last := make(map[virtualReg]int)
+	for i, inst := range insts {
+		for _, op := range inst.Operands {
+			if op.Kind == OperandReg {
+				last[virtualReg{op.Reg, op.Int}] = i
+			}
+		}
+	}
+
+	assigned := make(map[virtualReg]int64)
+	inUse := map[byte][]bool{
+		'R': make([]bool, registerLimits['R']),
+		'F': make([]bool, registerLimits['F']),
+		'V': make([]bool, registerLimits['V']),
+	}
+
+	out := make([]Instruction, len(insts))
+	for i, inst := range insts {
+		ops := make([]Operand, len(inst.Operands))
+		for j, op := range inst.Operands {
+			ops[j] = op
+			if op.Kind != OperandReg {
+				continue
+			}
+			reg := virtualReg{op.Reg, op.Int}
+			phys, ok := assigned[reg]
+			if !ok {
+				if phys, ok = pickRegister(inUse[op.Reg], op.Int); !ok {
+					return nil, fmt.Errorf("%w: more than %d %c registers live at %q", ErrRegisterExhaustion, registerLimits[op.Reg], op.Reg, inst)
+				}
+				assigned[reg] = phys
+				inUse[op.Reg][phys] = true
+			}
+			ops[j].Int = phys
+		}
+		out[i] = Instruction{Op: inst.Op, Operands: ops}
+
+		// Free registers after the whole instruction so a result never
+		// shares a register with one of its own operands.
+		for _, op := range inst.Operands {
+			if reg := (virtualReg{op.Reg, op.Int}); op.Kind == OperandReg && last[reg] == i {
+				inUse[op.Reg][assigned[reg]] = false
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// pickRegister returns num when it is free, otherwise the lowest free
+// register.
+func pickRegister(inUse []bool, num int64) (int64, bool) {
+	if num < int64(len(inUse)) && !inUse[num] {
+		return num, true
+	}
+	for n, used := range inUse {
+		if !used {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
@@ -55,6 +55,7 @@ const (
 	TokenLeftJoin  // left_join
 	TokenRightJoin // right_join
 	TokenOuterJoin // outer_join
+	TokenCrossJoin // cross_join
 	TokenReturn    // return
 
 	// Aggregation functions
@@ -84,11 +85,16 @@ const (
 
 	// Data loading
 	TokenLoadJSON    // load_json
+	TokenLoadJSONL   // load_jsonl
+	TokenLoadURL     // load_url
 	TokenLoadParquet // load_parquet
 
 	// Index operations
 	TokenTake // take
 
+	// Reshaping operations
+	TokenPivot // pivot
+
 	// Literals
 	TokenTrue  // true
 	TokenFalse // false
@@ -183,6 +189,8 @@ func (t TokenType) String() string {
 		return "RIGHT_JOIN"
 	case TokenOuterJoin:
 		return "OUTER_JOIN"
+	case TokenCrossJoin:
+		return "CROSS_JOIN"
 	case TokenReturn:
 		return "RETURN"
 	case TokenSum:
@@ -225,10 +233,16 @@ func (t TokenType) String() string {
 		return "COL_COUNT"
 	case TokenLoadJSON:
 		return "LOAD_JSON"
+	case TokenLoadJSONL:
+		return "LOAD_JSONL"
+	case TokenLoadURL:
+		return "LOAD_URL"
 	case TokenLoadParquet:
 		return "LOAD_PARQUET"
 	case TokenTake:
 		return "TAKE"
+	case TokenPivot:
+		return "PIVOT"
 	case TokenTrue:
 		return "TRUE"
 	case TokenFalse:
@@ -298,9 +312,13 @@ var keywords = map[string]TokenType{
 	"row_count":    TokenRowCount,
 	"col_count":    TokenColCount,
 	"load_json":    TokenLoadJSON,
+	"load_jsonl":   TokenLoadJSONL,
+	"load_url":     TokenLoadURL,
 	"load_parquet": TokenLoadParquet,
 	"take":         TokenTake,
+	"pivot":        TokenPivot,
 	"outer_join":   TokenOuterJoin,
+	"cross_join":   TokenCrossJoin,
 }
 
 // LookupIdent returns the token type for an identifier.
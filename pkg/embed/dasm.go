@@ -23,12 +23,19 @@
 //	    REDUCE_SUM_F  F0, V0
 //	    HALT_F        F0
 //	`, map[string]*dataframe.DataFrame{"sales": frame})
+//
+// With typed accessors instead of a type switch:
+//
+//	result, err := dasm.ExecuteResult(code)
+//	total, err := result.AsFloat()
 package embed
 
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
+	"sync"
 	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
@@ -44,8 +51,43 @@ var (
 	ErrInstructionLimit = errors.New("instruction limit exceeded")
 	ErrMemoryLimit      = errors.New("memory limit exceeded")
 	ErrFileAccessDenied = errors.New("file access denied in sandbox mode")
+	ErrURLAccessDenied  = errors.New("URL access denied in sandbox mode")
+	ErrNoReturn         = errors.New("script has no return statement")
+
+	// ErrResultKind is returned by Result accessors that do not match the
+	// kind of value the program returned.
+	ErrResultKind = vm.ErrResultKind
+)
+
+// Result wraps a program's return value with typed accessors (AsInt,
+// AsFloat, AsSeries, AsFrame, AsString) and Kind.
+type Result = vm.Result
+
+// ResultKind identifies the type of value a program returned.
+type ResultKind = vm.ResultKind
+
+// Result kinds.
+const (
+	ResultNone   = vm.ResultNone
+	ResultInt    = vm.ResultInt
+	ResultFloat  = vm.ResultFloat
+	ResultSeries = vm.ResultSeries
+	ResultFrame  = vm.ResultFrame
+	ResultString = vm.ResultString
 )
 
+// compileHook, when non-nil, is called each time this package compiles
+// source code. Tests use it to count compilations.
+var compileHook func()
+
+// compile compiles assembly source for the Execute functions and Prepare.
+func compile(code string) (*vm.Program, error) {
+	if compileHook != nil {
+		compileHook()
+	}
+	return vm.Assemble(code)
+}
+
 // Execute compiles and runs DFL assembly code, returns the result.
 func Execute(code string) (any, error) {
 	return ExecuteWithFrames(code, nil)
@@ -64,7 +106,7 @@ func ExecuteFile(path string) (any, error) {
 // Scripts access these via LOAD_FRAME instruction.
 func ExecuteWithFrames(code string, frames map[string]*dataframe.DataFrame) (any, error) {
 	// Compile
-	program, err := compiler.Compile(code)
+	program, err := compile(code)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +134,8 @@ type Options struct {
 	// Timeout sets maximum execution time. Zero means no timeout.
 	Timeout time.Duration
 
-	// MaxInstructions limits the number of instructions executed.
+	// MaxInstructions limits the number of instructions executed. Joins,
+	// group-bys and sorts also count one step per 1000 input rows.
 	// Zero means unlimited.
 	MaxInstructions int64
 
@@ -108,8 +151,14 @@ type Options struct {
 	// Supports exact paths only (no globs).
 	AllowedPaths []string
 
+	// AllowedURLs lists URL prefixes that can be fetched even in sandbox mode.
+	AllowedURLs []string
+
 	// Context for cancellation. If nil, context.Background() is used.
 	Context context.Context
+
+	// Output receives the text PRINT writes. Nil discards it.
+	Output io.Writer
 }
 
 // Option is a functional option for configuring execution.
@@ -157,6 +206,13 @@ func WithAllowedPaths(paths ...string) Option {
 	}
 }
 
+// WithAllowedURLs sets URL prefixes accessible in sandbox mode.
+func WithAllowedURLs(urls ...string) Option {
+	return func(o *Options) {
+		o.AllowedURLs = urls
+	}
+}
+
 // WithContext sets the context for cancellation.
 func WithContext(ctx context.Context) Option {
 	return func(o *Options) {
@@ -164,6 +220,13 @@ func WithContext(ctx context.Context) Option {
 	}
 }
 
+// WithOutput sets the writer PRINT writes to.
+func WithOutput(w io.Writer) Option {
+	return func(o *Options) {
+		o.Output = w
+	}
+}
+
 // ExecuteWithOptions executes code with advanced configuration.
 // Supports resource limits, timeouts, and sandboxing.
 //
@@ -176,38 +239,60 @@ func WithContext(ctx context.Context) Option {
 //	    dfl.WithFrames(map[string]*dataframe.DataFrame{"data": frame}),
 //	)
 func ExecuteWithOptions(code string, opts ...Option) (any, error) {
-	// Apply options
-	options := &Options{
-		Context: context.Background(),
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+	options := newOptions(opts...)
 
 	// Compile
-	program, err := compiler.Compile(code)
+	program, err := compile(code)
 	if err != nil {
 		return nil, err
 	}
+	return executeProgram(program, options)
+}
 
+// executeProgram runs a compiled program under options.
+func executeProgram(program *vm.Program, options *Options) (any, error) {
 	// Create VM with options
 	machine := vm.NewVM()
 	if options.Frames != nil {
 		machine.SetPredeclaredFrames(options.Frames)
 	}
-
-	// Configure VM limits
-	machine.SetInstructionLimit(options.MaxInstructions)
-	machine.SetMemoryLimit(options.MaxMemoryBytes)
-	machine.SetSandbox(options.Sandbox, options.AllowedPaths)
+	configure(machine, options)
 
 	// Load program
 	if err := machine.Load(program); err != nil {
 		return nil, err
 	}
+	return execute(machine, options)
+}
 
+// newOptions returns the Options opts describe.
+func newOptions(opts ...Option) *Options {
+	options := &Options{
+		Context: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// configure applies the limits, sandbox and output of options to machine.
+func configure(machine *vm.VM, options *Options) {
+	machine.SetInstructionLimit(options.MaxInstructions)
+	machine.SetMemoryLimit(options.MaxMemoryBytes)
+	machine.SetSandbox(options.Sandbox, options.AllowedPaths)
+	machine.SetAllowedURLs(options.AllowedURLs)
+	machine.SetOutput(options.Output)
+}
+
+// execute runs the program loaded in machine under the context and timeout
+// of options, mapping VM errors to this package's.
+func execute(machine *vm.VM, options *Options) (any, error) {
 	// Setup timeout context
 	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	if options.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
@@ -226,6 +311,8 @@ func ExecuteWithOptions(code string, opts ...Option) (any, error) {
 			return nil, ErrMemoryLimit
 		case errors.Is(err, vm.ErrFileAccessDenied):
 			return nil, ErrFileAccessDenied
+		case errors.Is(err, vm.ErrURLAccessDenied):
+			return nil, ErrURLAccessDenied
 		case errors.Is(err, context.DeadlineExceeded):
 			return nil, ErrTimeout
 		case errors.Is(err, context.Canceled):
@@ -237,8 +324,17 @@ func ExecuteWithOptions(code string, opts ...Option) (any, error) {
 	return result, nil
 }
 
+// ExecuteResult is ExecuteWithOptions returning a typed Result.
+func ExecuteResult(code string, opts ...Option) (Result, error) {
+	value, err := ExecuteWithOptions(code, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+	return vm.NewResult(value), nil
+}
+
 // ExecuteDSL compiles and runs high-level DSL code.
-// The DSL is compiled to assembly first, then executed.
+// The DSL is compiled straight to bytecode, then executed.
 //
 // Example:
 //
@@ -248,12 +344,31 @@ func ExecuteWithOptions(code string, opts ...Option) (any, error) {
 //	    return sum(data.price)
 //	`)
 func ExecuteDSL(code string, opts ...Option) (any, error) {
-	// Import DSL package inline to avoid circular dependency
-	dslAsm, err := compileDSL(code)
+	options := &Options{
+		Context: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	ast, err := dsl.NewParser(dsl.NewLexer(code).Tokenize()).Parse()
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteWithOptions(dslAsm, opts...)
+	program, err := compiler.CompileDSL(ast)
+	if err != nil {
+		return nil, err
+	}
+	return executeProgram(program, options)
+}
+
+// ExecuteDSLResult is ExecuteDSL returning a typed Result.
+func ExecuteDSLResult(code string, opts ...Option) (Result, error) {
+	value, err := ExecuteDSL(code, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+	return vm.NewResult(value), nil
 }
 
 // ExecuteDSLFile reads a .dfx file and executes it.
@@ -265,8 +380,127 @@ func ExecuteDSLFile(path string, opts ...Option) (any, error) {
 	return ExecuteDSL(string(data), opts...)
 }
 
+// ExecuteScript runs a DSL script with several return statements and returns
+// each returned value in order. The script runs once on one VM, pausing at
+// each return, so frames and variables defined before a return stay
+// available after it. Statements after the last return are not run.
+//
+// Example:
+//
+//	results, err := dasm.ExecuteScript(`
+//	    data = frame("sales")
+//	    return sum(data.price)
+//	    return mean(data.quantity)
+//	`, map[string]*dataframe.DataFrame{"sales": frame})
+func ExecuteScript(src string, frames map[string]*dataframe.DataFrame) ([]any, error) {
+	ast, err := dsl.NewParser(dsl.NewLexer(src).Tokenize()).Parse()
+	if err != nil {
+		return nil, err
+	}
+	returns := 0
+	for _, stmt := range ast.Statements {
+		if _, ok := stmt.(*dsl.ReturnStmt); ok {
+			returns++
+		}
+	}
+	if returns == 0 {
+		return nil, ErrNoReturn
+	}
+
+	program, err := compiler.CompileDSL(ast)
+	if err != nil {
+		return nil, err
+	}
+	machine := vm.NewVM()
+	if frames != nil {
+		machine.SetPredeclaredFrames(frames)
+	}
+	if err := machine.Load(program); err != nil {
+		return nil, err
+	}
+
+	results := make([]any, 0, returns)
+	value, err := machine.Execute()
+	for {
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, value)
+		if len(results) == returns {
+			return results, nil
+		}
+		value, err = machine.Resume()
+	}
+}
+
+// Prepared is an assembly program compiled and validated once by Prepare,
+// ready to run repeatedly against different frames. It is safe for
+// concurrent use; runs are serialized on a single VM.
+type Prepared struct {
+	mu      sync.Mutex
+	program *vm.Program
+	machine *vm.VM
+	opts    []Option
+}
+
+// Prepare compiles and validates code for repeated execution with Run. The
+// options apply to every run: limits, sandboxing, output, context and
+// timeout are set on the VM before each one, and Frames is used when Run is
+// given none.
+//
+// Example:
+//
+//	p, err := dasm.Prepare(`
+//	    LOAD_FRAME    R0, "sales"
+//	    SELECT_COL    V0, R0, "price"
+//	    REDUCE_SUM_F  F0, V0
+//	    HALT_F        F0
+//	`, dasm.WithMaxInstructions(10000), dasm.WithSandbox())
+//	monday, err := p.Run(map[string]*dataframe.DataFrame{"sales": mon})
+//	tuesday, err := p.Run(map[string]*dataframe.DataFrame{"sales": tue}, dasm.WithContext(ctx))
+func Prepare(code string, opts ...Option) (*Prepared, error) {
+	program, err := compile(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := vm.ValidateProgram(program); err != nil {
+		return nil, err
+	}
+	machine := vm.NewVM()
+	configure(machine, newOptions(opts...))
+	if err := machine.Load(program); err != nil {
+		return nil, err
+	}
+	return &Prepared{program: program, machine: machine, opts: opts}, nil
+}
+
+// Program returns the program Prepare compiled, which every Run executes.
+func (p *Prepared) Program() *vm.Program {
+	return p.program
+}
+
+// Run executes the prepared program with frames available to LOAD_FRAME,
+// under the options given to Prepare followed by opts. Registers, frames
+// and results left by a previous run are reset first.
+func (p *Prepared) Run(frames map[string]*dataframe.DataFrame, opts ...Option) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	options := newOptions(append(p.opts[:len(p.opts):len(p.opts)], opts...)...)
+	if frames == nil {
+		frames = options.Frames
+	}
+	configure(p.machine, options)
+	p.machine.SetPredeclaredFrames(frames)
+	p.machine.Reset()
+	return execute(p.machine, options)
+}
+
 // compileDSL compiles DSL code to assembly.
 func compileDSL(code string) (string, error) {
+	if compileHook != nil {
+		compileHook()
+	}
 	lexer := dsl.NewLexer(code)
 	tokens := lexer.Tokenize()
 
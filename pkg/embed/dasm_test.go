@@ -1,14 +1,22 @@
 package embed
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
+
+	"github.com/akhildatla/dasm/pkg/compiler"
+	"github.com/akhildatla/dasm/pkg/vm"
 )
 
 func TestExecute_BasicProgram(t *testing.T) {
@@ -151,6 +159,127 @@ HALT_F        F0
 	}
 }
 
+func TestExecuteResult_Kinds(t *testing.T) {
+	frames := WithFrames(map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0)),
+	})
+
+	result, err := ExecuteResult("LOAD_CONST R0, 9\nHALT R0")
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if result.Kind() != ResultInt {
+		t.Errorf("Kind = %s, want int", result.Kind())
+	}
+	if v, err := result.AsInt(); err != nil || v != 9 {
+		t.Errorf("AsInt = %d, %v; want 9", v, err)
+	}
+	if _, err := result.AsFloat(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsFloat on int result: expected ErrResultKind, got %v", err)
+	}
+
+	result, err = ExecuteResult(`
+LOAD_FRAME    R0, "sales"
+SELECT_COL    V0, R0, "price"
+HALT_V        V0
+`, frames)
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	series, err := result.AsSeries()
+	if err != nil {
+		t.Fatalf("AsSeries failed: %v", err)
+	}
+	if series.NRows() != 2 || series.Value(1) != 20.0 {
+		t.Errorf("unexpected series: %v", series)
+	}
+	if _, err := result.AsFrame(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsFrame on series result: expected ErrResultKind, got %v", err)
+	}
+
+	result, err = ExecuteDSLResult("data = frame(\"sales\")\nreturn sum(data.price)", frames)
+	if err != nil {
+		t.Fatalf("ExecuteDSLResult failed: %v", err)
+	}
+	if v, err := result.AsFloat(); err != nil || v != 30.0 {
+		t.Errorf("AsFloat = %g, %v; want 30", v, err)
+	}
+
+	if _, err := ExecuteResult("FROBNICATE R0\nHALT R0"); err == nil {
+		t.Error("expected compile error")
+	}
+}
+
+func TestExecuteDSL_GroupSizes(t *testing.T) {
+	frames := WithFrames(map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(
+			dataframe.NewSeriesString("category", nil, "toys", "food", "toys", "books", "toys", "food"),
+		),
+	})
+
+	result, err := ExecuteDSLResult(`
+data = frame("sales")
+grouped = data |> group_by(category)
+return group_sizes(grouped)
+`, frames)
+	if err != nil {
+		t.Fatalf("ExecuteDSLResult failed: %v", err)
+	}
+	sizes, err := result.AsFrame()
+	if err != nil {
+		t.Fatalf("AsFrame failed: %v", err)
+	}
+
+	// Keys come out in first-appearance order, the GROUP_BY KeyOrder
+	wantKeys := []string{"toys", "food", "books"}
+	wantSizes := []int64{3, 2, 1}
+	if sizes.NRows() != len(wantKeys) {
+		t.Fatalf("rows = %d, want %d", sizes.NRows(), len(wantKeys))
+	}
+	for i := range wantKeys {
+		if got := sizes.Series[0].Value(i); got != wantKeys[i] {
+			t.Errorf("row %d: key = %v, want %s", i, got, wantKeys[i])
+		}
+		if got := sizes.Series[1].Value(i); got != wantSizes[i] {
+			t.Errorf("row %d: size = %v, want %d", i, got, wantSizes[i])
+		}
+	}
+}
+
+func TestExecuteDSL_ReturnFrame(t *testing.T) {
+	frames := WithFrames(map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 10.0, nil, 30.0)),
+	})
+
+	// Returning a frame halts with HALT_FRAME, so the frame itself comes back
+	result, err := ExecuteDSLResult("data = frame(\"sales\")\nreturn dropna(data)", frames)
+	if err != nil {
+		t.Fatalf("ExecuteDSLResult failed: %v", err)
+	}
+	if result.Kind() != ResultFrame {
+		t.Fatalf("Kind = %s, want frame", result.Kind())
+	}
+	frame, _ := result.AsFrame()
+	if frame.NRows() != 2 {
+		t.Errorf("rows = %d, want 2", frame.NRows())
+	}
+}
+
+func TestExecuteWithOptions_Output(t *testing.T) {
+	var out bytes.Buffer
+	_, err := ExecuteWithOptions(`
+LOAD_CONST    R0, 42
+PRINT         R0, "int"
+HALT          R0
+`, WithOutput(&out))
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if got := out.String(); got != "R0 = 42\n" {
+		t.Errorf("output = %q, want %q", got, "R0 = 42\n")
+	}
+}
+
 func TestExecute_WithCSV(t *testing.T) {
 	// Create temp CSV file
 	csvData := `price,quantity
@@ -190,6 +319,32 @@ HALT_F        F0
 	}
 }
 
+func TestExecute_WithCSVOptions(t *testing.T) {
+	// Headerless, semicolon-delimited file
+	csvData := `10;5
+20;15
+30;20`
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "sales.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write CSV file: %v", err)
+	}
+
+	result, err := Execute(`
+LOAD_CSV_OPTS R0, "` + csvPath + `", "delim=; header=false"
+SELECT_COL    V0, R0, "col1"
+REDUCE_SUM    R1, V0
+HALT          R1
+`)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if result != int64(40) {
+		t.Errorf("expected 40, got %v", result)
+	}
+}
+
 // ===== Phase 3: ExecuteWithOptions Tests =====
 
 func TestExecuteWithOptions_BasicProgram(t *testing.T) {
@@ -331,6 +486,77 @@ HALT_F        F0
 	}
 }
 
+func TestExecuteWithOptions_SandboxURLDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("price\n10.0\n"))
+	}))
+	defer server.Close()
+
+	_, err := ExecuteWithOptions(`
+LOAD_HTTP     R0, "`+server.URL+`/sales.csv"
+HALT          R0
+`, WithSandbox())
+
+	if !errors.Is(err, ErrURLAccessDenied) {
+		t.Errorf("expected ErrURLAccessDenied, got %v", err)
+	}
+}
+
+func TestExecuteWithOptions_SandboxWithAllowedURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("price,quantity\n10.0,5\n2.5,3\n"))
+	}))
+	defer server.Close()
+
+	result, err := ExecuteWithOptions(`
+LOAD_HTTP     R0, "`+server.URL+`/sales.csv"
+SELECT_COL    V0, R0, "price"
+REDUCE_SUM_F  F0, V0
+HALT_F        F0
+`, WithSandbox(), WithAllowedURLs(server.URL))
+
+	if err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	if result != 12.5 {
+		t.Errorf("expected 12.5, got %v", result)
+	}
+}
+
+func TestExecuteWithOptions_SandboxRedirectDenied(t *testing.T) {
+	outside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret\n1\n"))
+	}))
+	defer outside.Close()
+	allowed := httptest.NewServer(http.RedirectHandler(outside.URL+"/secret.csv", http.StatusFound))
+	defer allowed.Close()
+
+	_, err := ExecuteWithOptions(`
+LOAD_HTTP     R0, "`+allowed.URL+`/sales.csv"
+HALT          R0
+`, WithSandbox(), WithAllowedURLs(allowed.URL))
+
+	if !errors.Is(err, ErrURLAccessDenied) {
+		t.Errorf("expected ErrURLAccessDenied for a redirect off the allow-list, got %v", err)
+	}
+}
+
+func TestExecuteWithOptions_URLResponseOverMemoryLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("price\n" + strings.Repeat("1.5\n", 1000)))
+	}))
+	defer server.Close()
+
+	_, err := ExecuteWithOptions(`
+LOAD_HTTP     R0, "`+server.URL+`/sales.csv"
+HALT          R0
+`, WithMaxMemory(100))
+
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("expected ErrMemoryLimit, got %v", err)
+	}
+}
+
 // ===== Phase 3: ExecuteDSL Tests =====
 
 func TestExecuteDSL_SimpleExpression(t *testing.T) {
@@ -358,6 +584,98 @@ return sum(col)
 	}
 }
 
+func TestExecuteDSL_ReturnObjectResults(t *testing.T) {
+	asm, err := compileDSL(`
+data = frame("sales")
+return { total: sum(data.price), avg: mean(data.price) }
+`)
+	if err != nil {
+		t.Fatalf("compileDSL failed: %v", err)
+	}
+	program, err := compiler.Compile(asm)
+	if err != nil {
+		t.Fatalf("Compile failed: %v\n%s", err, asm)
+	}
+
+	machine := vm.NewVM()
+	machine.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0, 30.0)),
+	})
+	if err := machine.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := machine.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != int64(2) {
+		t.Errorf("expected the field count 2, got %v", result)
+	}
+
+	results := machine.Results()
+	if got := results["total"]; got != 60.0 {
+		t.Errorf("total = %v, want 60", got)
+	}
+	if got := results["avg"]; got != 20.0 {
+		t.Errorf("avg = %v, want 20", got)
+	}
+}
+
+func TestExecuteDSL_StringAndBoolLiterals(t *testing.T) {
+	result, err := ExecuteDSL(`return "hello"`)
+	if err != nil {
+		t.Fatalf("ExecuteDSL failed: %v", err)
+	}
+	if result != "hello" {
+		t.Errorf("expected \"hello\", got %#v", result)
+	}
+
+	r, err := ExecuteDSLResult("greeting = \"hi there\"\nreturn greeting")
+	if err != nil {
+		t.Fatalf("ExecuteDSLResult failed: %v", err)
+	}
+	if v, err := r.AsString(); err != nil || v != "hi there" {
+		t.Errorf("AsString = %q, %v; want \"hi there\"", v, err)
+	}
+
+	// Bools are ints in R registers
+	result, err = ExecuteDSL("return true")
+	if err != nil {
+		t.Fatalf("ExecuteDSL failed: %v", err)
+	}
+	if result != int64(1) {
+		t.Errorf("expected 1 for true, got %#v", result)
+	}
+	result, err = ExecuteDSL("flag = false\nreturn flag")
+	if err != nil {
+		t.Fatalf("ExecuteDSL failed: %v", err)
+	}
+	if result != int64(0) {
+		t.Errorf("expected 0 for false, got %#v", result)
+	}
+}
+
+func TestExecuteDSL_ManyTemporaries(t *testing.T) {
+	// More vector temporaries than V registers while base stays live
+	code := "data = frame(\"t\")\nbase = data.x\n"
+	for i := 1; i <= 10; i++ {
+		code += fmt.Sprintf("s%d = sum(data.y * %d)\n", i, i)
+	}
+	code += "return sum(base)\n"
+
+	result, err := ExecuteDSL(code, WithFrames(map[string]*dataframe.DataFrame{
+		"t": dataframe.NewDataFrame(
+			dataframe.NewSeriesFloat64("x", nil, 1.0, 2.0, 3.0),
+			dataframe.NewSeriesFloat64("y", nil, 10.0, 20.0, 30.0),
+		),
+	}))
+	if err != nil {
+		t.Fatalf("ExecuteDSL failed: %v", err)
+	}
+	if result != 6.0 {
+		t.Errorf("expected sum(base) = 6, got %v", result)
+	}
+}
 func TestExecuteDSLFile(t *testing.T) {
 	// Create temp DSL file - simple return statement
 	dslCode := `return 42`
@@ -805,3 +1123,199 @@ func TestExecuteDSL_ReturnConstant(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteDSL_Between(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 5.0, 10.0, 15.0, 20.0, 25.0),
+	)
+
+	tests := []struct {
+		name   string
+		bounds string
+	}{
+		{"int bounds", "10, 20"},
+		{"float bounds", "10.0, 20.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExecuteDSL(`
+data = frame("sales")
+in_range = data |> filter(between(price, `+tt.bounds+`))
+return count(in_range.price)
+`, WithFrames(map[string]*dataframe.DataFrame{"sales": frame}))
+			if err != nil {
+				t.Fatalf("ExecuteDSL failed: %v", err)
+			}
+			// 10, 15 and 20 fall in range; both bounds are inclusive
+			if result != int64(3) {
+				t.Errorf("expected 3 rows in range, got %v", result)
+			}
+		})
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	frames := map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(
+			dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0, 30.0),
+			dataframe.NewSeriesInt64("quantity", nil, 1, 2, 6),
+		),
+	}
+
+	results, err := ExecuteScript(`
+data = frame("sales")
+return sum(data.price)
+big = data |> filter(quantity > 1)
+return count(big.price)
+return "done"
+`, frames)
+	if err != nil {
+		t.Fatalf("ExecuteScript failed: %v", err)
+	}
+
+	want := []any{60.0, int64(2), "done"}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %v", len(want), results)
+	}
+	for i, w := range want {
+		if results[i] != w {
+			t.Errorf("result %d: expected %v, got %v", i, w, results[i])
+		}
+	}
+}
+
+func TestExecuteScript_Errors(t *testing.T) {
+	if _, err := ExecuteScript(`x = 1`, nil); !errors.Is(err, ErrNoReturn) {
+		t.Errorf("expected ErrNoReturn, got %v", err)
+	}
+	if _, err := ExecuteScript(`return ((`, nil); err == nil {
+		t.Error("expected a parse error")
+	}
+	if _, err := ExecuteScript("return 1\ndata = frame(\"missing\")\nreturn count(data.x)", nil); err == nil {
+		t.Error("expected an error from the second statement")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	compiles := 0
+	compileHook = func() { compiles++ }
+	defer func() { compileHook = nil }()
+
+	p, err := Prepare(`
+		LOAD_FRAME    R0, "sales"
+		SELECT_COL    V0, R0, "price"
+		REDUCE_SUM_F  F0, V0
+		HALT_F        F0
+	`)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	program := p.Program()
+
+	runs := []struct {
+		prices []float64
+		want   float64
+	}{
+		{[]float64{1.5, 2.5}, 4.0},
+		{[]float64{10, 20, 30}, 60.0},
+	}
+	for i, r := range runs {
+		vals := make([]interface{}, len(r.prices))
+		for j, v := range r.prices {
+			vals[j] = v
+		}
+		frames := map[string]*dataframe.DataFrame{
+			"sales": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("price", nil, vals...)),
+		}
+		result, err := p.Run(frames)
+		if err != nil {
+			t.Fatalf("run %d: Run failed: %v", i, err)
+		}
+		if result != r.want {
+			t.Errorf("run %d: expected %v, got %v", i, r.want, result)
+		}
+	}
+
+	// Every run executes the program compiled by Prepare
+	if compiles != 1 {
+		t.Errorf("expected 1 compilation, got %d", compiles)
+	}
+	if p.Program() != program {
+		t.Error("expected runs to reuse the prepared program")
+	}
+}
+
+func TestPrepare_Options(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "sales.csv")
+	if err := os.WriteFile(csvPath, []byte("price\n10.0\n"), 0644); err != nil {
+		t.Fatalf("failed to write CSV file: %v", err)
+	}
+	load := `
+		LOAD_CSV      R0, "` + csvPath + `"
+		ROW_COUNT     R1, R0
+		HALT          R1
+	`
+
+	sandboxed, err := Prepare(load, WithSandbox())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if _, err := sandboxed.Run(nil); !errors.Is(err, ErrFileAccessDenied) {
+		t.Errorf("expected ErrFileAccessDenied, got %v", err)
+	}
+
+	allowed, err := Prepare(load, WithSandbox(), WithAllowedPaths(csvPath))
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if result, err := allowed.Run(nil); err != nil || result != int64(1) {
+		t.Errorf("expected 1 row from an allowed path, got %v (err %v)", result, err)
+	}
+
+	limited, err := Prepare(load, WithMaxInstructions(2))
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if _, err := limited.Run(nil); !errors.Is(err, ErrInstructionLimit) {
+		t.Errorf("expected ErrInstructionLimit, got %v", err)
+	}
+
+	// Per-run options apply to that run only
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := allowed.Run(nil, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if result, err := allowed.Run(nil); err != nil || result != int64(1) {
+		t.Errorf("expected the next run to succeed, got %v (err %v)", result, err)
+	}
+}
+
+func TestPrepare_Errors(t *testing.T) {
+	if _, err := Prepare(`BOGUS R0`); err == nil {
+		t.Error("expected a compile error")
+	}
+	if _, err := Prepare(`LOAD_CONST R0, 1`); err == nil {
+		t.Error("expected a validation error for a program without HALT")
+	}
+
+	p, err := Prepare(`
+		LOAD_FRAME    R0, "sales"
+		ROW_COUNT     R1, R0
+		HALT          R1
+	`)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	frames := map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(dataframe.NewSeriesInt64("id", nil, 1, 2, 3)),
+	}
+	if result, err := p.Run(frames); err != nil || result != int64(3) {
+		t.Fatalf("expected 3, got %v (err %v)", result, err)
+	}
+	// Frames from the previous run are not carried over
+	if _, err := p.Run(nil); err == nil {
+		t.Error("expected an error for a missing frame")
+	}
+}
@@ -0,0 +1,85 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// cacheKey identifies one version of a file on disk. A rewritten file gets a
+// new modification time (and usually a new size), so it misses the cache.
+type cacheKey struct {
+	path    string
+	modTime int64
+	size    int64
+}
+
+// frameCache holds the frames parsed by LoadCSVCached.
+var frameCache = struct {
+	sync.Mutex
+	frames map[cacheKey]*dataframe.DataFrame
+}{frames: make(map[cacheKey]*dataframe.DataFrame)}
+
+// parseHook, when non-nil, is called with the absolute path each time
+// LoadCSVCached parses a file rather than serving it from the cache.
+var parseHook func(path string)
+
+// LoadCSVCached loads a CSV file like LoadCSV, but reuses the frame parsed by
+// an earlier call for the same absolute path as long as the file's
+// modification time and size are unchanged.
+//
+// The returned frame has its own column list, so adding columns to it does
+// not affect the cache, but its series are shared with every other caller
+// and must be treated as read-only.
+func LoadCSVCached(path string) (*dataframe.DataFrame, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey{path: abs, modTime: info.ModTime().UnixNano(), size: info.Size()}
+
+	frameCache.Lock()
+	df, ok := frameCache.frames[key]
+	frameCache.Unlock()
+	if ok {
+		return shareFrame(df), nil
+	}
+
+	if parseHook != nil {
+		parseHook(abs)
+	}
+	df, err = LoadCSV(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	frameCache.Lock()
+	for k := range frameCache.frames {
+		if k.path == abs {
+			delete(frameCache.frames, k)
+		}
+	}
+	frameCache.frames[key] = df
+	frameCache.Unlock()
+	return shareFrame(df), nil
+}
+
+// ClearCache drops every frame held by LoadCSVCached.
+func ClearCache() {
+	frameCache.Lock()
+	frameCache.frames = make(map[cacheKey]*dataframe.DataFrame)
+	frameCache.Unlock()
+}
+
+// shareFrame returns a new frame over the same series as df.
+func shareFrame(df *dataframe.DataFrame) *dataframe.DataFrame {
+	series := make([]dataframe.Series, len(df.Series))
+	copy(series, df.Series)
+	return dataframe.NewDataFrame(series...)
+}
@@ -0,0 +1,122 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countParses installs a parseHook for the duration of the test and returns
+// a pointer to the number of files actually parsed.
+func countParses(t *testing.T) *int {
+	t.Helper()
+	ClearCache()
+	parses := 0
+	parseHook = func(string) { parses++ }
+	t.Cleanup(func() {
+		parseHook = nil
+		ClearCache()
+	})
+	return &parses
+}
+
+func TestLoadCSVCached_Hit(t *testing.T) {
+	parses := countParses(t)
+
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte("id,value\n1,10\n2,20\n"), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	first, err := LoadCSVCached(csvPath)
+	if err != nil {
+		t.Fatalf("LoadCSVCached failed: %v", err)
+	}
+	second, err := LoadCSVCached(csvPath)
+	if err != nil {
+		t.Fatalf("LoadCSVCached failed: %v", err)
+	}
+
+	if *parses != 1 {
+		t.Errorf("expected 1 parse, got %d", *parses)
+	}
+	if first == second {
+		t.Error("expected each load to return its own frame")
+	}
+	if second.NRows() != 2 || len(second.Series) != 2 {
+		t.Errorf("expected 2x2 frame, got %dx%d", second.NRows(), len(second.Series))
+	}
+
+	// A relative path to the same file shares the entry.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCSVCached(rel); err != nil {
+		t.Fatalf("LoadCSVCached failed: %v", err)
+	}
+	if *parses != 1 {
+		t.Errorf("expected relative path to hit the cache, got %d parses", *parses)
+	}
+}
+
+func TestLoadCSVCached_ModifiedFile(t *testing.T) {
+	parses := countParses(t)
+
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	if _, err := LoadCSVCached(csvPath); err != nil {
+		t.Fatalf("LoadCSVCached failed: %v", err)
+	}
+
+	if err := os.WriteFile(csvPath, []byte("id\n1\n2\n3\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test CSV: %v", err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(csvPath, later, later); err != nil {
+		t.Fatalf("failed to set modtime: %v", err)
+	}
+
+	df, err := LoadCSVCached(csvPath)
+	if err != nil {
+		t.Fatalf("LoadCSVCached failed: %v", err)
+	}
+	if *parses != 2 {
+		t.Errorf("expected modified file to be reparsed, got %d parses", *parses)
+	}
+	if df.NRows() != 3 {
+		t.Errorf("expected 3 rows from modified file, got %d", df.NRows())
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	parses := countParses(t)
+
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := LoadCSVCached(csvPath); err != nil {
+			t.Fatalf("LoadCSVCached failed: %v", err)
+		}
+		ClearCache()
+	}
+	if *parses != 2 {
+		t.Errorf("expected ClearCache to force a reparse, got %d parses", *parses)
+	}
+}
+
+func TestLoadCSVCached_MissingFile(t *testing.T) {
+	countParses(t)
+	if _, err := LoadCSVCached(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
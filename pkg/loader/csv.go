@@ -1,9 +1,14 @@
 package loader
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
-	"os"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 	"github.com/rocketlaunchr/dataframe-go/imports"
@@ -16,19 +21,60 @@ var (
 	ErrInvalidFormat = errors.New("invalid CSV format")
 )
 
+// DataType is a column type hint for CSV loading.
+// Values mirror vm.DataType so hints can be converted directly.
+type DataType uint8
+
+const (
+	TypeInt64 DataType = iota
+	TypeFloat64
+	TypeString
+)
+
+// String returns the string representation of the data type.
+func (t DataType) String() string {
+	switch t {
+	case TypeInt64:
+		return "int64"
+	case TypeFloat64:
+		return "float64"
+	case TypeString:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// CSVOptions configures how LoadCSVWithOptions parses a file.
+type CSVOptions struct {
+	Delimiter rune                // Field delimiter (default ',')
+	HasHeader bool                // First row holds column names; otherwise col0, col1, ... are generated
+	SkipRows  int                 // Number of leading lines to discard before parsing
+	Comment   rune                // Lines starting with this rune are ignored (0 disables)
+	Schema    map[string]DataType // Forced column types; unlisted columns are inferred
+}
+
+// DefaultCSVOptions returns the options LoadCSV uses: comma-delimited with a header row.
+func DefaultCSVOptions() CSVOptions {
+	return CSVOptions{
+		Delimiter: ',',
+		HasHeader: true,
+	}
+}
+
 // LoadCSV reads a CSV file and returns a DataFrame using dataframe-go.
 // - First row is header (column names)
 // - Auto-detects column types (int64, float64, bool, string)
 // - Empty values become nil
+// - Gzip-compressed files are decompressed transparently
 func LoadCSV(path string) (*dataframe.DataFrame, error) {
-	file, err := os.Open(path)
+	data, err := readFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	ctx := context.Background()
-	df, err := imports.LoadFromCSV(ctx, file, imports.CSVLoadOptions{
+	df, err := imports.LoadFromCSV(ctx, bytes.NewReader(data), imports.CSVLoadOptions{
 		// Auto-detect types (default behavior)
 		InferDataTypes: true,
 	})
@@ -42,3 +88,179 @@ func LoadCSV(path string) (*dataframe.DataFrame, error) {
 
 	return df, nil
 }
+
+// LoadCSVWithOptions reads a delimited text file using the given options.
+// Column types are auto-detected as in LoadCSV.
+func LoadCSVWithOptions(path string, opts CSVOptions) (*dataframe.DataFrame, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = skipLines(data, opts.SkipRows)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	delim := opts.Delimiter
+	if delim == 0 {
+		delim = ','
+	}
+
+	loadOpts := imports.CSVLoadOptions{
+		Comma:          delim,
+		Comment:        opts.Comment,
+		InferDataTypes: true,
+	}
+
+	if !opts.HasHeader {
+		n, err := countFields(data, delim, opts.Comment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+		}
+		headers := make([]string, n)
+		for i := range headers {
+			headers[i] = "col" + strconv.Itoa(i)
+		}
+		loadOpts.Headers = headers
+	}
+
+	if len(opts.Schema) > 0 {
+		loadOpts.DictateDataType = make(map[string]interface{}, len(opts.Schema))
+		for name, typ := range opts.Schema {
+			switch typ {
+			case TypeInt64:
+				loadOpts.DictateDataType[name] = int64(0)
+			case TypeFloat64:
+				loadOpts.DictateDataType[name] = float64(0)
+			case TypeString:
+				loadOpts.DictateDataType[name] = ""
+			default:
+				return nil, fmt.Errorf("unsupported type %d for column %s", typ, name)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	df, err := imports.LoadFromCSV(ctx, bytes.NewReader(data), loadOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if df == nil || len(df.Series) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return df, nil
+}
+
+// ParseCSVOptions parses an options spec of whitespace-separated key=value pairs,
+// e.g. "delim=; header=false skip=2 comment=# schema=zip:string,id:int".
+// Unlisted keys keep their defaults. The delimiter may also be given as "tab" or "space".
+func ParseCSVOptions(spec string) (CSVOptions, error) {
+	opts := DefaultCSVOptions()
+
+	for _, field := range strings.Fields(spec) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return opts, fmt.Errorf("invalid CSV option %q", field)
+		}
+
+		switch strings.ToLower(key) {
+		case "delim", "delimiter":
+			r, err := parseOptionRune(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid delimiter %q: %w", value, err)
+			}
+			opts.Delimiter = r
+		case "header":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid header value %q", value)
+			}
+			opts.HasHeader = b
+		case "skip":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return opts, fmt.Errorf("invalid skip value %q", value)
+			}
+			opts.SkipRows = n
+		case "comment":
+			r, err := parseOptionRune(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid comment %q: %w", value, err)
+			}
+			opts.Comment = r
+		case "schema":
+			schema, err := parseSchema(value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Schema = schema
+		default:
+			return opts, fmt.Errorf("unknown CSV option %q", key)
+		}
+	}
+
+	return opts, nil
+}
+
+func parseOptionRune(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "tab":
+		return '\t', nil
+	case "space":
+		return ' ', nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError || size != len(value) {
+		return 0, errors.New("must be a single character")
+	}
+	return r, nil
+}
+
+// parseSchema parses a comma-separated list of column:type pairs.
+func parseSchema(value string) (map[string]DataType, error) {
+	schema := make(map[string]DataType)
+	for _, entry := range strings.Split(value, ",") {
+		name, typeName, ok := strings.Cut(entry, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid schema entry %q", entry)
+		}
+		switch strings.ToLower(typeName) {
+		case "int", "int64":
+			schema[name] = TypeInt64
+		case "float", "float64":
+			schema[name] = TypeFloat64
+		case "string", "str":
+			schema[name] = TypeString
+		default:
+			return nil, fmt.Errorf("unknown schema type %q for column %s", typeName, name)
+		}
+	}
+	return schema, nil
+}
+
+// skipLines drops the first n lines of data.
+func skipLines(data []byte, n int) []byte {
+	for i := 0; i < n && len(data) > 0; i++ {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			return nil
+		}
+		data = data[idx+1:]
+	}
+	return data
+}
+
+// countFields returns the number of fields in the first record of data.
+func countFields(data []byte, delim, comment rune) (int, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delim
+	r.Comment = comment
+	record, err := r.Read()
+	if err != nil {
+		return 0, err
+	}
+	return len(record), nil
+}
@@ -446,3 +446,135 @@ func TestLoadCSV_ErrorDefinitions(t *testing.T) {
 		t.Error("ErrInvalidFormat should not be nil")
 	}
 }
+
+func TestLoadCSVWithOptions_Semicolon(t *testing.T) {
+	csvData := `# exported from billing
+id;name;value
+1;alice;100
+2;bob;200`
+
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	opts := DefaultCSVOptions()
+	opts.Delimiter = ';'
+	opts.SkipRows = 1
+
+	df, err := LoadCSVWithOptions(csvPath, opts)
+	if err != nil {
+		t.Fatalf("LoadCSVWithOptions failed: %v", err)
+	}
+
+	if len(df.Series) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(df.Series))
+	}
+	if df.Series[1].Name() != "name" {
+		t.Errorf("expected column 'name', got %q", df.Series[1].Name())
+	}
+	if df.Series[0].NRows() != 2 {
+		t.Errorf("expected 2 rows, got %d", df.Series[0].NRows())
+	}
+	if _, ok := df.Series[2].(*dataframe.SeriesInt64); !ok {
+		t.Errorf("expected value column to be SeriesInt64, got %T", df.Series[2])
+	}
+}
+
+func TestLoadCSVWithOptions_Headerless(t *testing.T) {
+	csvData := `1,alice,1.5
+# skipped comment
+2,bob,2.5
+3,carol,3.5`
+
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	df, err := LoadCSVWithOptions(csvPath, CSVOptions{Delimiter: ',', Comment: '#'})
+	if err != nil {
+		t.Fatalf("LoadCSVWithOptions failed: %v", err)
+	}
+
+	names := make([]string, len(df.Series))
+	for i, s := range df.Series {
+		names[i] = s.Name()
+	}
+	if len(names) != 3 || names[0] != "col0" || names[1] != "col1" || names[2] != "col2" {
+		t.Errorf("expected [col0, col1, col2], got %v", names)
+	}
+	if df.Series[0].NRows() != 3 {
+		t.Errorf("expected 3 rows, got %d", df.Series[0].NRows())
+	}
+	if v := df.Series[1].Value(0); v != "alice" {
+		t.Errorf("expected first row to be data, got %v", v)
+	}
+}
+
+func TestParseCSVOptions(t *testing.T) {
+	opts, err := ParseCSVOptions("delim=tab header=false skip=2 comment=#")
+	if err != nil {
+		t.Fatalf("ParseCSVOptions failed: %v", err)
+	}
+	if opts.Delimiter != '\t' || opts.HasHeader || opts.SkipRows != 2 || opts.Comment != '#' {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	opts, err = ParseCSVOptions("")
+	if err != nil {
+		t.Fatalf("ParseCSVOptions failed: %v", err)
+	}
+	if opts.Delimiter != ',' || !opts.HasHeader || opts.SkipRows != 0 || opts.Schema != nil {
+		t.Errorf("empty spec should yield defaults, got %+v", opts)
+	}
+
+	opts, err = ParseCSVOptions("schema=zip:string,qty:int")
+	if err != nil {
+		t.Fatalf("ParseCSVOptions failed: %v", err)
+	}
+	if opts.Schema["zip"] != TypeString || opts.Schema["qty"] != TypeInt64 {
+		t.Errorf("unexpected schema: %v", opts.Schema)
+	}
+
+	for _, spec := range []string{"delim=ab", "header=maybe", "skip=-1", "bogus=1", "header", "schema=zip", "schema=zip:date"} {
+		if _, err := ParseCSVOptions(spec); err == nil {
+			t.Errorf("expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestLoadCSVWithOptions_Schema(t *testing.T) {
+	csvData := `zip,amount
+02134,10
+90210,20`
+
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	opts := DefaultCSVOptions()
+	opts.Schema = map[string]DataType{"zip": TypeString}
+
+	df, err := LoadCSVWithOptions(csvPath, opts)
+	if err != nil {
+		t.Fatalf("LoadCSVWithOptions failed: %v", err)
+	}
+
+	zip, ok := df.Series[0].(*dataframe.SeriesString)
+	if !ok {
+		t.Fatalf("expected zip to be SeriesString, got %T", df.Series[0])
+	}
+	if v := zip.Value(0); v != "02134" {
+		t.Errorf("expected leading zero preserved, got %v", v)
+	}
+
+	// Unlisted columns keep inference
+	if _, ok := df.Series[1].(*dataframe.SeriesInt64); !ok {
+		t.Errorf("expected amount to be SeriesInt64, got %T", df.Series[1])
+	}
+}
@@ -0,0 +1,82 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrInvalidGzip is returned when a .gz file does not contain gzip data.
+var ErrInvalidGzip = errors.New("invalid gzip data")
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readFile reads a file, transparently decompressing it when it starts with
+// the gzip magic header. Files with a .gz extension must be valid gzip.
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, gzipMagic) {
+		if strings.HasSuffix(strings.ToLower(path), ".gz") {
+			return nil, fmt.Errorf("%w: %s has a .gz extension but no gzip header", ErrInvalidGzip, path)
+		}
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGzip, err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGzip, err)
+	}
+	return out, nil
+}
+
+// openFile opens a file for streaming, with the same transparent gzip
+// handling as readFile. Closing the returned reader closes the file.
+func openFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) {
+		if strings.HasSuffix(strings.ToLower(path), ".gz") {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s has a .gz extension but no gzip header", ErrInvalidGzip, path)
+		}
+		return readCloser{br, f}, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGzip, err)
+	}
+	return readCloser{zr, f}, nil
+}
+
+// readCloser reads from r and closes the underlying file c.
+type readCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (rc readCloser) Close() error {
+	return rc.c.Close()
+}
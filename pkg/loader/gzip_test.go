@@ -0,0 +1,107 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeGzip(t *testing.T, path string, data []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+}
+
+func TestLoadCSV_Gzip(t *testing.T) {
+	csvData := []byte(`id,name,value
+1,alice,100.5
+2,bob,200.25
+3,charlie,300`)
+
+	tmpDir := t.TempDir()
+	plainPath := filepath.Join(tmpDir, "test.csv")
+	gzPath := filepath.Join(tmpDir, "test.csv.gz")
+	if err := os.WriteFile(plainPath, csvData, 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	writeGzip(t, gzPath, csvData)
+
+	want, err := LoadCSV(plainPath)
+	if err != nil {
+		t.Fatalf("LoadCSV (plain) failed: %v", err)
+	}
+	got, err := LoadCSV(gzPath)
+	if err != nil {
+		t.Fatalf("LoadCSV (gzip) failed: %v", err)
+	}
+
+	if got.NRows() != want.NRows() || len(got.Series) != len(want.Series) {
+		t.Fatalf("shape mismatch: got %dx%d, want %dx%d",
+			got.NRows(), len(got.Series), want.NRows(), len(want.Series))
+	}
+	for i := range want.Series {
+		if got.Series[i].Name() != want.Series[i].Name() {
+			t.Errorf("column %d: got name %q, want %q", i, got.Series[i].Name(), want.Series[i].Name())
+		}
+		if got.Series[i].Type() != want.Series[i].Type() {
+			t.Errorf("column %d: got type %s, want %s", i, got.Series[i].Type(), want.Series[i].Type())
+		}
+		for r := 0; r < want.NRows(); r++ {
+			if got.Series[i].Value(r) != want.Series[i].Value(r) {
+				t.Errorf("column %d row %d: got %v, want %v", i, r, got.Series[i].Value(r), want.Series[i].Value(r))
+			}
+		}
+	}
+}
+
+func TestLoadJSON_Gzip(t *testing.T) {
+	tmpDir := t.TempDir()
+	gzPath := filepath.Join(tmpDir, "test.json.gz")
+	writeGzip(t, gzPath, []byte(`[{"id": 1}, {"id": 2}]`))
+
+	df, err := LoadJSON(gzPath)
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	if df.NRows() != 2 {
+		t.Errorf("expected 2 rows, got %d", df.NRows())
+	}
+}
+
+func TestLoadCSV_GzipExtensionNotGzip(t *testing.T) {
+	tmpDir := t.TempDir()
+	gzPath := filepath.Join(tmpDir, "fake.csv.gz")
+	if err := os.WriteFile(gzPath, []byte("id,name\n1,alice\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := LoadCSV(gzPath)
+	if !errors.Is(err, ErrInvalidGzip) {
+		t.Errorf("expected ErrInvalidGzip, got %v", err)
+	}
+}
+
+func TestLoadCSV_GzipTruncated(t *testing.T) {
+	tmpDir := t.TempDir()
+	gzPath := filepath.Join(tmpDir, "truncated.csv.gz")
+	if err := os.WriteFile(gzPath, []byte{0x1f, 0x8b, 0x08}, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := LoadCSV(gzPath)
+	if !errors.Is(err, ErrInvalidGzip) {
+		t.Errorf("expected ErrInvalidGzip, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/imports"
+)
+
+// HTTP-specific errors
+var (
+	ErrHTTPStatus        = errors.New("unexpected HTTP status")
+	ErrUnsupportedScheme = errors.New("unsupported URL scheme")
+	ErrResponseTooLarge  = errors.New("HTTP response too large")
+)
+
+// HTTPTimeout bounds each remote fetch, including reading the body.
+var HTTPTimeout = 30 * time.Second
+
+// maxRedirects matches the limit of http.Client's default redirect policy.
+const maxRedirects = 10
+
+// URLOptions configures how LoadURLWithOptions fetches a remote file.
+type URLOptions struct {
+	// Context cancels the request. Nil means context.Background().
+	Context context.Context
+
+	// MaxBytes limits the response body size; larger responses fail with
+	// ErrResponseTooLarge. Zero means unlimited.
+	MaxBytes int64
+
+	// CheckURL, when set, vets every URL fetched, the first one and each
+	// redirect target. A non-nil error stops the fetch and is returned.
+	CheckURL func(rawURL string) error
+}
+
+// LoadCSVURL fetches a CSV file over HTTP(S) and parses it like LoadCSV.
+func LoadCSVURL(rawURL string) (*dataframe.DataFrame, error) {
+	return loadCSVURL(rawURL, URLOptions{})
+}
+
+func loadCSVURL(rawURL string, opts URLOptions) (*dataframe.DataFrame, error) {
+	data, err := fetchURL(rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	df, err := imports.LoadFromCSV(context.Background(), bytes.NewReader(data), imports.CSVLoadOptions{
+		InferDataTypes: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if df == nil || len(df.Series) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return df, nil
+}
+
+// LoadJSONURL fetches a JSON array of objects over HTTP(S) and parses it like LoadJSON.
+func LoadJSONURL(rawURL string) (*dataframe.DataFrame, error) {
+	data, err := fetchURL(rawURL, URLOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return parseJSON(data)
+}
+
+// LoadURL fetches a remote file and picks the parser from the URL path
+// extension: .json, .jsonl/.ndjson, otherwise CSV.
+func LoadURL(rawURL string) (*dataframe.DataFrame, error) {
+	return LoadURLWithOptions(rawURL, URLOptions{})
+}
+
+// LoadURLWithOptions is LoadURL with a context, a response size limit and a
+// check applied to every URL the fetch visits.
+func LoadURLWithOptions(rawURL string, opts URLOptions) (*dataframe.DataFrame, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ext := strings.ToLower(path.Ext(u.Path)); ext {
+	case ".json", ".jsonl", ".ndjson":
+		data, err := fetchURL(rawURL, opts)
+		if err != nil {
+			return nil, err
+		}
+		if ext == ".json" {
+			return parseJSON(data)
+		}
+		return parseJSONLines(data)
+	default:
+		return loadCSVURL(rawURL, opts)
+	}
+}
+
+// fetchURL downloads the body of an http or https URL.
+func fetchURL(rawURL string, opts URLOptions) ([]byte, error) {
+	if err := checkURL(rawURL, opts); err != nil {
+		return nil, err
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: HTTPTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkURL(req.URL.String(), opts)
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrHTTPStatus, resp.Status)
+	}
+
+	if opts.MaxBytes <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	// Read one byte past the limit to tell a full-size body from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > opts.MaxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, opts.MaxBytes)
+	}
+	return data, nil
+}
+
+// checkURL rejects URLs that are not http or https and those opts.CheckURL
+// refuses.
+func checkURL(rawURL string, opts URLOptions) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedScheme, u.Scheme)
+	}
+	if opts.CheckURL != nil {
+		return opts.CheckURL(rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadCSVURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id,name\n1,alice\n2,bob\n"))
+	}))
+	defer server.Close()
+
+	df, err := LoadCSVURL(server.URL + "/people.csv")
+	if err != nil {
+		t.Fatalf("LoadCSVURL failed: %v", err)
+	}
+
+	if df.NRows() != 2 {
+		t.Errorf("expected 2 rows, got %d", df.NRows())
+	}
+	if v := df.Series[1].Value(1); v != "bob" {
+		t.Errorf("expected bob, got %v", v)
+	}
+}
+
+func TestLoadURL_DetectsJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+	}))
+	defer server.Close()
+
+	df, err := LoadURL(server.URL + "/data.json")
+	if err != nil {
+		t.Fatalf("LoadURL failed: %v", err)
+	}
+
+	if df.NRows() != 3 {
+		t.Errorf("expected 3 rows, got %d", df.NRows())
+	}
+}
+
+func TestLoadURLWithOptions_CheckURLOnRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id\n1\n"))
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.RedirectHandler(target.URL+"/data.csv", http.StatusFound))
+	defer origin.Close()
+
+	errDenied := errors.New("denied")
+	var checked []string
+	_, err := LoadURLWithOptions(origin.URL+"/data.csv", URLOptions{
+		CheckURL: func(rawURL string) error {
+			checked = append(checked, rawURL)
+			if strings.HasPrefix(rawURL, target.URL) {
+				return errDenied
+			}
+			return nil
+		},
+	})
+	if !errors.Is(err, errDenied) {
+		t.Fatalf("expected the redirect to be refused, got %v", err)
+	}
+	if len(checked) != 2 || checked[1] != target.URL+"/data.csv" {
+		t.Errorf("checked %v, want the original URL then the redirect target", checked)
+	}
+}
+
+func TestLoadURLWithOptions_MaxBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id,name\n1,alice\n2,bob\n"))
+	}))
+	defer server.Close()
+
+	if _, err := LoadURLWithOptions(server.URL+"/people.csv", URLOptions{MaxBytes: 10}); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	df, err := LoadURLWithOptions(server.URL+"/people.csv", URLOptions{MaxBytes: 1 << 10})
+	if err != nil {
+		t.Fatalf("LoadURLWithOptions failed: %v", err)
+	}
+	if df.NRows() != 2 {
+		t.Errorf("expected 2 rows, got %d", df.NRows())
+	}
+}
+
+func TestLoadURLWithOptions_Context(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id\n1\n"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadURLWithOptions(server.URL+"/data.csv", URLOptions{Context: ctx}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLoadCSVURL_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := LoadCSVURL(server.URL + "/missing.csv")
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("expected ErrHTTPStatus, got %v", err)
+	}
+}
+
+func TestLoadCSVURL_UnsupportedScheme(t *testing.T) {
+	_, err := LoadCSVURL("file:///etc/passwd")
+	if !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("expected ErrUnsupportedScheme, got %v", err)
+	}
+}
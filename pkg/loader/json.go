@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"os"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 	"github.com/rocketlaunchr/dataframe-go/imports"
@@ -18,13 +17,18 @@ var (
 
 // LoadJSON reads a JSON file containing an array of objects and returns a DataFrame.
 // The JSON must be in the format: [{"col1": val1, "col2": val2}, ...]
-// Column types are inferred automatically.
+// Column types are inferred automatically. Gzip-compressed files are decompressed transparently.
 func LoadJSON(path string) (*dataframe.DataFrame, error) {
-	data, err := os.ReadFile(path)
+	data, err := readFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	return parseJSON(data)
+}
+
+// parseJSON parses an in-memory JSON array of objects.
+func parseJSON(data []byte) (*dataframe.DataFrame, error) {
 	if len(data) == 0 {
 		return nil, ErrEmptyJSON
 	}
@@ -1,9 +1,12 @@
 package loader
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
 func TestLoadJSON_Simple(t *testing.T) {
@@ -183,3 +186,69 @@ func TestLoadParquet_EmptyFile(t *testing.T) {
 		t.Error("expected error for empty parquet file")
 	}
 }
+
+func TestLoadJSONLines_MissingField(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonlFile := filepath.Join(tmpDir, "events.jsonl")
+
+	jsonlData := `{"id": 1, "name": "Alice", "score": 95.5}
+{"id": 2, "name": "Bob", "score": 87}
+
+{"id": 3, "name": "Charlie"}
+`
+	if err := os.WriteFile(jsonlFile, []byte(jsonlData), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	df, err := LoadJSONLines(jsonlFile)
+	if err != nil {
+		t.Fatalf("LoadJSONLines failed: %v", err)
+	}
+
+	if df.NRows() != 3 {
+		t.Errorf("expected 3 rows, got %d", df.NRows())
+	}
+
+	names := df.Names()
+	if len(names) != 3 || names[0] != "id" || names[1] != "name" || names[2] != "score" {
+		t.Fatalf("expected [id name score], got %v", names)
+	}
+
+	score := df.Series[2]
+	if _, ok := score.(*dataframe.SeriesFloat64); !ok {
+		t.Errorf("expected score to be SeriesFloat64, got %T", score)
+	}
+	if score.Value(2) != nil {
+		t.Errorf("expected nil score in third row, got %v", score.Value(2))
+	}
+	if _, ok := df.Series[0].(*dataframe.SeriesInt64); !ok {
+		t.Errorf("expected id to be SeriesInt64, got %T", df.Series[0])
+	}
+}
+
+func TestLoadJSONLines_InvalidLine(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonlFile := filepath.Join(tmpDir, "bad.jsonl")
+
+	if err := os.WriteFile(jsonlFile, []byte("{\"id\": 1}\n{not json}\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	_, err := LoadJSONLines(jsonlFile)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestLoadJSONLines_Empty(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonlFile := filepath.Join(tmpDir, "empty.jsonl")
+
+	if err := os.WriteFile(jsonlFile, []byte("\n\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := LoadJSONLines(jsonlFile); err != ErrEmptyJSON {
+		t.Errorf("expected ErrEmptyJSON, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// LoadJSONLines reads a newline-delimited JSON file (one object per line) and
+// returns a DataFrame. Columns are the union of keys across all objects in
+// first-seen order; rows missing a key hold nil for that column.
+// Column types are inferred: integers, floats, bools, or strings. Columns with
+// mixed value types fall back to strings.
+func LoadJSONLines(path string) (*dataframe.DataFrame, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONLines(data)
+}
+
+// parseJSONLines parses in-memory newline-delimited JSON.
+func parseJSONLines(data []byte) (*dataframe.DataFrame, error) {
+	var (
+		names []string
+		index = make(map[string]int)
+		rows  []map[string]any
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidJSON, lineNo, err)
+		}
+
+		for _, key := range orderedKeys(line, obj) {
+			if _, ok := index[key]; !ok {
+				index[key] = len(names)
+				names = append(names, key)
+			}
+		}
+		rows = append(rows, obj)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 || len(names) == 0 {
+		return nil, ErrEmptyJSON
+	}
+
+	series := make([]dataframe.Series, len(names))
+	for i, name := range names {
+		vals := make([]any, len(rows))
+		for r, row := range rows {
+			vals[r] = row[name]
+		}
+		series[i] = buildJSONSeries(name, vals)
+	}
+
+	return dataframe.NewDataFrame(series...), nil
+}
+
+// orderedKeys returns the keys of a decoded object in the order they appear
+// in the source line, so column order follows the file rather than map order.
+func orderedKeys(line []byte, obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if _, err := dec.Token(); err != nil { // opening brace
+		return keys
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			break
+		}
+	}
+	return keys
+}
+
+// buildJSONSeries creates a typed series from decoded JSON values.
+func buildJSONSeries(name string, vals []any) dataframe.Series {
+	isInt, isFloat, isBool, isString := true, true, true, true
+	for _, v := range vals {
+		switch x := v.(type) {
+		case nil:
+		case json.Number:
+			if _, err := x.Int64(); err != nil {
+				isInt = false
+			}
+			isBool, isString = false, false
+		case bool:
+			isInt, isFloat, isString = false, false, false
+		case string:
+			isInt, isFloat, isBool = false, false, false
+		default:
+			isInt, isFloat, isBool, isString = false, false, false, false
+		}
+	}
+
+	out := make([]any, len(vals))
+	switch {
+	case isInt:
+		for i, v := range vals {
+			if n, ok := v.(json.Number); ok {
+				out[i], _ = n.Int64()
+			}
+		}
+		return dataframe.NewSeriesInt64(name, nil, out...)
+	case isFloat:
+		for i, v := range vals {
+			if n, ok := v.(json.Number); ok {
+				out[i], _ = n.Float64()
+			}
+		}
+		return dataframe.NewSeriesFloat64(name, nil, out...)
+	case isBool:
+		return dataframe.NewSeriesGeneric(name, false, nil, vals...)
+	case isString:
+		return dataframe.NewSeriesString(name, nil, vals...)
+	default:
+		for i, v := range vals {
+			switch x := v.(type) {
+			case nil:
+			case string:
+				out[i] = x
+			case json.Number:
+				out[i] = x.String()
+			default:
+				b, _ := json.Marshal(x)
+				out[i] = string(b)
+			}
+		}
+		return dataframe.NewSeriesString(name, nil, out...)
+	}
+}
@@ -0,0 +1,185 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// In-memory construction errors
+var (
+	ErrNoColumns        = errors.New("no columns provided")
+	ErrRaggedColumns    = errors.New("columns have different lengths")
+	ErrMixedTypes       = errors.New("column has mixed value types")
+	ErrUnknownColumn    = errors.New("column order references unknown column")
+	ErrUnsupportedValue = errors.New("unsupported value type")
+)
+
+// FromColumns builds a DataFrame from Go slices keyed by column name.
+// Each column's type is inferred from its values: integers become int64,
+// floats (or floats mixed with integers) become float64, strings and bools
+// keep their type. nil entries become nil values.
+//
+// order lists the column names in output order; when omitted, columns are
+// sorted by name. All columns must have the same length.
+func FromColumns(cols map[string][]any, order ...string) (*dataframe.DataFrame, error) {
+	names, err := columnOrder(cols, order)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]dataframe.Series, len(names))
+	for i, name := range names {
+		s, err := seriesFromValues(name, cols[name])
+		if err != nil {
+			return nil, err
+		}
+		series[i] = s
+	}
+
+	return dataframe.NewDataFrame(series...), nil
+}
+
+// FromInts builds a DataFrame of int64 columns. Columns are sorted by name.
+func FromInts(cols map[string][]int64) (*dataframe.DataFrame, error) {
+	return fromTyped(cols, func(name string, vals []int64) dataframe.Series {
+		s := dataframe.NewSeriesInt64(name, &dataframe.SeriesInit{Capacity: len(vals)})
+		for _, v := range vals {
+			s.Append(v)
+		}
+		return s
+	})
+}
+
+// FromFloats builds a DataFrame of float64 columns. Columns are sorted by name.
+func FromFloats(cols map[string][]float64) (*dataframe.DataFrame, error) {
+	return fromTyped(cols, func(name string, vals []float64) dataframe.Series {
+		s := dataframe.NewSeriesFloat64(name, &dataframe.SeriesInit{Capacity: len(vals)})
+		for _, v := range vals {
+			s.Append(v)
+		}
+		return s
+	})
+}
+
+// FromStrings builds a DataFrame of string columns. Columns are sorted by name.
+func FromStrings(cols map[string][]string) (*dataframe.DataFrame, error) {
+	return fromTyped(cols, func(name string, vals []string) dataframe.Series {
+		s := dataframe.NewSeriesString(name, &dataframe.SeriesInit{Capacity: len(vals)})
+		for _, v := range vals {
+			s.Append(v)
+		}
+		return s
+	})
+}
+
+func fromTyped[T any](cols map[string][]T, build func(string, []T) dataframe.Series) (*dataframe.DataFrame, error) {
+	names, err := columnOrder(cols, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]dataframe.Series, len(names))
+	for i, name := range names {
+		series[i] = build(name, cols[name])
+	}
+
+	return dataframe.NewDataFrame(series...), nil
+}
+
+// columnOrder validates column lengths and returns the output column order.
+func columnOrder[T any](cols map[string][]T, order []string) ([]string, error) {
+	if len(cols) == 0 {
+		return nil, ErrNoColumns
+	}
+
+	names := order
+	if len(names) == 0 {
+		names = make([]string, 0, len(cols))
+		for name := range cols {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	} else if len(names) != len(cols) {
+		return nil, fmt.Errorf("%w: order has %d names for %d columns", ErrUnknownColumn, len(names), len(cols))
+	}
+
+	length := -1
+	for _, name := range names {
+		vals, ok := cols[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+		}
+		if length == -1 {
+			length = len(vals)
+		} else if len(vals) != length {
+			return nil, fmt.Errorf("%w: %s has %d rows, expected %d", ErrRaggedColumns, name, len(vals), length)
+		}
+	}
+
+	return names, nil
+}
+
+// seriesFromValues creates a typed series from untyped Go values.
+func seriesFromValues(name string, vals []any) (dataframe.Series, error) {
+	const (
+		kindNone = iota
+		kindInt
+		kindFloat
+		kindString
+		kindBool
+	)
+
+	kind := kindNone
+	out := make([]any, len(vals))
+	for i, v := range vals {
+		var k int
+		switch x := v.(type) {
+		case nil:
+			continue
+		case int:
+			k, out[i] = kindInt, int64(x)
+		case int32:
+			k, out[i] = kindInt, int64(x)
+		case int64:
+			k, out[i] = kindInt, x
+		case float32:
+			k, out[i] = kindFloat, float64(x)
+		case float64:
+			k, out[i] = kindFloat, x
+		case string:
+			k, out[i] = kindString, x
+		case bool:
+			k, out[i] = kindBool, x
+		default:
+			return nil, fmt.Errorf("%w: %T in column %s", ErrUnsupportedValue, v, name)
+		}
+
+		switch {
+		case kind == kindNone || kind == k:
+			kind = k
+		case (kind == kindInt && k == kindFloat) || (kind == kindFloat && k == kindInt):
+			kind = kindFloat
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrMixedTypes, name)
+		}
+	}
+
+	switch kind {
+	case kindFloat:
+		for i, v := range out {
+			if n, ok := v.(int64); ok {
+				out[i] = float64(n)
+			}
+		}
+		return dataframe.NewSeriesFloat64(name, nil, out...), nil
+	case kindString:
+		return dataframe.NewSeriesString(name, nil, out...), nil
+	case kindBool:
+		return dataframe.NewSeriesGeneric(name, false, nil, out...), nil
+	default:
+		return dataframe.NewSeriesInt64(name, nil, out...), nil
+	}
+}
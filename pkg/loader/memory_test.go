@@ -0,0 +1,113 @@
+package loader
+
+import (
+	"errors"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func TestFromColumns(t *testing.T) {
+	df, err := FromColumns(map[string][]any{
+		"id":     {1, 2, 3},
+		"price":  {1.5, 2, nil},
+		"name":   {"a", "b", "c"},
+		"active": {true, false, true},
+	}, "id", "name", "price", "active")
+	if err != nil {
+		t.Fatalf("FromColumns failed: %v", err)
+	}
+
+	if df.NRows() != 3 {
+		t.Errorf("expected 3 rows, got %d", df.NRows())
+	}
+
+	names := df.Names()
+	if names[0] != "id" || names[1] != "name" || names[2] != "price" || names[3] != "active" {
+		t.Errorf("expected [id name price active], got %v", names)
+	}
+
+	if _, ok := df.Series[0].(*dataframe.SeriesInt64); !ok {
+		t.Errorf("expected id to be SeriesInt64, got %T", df.Series[0])
+	}
+	if _, ok := df.Series[1].(*dataframe.SeriesString); !ok {
+		t.Errorf("expected name to be SeriesString, got %T", df.Series[1])
+	}
+	price, ok := df.Series[2].(*dataframe.SeriesFloat64)
+	if !ok {
+		t.Fatalf("expected price to be SeriesFloat64, got %T", df.Series[2])
+	}
+	if price.Value(1) != 2.0 || price.Value(2) != nil {
+		t.Errorf("unexpected price values: %v, %v", price.Value(1), price.Value(2))
+	}
+	if v := df.Series[3].Value(1); v != false {
+		t.Errorf("expected active[1] = false, got %v", v)
+	}
+}
+
+func TestFromColumns_SortedByDefault(t *testing.T) {
+	df, err := FromColumns(map[string][]any{"b": {1}, "a": {2}})
+	if err != nil {
+		t.Fatalf("FromColumns failed: %v", err)
+	}
+	if names := df.Names(); names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected [a b], got %v", names)
+	}
+}
+
+func TestFromColumns_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		cols  map[string][]any
+		order []string
+		want  error
+	}{
+		{"empty", map[string][]any{}, nil, ErrNoColumns},
+		{"ragged", map[string][]any{"a": {1, 2}, "b": {1}}, nil, ErrRaggedColumns},
+		{"mixed", map[string][]any{"a": {1, "x"}}, nil, ErrMixedTypes},
+		{"unsupported", map[string][]any{"a": {struct{}{}}}, nil, ErrUnsupportedValue},
+		{"unknown order", map[string][]any{"a": {1}}, []string{"b"}, ErrUnknownColumn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromColumns(tt.cols, tt.order...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFromTypedConstructors(t *testing.T) {
+	ints, err := FromInts(map[string][]int64{"x": {1, 2, 3}, "y": {4, 5, 6}})
+	if err != nil {
+		t.Fatalf("FromInts failed: %v", err)
+	}
+	if ints.NRows() != 3 || len(ints.Series) != 2 {
+		t.Errorf("expected 3x2 frame, got %dx%d", ints.NRows(), len(ints.Series))
+	}
+	if _, ok := ints.Series[0].(*dataframe.SeriesInt64); !ok {
+		t.Errorf("expected SeriesInt64, got %T", ints.Series[0])
+	}
+
+	floats, err := FromFloats(map[string][]float64{"v": {1.5, 2.5}})
+	if err != nil {
+		t.Fatalf("FromFloats failed: %v", err)
+	}
+	if _, ok := floats.Series[0].(*dataframe.SeriesFloat64); !ok {
+		t.Errorf("expected SeriesFloat64, got %T", floats.Series[0])
+	}
+
+	strs, err := FromStrings(map[string][]string{"s": {"a"}})
+	if err != nil {
+		t.Fatalf("FromStrings failed: %v", err)
+	}
+	if strs.Series[0].Value(0) != "a" {
+		t.Errorf("expected \"a\", got %v", strs.Series[0].Value(0))
+	}
+
+	if _, err := FromInts(map[string][]int64{"x": {1}, "y": {1, 2}}); !errors.Is(err, ErrRaggedColumns) {
+		t.Errorf("expected ErrRaggedColumns, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package loader
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// StreamCSV reads a CSV file with a header row and calls fn with successive
+// frames of at most rowBatch rows, so files larger than memory can be
+// aggregated incrementally. Column types (int64, float64 or string) are
+// inferred from the first batch. An int64 column is widened to float64 from
+// the first later batch holding a non-integer number, so earlier batches keep
+// int64 and every later one is float64; any other later cell that does not
+// fit its column's type is an error. Empty values become nil.
+// Gzip-compressed files are decompressed transparently.
+//
+// Streaming stops at the first error returned by fn, which StreamCSV returns.
+func StreamCSV(path string, rowBatch int, fn func(*dataframe.DataFrame) error) error {
+	if rowBatch <= 0 {
+		return fmt.Errorf("row batch must be positive, got %d", rowBatch)
+	}
+
+	f, err := openFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return ErrEmptyFile
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	var types []DataType
+	batch := make([][]string, 0, rowBatch)
+	flush := func() error {
+		if types == nil {
+			types = inferTypes(len(header), batch)
+		} else {
+			widenTypes(types, batch)
+		}
+		df, err := buildBatch(header, types, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return fn(df)
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+		}
+		batch = append(batch, record)
+		if len(batch) == rowBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// inferTypes picks the narrowest type that parses every non-empty cell of
+// each column: int64, then float64, then string.
+func inferTypes(n int, rows [][]string) []DataType {
+	types := make([]DataType, n)
+	for col := range types {
+		typ := TypeInt64
+		for _, row := range rows {
+			cell := row[col]
+			if cell == "" {
+				continue
+			}
+			if typ == TypeInt64 {
+				if _, err := strconv.ParseInt(cell, 10, 64); err == nil {
+					continue
+				}
+				typ = TypeFloat64
+			}
+			if _, err := strconv.ParseFloat(cell, 64); err != nil {
+				typ = TypeString
+				break
+			}
+		}
+		types[col] = typ
+	}
+	return types
+}
+
+// widenTypes changes each int64 column of types to float64 when rows hold
+// a cell that is not an int64. A cell that is not a number either still
+// fails in buildBatch.
+func widenTypes(types []DataType, rows [][]string) {
+	for col, typ := range inferTypes(len(types), rows) {
+		if types[col] == TypeInt64 && typ != TypeInt64 {
+			types[col] = TypeFloat64
+		}
+	}
+}
+
+// buildBatch converts rows of cells into a frame with the given column types.
+func buildBatch(header []string, types []DataType, rows [][]string) (*dataframe.DataFrame, error) {
+	series := make([]dataframe.Series, len(header))
+	for col, name := range header {
+		vals := make([]interface{}, len(rows))
+		for i, row := range rows {
+			cell := row[col]
+			if cell == "" {
+				continue
+			}
+			switch types[col] {
+			case TypeInt64:
+				v, err := strconv.ParseInt(cell, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: column %s: %q is not an int64", ErrInvalidFormat, name, cell)
+				}
+				vals[i] = v
+			case TypeFloat64:
+				v, err := strconv.ParseFloat(cell, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: column %s: %q is not a float64", ErrInvalidFormat, name, cell)
+				}
+				vals[i] = v
+			default:
+				vals[i] = cell
+			}
+		}
+
+		switch types[col] {
+		case TypeInt64:
+			series[col] = dataframe.NewSeriesInt64(name, nil, vals...)
+		case TypeFloat64:
+			series[col] = dataframe.NewSeriesFloat64(name, nil, vals...)
+		default:
+			series[col] = dataframe.NewSeriesString(name, nil, vals...)
+		}
+	}
+	return dataframe.NewDataFrame(series...), nil
+}
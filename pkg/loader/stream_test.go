@@ -0,0 +1,163 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func writeRowsCSV(t *testing.T, n int) string {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("id,value,name\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%d,%d.5,row%d\n", i, i, i)
+	}
+	path := filepath.Join(t.TempDir(), "rows.csv")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	return path
+}
+
+func TestStreamCSV_Batches(t *testing.T) {
+	path := writeRowsCSV(t, 1000)
+
+	batches, rows := 0, 0
+	var idSum int64
+	err := StreamCSV(path, 100, func(df *dataframe.DataFrame) error {
+		batches++
+		rows += df.NRows()
+		for i := 0; i < df.NRows(); i++ {
+			idSum += df.Series[0].Value(i).(int64)
+		}
+		if _, ok := df.Series[1].(*dataframe.SeriesFloat64); !ok {
+			t.Errorf("expected value column to be float64, got %T", df.Series[1])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCSV failed: %v", err)
+	}
+
+	if batches != 10 {
+		t.Errorf("expected 10 batches, got %d", batches)
+	}
+	if rows != 1000 {
+		t.Errorf("expected 1000 rows, got %d", rows)
+	}
+	if idSum != 999*1000/2 {
+		t.Errorf("expected id sum %d, got %d", 999*1000/2, idSum)
+	}
+}
+
+func TestStreamCSV_PartialLastBatch(t *testing.T) {
+	path := writeRowsCSV(t, 250)
+
+	var sizes []int
+	err := StreamCSV(path, 100, func(df *dataframe.DataFrame) error {
+		sizes = append(sizes, df.NRows())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCSV failed: %v", err)
+	}
+	if fmt.Sprint(sizes) != "[100 100 50]" {
+		t.Errorf("expected batch sizes [100 100 50], got %v", sizes)
+	}
+}
+
+func TestStreamCSV_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rows.csv.gz")
+	writeGzip(t, path, []byte("id,name\n1,a\n2,\n3,c\n"))
+
+	var got []any
+	err := StreamCSV(path, 2, func(df *dataframe.DataFrame) error {
+		for i := 0; i < df.NRows(); i++ {
+			got = append(got, df.Series[1].Value(i))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCSV failed: %v", err)
+	}
+	if fmt.Sprint(got) != "[a <nil> c]" {
+		t.Errorf("expected [a <nil> c], got %v", got)
+	}
+}
+
+func TestStreamCSV_WidensIntToFloat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "widen.csv")
+	if err := os.WriteFile(path, []byte("id,qty\n1,1\n2,2\n3,2.5\n4,\n5,7\n"), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	var types []string
+	var sum float64
+	err := StreamCSV(path, 2, func(df *dataframe.DataFrame) error {
+		types = append(types, fmt.Sprintf("%T", df.Series[1]))
+		if _, ok := df.Series[0].(*dataframe.SeriesInt64); !ok {
+			t.Errorf("expected id column to stay int64, got %T", df.Series[0])
+		}
+		for i := 0; i < df.NRows(); i++ {
+			switch v := df.Series[1].Value(i).(type) {
+			case int64:
+				sum += float64(v)
+			case float64:
+				sum += v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCSV failed: %v", err)
+	}
+
+	want := []string{"*dataframe.SeriesInt64", "*dataframe.SeriesFloat64", "*dataframe.SeriesFloat64"}
+	if strings.Join(types, " ") != strings.Join(want, " ") {
+		t.Errorf("expected qty types %v, got %v", want, types)
+	}
+	if sum != 12.5 {
+		t.Errorf("expected qty sum 12.5, got %v", sum)
+	}
+}
+
+func TestStreamCSV_Errors(t *testing.T) {
+	path := writeRowsCSV(t, 10)
+	stop := errors.New("stop")
+
+	calls := 0
+	err := StreamCSV(path, 3, func(*dataframe.DataFrame) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected callback error after 1 call, got %v after %d", err, calls)
+	}
+
+	if err := StreamCSV(path, 0, func(*dataframe.DataFrame) error { return nil }); err == nil {
+		t.Error("expected error for non-positive batch size")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	if err := StreamCSV(empty, 10, func(*dataframe.DataFrame) error { return nil }); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("expected ErrEmptyFile, got %v", err)
+	}
+
+	// Only int64 widens; a later non-numeric id still fails
+	mixed := filepath.Join(t.TempDir(), "mixed.csv")
+	if err := os.WriteFile(mixed, []byte("id\n1\n2\nx\n"), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+	if err := StreamCSV(mixed, 2, func(*dataframe.DataFrame) error { return nil }); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
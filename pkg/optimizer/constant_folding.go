@@ -1,6 +1,8 @@
 package optimizer
 
 import (
+	"math"
+
 	"github.com/akhildatla/dasm/pkg/vm"
 )
 
@@ -20,10 +22,7 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 	fregConstants := make(map[uint8]float64) // F registers
 
 	newCode := make([]vm.Instruction, 0, len(program.Code))
-	newConstants := make([]any, len(program.Constants))
-	copy(newConstants, program.Constants)
-	newFloatConstants := make([]float64, len(program.FloatConstants))
-	copy(newFloatConstants, program.FloatConstants)
+	pool := newConstantPool(program)
 
 	for _, inst := range program.Code {
 		op := inst.Opcode()
@@ -56,8 +55,7 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 			if ok1 && ok2 {
 				// Both operands are constants, fold them
 				result := val1 + val2
-				constIdx := uint16(len(newConstants))
-				newConstants = append(newConstants, result)
+				constIdx := pool.add(result)
 				newInst := vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx)
 				newCode = append(newCode, newInst)
 				regConstants[dst] = result
@@ -74,8 +72,7 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 
 			if ok1 && ok2 {
 				result := val1 - val2
-				constIdx := uint16(len(newConstants))
-				newConstants = append(newConstants, result)
+				constIdx := pool.add(result)
 				newInst := vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx)
 				newCode = append(newCode, newInst)
 				regConstants[dst] = result
@@ -91,8 +88,7 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 
 			if ok1 && ok2 {
 				result := val1 * val2
-				constIdx := uint16(len(newConstants))
-				newConstants = append(newConstants, result)
+				constIdx := pool.add(result)
 				newInst := vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx)
 				newCode = append(newCode, newInst)
 				regConstants[dst] = result
@@ -108,8 +104,7 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 
 			if ok1 && ok2 && val2 != 0 {
 				result := val1 / val2
-				constIdx := uint16(len(newConstants))
-				newConstants = append(newConstants, result)
+				constIdx := pool.add(result)
 				newInst := vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx)
 				newCode = append(newCode, newInst)
 				regConstants[dst] = result
@@ -118,6 +113,28 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 				newCode = append(newCode, inst)
 			}
 
+		case vm.OpNegI:
+			if val, ok := regConstants[inst.Src1()]; ok {
+				result := -val
+				constIdx := pool.add(result)
+				newCode = append(newCode, vm.EncodeInstruction(vm.OpLoadConst, 0, dst, 0, 0, constIdx))
+				regConstants[dst] = result
+			} else {
+				delete(regConstants, dst)
+				newCode = append(newCode, inst)
+			}
+
+		case vm.OpNegF:
+			if val, ok := fregConstants[inst.Src1()]; ok {
+				result := -val
+				constIdx := pool.addFloat(result)
+				newCode = append(newCode, vm.EncodeInstruction(vm.OpLoadConstF, 0, dst, 0, 0, constIdx))
+				fregConstants[dst] = result
+			} else {
+				delete(fregConstants, dst)
+				newCode = append(newCode, inst)
+			}
+
 		case vm.OpMoveR:
 			src := inst.Src1()
 			if val, ok := regConstants[src]; ok {
@@ -147,7 +164,61 @@ func (o *Optimizer) constantFolding(program *vm.Program) *vm.Program {
 
 	return &vm.Program{
 		Code:           newCode,
-		Constants:      newConstants,
-		FloatConstants: newFloatConstants,
+		Constants:      pool.constants,
+		FloatConstants: pool.floats,
+	}
+}
+
+// constantPool extends a program's constant pools with folded values,
+// reusing the slot of an existing constant of the same type and value.
+type constantPool struct {
+	constants  []any
+	floats     []float64
+	index      map[int64]uint16  // int64 constants
+	floatIndex map[uint64]uint16 // float constants by math.Float64bits
+}
+
+func newConstantPool(program *vm.Program) *constantPool {
+	p := &constantPool{
+		constants:  append([]any(nil), program.Constants...),
+		floats:     append([]float64(nil), program.FloatConstants...),
+		index:      make(map[int64]uint16),
+		floatIndex: make(map[uint64]uint16),
+	}
+	for i, c := range p.constants {
+		if v, ok := c.(int64); ok {
+			if _, seen := p.index[v]; !seen {
+				p.index[v] = uint16(i)
+			}
+		}
+	}
+	for i, f := range p.floats {
+		if _, seen := p.floatIndex[math.Float64bits(f)]; !seen {
+			p.floatIndex[math.Float64bits(f)] = uint16(i)
+		}
+	}
+	return p
+}
+
+// add returns the index of the int64 constant v, appending it if needed.
+func (p *constantPool) add(v int64) uint16 {
+	if idx, ok := p.index[v]; ok {
+		return idx
+	}
+	idx := uint16(len(p.constants))
+	p.constants = append(p.constants, v)
+	p.index[v] = idx
+	return idx
+}
+
+// addFloat returns the index of the float constant f, appending it if needed.
+func (p *constantPool) addFloat(f float64) uint16 {
+	bits := math.Float64bits(f)
+	if idx, ok := p.floatIndex[bits]; ok {
+		return idx
 	}
+	idx := uint16(len(p.floats))
+	p.floats = append(p.floats, f)
+	p.floatIndex[bits] = idx
+	return idx
 }
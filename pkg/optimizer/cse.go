@@ -0,0 +1,229 @@
+package optimizer
+
+import (
+	"github.com/akhildatla/dasm/pkg/vm"
+)
+
+// WithCommonSubexpressionElimination enables common subexpression elimination.
+func WithCommonSubexpressionElimination() Option {
+	return func(o *Optimizer) {
+		o.enableCSE = true
+	}
+}
+
+// regFile identifies one of the VM's register files, or fileNone for an
+// operand field that holds part of a constant index instead.
+type regFile uint8
+
+const (
+	fileNone regFile = iota
+	fileR
+	fileF
+	fileV
+)
+
+// cseKey identifies a computation: the instruction with its destination
+// cleared, the WIDE prefix that extends its constant index, and the write
+// versions of its source registers at the time it ran.
+type cseKey struct {
+	inst     vm.Instruction
+	wide     uint16
+	versions [2]int
+}
+
+// cseValue records the register holding a computation's result and the
+// version of that register when it was written. The file is implied by the
+// opcode in the key.
+type cseValue struct {
+	reg     uint8
+	version int
+}
+
+// commonSubexpressionElimination removes recomputations of values that are
+// still held in a register. Programs have no jumps, so one forward scan
+// sees every path.
+//
+// For example:
+//
+//	SELECT_COL V0, R0, "price"
+//	REDUCE_SUM R1, V0
+//	SELECT_COL V0, R0, "price"  ; V0 already holds the column
+//	REDUCE_SUM R2, V0           ; same sum as R1
+//	ADD_R      R3, R1, R2
+//
+// Becomes:
+//
+//	SELECT_COL V0, R0, "price"
+//	REDUCE_SUM R1, V0
+//	MOVE_R     R2, R1
+//	ADD_R      R3, R1, R2
+//
+// A repeated computation is dropped when its destination already holds the
+// value, and becomes MOVE_R or MOVE_F when another scalar register does.
+// Vector results have no move instruction, so they are only dropped.
+// Any other instruction that writes a register, such as a load, join or
+// ADD_COL, forgets everything seen so far, since frame handles can be
+// aliased by MOVE_R.
+func (o *Optimizer) commonSubexpressionElimination(program *vm.Program) *vm.Program {
+	if len(program.Code) == 0 {
+		return program
+	}
+
+	// versions[file][n] counts writes to register n of that file; the
+	// fileNone row stays zero.
+	var versions [4][16]int
+	available := make(map[cseKey]cseValue)
+
+	code := make([]vm.Instruction, 0, len(program.Code))
+	for i := 0; i < len(program.Code); i++ {
+		inst := program.Code[i]
+		var prefix []vm.Instruction
+		var wide uint16
+		if inst.Opcode() == vm.OpWide && i+1 < len(program.Code) {
+			prefix = []vm.Instruction{inst}
+			wide = inst.Imm16()
+			i++
+			inst = program.Code[i]
+		}
+
+		op := inst.Opcode()
+		dst := inst.Dst()
+
+		if !writesRegister(op) {
+			code = append(code, prefix...)
+			code = append(code, inst)
+			continue
+		}
+
+		file, src1File, src2File, pure := pureOperands(op)
+		if !pure {
+			code = append(code, prefix...)
+			code = append(code, inst)
+			switch op {
+			case vm.OpMoveR:
+				versions[fileR][dst]++
+			case vm.OpMoveF:
+				versions[fileF][dst]++
+			default:
+				// Loads, joins, ADD_COL and the group ops create or modify
+				// frames and group results that any register may alias.
+				clear(available)
+			}
+			continue
+		}
+
+		src1, src2 := inst.Src1(), inst.Src2()
+		key := cseKey{
+			inst:     vm.EncodeInstruction(op, inst.Modifier(), 0, 0, 0, inst.Imm16()),
+			wide:     wide,
+			versions: [2]int{versions[src1File][src1], versions[src2File][src2]},
+		}
+
+		if prev, ok := available[key]; ok && versions[file][prev.reg] == prev.version {
+			if prev.reg == dst {
+				// The destination already holds this value.
+				continue
+			}
+			switch file {
+			case fileR:
+				code = append(code, vm.EncodeInstruction(vm.OpMoveR, 0, dst, prev.reg, 0, 0))
+				versions[fileR][dst]++
+				continue
+			case fileF:
+				code = append(code, vm.EncodeInstruction(vm.OpMoveF, 0, dst, prev.reg, 0, 0))
+				versions[fileF][dst]++
+				continue
+			}
+		}
+
+		code = append(code, prefix...)
+		code = append(code, inst)
+		versions[file][dst]++
+		available[key] = cseValue{reg: dst, version: versions[file][dst]}
+	}
+
+	if len(code) == len(program.Code) {
+		return program
+	}
+
+	return &vm.Program{
+		Code:           code,
+		Constants:      program.Constants,
+		FloatConstants: program.FloatConstants,
+	}
+}
+
+// writesRegister reports whether op stores a result in its Dst register.
+func writesRegister(op vm.Opcode) bool {
+	switch op {
+	case vm.OpNop, vm.OpAssertEq, vm.OpPrint, vm.OpSetResult, vm.OpWide,
+		vm.OpHalt, vm.OpHaltF, vm.OpHaltStr, vm.OpHaltFrame, vm.OpHaltV:
+		return false
+	}
+	return true
+}
+
+// pureOperands reports the register files op writes and reads through its
+// Dst, Src1 and Src2 fields, and whether op is a deterministic function of
+// those registers and its constants, so that running it again with the same
+// inputs yields the same value.
+func pureOperands(op vm.Opcode) (dst, src1, src2 regFile, ok bool) {
+	switch op {
+	case vm.OpLoadConst, vm.OpLoadConstStr:
+		return fileR, fileNone, fileNone, true
+	case vm.OpLoadConstF:
+		return fileF, fileNone, fileNone, true
+	case vm.OpRange:
+		return fileV, fileNone, fileNone, true
+
+	case vm.OpReduceSum, vm.OpReduceCount, vm.OpReduceMin, vm.OpReduceMax,
+		vm.OpReduceAny, vm.OpReduceAll, vm.OpReduceProd:
+		return fileR, fileV, fileNone, true
+	case vm.OpReduceSumF, vm.OpReduceMinF, vm.OpReduceMaxF, vm.OpReduceMean,
+		vm.OpReduceQuantileF, vm.OpReduceProdF:
+		return fileF, fileV, fileNone, true
+	case vm.OpCorrF, vm.OpCovF, vm.OpReduceWMeanF:
+		return fileF, fileV, fileV, true
+
+	case vm.OpAddR, vm.OpSubR, vm.OpMulR, vm.OpDivR:
+		return fileR, fileR, fileR, true
+	case vm.OpNegI, vm.OpColCount, vm.OpRowCount:
+		return fileR, fileR, fileNone, true
+	case vm.OpNegF:
+		return fileF, fileF, fileNone, true
+	case vm.OpVecIndex:
+		return fileR, fileV, fileR, true
+	case vm.OpVecIndexF:
+		return fileF, fileV, fileR, true
+
+	case vm.OpSelectCol, vm.OpRowIndex:
+		return fileV, fileR, fileNone, true
+	case vm.OpBroadcast:
+		return fileV, fileR, fileV, true
+	case vm.OpBroadcastF:
+		return fileV, fileF, fileV, true
+
+	case vm.OpVecAddI, vm.OpVecSubI, vm.OpVecMulI, vm.OpVecDivI, vm.OpVecModI,
+		vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF,
+		vm.OpVecMinI, vm.OpVecMaxI, vm.OpVecMinF, vm.OpVecMaxF,
+		vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE, vm.OpCmpEqEpsF,
+		vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect, vm.OpFilter, vm.OpTake,
+		vm.OpStrConcat, vm.OpCoalesce, vm.OpDateAddDays, vm.OpDateDiffDays:
+		return fileV, fileV, fileV, true
+
+	// FILL reads V[src1] only with FillLikeVector; treating the field as a
+	// register otherwise is merely conservative.
+	case vm.OpFillI, vm.OpFillF, vm.OpFillStr,
+		vm.OpClampF, vm.OpVecNegF, vm.OpInSet, vm.OpNot,
+		vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTitle, vm.OpStrCapitalize,
+		vm.OpStrTrim, vm.OpStrTrimLeft, vm.OpStrTrimRight, vm.OpStrTrimChars,
+		vm.OpStrContains, vm.OpStrContainsCI, vm.OpStrStartsWith, vm.OpStrEndsWith,
+		vm.OpStrSplit, vm.OpStrReplace, vm.OpStrRegexMatch, vm.OpStrRegexExtract,
+		vm.OpStrSubstr, vm.OpStrPadLeft, vm.OpStrPadRight, vm.OpFormatF,
+		vm.OpShift, vm.OpBinF, vm.OpRankF,
+		vm.OpFillNa, vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF,
+		vm.OpHashStr, vm.OpParseDate, vm.OpDatePart:
+		return fileV, fileV, fileNone, true
+	}
+	return fileNone, fileNone, fileNone, false
+}
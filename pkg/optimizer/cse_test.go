@@ -0,0 +1,150 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/akhildatla/dasm/pkg/vm"
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// repeatedSumProgram sums the "price" column twice, reselecting it into the
+// same vector register, and returns the total of both sums.
+func repeatedSumProgram() *vm.Program {
+	return &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0), // R0 = data
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 1), // V0 = R0.price
+			vm.EncodeInstruction(vm.OpReduceSum, 0, 1, 0, 0, 0), // R1 = sum(V0)
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 1), // V0 = R0.price (repeat)
+			vm.EncodeInstruction(vm.OpReduceSum, 0, 2, 0, 0, 0), // R2 = sum(V0) (repeat)
+			vm.EncodeInstruction(vm.OpAddR, 0, 3, 1, 2, 0),      // R3 = R1 + R2
+			vm.EncodeInstruction(vm.OpHalt, 0, 3, 0, 0, 0),      // return R3
+		},
+		Constants: []any{"data", "price"},
+	}
+}
+
+func runWithPrices(t *testing.T, program *vm.Program) any {
+	t.Helper()
+	machine := vm.NewVM()
+	machine.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"data": dataframe.NewDataFrame(dataframe.NewSeriesInt64("price", nil, 1, 2, 3)),
+	})
+	if err := machine.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := machine.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return result
+}
+
+func TestCSE_ReusesRepeatedComputation(t *testing.T) {
+	program := repeatedSumProgram()
+	result := New(WithCommonSubexpressionElimination()).Optimize(program)
+
+	want := []vm.Opcode{vm.OpLoadFrame, vm.OpSelectCol, vm.OpReduceSum, vm.OpMoveR, vm.OpAddR, vm.OpHalt}
+	if len(result.Code) != len(want) {
+		t.Fatalf("expected %d instructions, got %d", len(want), len(result.Code))
+	}
+	for i, op := range want {
+		if result.Code[i].Opcode() != op {
+			t.Errorf("instruction %d: expected %v, got %v", i, op, result.Code[i].Opcode())
+		}
+	}
+	if move := result.Code[3]; move.Dst() != 2 || move.Src1() != 1 {
+		t.Errorf("expected MOVE_R R2, R1, got dst=%d src=%d", move.Dst(), move.Src1())
+	}
+
+	if got, want := runWithPrices(t, result), runWithPrices(t, program); got != want {
+		t.Errorf("optimized program returned %v, want %v", got, want)
+	}
+}
+
+func TestCSE_OnlyAtLevelTwo(t *testing.T) {
+	program := repeatedSumProgram()
+
+	if got := New(WithLevel(1)).Optimize(program); len(got.Code) != len(program.Code) {
+		t.Errorf("level 1: expected %d instructions, got %d", len(program.Code), len(got.Code))
+	}
+	if got := New(WithLevel(2)).Optimize(program); len(got.Code) >= len(program.Code) {
+		t.Errorf("level 2: expected fewer than %d instructions, got %d", len(program.Code), len(got.Code))
+	}
+}
+
+func TestCSE_SourceOverwritten(t *testing.T) {
+	// REDUCE_SUM reads V0 before and after V0 is recomputed, so the
+	// second sum is a different value.
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpReduceSum, 0, 1, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpVecAddI, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpReduceSum, 0, 2, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpAddR, 0, 3, 1, 2, 0),
+			vm.EncodeInstruction(vm.OpHalt, 0, 3, 0, 0, 0),
+		},
+		Constants: []any{"data", "price"},
+	}
+
+	result := New(WithCommonSubexpressionElimination()).Optimize(program)
+	if len(result.Code) != len(program.Code) {
+		t.Fatalf("expected %d instructions, got %d", len(program.Code), len(result.Code))
+	}
+	if got := runWithPrices(t, result); got != int64(18) {
+		t.Errorf("expected 18, got %v", got)
+	}
+}
+
+func TestCSE_FrameWriteForgetsValues(t *testing.T) {
+	// ADD_COL changes the frame in R0, so the repeated SELECT_COL must
+	// run again.
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpAddCol, 0, 0, 0, 0, 2),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", "copy"},
+	}
+
+	result := New(WithCommonSubexpressionElimination()).Optimize(program)
+	if len(result.Code) != len(program.Code) {
+		t.Errorf("expected %d instructions, got %d", len(program.Code), len(result.Code))
+	}
+}
+
+func TestCSE_WidePrefix(t *testing.T) {
+	wideSelect := func(idx uint16) []vm.Instruction {
+		return []vm.Instruction{
+			vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, idx>>8),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, idx&0xFF),
+		}
+	}
+	code := []vm.Instruction{vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0)}
+	code = append(code, wideSelect(300)...)
+	code = append(code, wideSelect(556)...) // same low byte, different column
+	code = append(code, wideSelect(556)...) // repeat, dropped with its prefix
+	code = append(code, vm.EncodeInstruction(vm.OpHaltV, 0, 0, 0, 0, 0))
+	constants := make([]any, 557)
+	constants[0], constants[300], constants[556] = "data", "qty", "price"
+
+	result := New(WithCommonSubexpressionElimination()).Optimize(&vm.Program{Code: code, Constants: constants})
+
+	want := []vm.Opcode{vm.OpLoadFrame, vm.OpWide, vm.OpSelectCol, vm.OpWide, vm.OpSelectCol, vm.OpHaltV}
+	if len(result.Code) != len(want) {
+		t.Fatalf("expected %d instructions, got %d", len(want), len(result.Code))
+	}
+	for i, op := range want {
+		if result.Code[i].Opcode() != op {
+			t.Errorf("instruction %d: expected %v, got %v", i, op, result.Code[i].Opcode())
+		}
+	}
+	if err := vm.ValidateProgram(result); err != nil {
+		t.Errorf("optimized program is invalid: %v", err)
+	}
+}
@@ -26,7 +26,7 @@ func (o *Optimizer) deadCodeElimination(program *vm.Program) *vm.Program {
 	haltIdx := -1
 	for i := len(program.Code) - 1; i >= 0; i-- {
 		op := program.Code[i].Opcode()
-		if op == vm.OpHalt || op == vm.OpHaltF {
+		if op == vm.OpHalt || op == vm.OpHaltF || op == vm.OpHaltStr || op == vm.OpHaltFrame {
 			haltIdx = i
 			break
 		}
@@ -42,7 +42,7 @@ func (o *Optimizer) deadCodeElimination(program *vm.Program) *vm.Program {
 	haltOp := haltInst.Opcode()
 	haltDst := haltInst.Dst()
 
-	if haltOp == vm.OpHalt {
+	if haltOp == vm.OpHalt || haltOp == vm.OpHaltStr || haltOp == vm.OpHaltFrame {
 		usedRegs[haltDst] = true
 	} else {
 		usedFloats[haltDst] = true
@@ -70,37 +70,40 @@ func (o *Optimizer) deadCodeElimination(program *vm.Program) *vm.Program {
 
 			switch op {
 			// Instructions that write to R registers
-			case vm.OpLoadCSV, vm.OpLoadJSON, vm.OpLoadParquet, vm.OpLoadFrame, vm.OpLoadConst, vm.OpReduceSum,
-				vm.OpReduceCount, vm.OpReduceMin, vm.OpReduceMax,
-				vm.OpMoveR, vm.OpAddR, vm.OpSubR, vm.OpMulR, vm.OpDivR,
-				vm.OpNewFrame, vm.OpRowCount, vm.OpColCount, vm.OpGroupBy:
+			case vm.OpLoadCSV, vm.OpLoadCSVOpts, vm.OpLoadJSON, vm.OpLoadJSONL, vm.OpLoadHTTP, vm.OpLoadParquet, vm.OpLoadFrame, vm.OpLoadConst, vm.OpLoadConstStr, vm.OpReduceSum,
+				vm.OpReduceCount, vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceAny, vm.OpReduceAll, vm.OpReduceProd,
+				vm.OpMoveR, vm.OpAddR, vm.OpSubR, vm.OpMulR, vm.OpDivR, vm.OpVecIndex, vm.OpNegI,
+				vm.OpNewFrame, vm.OpRowCount, vm.OpColCount, vm.OpGroupBy, vm.OpGroupSizes, vm.OpPivot, vm.OpDropNa, vm.OpTopN, vm.OpSample, vm.OpShuffle:
 				if usedRegs[dst] {
 					isNeeded = true
 				}
 
 			// Instructions that write to F registers
 			case vm.OpLoadConstF, vm.OpReduceSumF, vm.OpReduceMinF, vm.OpReduceMaxF,
-				vm.OpReduceMean, vm.OpMoveF:
+				vm.OpReduceMean, vm.OpReduceQuantileF, vm.OpCorrF, vm.OpCovF, vm.OpReduceWMeanF, vm.OpReduceProdF, vm.OpMoveF, vm.OpVecIndexF, vm.OpNegF:
 				if usedFloats[dst] {
 					isNeeded = true
 				}
 
 			// Instructions that write to V registers
-			case vm.OpSelectCol, vm.OpBroadcast, vm.OpBroadcastF,
+			case vm.OpSelectCol, vm.OpBroadcast, vm.OpBroadcastF, vm.OpFillI, vm.OpFillF, vm.OpFillStr, vm.OpRange,
 				vm.OpVecAddI, vm.OpVecSubI, vm.OpVecMulI, vm.OpVecDivI, vm.OpVecModI,
 				vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF,
-				vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE,
-				vm.OpAnd, vm.OpOr, vm.OpNot, vm.OpFilter, vm.OpTake,
+				vm.OpVecMinI, vm.OpVecMaxI, vm.OpVecMinF, vm.OpVecMaxF, vm.OpVecNegF,
+				vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE, vm.OpCmpEqEpsF,
+				vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect, vm.OpNot, vm.OpFilter, vm.OpTake,
 				vm.OpGroupSum, vm.OpGroupSumF, vm.OpGroupMin, vm.OpGroupMax,
 				vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean, vm.OpGroupCount, vm.OpGroupKeys,
-				vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTrim, vm.OpStrConcat,
-				vm.OpStrContains, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace:
+				vm.OpGroupFirst, vm.OpGroupLast, vm.OpGroupMedianF, vm.OpGroupConcat, vm.OpGroupQuantileF,
+				vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTitle, vm.OpStrCapitalize, vm.OpStrTrim, vm.OpStrTrimLeft, vm.OpStrTrimRight, vm.OpStrTrimChars, vm.OpStrConcat,
+				vm.OpStrContains, vm.OpStrContainsCI, vm.OpStrRegexMatch, vm.OpStrRegexExtract, vm.OpStrSubstr, vm.OpStrPadLeft, vm.OpStrPadRight, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace, vm.OpFormatF,
+				vm.OpShift, vm.OpBinF, vm.OpRankF, vm.OpClampF, vm.OpFillNa, vm.OpCoalesce, vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF, vm.OpInSet, vm.OpRowIndex, vm.OpHashStr, vm.OpParseDate, vm.OpDatePart, vm.OpDateAddDays, vm.OpDateDiffDays:
 				if usedVecs[dst] {
 					isNeeded = true
 				}
 
 			// Instructions with side effects are always needed
-			case vm.OpAddCol, vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter:
+			case vm.OpAddCol, vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter, vm.OpJoinCross, vm.OpJoinInnerSorted, vm.OpConcat, vm.OpAssertEq, vm.OpPrint, vm.OpSetResult:
 				isNeeded = true
 
 			case vm.OpNop:
@@ -121,7 +124,7 @@ func (o *Optimizer) deadCodeElimination(program *vm.Program) *vm.Program {
 	// Also keep instructions that have side effects (like frame operations)
 	for i := 0; i <= haltIdx; i++ {
 		op := program.Code[i].Opcode()
-		if op == vm.OpLoadCSV || op == vm.OpLoadJSON || op == vm.OpLoadParquet || op == vm.OpLoadFrame {
+		if op == vm.OpLoadCSV || op == vm.OpLoadCSVOpts || op == vm.OpLoadJSON || op == vm.OpLoadJSONL || op == vm.OpLoadHTTP || op == vm.OpLoadParquet || op == vm.OpLoadFrame {
 			// Check if the frame is used
 			dst := program.Code[i].Dst()
 			if usedRegs[dst] {
@@ -131,6 +134,13 @@ func (o *Optimizer) deadCodeElimination(program *vm.Program) *vm.Program {
 		}
 	}
 
+	// A WIDE prefix is kept exactly when the instruction it extends is
+	for i := 0; i < haltIdx; i++ {
+		if program.Code[i].Opcode() == vm.OpWide {
+			needed[i] = needed[i+1]
+		}
+	}
+
 	// Count how many instructions we're keeping
 	keepCount := 0
 	for i := 0; i <= haltIdx; i++ {
@@ -169,22 +179,29 @@ func markSourcesUsed(inst vm.Instruction, usedRegs, usedVecs, usedFloats map[uin
 	// Vector binary ops: V[src1], V[src2]
 	case vm.OpVecAddI, vm.OpVecSubI, vm.OpVecMulI, vm.OpVecDivI, vm.OpVecModI,
 		vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF,
-		vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE,
-		vm.OpAnd, vm.OpOr, vm.OpFilter, vm.OpTake, vm.OpStrConcat:
+		vm.OpVecMinI, vm.OpVecMaxI, vm.OpVecMinF, vm.OpVecMaxF,
+		vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE, vm.OpCmpEqEpsF,
+		vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect, vm.OpFilter, vm.OpTake, vm.OpStrConcat, vm.OpCoalesce,
+		vm.OpCorrF, vm.OpCovF, vm.OpReduceWMeanF, vm.OpDateAddDays, vm.OpDateDiffDays:
 		usedVecs[src1] = true
 		usedVecs[src2] = true
 
 	// Vector unary ops: V[src1]
-	case vm.OpNot, vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTrim:
+	case vm.OpNot, vm.OpVecNegF, vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTitle, vm.OpStrCapitalize, vm.OpStrTrim, vm.OpStrTrimLeft, vm.OpStrTrimRight, vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF, vm.OpInSet, vm.OpHashStr, vm.OpParseDate, vm.OpDatePart:
 		usedVecs[src1] = true
 
 	// String pattern ops: V[src1]
-	case vm.OpStrContains, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace:
+	case vm.OpStrContains, vm.OpStrContainsCI, vm.OpStrRegexMatch, vm.OpStrRegexExtract, vm.OpStrSubstr, vm.OpStrPadLeft, vm.OpStrPadRight, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace, vm.OpFormatF, vm.OpStrTrimChars:
+		usedVecs[src1] = true
+
+	// Window ops: V[src1]
+	case vm.OpShift, vm.OpBinF, vm.OpRankF, vm.OpClampF, vm.OpFillNa:
 		usedVecs[src1] = true
 
 	// Reduce ops: V[src1]
 	case vm.OpReduceSum, vm.OpReduceSumF, vm.OpReduceCount,
-		vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceMinF, vm.OpReduceMaxF, vm.OpReduceMean:
+		vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceMinF, vm.OpReduceMaxF, vm.OpReduceMean,
+		vm.OpReduceAny, vm.OpReduceAll, vm.OpReduceQuantileF, vm.OpReduceProd, vm.OpReduceProdF:
 		usedVecs[src1] = true
 
 	// SelectCol: R[src1] (frame)
@@ -201,39 +218,87 @@ func markSourcesUsed(inst vm.Instruction, usedRegs, usedVecs, usedFloats map[uin
 		usedFloats[src1] = true
 		usedVecs[src2] = true
 
+	// Fill: V[src1] (length) when FillLikeVector is set
+	case vm.OpFillI, vm.OpFillF, vm.OpFillStr:
+		if inst.Modifier()&vm.FillLikeVector != 0 {
+			usedVecs[src1] = true
+		}
+
 	// Scalar ops: R[src1], R[src2]
 	case vm.OpAddR, vm.OpSubR, vm.OpMulR, vm.OpDivR:
 		usedRegs[src1] = true
 		usedRegs[src2] = true
 
 	// Scalar unary: R[src1] or F[src1]
-	case vm.OpMoveR, vm.OpRowCount, vm.OpColCount:
+	case vm.OpMoveR, vm.OpNegI, vm.OpRowCount, vm.OpColCount, vm.OpPivot, vm.OpDropNa, vm.OpRowIndex, vm.OpTopN, vm.OpSample, vm.OpShuffle:
 		usedRegs[src1] = true
 
-	case vm.OpMoveF:
+	case vm.OpMoveF, vm.OpNegF:
 		usedFloats[src1] = true
 
+	// Vector index: V[src1] (vector), R[src2] (index)
+	case vm.OpVecIndex, vm.OpVecIndexF:
+		usedVecs[src1] = true
+		usedRegs[src2] = true
+
 	// GroupBy: V[src1]
 	case vm.OpGroupBy:
 		usedVecs[src1] = true
 
 	// GroupAgg: R[src1] (gb), V[src2] (values)
 	case vm.OpGroupSum, vm.OpGroupSumF, vm.OpGroupMin, vm.OpGroupMax,
-		vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean:
+		vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean, vm.OpGroupFirst, vm.OpGroupLast,
+		vm.OpGroupMedianF, vm.OpGroupConcat, vm.OpGroupQuantileF:
 		usedRegs[src1] = true
 		usedVecs[src2] = true
 
 	// GroupUnary: R[src1]
-	case vm.OpGroupCount, vm.OpGroupKeys:
+	case vm.OpGroupCount, vm.OpGroupKeys, vm.OpGroupSizes:
 		usedRegs[src1] = true
 
 	// Join: R[src1], R[src2]
-	case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter:
+	case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter, vm.OpJoinCross, vm.OpJoinInnerSorted, vm.OpConcat:
 		usedRegs[src1] = true
 		usedRegs[src2] = true
 
 	// AddCol: R[dst] (frame), V[src1] (column)
 	case vm.OpAddCol:
 		usedVecs[src1] = true
+
+	// AssertEq: src1, src2 in the register file its imm8 selects
+	case vm.OpAssertEq:
+		switch inst.Imm8() {
+		case vm.RegFloat:
+			usedFloats[src1] = true
+			usedFloats[src2] = true
+		case vm.RegVector:
+			usedVecs[src1] = true
+			usedVecs[src2] = true
+		default:
+			usedRegs[src1] = true
+			usedRegs[src2] = true
+		}
+
+	// Print: src1 in the register file its imm8 selects
+	case vm.OpPrint:
+		switch inst.Imm8() {
+		case vm.RegFloat:
+			usedFloats[src1] = true
+		case vm.RegVector:
+			usedVecs[src1] = true
+		default:
+			usedRegs[src1] = true
+		}
+
+	// Set result: src1 in the register file its modifier selects
+	case vm.OpSetResult:
+		switch inst.Modifier() {
+		case vm.RegFloat:
+			usedFloats[src1] = true
+		case vm.RegVector:
+			usedVecs[src1] = true
+		default:
+			usedRegs[src1] = true
+		}
 	}
 }
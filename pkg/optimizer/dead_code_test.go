@@ -193,3 +193,36 @@ func TestDeadCodeElimination_MultipleDeadBranches(t *testing.T) {
 		t.Errorf("expected 2 instructions after removing 3 dead loads, got %d", len(result.Code))
 	}
 }
+
+func TestDeadCodeElimination_WidePrefix(t *testing.T) {
+	wideSelect := func(dst uint8, idx uint16) []vm.Instruction {
+		return []vm.Instruction{
+			vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, idx>>8),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, dst, 0, 0, idx&0xFF),
+		}
+	}
+	code := []vm.Instruction{vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0)}
+	code = append(code, wideSelect(0, 300)...) // dead
+	code = append(code, wideSelect(1, 301)...)
+	code = append(code,
+		vm.EncodeInstruction(vm.OpReduceSumF, 0, 0, 1, 0, 0),
+		vm.EncodeInstruction(vm.OpHaltF, 0, 0, 0, 0, 0),
+	)
+	constants := make([]any, 302)
+	constants[0], constants[300], constants[301] = "data", "qty", "price"
+
+	result := New(WithDeadCodeElimination()).Optimize(&vm.Program{Code: code, Constants: constants})
+
+	want := []vm.Opcode{vm.OpLoadFrame, vm.OpWide, vm.OpSelectCol, vm.OpReduceSumF, vm.OpHaltF}
+	if len(result.Code) != len(want) {
+		t.Fatalf("expected %d instructions, got %d", len(want), len(result.Code))
+	}
+	for i, op := range want {
+		if result.Code[i].Opcode() != op {
+			t.Errorf("instruction %d: expected %v, got %v", i, op, result.Code[i].Opcode())
+		}
+	}
+	if err := vm.ValidateProgram(result); err != nil {
+		t.Errorf("optimized program is invalid: %v", err)
+	}
+}
@@ -10,6 +10,8 @@ type Optimizer struct {
 	enablePredicatePushdown bool
 	enableProjectionPruning bool
 	enableDeadCode          bool
+	enableCSE               bool
+	enablePeephole          bool
 }
 
 // Option is a functional option for the Optimizer.
@@ -36,6 +38,23 @@ func WithProjectionPruning() Option {
 	}
 }
 
+// WithLevel enables the optimizations for a tier: 0 disables everything,
+// 1 enables the safe subset (constant folding and dead code elimination),
+// and 2 or higher enables all optimizations, adding predicate pushdown,
+// projection pruning, common subexpression elimination and peephole
+// optimization.
+func WithLevel(level int) Option {
+	return func(o *Optimizer) {
+		switch {
+		case level >= 2:
+			WithAllOptimizations()(o)
+		case level == 1:
+			o.enableConstantFolding = true
+			o.enableDeadCode = true
+		}
+	}
+}
+
 // WithAllOptimizations enables all optimizations.
 func WithAllOptimizations() Option {
 	return func(o *Optimizer) {
@@ -43,6 +62,8 @@ func WithAllOptimizations() Option {
 		o.enablePredicatePushdown = true
 		o.enableProjectionPruning = true
 		o.enableDeadCode = true
+		o.enableCSE = true
+		o.enablePeephole = true
 	}
 }
 
@@ -71,6 +92,14 @@ func (o *Optimizer) Optimize(program *vm.Program) *vm.Program {
 		result = o.projectionPruning(result)
 	}
 
+	if o.enableCSE {
+		result = o.commonSubexpressionElimination(result)
+	}
+
+	if o.enablePeephole {
+		result = o.peephole(result)
+	}
+
 	if o.enableDeadCode {
 		result = o.deadCodeElimination(result)
 	}
@@ -45,6 +45,31 @@ func TestConstantFolding_Addition(t *testing.T) {
 	}
 }
 
+func TestConstantFolding_ReusesExistingConstant(t *testing.T) {
+	// LOAD_CONST R0, 5
+	// LOAD_CONST R1, 10
+	// ADD_R R2, R0, R0  (folds to 10, already in the pool)
+	// HALT R2
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 1, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpAddR, 0, 2, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{int64(5), int64(10)},
+	}
+
+	result := New(WithConstantFolding()).Optimize(program)
+
+	if len(result.Constants) != 2 {
+		t.Fatalf("expected no new constants, got %v", result.Constants)
+	}
+	if result.Code[2].Opcode() != vm.OpLoadConst || result.Code[2].Imm16() != 1 {
+		t.Errorf("expected LOAD_CONST R2 from slot 1, got %v", result.Code[2])
+	}
+}
+
 func TestConstantFolding_Multiplication(t *testing.T) {
 	program := &vm.Program{
 		Code: []vm.Instruction{
@@ -164,6 +189,45 @@ func TestProjectionPruning_RemovesUnusedColumns(t *testing.T) {
 	}
 }
 
+func TestProjectionPruning_RemovesWidePrefix(t *testing.T) {
+	// LOAD_FRAME R0, "data"
+	// WIDE 1
+	// SELECT_COL V0, R0, constants[300]  ; unused
+	// WIDE 1
+	// SELECT_COL V1, R0, constants[301]
+	// REDUCE_SUM_F F0, V1
+	// HALT_F F0
+	constants := make([]any, 302)
+	constants[0], constants[300], constants[301] = "data", "qty", "price"
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadFrame, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 0, 0, 0, 300&0xFF),
+			vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, 1),
+			vm.EncodeInstruction(vm.OpSelectCol, 0, 1, 0, 0, 301&0xFF),
+			vm.EncodeInstruction(vm.OpReduceSumF, 0, 0, 1, 0, 0),
+			vm.EncodeInstruction(vm.OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants: constants,
+	}
+
+	result := New(WithProjectionPruning()).Optimize(program)
+
+	expectedOps := []vm.Opcode{vm.OpLoadFrame, vm.OpWide, vm.OpSelectCol, vm.OpReduceSumF, vm.OpHaltF}
+	if len(result.Code) != len(expectedOps) {
+		t.Fatalf("expected %d instructions after pruning, got %d", len(expectedOps), len(result.Code))
+	}
+	for i, expected := range expectedOps {
+		if result.Code[i].Opcode() != expected {
+			t.Errorf("instruction %d: expected %v, got %v", i, expected, result.Code[i].Opcode())
+		}
+	}
+	if result.Code[2].Dst() != 1 {
+		t.Errorf("expected the used SELECT_COL V1 to remain, got V%d", result.Code[2].Dst())
+	}
+}
+
 func TestProjectionPruning_KeepsUsedColumns(t *testing.T) {
 	// LOAD_CSV R0, "data.csv"
 	// SELECT_COL V0, R0, "price"
@@ -272,6 +336,27 @@ func TestOptimizer_NoOptimizations(t *testing.T) {
 	}
 }
 
+func TestOptimizer_WithLevel(t *testing.T) {
+	tests := []struct {
+		level                                           int
+		folding, pushdown, pruning, dead, cse, peephole bool
+	}{
+		{0, false, false, false, false, false, false},
+		{1, true, false, false, true, false, false},
+		{2, true, true, true, true, true, true},
+		{3, true, true, true, true, true, true},
+	}
+
+	for _, tt := range tests {
+		o := New(WithLevel(tt.level))
+		if o.enableConstantFolding != tt.folding || o.enablePredicatePushdown != tt.pushdown ||
+			o.enableProjectionPruning != tt.pruning || o.enableDeadCode != tt.dead || o.enableCSE != tt.cse ||
+			o.enablePeephole != tt.peephole {
+			t.Errorf("level %d: unexpected passes %+v", tt.level, *o)
+		}
+	}
+}
+
 func TestConstantFolding_Subtraction(t *testing.T) {
 	program := &vm.Program{
 		Code: []vm.Instruction{
@@ -0,0 +1,110 @@
+package optimizer
+
+import (
+	"github.com/akhildatla/dasm/pkg/vm"
+)
+
+// WithPeephole enables peephole optimization.
+func WithPeephole() Option {
+	return func(o *Optimizer) {
+		o.enablePeephole = true
+	}
+}
+
+// peepholeUnit is one instruction together with the WIDE prefix, if any,
+// that extends its constant index.
+type peepholeUnit struct {
+	code []vm.Instruction
+	inst vm.Instruction
+}
+
+// peephole rewrites short runs of adjacent instructions:
+//
+//	NOP                       ; dropped
+//	MOVE_R     R1, R1         ; self move, dropped
+//	MOVE_R     R2, R1
+//	MOVE_R     R1, R2         ; R1 already holds R2, dropped
+//	LOAD_CONST R3, 10         ; overwritten before it is read, dropped
+//	LOAD_CONST R3, 20
+//
+// A write is only dropped when it has no side effects and the instruction
+// right after it replaces the same register without reading it. Programs
+// have no jumps, so adjacent instructions always run one after the other.
+func (o *Optimizer) peephole(program *vm.Program) *vm.Program {
+	if len(program.Code) == 0 {
+		return program
+	}
+
+	kept := make([]peepholeUnit, 0, len(program.Code))
+	for i := 0; i < len(program.Code); i++ {
+		start := i
+		if program.Code[i].Opcode() == vm.OpWide && i+1 < len(program.Code) {
+			i++
+		}
+		unit := peepholeUnit{code: program.Code[start : i+1], inst: program.Code[i]}
+
+		op := unit.inst.Opcode()
+		if op == vm.OpNop {
+			continue
+		}
+		if (op == vm.OpMoveR || op == vm.OpMoveF) && unit.inst.Dst() == unit.inst.Src1() {
+			continue
+		}
+
+		if len(kept) > 0 {
+			prev := kept[len(kept)-1].inst
+			if (op == vm.OpMoveR || op == vm.OpMoveF) && prev.Opcode() == op &&
+				prev.Dst() == unit.inst.Src1() && prev.Src1() == unit.inst.Dst() {
+				// The move copies back the value it was copied from
+				continue
+			}
+		}
+
+		for len(kept) > 0 && overwrites(unit.inst, kept[len(kept)-1].inst) {
+			kept = kept[:len(kept)-1]
+		}
+		kept = append(kept, unit)
+	}
+
+	code := make([]vm.Instruction, 0, len(program.Code))
+	for _, unit := range kept {
+		code = append(code, unit.code...)
+	}
+	if len(code) == len(program.Code) {
+		return program
+	}
+
+	return &vm.Program{
+		Code:           code,
+		Constants:      program.Constants,
+		FloatConstants: program.FloatConstants,
+	}
+}
+
+// overwrites reports whether next replaces the register prev writes
+// without reading it first, and prev has no other effect, so prev can be
+// dropped.
+func overwrites(next, prev vm.Instruction) bool {
+	prevFile, _, _, ok := peepholeOperands(prev.Opcode())
+	if !ok {
+		return false
+	}
+	file, src1File, src2File, ok := peepholeOperands(next.Opcode())
+	if !ok || file != prevFile || next.Dst() != prev.Dst() {
+		return false
+	}
+	reg := next.Dst()
+	return !(src1File == file && next.Src1() == reg) && !(src2File == file && next.Src2() == reg)
+}
+
+// peepholeOperands is pureOperands extended with MOVE_R and MOVE_F, which
+// common subexpression elimination tracks separately.
+func peepholeOperands(op vm.Opcode) (dst, src1, src2 regFile, ok bool) {
+	switch op {
+	case vm.OpMoveR:
+		return fileR, fileR, fileNone, true
+	case vm.OpMoveF:
+		return fileF, fileF, fileNone, true
+	}
+	return pureOperands(op)
+}
@@ -0,0 +1,121 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/akhildatla/dasm/pkg/vm"
+)
+
+func runProgram(t *testing.T, program *vm.Program) any {
+	t.Helper()
+	machine := vm.NewVM()
+	if err := machine.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := machine.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return result
+}
+
+func TestPeephole_RemovesRedundantInstructions(t *testing.T) {
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0), // R0 = 10 (overwritten)
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 1), // R0 = 20
+			vm.EncodeInstruction(vm.OpNop, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpMoveR, 0, 1, 0, 0, 0), // R1 = R0
+			vm.EncodeInstruction(vm.OpMoveR, 0, 0, 1, 0, 0), // R0 = R1 (copies back)
+			vm.EncodeInstruction(vm.OpMoveR, 0, 1, 1, 0, 0), // R1 = R1 (self move)
+			vm.EncodeInstruction(vm.OpAddR, 0, 2, 0, 1, 0),  // R2 = R0 + R1
+			vm.EncodeInstruction(vm.OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{int64(10), int64(20)},
+	}
+
+	result := New(WithPeephole()).Optimize(program)
+
+	want := []vm.Opcode{vm.OpLoadConst, vm.OpMoveR, vm.OpAddR, vm.OpHalt}
+	if len(result.Code) != len(want) {
+		t.Fatalf("expected %d instructions, got %d", len(want), len(result.Code))
+	}
+	for i, op := range want {
+		if result.Code[i].Opcode() != op {
+			t.Errorf("instruction %d: expected %v, got %v", i, op, result.Code[i].Opcode())
+		}
+	}
+	if got := runProgram(t, result); got != int64(40) {
+		t.Errorf("expected 40, got %v", got)
+	}
+}
+
+func TestPeephole_OnlyAtLevelTwo(t *testing.T) {
+	// Dead code elimination keeps the self move, since R0 is returned.
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpMoveR, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(7)},
+	}
+
+	if got := New(WithLevel(1)).Optimize(program); len(got.Code) != 3 {
+		t.Errorf("level 1: expected 3 instructions, got %d", len(got.Code))
+	}
+	got := New(WithLevel(2)).Optimize(program)
+	if len(got.Code) != 2 {
+		t.Errorf("level 2: expected 2 instructions, got %d", len(got.Code))
+	}
+	if result := runProgram(t, got); result != int64(7) {
+		t.Errorf("expected 7, got %v", result)
+	}
+}
+
+func TestPeephole_KeepsWritesThatAreRead(t *testing.T) {
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpAddR, 0, 0, 0, 0, 0), // reads R0 before replacing it
+			vm.EncodeInstruction(vm.OpPrint, 0, 0, 0, 0, uint16(vm.RegInt)),
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0), // PRINT is not a write
+			vm.EncodeInstruction(vm.OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(3)},
+	}
+
+	result := New(WithPeephole()).Optimize(program)
+	if len(result.Code) != len(program.Code) {
+		t.Errorf("expected %d instructions, got %d", len(program.Code), len(result.Code))
+	}
+}
+
+func TestPeephole_WidePrefix(t *testing.T) {
+	constants := make([]any, 301)
+	constants[0], constants[300] = int64(1), int64(2)
+	program := &vm.Program{
+		Code: []vm.Instruction{
+			vm.EncodeInstruction(vm.OpWide, 0, 0, 0, 0, 300>>8),
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 300&0xFF), // overwritten, dropped with its prefix
+			vm.EncodeInstruction(vm.OpLoadConst, 0, 0, 0, 0, 0),
+			vm.EncodeInstruction(vm.OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: constants,
+	}
+
+	result := New(WithPeephole()).Optimize(program)
+
+	want := []vm.Opcode{vm.OpLoadConst, vm.OpHalt}
+	if len(result.Code) != len(want) {
+		t.Fatalf("expected %d instructions, got %d", len(want), len(result.Code))
+	}
+	for i, op := range want {
+		if result.Code[i].Opcode() != op {
+			t.Errorf("instruction %d: expected %v, got %v", i, op, result.Code[i].Opcode())
+		}
+	}
+	if err := vm.ValidateProgram(result); err != nil {
+		t.Errorf("optimized program is invalid: %v", err)
+	}
+}
@@ -46,12 +46,12 @@ func (o *Optimizer) predicatePushdown(program *vm.Program) *vm.Program {
 		dst := inst.Dst()
 
 		switch op {
-		case vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE:
+		case vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE, vm.OpCmpEqEpsF:
 			// Mark the result as a boolean mask
 			boolMasks[dst] = true
 			newCode = append(newCode, inst)
 
-		case vm.OpAnd, vm.OpOr:
+		case vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect:
 			// Combining masks still produces a mask
 			boolMasks[dst] = true
 			newCode = append(newCode, inst)
@@ -61,6 +61,11 @@ func (o *Optimizer) predicatePushdown(program *vm.Program) *vm.Program {
 			boolMasks[dst] = true
 			newCode = append(newCode, inst)
 
+		case vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF, vm.OpInSet, vm.OpStrRegexMatch:
+			// Null checks, set membership and regex matches always produce a mask
+			boolMasks[dst] = true
+			newCode = append(newCode, inst)
+
 		case vm.OpFilter:
 			// Check if we're filtering with a known mask
 			src2 := inst.Src2() // mask register
@@ -42,13 +42,15 @@ func (o *Optimizer) projectionPruning(program *vm.Program) *vm.Program {
 		// Vector operations use V registers as sources
 		case vm.OpVecAddI, vm.OpVecSubI, vm.OpVecMulI, vm.OpVecDivI, vm.OpVecModI,
 			vm.OpVecAddF, vm.OpVecSubF, vm.OpVecMulF, vm.OpVecDivF,
-			vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE,
-			vm.OpAnd, vm.OpOr, vm.OpStrConcat:
+			vm.OpVecMinI, vm.OpVecMaxI, vm.OpVecMinF, vm.OpVecMaxF,
+			vm.OpCmpEQ, vm.OpCmpNE, vm.OpCmpLT, vm.OpCmpLE, vm.OpCmpGT, vm.OpCmpGE, vm.OpCmpEqEpsF,
+			vm.OpAnd, vm.OpOr, vm.OpXor, vm.OpMaskedSelect, vm.OpStrConcat, vm.OpCoalesce, vm.OpCorrF, vm.OpCovF, vm.OpReduceWMeanF, vm.OpDateAddDays, vm.OpDateDiffDays:
 			usedVRegs[src1] = true
 			usedVRegs[src2] = true
 
-		case vm.OpNot, vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTrim,
-			vm.OpStrContains, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace:
+		case vm.OpNot, vm.OpVecNegF, vm.OpStrLen, vm.OpStrUpper, vm.OpStrLower, vm.OpStrTitle, vm.OpStrCapitalize, vm.OpStrTrim, vm.OpStrTrimLeft, vm.OpStrTrimRight, vm.OpStrTrimChars,
+			vm.OpStrContains, vm.OpStrContainsCI, vm.OpStrRegexMatch, vm.OpStrRegexExtract, vm.OpStrSubstr, vm.OpStrPadLeft, vm.OpStrPadRight, vm.OpStrStartsWith, vm.OpStrEndsWith, vm.OpStrSplit, vm.OpStrReplace, vm.OpFormatF,
+			vm.OpShift, vm.OpBinF, vm.OpRankF, vm.OpClampF, vm.OpFillNa, vm.OpIsNull, vm.OpIsNotNull, vm.OpIsNaNF, vm.OpIsInfF, vm.OpInSet, vm.OpHashStr, vm.OpParseDate, vm.OpDatePart:
 			usedVRegs[src1] = true
 
 		case vm.OpFilter:
@@ -57,18 +59,20 @@ func (o *Optimizer) projectionPruning(program *vm.Program) *vm.Program {
 
 		case vm.OpReduceSum, vm.OpReduceSumF, vm.OpReduceCount,
 			vm.OpReduceMin, vm.OpReduceMax, vm.OpReduceMinF, vm.OpReduceMaxF,
-			vm.OpReduceMean:
+			vm.OpReduceMean, vm.OpReduceAny, vm.OpReduceAll, vm.OpReduceQuantileF,
+			vm.OpReduceProd, vm.OpReduceProdF:
 			usedVRegs[src1] = true
 
 		case vm.OpGroupBy:
 			usedVRegs[src1] = true // key column
 
 		case vm.OpGroupSum, vm.OpGroupSumF, vm.OpGroupMin, vm.OpGroupMax,
-			vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean:
+			vm.OpGroupMinF, vm.OpGroupMaxF, vm.OpGroupMean, vm.OpGroupFirst, vm.OpGroupLast, vm.OpGroupMedianF,
+			vm.OpGroupConcat, vm.OpGroupQuantileF:
 			usedRRegs[src1] = true // groupby result
 			usedVRegs[src2] = true // value column
 
-		case vm.OpGroupCount, vm.OpGroupKeys:
+		case vm.OpGroupCount, vm.OpGroupKeys, vm.OpGroupSizes:
 			usedRRegs[src1] = true
 
 		// Scalar operations use R registers
@@ -76,18 +80,22 @@ func (o *Optimizer) projectionPruning(program *vm.Program) *vm.Program {
 			usedRRegs[src1] = true
 			usedRRegs[src2] = true
 
-		case vm.OpMoveR:
+		case vm.OpMoveR, vm.OpNegI:
 			usedRRegs[src1] = true
 
-		case vm.OpMoveF:
+		case vm.OpMoveF, vm.OpNegF:
 			usedFRegs[src1] = true
 
+		case vm.OpVecIndex, vm.OpVecIndexF:
+			usedVRegs[src1] = true
+			usedRRegs[src2] = true
+
 		// Join operations use R registers for frames
-		case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter:
+		case vm.OpJoinInner, vm.OpJoinLeft, vm.OpJoinRight, vm.OpJoinOuter, vm.OpJoinCross, vm.OpJoinInnerSorted, vm.OpConcat:
 			usedRRegs[src1] = true
 			usedRRegs[src2] = true
 
-		case vm.OpRowCount, vm.OpColCount:
+		case vm.OpRowCount, vm.OpColCount, vm.OpPivot, vm.OpDropNa, vm.OpRowIndex, vm.OpTopN, vm.OpSample, vm.OpShuffle:
 			usedRRegs[src1] = true
 
 		case vm.OpBroadcast:
@@ -98,7 +106,45 @@ func (o *Optimizer) projectionPruning(program *vm.Program) *vm.Program {
 			usedFRegs[src1] = true
 			usedVRegs[src2] = true
 
-		case vm.OpHalt:
+		case vm.OpFillI, vm.OpFillF, vm.OpFillStr:
+			if inst.Modifier()&vm.FillLikeVector != 0 {
+				usedVRegs[src1] = true
+			}
+
+		case vm.OpAssertEq:
+			switch inst.Imm8() {
+			case vm.RegFloat:
+				usedFRegs[src1] = true
+				usedFRegs[src2] = true
+			case vm.RegVector:
+				usedVRegs[src1] = true
+				usedVRegs[src2] = true
+			default:
+				usedRRegs[src1] = true
+				usedRRegs[src2] = true
+			}
+
+		case vm.OpPrint:
+			switch inst.Imm8() {
+			case vm.RegFloat:
+				usedFRegs[src1] = true
+			case vm.RegVector:
+				usedVRegs[src1] = true
+			default:
+				usedRRegs[src1] = true
+			}
+
+		case vm.OpSetResult:
+			switch inst.Modifier() {
+			case vm.RegFloat:
+				usedFRegs[src1] = true
+			case vm.RegVector:
+				usedVRegs[src1] = true
+			default:
+				usedRRegs[src1] = true
+			}
+
+		case vm.OpHalt, vm.OpHaltStr, vm.OpHaltFrame:
 			usedRRegs[inst.Dst()] = true
 
 		case vm.OpHaltF:
@@ -118,8 +164,10 @@ func (o *Optimizer) projectionPruning(program *vm.Program) *vm.Program {
 			// Only include if the destination V register is used
 			if usedVRegs[dst] {
 				newCode = append(newCode, inst)
+			} else if n := len(newCode); n > 0 && newCode[n-1].Opcode() == vm.OpWide {
+				// Skip unused SELECT_COL along with its WIDE prefix
+				newCode = newCode[:n-1]
 			}
-			// Skip unused SELECT_COL
 
 		case vm.OpBroadcast, vm.OpBroadcastF:
 			if usedVRegs[dst] {
@@ -2,13 +2,15 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 
-	"github.com/akhildatla/dasm/pkg/compiler"
+	_ "github.com/akhildatla/dasm/pkg/compiler" // registers vm.Assemble
 	"github.com/akhildatla/dasm/pkg/dsl"
 	"github.com/akhildatla/dasm/pkg/vm"
 )
@@ -122,7 +124,8 @@ func (r *REPL) handleCommand(line string, out io.Writer) bool {
 		return true
 	}
 
-	switch parts[0] {
+	// Commands may be written with a leading colon, as in ":make"
+	switch strings.TrimPrefix(parts[0], ":") {
 	case "quit", "exit", "q":
 		fmt.Fprintln(out, "Goodbye!")
 		return true // This will exit on next iteration when scanner fails
@@ -168,6 +171,14 @@ func (r *REPL) handleCommand(line string, out io.Writer) bool {
 		}
 		return true
 
+	case "make":
+		if len(parts) > 2 {
+			r.makeFrame(parts[1], parts[2:], out)
+		} else {
+			fmt.Fprintln(out, "Usage: make <name> <col>=<v1>,<v2>,... [<col>=...]")
+		}
+		return true
+
 	case "clear":
 		r.variables = make(map[string]any)
 		fmt.Fprintln(out, "Variables cleared")
@@ -194,13 +205,13 @@ func (r *REPL) eval(input string, out io.Writer) {
 	var err error
 
 	if r.mode == ModeDSL {
-		result, err = r.evalDSL(input)
+		result, err = r.evalDSL(input, out)
 	} else {
-		result, err = r.evalASM(input)
+		result, err = r.evalASM(input, out)
 	}
 
 	if err != nil {
-		fmt.Fprintf(out, "Error: %v\n", err)
+		fmt.Fprintln(out, FormatError(input, err))
 		return
 	}
 
@@ -209,7 +220,43 @@ func (r *REPL) eval(input string, out io.Writer) {
 	}
 }
 
-func (r *REPL) evalDSL(input string) (any, error) {
+// FormatError formats an error from evaluating input. A DSL parse error is
+// followed by the offending source line with a caret under the column it
+// names:
+//
+//	Error: line 1, col 12: unexpected token: EOF@1:12
+//	  data = ((((
+//	             ^
+func FormatError(input string, err error) string {
+	msg := fmt.Sprintf("Error: %v", err)
+
+	var parseErr *dsl.ParseError
+	if !errors.As(err, &parseErr) {
+		return msg
+	}
+	lines := strings.Split(input, "\n")
+	if parseErr.Line < 1 || parseErr.Line > len(lines) || parseErr.Col < 1 {
+		return msg
+	}
+	line := strings.TrimRight(lines[parseErr.Line-1], "\r")
+
+	// Pad with the line's own tabs so the caret lines up, one column per rune
+	var pad strings.Builder
+	prefix := line[:min(parseErr.Col-1, len(line))]
+	for _, ch := range prefix {
+		if ch == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	pad.WriteString(strings.Repeat(" ", parseErr.Col-1-len(prefix)))
+
+	return fmt.Sprintf("%s\n  %s\n  %s^", msg, line, pad.String())
+}
+
+// evalDSL compiles and runs DSL input; PRINT output goes to out.
+func (r *REPL) evalDSL(input string, out io.Writer) (any, error) {
 	// Tokenize
 	lexer := dsl.NewLexer(input)
 	tokens := lexer.Tokenize()
@@ -229,12 +276,13 @@ func (r *REPL) evalDSL(input string) (any, error) {
 	}
 
 	// Execute assembly
-	return r.evalASM(asm)
+	return r.evalASM(asm, out)
 }
 
-func (r *REPL) evalASM(input string) (any, error) {
+// evalASM assembles and runs input on a fresh VM; PRINT output goes to out.
+func (r *REPL) evalASM(input string, out io.Writer) (any, error) {
 	// Compile assembly
-	program, err := compiler.Compile(input)
+	program, err := vm.Assemble(input)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +290,7 @@ func (r *REPL) evalASM(input string) (any, error) {
 	// Create fresh VM
 	execVM := vm.NewVM()
 	execVM.SetPredeclaredFrames(r.frames)
+	execVM.SetOutput(out)
 
 	// Load and execute
 	if err := execVM.Load(program); err != nil {
@@ -269,6 +318,77 @@ func (r *REPL) loadFrame(name, path string, out io.Writer) {
 		name, path, numRows, numCols)
 }
 
+// makeFrame stores a frame built from col=v1,v2,... arguments under name.
+func (r *REPL) makeFrame(name string, columns []string, out io.Writer) {
+	frame, err := buildFrame(columns)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return
+	}
+
+	if r.frames == nil {
+		r.frames = make(map[string]*dataframe.DataFrame)
+	}
+	r.frames[name] = frame
+	fmt.Fprintf(out, "Created frame '%s' (%d rows, %d columns)\n",
+		name, frame.NRows(), len(frame.Series))
+}
+
+// buildFrame parses col=v1,v2,... arguments into a frame. A column is int64
+// when every value is an integer, float64 when every value is a number, and
+// string otherwise; an empty value is null.
+func buildFrame(columns []string) (*dataframe.DataFrame, error) {
+	series := make([]dataframe.Series, 0, len(columns))
+	seen := make(map[string]bool)
+	for _, arg := range columns {
+		name, list, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected <col>=<values>, got %q", arg)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[name] = true
+
+		values := strings.Split(list, ",")
+		if len(series) > 0 && len(values) != series[0].NRows() {
+			return nil, fmt.Errorf("column %q has %d values, expected %d", name, len(values), series[0].NRows())
+		}
+		series = append(series, buildSeries(name, values))
+	}
+	return dataframe.NewDataFrame(series...), nil
+}
+
+// buildSeries returns values as the narrowest series type that holds them.
+func buildSeries(name string, values []string) dataframe.Series {
+	ints := make([]any, len(values))
+	floats := make([]any, len(values))
+	strs := make([]any, len(values))
+	isInt, isFloat := true, true
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		strs[i] = v
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			ints[i], floats[i] = n, float64(n)
+		} else if f, err := strconv.ParseFloat(v, 64); err == nil {
+			isInt = false
+			floats[i] = f
+		} else {
+			isInt, isFloat = false, false
+		}
+	}
+
+	switch {
+	case isInt:
+		return dataframe.NewSeriesInt64(name, nil, ints...)
+	case isFloat:
+		return dataframe.NewSeriesFloat64(name, nil, floats...)
+	}
+	return dataframe.NewSeriesString(name, nil, strs...)
+}
+
 func loadCSVFile(_ string) (*dataframe.DataFrame, error) {
 	// Import loader dynamically to avoid circular dependency
 	return nil, fmt.Errorf("use dfl.Execute to load CSV files")
@@ -313,6 +433,9 @@ DASM REPL Commands:
   frames          List loaded data frames
   vars            List defined variables
   load <n> <path> Load CSV file as frame
+  make <n> <col>=<v1>,<v2>,... ...
+                  Build a frame from typed values, e.g.
+                  make sales category=A,B,A amount=10,25,7.5
   clear           Clear all variables
   history         Show command history
 
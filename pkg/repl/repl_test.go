@@ -2,6 +2,8 @@ package repl
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -196,6 +198,128 @@ func TestREPL_HandleCommand_Load_Usage(t *testing.T) {
 	}
 }
 
+func TestREPL_HandleCommand_Make(t *testing.T) {
+	r := New()
+	var out bytes.Buffer
+
+	if !r.handleCommand(":make sales category=A,B,A amount=10,25,7.5 units=1,,3", &out) {
+		t.Fatal("expected :make to be handled")
+	}
+	if !strings.Contains(out.String(), "Created frame 'sales' (3 rows, 3 columns)") {
+		t.Errorf("expected confirmation, got: %s", out.String())
+	}
+
+	frame := r.frames["sales"]
+	if frame == nil {
+		t.Fatal("expected frame 'sales' to be stored")
+	}
+	wantTypes := map[string]string{"category": "string", "amount": "float64", "units": "int64"}
+	for _, s := range frame.Series {
+		if got := s.Type(); got != wantTypes[s.Name()] {
+			t.Errorf("column %s: expected type %s, got %s", s.Name(), wantTypes[s.Name()], got)
+		}
+	}
+	if v := frame.Series[2].Value(1); v != nil {
+		t.Errorf("expected an empty value to be null, got %v", v)
+	}
+
+	out.Reset()
+	r.eval("data = frame(\"sales\")\nreturn sum(data.amount)", &out)
+	if !strings.Contains(out.String(), "42.5") {
+		t.Errorf("expected result 42.5, got: %s", out.String())
+	}
+}
+
+func TestREPL_HandleCommand_Make_Errors(t *testing.T) {
+	tests := map[string]string{
+		"make":                          "Usage:",
+		"make sales":                    "Usage:",
+		"make sales amount":             "expected <col>=<values>",
+		"make sales a=1,2 b=3":          "has 1 values, expected 2",
+		"make sales a=1 a=2":            "duplicate column",
+		"make sales =1,2":               "expected <col>=<values>",
+		"make sales a=1,2 b=x,y c=1,2,": "has 3 values, expected 2",
+	}
+	for cmd, want := range tests {
+		r := New()
+		var out bytes.Buffer
+		r.handleCommand(cmd, &out)
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%q: expected %q, got: %s", cmd, want, out.String())
+		}
+		if len(r.frames) != 0 {
+			t.Errorf("%q: expected no frame to be stored", cmd)
+		}
+	}
+}
+
+func TestREPL_Start_MakeAndQuery(t *testing.T) {
+	r := New()
+
+	input := "make sales category=A,B,A amount=10,25,7.5\n" +
+		"data = frame(\"sales\")\\\nreturn mean(data.amount)\n\nquit\n"
+	var out bytes.Buffer
+	r.Start(strings.NewReader(input), &out)
+
+	if !strings.Contains(out.String(), "Created frame 'sales'") {
+		t.Errorf("expected confirmation, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), "=> 14.1666") {
+		t.Errorf("expected mean 14.1666..., got: %s", out.String())
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		input   string
+		srcLine string
+		caret   string // expected caret line, without the two-space indent
+	}{
+		{"data = ((((", "data = ((((", "           ^"},
+		{"x = 1 +", "x = 1 +", "       ^"},
+		{"a = 1\nb = )", "b = )", "    ^"},
+		{"\tx = )", "\tx = )", "\t    ^"},
+		{"s = \"é\" +", "s = \"é\" +", "         ^"},
+	}
+	for _, tt := range tests {
+		_, err := New().evalDSL(tt.input, io.Discard)
+		if err == nil {
+			t.Fatalf("%q: expected a parse error", tt.input)
+		}
+		lines := strings.Split(FormatError(tt.input, err), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("%q: expected message, source and caret lines, got %q", tt.input, lines)
+		}
+		if lines[0] != "Error: "+err.Error() {
+			t.Errorf("%q: expected the error first, got %q", tt.input, lines[0])
+		}
+		if lines[1] != "  "+tt.srcLine {
+			t.Errorf("%q: expected source line %q, got %q", tt.input, tt.srcLine, lines[1])
+		}
+		if lines[2] != "  "+tt.caret {
+			t.Errorf("%q: expected caret line %q, got %q", tt.input, "  "+tt.caret, lines[2])
+		}
+	}
+}
+
+func TestFormatError_NotParseError(t *testing.T) {
+	err := errors.New("boom")
+	if got := FormatError("return 1", err); got != "Error: boom" {
+		t.Errorf("expected the plain message, got %q", got)
+	}
+}
+
+func TestREPL_Eval_ParseErrorCaret(t *testing.T) {
+	r := New()
+	var out bytes.Buffer
+
+	r.eval("data = ((((", &out)
+	want := "Error: line 1, col 12: unexpected token: EOF@1:12\n  data = ((((\n             ^\n"
+	if out.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
 func TestREPL_HandleCommand_Empty(t *testing.T) {
 	r := New()
 	var out bytes.Buffer
@@ -247,6 +371,17 @@ func TestREPL_Eval_ASM(t *testing.T) {
 	}
 }
 
+func TestREPL_Eval_Print(t *testing.T) {
+	r := New()
+	r.SetMode(ModeASM)
+	var out bytes.Buffer
+
+	r.eval("LOAD_CONST R0, 7\nPRINT R0, \"int\"\nHALT R0", &out)
+	if !strings.Contains(out.String(), "R0 = 7\n") {
+		t.Errorf("expected PRINT output, got: %s", out.String())
+	}
+}
+
 func TestREPL_Eval_ASM_Error(t *testing.T) {
 	r := New()
 	r.SetMode(ModeASM)
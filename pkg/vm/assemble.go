@@ -0,0 +1,36 @@
+package vm
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNoAssembler is returned by Assemble when no assembler is registered.
+var ErrNoAssembler = errors.New("no assembler registered; import github.com/akhildatla/dasm/pkg/compiler")
+
+var (
+	assemblerMu sync.RWMutex
+	assembler   func(src string) (*Program, error)
+)
+
+// RegisterAssembler makes fn the implementation behind Assemble. Package
+// compiler, which imports vm and so cannot be imported by it, registers its
+// Compile function when it is initialized.
+func RegisterAssembler(fn func(src string) (*Program, error)) {
+	assemblerMu.Lock()
+	defer assemblerMu.Unlock()
+	assembler = fn
+}
+
+// Assemble turns DASM assembly source into a Program. It is the single entry
+// point the CLI, the REPL and package embed assemble through; programs that
+// call it must link in package compiler, directly or with a blank import.
+func Assemble(src string) (*Program, error) {
+	assemblerMu.RLock()
+	fn := assembler
+	assemblerMu.RUnlock()
+	if fn == nil {
+		return nil, ErrNoAssembler
+	}
+	return fn(src)
+}
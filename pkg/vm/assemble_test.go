@@ -0,0 +1,26 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAssemble_Registration(t *testing.T) {
+	// Package compiler is not linked into vm's tests
+	if _, err := Assemble("HALT R0"); !errors.Is(err, ErrNoAssembler) {
+		t.Fatalf("expected ErrNoAssembler, got %v", err)
+	}
+
+	want := &Program{Code: []Instruction{EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}}
+	var got string
+	RegisterAssembler(func(src string) (*Program, error) {
+		got = src
+		return want, nil
+	})
+	defer RegisterAssembler(nil)
+
+	program, err := Assemble("HALT R0")
+	if err != nil || program != want || got != "HALT R0" {
+		t.Errorf("expected the registered assembler's program for %q, got %v, %v (saw %q)", "HALT R0", program, err, got)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
+	"strings"
 )
 
 // Bytecode file format:
@@ -146,21 +149,423 @@ func DeserializeProgram(data []byte) (*Program, error) {
 	}, nil
 }
 
+// ValidateProgram statically checks p without executing it: every opcode must
+// be known, constant indices must fall inside their pool, vector registers
+// must exist, and the program must contain a HALT instruction.
+func ValidateProgram(p *Program) error {
+	if p == nil {
+		return fmt.Errorf("%w: nil program", ErrInvalidInstruction)
+	}
+
+	halts := false
+	for i, inst := range p.Code {
+		op := inst.Opcode()
+		if op.String() == "UNKNOWN" {
+			return fmt.Errorf("instruction %d: %w: opcode 0x%02X", i, ErrInvalidInstruction, uint8(op))
+		}
+		if op == OpHalt || op == OpHaltF || op == OpHaltV || op == OpHaltStr || op == OpHaltFrame {
+			halts = true
+		}
+		if op == OpWide && (i+1 == len(p.Code) || !widensImm8(p.Code[i+1].Opcode())) {
+			return fmt.Errorf("instruction %d: %w: WIDE must precede an instruction with an imm8 constant index", i, ErrInvalidInstruction)
+		}
+
+		if err := checkConstants(inst, wideOffset(p.Code, i), p.Constants, p.FloatConstants); err != nil {
+			return fmt.Errorf("instruction %d (%s): %w", i, op, err)
+		}
+
+		for _, reg := range vectorOperands(inst) {
+			if reg >= NumVectorRegs {
+				return fmt.Errorf("instruction %d (%s): %w: V%d", i, op, ErrInvalidRegister, reg)
+			}
+		}
+	}
+
+	if !halts {
+		return ErrNoHalt
+	}
+	return nil
+}
+
 // Disassemble converts a Program back to assembly source code.
 func Disassemble(p *Program) string {
+	return disassemble(p, false)
+}
+
+// DisassembleAnnotated is like Disassemble but ends each instruction with a
+// comment naming the constant-pool slot it reads and the registers it writes
+// and reads, e.g. "LOAD_CONST R0, 42 ; const[0]; dst R0".
+func DisassembleAnnotated(p *Program) string {
+	return disassemble(p, true)
+}
+
+func disassemble(p *Program, annotate bool) string {
 	var buf bytes.Buffer
 
 	buf.WriteString("; Disassembled from DASM bytecode\n")
 	buf.WriteString(fmt.Sprintf("; %d instructions, %d constants, %d float constants\n\n",
 		len(p.Code), len(p.Constants), len(p.FloatConstants)))
 
+	// Declare the pools up front so unused, duplicate and reordered
+	// constants keep their slots when the output is reassembled
+	for i := 0; i < len(p.Constants); i += poolDirectiveWidth {
+		vals := p.Constants[i:min(i+poolDirectiveWidth, len(p.Constants))]
+		buf.WriteString(fmt.Sprintf("%-14s %s\n", ".const", asmConsts(vals...)))
+	}
+	for i := 0; i < len(p.FloatConstants); i += poolDirectiveWidth {
+		vals := p.FloatConstants[i:min(i+poolDirectiveWidth, len(p.FloatConstants))]
+		floats := make([]string, len(vals))
+		for j, f := range vals {
+			floats[j] = asmFloat(f)
+		}
+		buf.WriteString(fmt.Sprintf("%-14s %s\n", ".float", strings.Join(floats, ", ")))
+	}
+	if len(p.Constants) > 0 || len(p.FloatConstants) > 0 {
+		buf.WriteString("\n")
+	}
+
 	for i, inst := range p.Code {
-		buf.WriteString(fmt.Sprintf("%04d: %s\n", i, disassembleInstruction(inst, p.Constants, p.FloatConstants)))
+		wide := wideOffset(p.Code, i)
+		constants, floatConsts := widePools(wide, p.Constants, p.FloatConstants)
+		text := disassembleInstruction(inst, constants, floatConsts)
+		if inst.Opcode() == OpWide {
+			// The assembler inserts WIDE itself, so keep it out of the source
+			text = "; " + text
+		} else if annotate {
+			if note := annotateInstruction(inst, text, wide); note != "" {
+				text = fmt.Sprintf("%-40s ; %s", text, note)
+			}
+		}
+		buf.WriteString(fmt.Sprintf("%04d: %s\n", i, text))
 	}
 
 	return buf.String()
 }
 
+// poolDirectiveWidth is how many constants each .const or .float line of a
+// disassembly declares.
+const poolDirectiveWidth = 8
+
+// asmConst formats a constant-pool value as an assembly operand. Strings are
+// quoted without escapes since the assembler reads them verbatim.
+func asmConst(v any) string {
+	switch val := v.(type) {
+	case string:
+		return `"` + val + `"`
+	case float64:
+		return asmFloat(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// asmConsts formats several constants as a comma-separated operand list.
+func asmConsts(vals ...any) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		parts[i] = asmConst(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// asmFloat formats f in plain decimal notation with at least one fractional
+// digit, since the assembler reads neither exponents nor integers wider than
+// int64. The shortest representation that round-trips is used.
+func asmFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if math.IsInf(f, 0) || math.IsNaN(f) || strings.Contains(s, ".") {
+		return s
+	}
+	return s + ".0"
+}
+
+// annotateInstruction describes the constant slot and registers used by
+// inst, given its disassembled text and the offset of any WIDE prefix.
+func annotateInstruction(inst Instruction, text string, wide int) string {
+	var notes []string
+
+	if pool := constantPool(inst.Opcode()); pool != "" {
+		notes = append(notes, fmt.Sprintf("%s[%d]", pool, constantIndex(inst, wide)))
+	}
+
+	var dst string
+	var srcs []string
+	mnemonic, operands, _ := strings.Cut(text, " ")
+	for i, operand := range strings.Split(operands, ",") {
+		operand = strings.TrimSpace(operand)
+		if !isRegisterName(operand) {
+			continue
+		}
+		// HALT variants only read their operand
+		if i == 0 && !strings.HasPrefix(mnemonic, "HALT") {
+			dst = operand
+		} else {
+			srcs = append(srcs, operand)
+		}
+	}
+	if dst != "" {
+		notes = append(notes, "dst "+dst)
+	}
+	if len(srcs) > 0 {
+		notes = append(notes, "src "+strings.Join(srcs, ", "))
+	}
+
+	return strings.Join(notes, "; ")
+}
+
+// isRegisterName reports whether s names a register such as R0, F3 or V7.
+func isRegisterName(s string) bool {
+	if len(s) < 2 || !strings.ContainsRune("RFV", rune(s[0])) {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// widensImm8 reports whether op reads a constant index from imm8, which an
+// OpWide prefix extends. Opcodes with an imm16 index never take the prefix.
+func widensImm8(op Opcode) bool {
+	switch op {
+	case OpLoadCSV, OpLoadCSVOpts, OpLoadFrame, OpLoadJSON, OpLoadJSONL, OpLoadHTTP, OpLoadParquet,
+		OpLoadConst, OpLoadConstStr, OpLoadConstF:
+		return false
+	}
+	return constantPool(op) != ""
+}
+
+// constantIndex returns the constant-pool index inst reads: imm8 plus the
+// WIDE offset for opcodes that take the prefix, imm16 for the rest.
+func constantIndex(inst Instruction, wide int) int {
+	if widensImm8(inst.Opcode()) {
+		return wide | int(inst.Imm8())
+	}
+	return int(inst.Imm16())
+}
+
+// wideOffset returns the constant index bits supplied by an OpWide
+// immediately before code[i], or 0.
+func wideOffset(code []Instruction, i int) int {
+	if i > 0 && code[i-1].Opcode() == OpWide {
+		return int(code[i-1].Imm16()) << 8
+	}
+	return 0
+}
+
+// widePools returns the constant pools as seen by an instruction with the
+// given WIDE offset: starting at the offset, so its imm8 indexes them
+// directly.
+func widePools(wide int, constants []any, floatConsts []float64) ([]any, []float64) {
+	if wide == 0 {
+		return constants, floatConsts
+	}
+	return constants[min(wide, len(constants)):], floatConsts[min(wide, len(floatConsts)):]
+}
+
+// constKind is the type an instruction's handler asserts on a constant.
+type constKind uint8
+
+const (
+	anyConst   constKind = iota // checked by the handler itself
+	intConst                    // int64
+	countConst                  // non-negative int64 prefixing a value run
+	strConst                    // string
+)
+
+func (k constKind) accepts(v any) bool {
+	switch k {
+	case intConst:
+		_, ok := v.(int64)
+		return ok
+	case countConst:
+		n, ok := v.(int64)
+		return ok && n >= 0
+	case strConst:
+		_, ok := v.(string)
+		return ok
+	}
+	return true
+}
+
+func (k constKind) String() string {
+	switch k {
+	case intConst:
+		return "int"
+	case countConst:
+		return "non-negative int"
+	case strConst:
+		return "string"
+	}
+	return "any"
+}
+
+// constantRun returns the kinds of the consecutive constants inst reads from
+// the string/integer pool, starting at base. IN_SET and DROP_NA store a count
+// at base followed by that many values, so the run is sized from constants.
+func constantRun(inst Instruction, base int, constants []any) []constKind {
+	switch op := inst.Opcode(); op {
+	case OpLoadConst:
+		return []constKind{intConst}
+	case OpLoadCSVOpts:
+		return []constKind{strConst, strConst}
+	case OpRange:
+		return []constKind{anyConst, anyConst, anyConst}
+	case OpTopN:
+		return []constKind{strConst, intConst, strConst}
+	case OpSample:
+		if inst.Modifier()&SampleSeeded != 0 {
+			return []constKind{intConst, intConst}
+		}
+		return []constKind{intConst}
+	case OpFillI, OpFillF, OpFillStr:
+		if inst.Modifier()&FillLikeVector != 0 {
+			return []constKind{anyConst}
+		}
+		return []constKind{anyConst, anyConst}
+	case OpStrSubstr:
+		return []constKind{intConst, intConst}
+	case OpStrPadLeft, OpStrPadRight:
+		return []constKind{intConst, strConst}
+	case OpPivot:
+		return []constKind{strConst, strConst, strConst}
+	case OpFillNa, OpFormatF:
+		return []constKind{anyConst}
+	case OpInSet, OpDropNa:
+		kinds := []constKind{countConst}
+		if base >= len(constants) || !countConst.accepts(constants[base]) {
+			return kinds
+		}
+		value := anyConst
+		if op == OpDropNa {
+			value = strConst
+		}
+		// Past len(constants) the run is out of range anyway
+		for range min(constants[base].(int64), int64(len(constants)-base)) {
+			kinds = append(kinds, value)
+		}
+		return kinds
+	}
+	return []constKind{strConst}
+}
+
+// floatRun returns how many consecutive float constants op reads.
+func floatRun(op Opcode) int {
+	switch op {
+	case OpClampF:
+		return 2
+	case OpBinF:
+		return 3
+	}
+	return 1
+}
+
+// checkConstants reports whether the whole constant run inst reads, at its
+// index plus any WIDE offset, lies within the pools and holds the types the
+// VM asserts without checking.
+func checkConstants(inst Instruction, wide int, constants []any, floatConsts []float64) error {
+	pool := constantPool(inst.Opcode())
+	if pool == "" {
+		return nil
+	}
+	idx := constantIndex(inst, wide)
+	if pool == "fconst" {
+		if n := floatRun(inst.Opcode()); idx+n > len(floatConsts) {
+			return fmt.Errorf("fconst index %d (run of %d) out of range (pool size %d)", idx, n, len(floatConsts))
+		}
+		return nil
+	}
+
+	kinds := constantRun(inst, idx, constants)
+	if idx+len(kinds) > len(constants) {
+		return fmt.Errorf("const index %d (run of %d) out of range (pool size %d)", idx, len(kinds), len(constants))
+	}
+	for i, kind := range kinds {
+		if v := constants[idx+i]; !kind.accepts(v) {
+			return fmt.Errorf("%w: const[%d] is %T, want %s", ErrTypeMismatch, idx+i, v, kind)
+		}
+	}
+	return nil
+}
+
+// vectorOperands returns the V register numbers inst names in its dst, src1
+// and src2 fields.
+func vectorOperands(inst Instruction) []uint8 {
+	dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+	switch inst.Opcode() {
+	case OpVecAddI, OpVecSubI, OpVecMulI, OpVecDivI, OpVecModI,
+		OpVecAddF, OpVecSubF, OpVecMulF, OpVecDivF,
+		OpVecMinI, OpVecMaxI, OpVecMinF, OpVecMaxF,
+		OpCmpEQ, OpCmpNE, OpCmpLT, OpCmpLE, OpCmpGT, OpCmpGE, OpCmpEqEpsF,
+		OpAnd, OpOr, OpXor, OpMaskedSelect, OpFilter, OpTake, OpStrConcat, OpCoalesce,
+		OpDateAddDays, OpDateDiffDays:
+		return []uint8{dst, src1, src2}
+
+	case OpNot, OpVecNegF, OpStrLen, OpStrUpper, OpStrLower, OpStrTitle, OpStrCapitalize,
+		OpStrTrim, OpStrTrimLeft, OpStrTrimRight, OpIsNull, OpIsNotNull, OpIsNaNF, OpIsInfF,
+		OpStrContains, OpStrContainsCI, OpStrRegexMatch, OpStrRegexExtract, OpStrStartsWith,
+		OpStrEndsWith, OpStrSplit, OpStrReplace, OpStrTrimChars, OpStrSubstr, OpStrPadLeft, OpStrPadRight,
+		OpHashStr, OpParseDate, OpFormatF, OpDatePart, OpShift, OpClampF, OpBinF, OpRankF,
+		OpFillNa, OpInSet:
+		return []uint8{dst, src1}
+
+	case OpSelectCol, OpRange, OpRowIndex, OpGroupCount, OpGroupKeys, OpHaltV:
+		return []uint8{dst}
+
+	case OpFillI, OpFillF, OpFillStr:
+		if inst.Modifier()&FillLikeVector != 0 {
+			return []uint8{dst, src1}
+		}
+		return []uint8{dst}
+
+	case OpBroadcast, OpBroadcastF,
+		OpGroupSum, OpGroupSumF, OpGroupMin, OpGroupMax, OpGroupMinF, OpGroupMaxF, OpGroupMean,
+		OpGroupFirst, OpGroupLast, OpGroupMedianF, OpGroupConcat, OpGroupQuantileF:
+		return []uint8{dst, src2}
+
+	case OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax, OpReduceAny, OpReduceAll, OpReduceProd,
+		OpReduceSumF, OpReduceMinF, OpReduceMaxF, OpReduceMean, OpReduceProdF, OpReduceQuantileF,
+		OpVecIndex, OpVecIndexF, OpAddCol, OpGroupBy:
+		return []uint8{src1}
+
+	case OpCorrF, OpCovF, OpReduceWMeanF:
+		return []uint8{src1, src2}
+
+	case OpAssertEq:
+		if inst.Imm8() == RegVector {
+			return []uint8{src1, src2}
+		}
+	case OpPrint:
+		if inst.Imm8() == RegVector {
+			return []uint8{src1}
+		}
+	case OpSetResult:
+		if inst.Modifier() == RegVector {
+			return []uint8{src1}
+		}
+	}
+	return nil
+}
+
+// constantPool returns "const" or "fconst" for opcodes whose immediate
+// indexes the string/integer or float constant pool, and "" otherwise.
+func constantPool(op Opcode) string {
+	switch op {
+	case OpLoadCSV, OpLoadCSVOpts, OpLoadFrame, OpLoadJSON, OpLoadJSONL, OpLoadHTTP, OpLoadParquet, OpLoadConst, OpLoadConstStr,
+		OpSelectCol, OpAddCol, OpTopN, OpSample, OpGroupConcat, OpFillI, OpFillF, OpFillStr, OpRange,
+		OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter, OpJoinInnerSorted,
+		OpStrContains, OpStrContainsCI, OpStrRegexMatch, OpStrRegexExtract, OpStrStartsWith,
+		OpStrEndsWith, OpStrSplit, OpStrReplace, OpStrSubstr, OpStrPadLeft, OpStrPadRight, OpStrTrimChars,
+		OpFillNa, OpInSet, OpDropNa, OpPivot, OpParseDate, OpFormatF, OpSetResult:
+		return "const"
+	case OpLoadConstF, OpReduceQuantileF, OpGroupQuantileF, OpClampF, OpBinF, OpCmpEqEpsF:
+		return "fconst"
+	}
+	return ""
+}
+
 func disassembleInstruction(inst Instruction, constants []any, floatConsts []float64) string {
 	op := inst.Opcode()
 	dst := inst.Dst()
@@ -173,31 +578,39 @@ func disassembleInstruction(inst Instruction, constants []any, floatConsts []flo
 
 	switch op {
 	// Data loading with string constant
-	case OpLoadCSV, OpLoadFrame:
+	case OpLoadCSV, OpLoadFrame, OpLoadJSON, OpLoadJSONL, OpLoadHTTP, OpLoadParquet:
 		constVal := ""
 		if int(imm16) < len(constants) {
-			constVal = fmt.Sprintf("%q", constants[imm16])
+			constVal = asmConst(constants[imm16])
 		}
 		return fmt.Sprintf("%-14s R%d, %s", opName, dst, constVal)
 
-	case OpLoadConst:
+	case OpLoadCSVOpts:
+		pathVal, specVal := "", ""
+		if int(imm16)+1 < len(constants) {
+			pathVal = asmConst(constants[imm16])
+			specVal = asmConst(constants[imm16+1])
+		}
+		return fmt.Sprintf("%-14s R%d, %s, %s", opName, dst, pathVal, specVal)
+
+	case OpLoadConst, OpLoadConstStr:
 		constVal := ""
 		if int(imm16) < len(constants) {
-			constVal = fmt.Sprintf("%v", constants[imm16])
+			constVal = asmConst(constants[imm16])
 		}
 		return fmt.Sprintf("%-14s R%d, %s", opName, dst, constVal)
 
 	case OpLoadConstF:
 		constVal := ""
 		if int(imm16) < len(floatConsts) {
-			constVal = fmt.Sprintf("%v", floatConsts[imm16])
+			constVal = asmFloat(floatConsts[imm16])
 		}
 		return fmt.Sprintf("%-14s F%d, %s", opName, dst, constVal)
 
 	case OpSelectCol:
 		constVal := ""
 		if int(imm8) < len(constants) {
-			constVal = fmt.Sprintf("%q", constants[imm8])
+			constVal = asmConst(constants[imm8])
 		}
 		return fmt.Sprintf("%-14s V%d, R%d, %s", opName, dst, src1, constVal)
 
@@ -207,34 +620,101 @@ func disassembleInstruction(inst Instruction, constants []any, floatConsts []flo
 	case OpBroadcastF:
 		return fmt.Sprintf("%-14s V%d, F%d, V%d", opName, dst, src1, src2)
 
+	case OpRange:
+		args := ""
+		if int(imm8)+2 < len(constants) {
+			args = asmConsts(constants[imm8 : imm8+3]...)
+		}
+		return fmt.Sprintf("%-14s V%d, %s", opName, dst, args)
+
+	case OpFillI, OpFillF, OpFillStr:
+		if inst.Modifier()&FillLikeVector != 0 {
+			value := ""
+			if int(imm8) < len(constants) {
+				value = asmConst(constants[imm8])
+			}
+			return fmt.Sprintf("%-14s V%d, %s, V%d", opName, dst, value, src1)
+		}
+		args := ""
+		if int(imm8)+1 < len(constants) {
+			args = asmConsts(constants[imm8 : imm8+2]...)
+		}
+		return fmt.Sprintf("%-14s V%d, %s", opName, dst, args)
+
 	// Vector binary ops
 	case OpVecAddI, OpVecSubI, OpVecMulI, OpVecDivI, OpVecModI,
 		OpVecAddF, OpVecSubF, OpVecMulF, OpVecDivF,
+		OpVecMinI, OpVecMaxI, OpVecMinF, OpVecMaxF,
 		OpCmpEQ, OpCmpNE, OpCmpLT, OpCmpLE, OpCmpGT, OpCmpGE,
-		OpAnd, OpOr, OpFilter, OpTake, OpStrConcat:
+		OpAnd, OpOr, OpXor, OpMaskedSelect, OpFilter, OpTake, OpStrConcat, OpCoalesce,
+		OpDateAddDays, OpDateDiffDays:
 		return fmt.Sprintf("%-14s V%d, V%d, V%d", opName, dst, src1, src2)
 
+	case OpCmpEqEpsF:
+		eps := ""
+		if int(imm8) < len(floatConsts) {
+			eps = asmFloat(floatConsts[imm8])
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, V%d, %s", opName, dst, src1, src2, eps)
+
 	// Vector unary ops
-	case OpNot, OpStrLen, OpStrUpper, OpStrLower, OpStrTrim:
+	case OpNot, OpVecNegF, OpStrLen, OpStrUpper, OpStrLower, OpStrTitle, OpStrCapitalize, OpStrTrim, OpStrTrimLeft, OpStrTrimRight, OpIsNull, OpIsNotNull, OpIsNaNF, OpIsInfF:
 		return fmt.Sprintf("%-14s V%d, V%d", opName, dst, src1)
 
 	// Reduce ops
-	case OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax:
+	case OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax, OpReduceAny, OpReduceAll, OpReduceProd:
 		return fmt.Sprintf("%-14s R%d, V%d", opName, dst, src1)
 
-	case OpReduceSumF, OpReduceMinF, OpReduceMaxF, OpReduceMean:
+	case OpReduceSumF, OpReduceMinF, OpReduceMaxF, OpReduceMean, OpReduceProdF:
 		return fmt.Sprintf("%-14s F%d, V%d", opName, dst, src1)
 
+	case OpReduceQuantileF:
+		constVal := ""
+		if int(imm8) < len(floatConsts) {
+			constVal = asmFloat(floatConsts[imm8])
+		}
+		return fmt.Sprintf("%-14s F%d, V%d, %s", opName, dst, src1, constVal)
+
+	case OpCorrF, OpCovF, OpReduceWMeanF:
+		return fmt.Sprintf("%-14s F%d, V%d, V%d", opName, dst, src1, src2)
+
 	// Scalar ops
-	case OpMoveR, OpRowCount, OpColCount:
+	case OpMoveR, OpNegI, OpRowCount, OpColCount, OpShuffle:
 		return fmt.Sprintf("%-14s R%d, R%d", opName, dst, src1)
 
-	case OpMoveF:
+	case OpMoveF, OpNegF:
 		return fmt.Sprintf("%-14s F%d, F%d", opName, dst, src1)
 
+	case OpRowIndex:
+		return fmt.Sprintf("%-14s V%d, R%d", opName, dst, src1)
+
+	case OpTopN:
+		args := ""
+		if int(imm8)+2 < len(constants) {
+			args = asmConsts(constants[imm8 : imm8+3]...)
+		}
+		return fmt.Sprintf("%-14s R%d, R%d, %s", opName, dst, src1, args)
+
+	case OpSample:
+		count := 1
+		if inst.Modifier()&SampleSeeded != 0 {
+			count = 2
+		}
+		args := ""
+		if int(imm8)+count <= len(constants) {
+			args = asmConsts(constants[imm8 : int(imm8)+count]...)
+		}
+		return fmt.Sprintf("%-14s R%d, R%d, %s", opName, dst, src1, args)
+
 	case OpAddR, OpSubR, OpMulR, OpDivR:
 		return fmt.Sprintf("%-14s R%d, R%d, R%d", opName, dst, src1, src2)
 
+	case OpVecIndex:
+		return fmt.Sprintf("%-14s R%d, V%d, R%d", opName, dst, src1, src2)
+
+	case OpVecIndexF:
+		return fmt.Sprintf("%-14s F%d, V%d, R%d", opName, dst, src1, src2)
+
 	// Frame ops
 	case OpNewFrame:
 		return fmt.Sprintf("%-14s R%d", opName, dst)
@@ -242,7 +722,7 @@ func disassembleInstruction(inst Instruction, constants []any, floatConsts []flo
 	case OpAddCol:
 		constVal := ""
 		if int(imm8) < len(constants) {
-			constVal = fmt.Sprintf("%q", constants[imm8])
+			constVal = asmConst(constants[imm8])
 		}
 		return fmt.Sprintf("%-14s R%d, V%d, %s", opName, dst, src1, constVal)
 
@@ -253,35 +733,214 @@ func disassembleInstruction(inst Instruction, constants []any, floatConsts []flo
 	case OpGroupCount, OpGroupKeys:
 		return fmt.Sprintf("%-14s V%d, R%d", opName, dst, src1)
 
-	case OpGroupSum, OpGroupSumF, OpGroupMin, OpGroupMax, OpGroupMinF, OpGroupMaxF, OpGroupMean:
+	case OpGroupSizes:
+		return fmt.Sprintf("%-14s R%d, R%d", opName, dst, src1)
+
+	case OpGroupSum, OpGroupSumF, OpGroupMin, OpGroupMax, OpGroupMinF, OpGroupMaxF, OpGroupMean,
+		OpGroupFirst, OpGroupLast, OpGroupMedianF:
 		return fmt.Sprintf("%-14s V%d, R%d, V%d", opName, dst, src1, src2)
 
+	case OpGroupConcat:
+		sep := ""
+		if int(imm8) < len(constants) {
+			sep = asmConst(constants[imm8])
+		}
+		return fmt.Sprintf("%-14s V%d, R%d, V%d, %s", opName, dst, src1, src2, sep)
+
+	case OpGroupQuantileF:
+		constVal := ""
+		if int(imm8) < len(floatConsts) {
+			constVal = asmFloat(floatConsts[imm8])
+		}
+		return fmt.Sprintf("%-14s V%d, R%d, V%d, %s", opName, dst, src1, src2, constVal)
+
 	// Join ops
-	case OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter:
+	case OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter, OpJoinInnerSorted:
 		constVal := ""
 		if int(imm8) < len(constants) {
-			constVal = fmt.Sprintf("%q", constants[imm8])
+			constVal = asmConst(constants[imm8])
 		}
 		return fmt.Sprintf("%-14s R%d, R%d, R%d, %s", opName, dst, src1, src2, constVal)
 
 	// String pattern ops
-	case OpStrContains, OpStrStartsWith, OpStrEndsWith, OpStrSplit, OpStrReplace:
+	case OpStrContains, OpStrContainsCI, OpStrRegexMatch, OpStrRegexExtract, OpStrStartsWith, OpStrEndsWith, OpStrSplit, OpStrReplace, OpStrTrimChars:
+		constVal := ""
+		if int(imm8) < len(constants) {
+			constVal = asmConst(constants[imm8])
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, constVal)
+
+	case OpHashStr:
+		if imm8 > 0 {
+			return fmt.Sprintf("%-14s V%d, V%d, %d", opName, dst, src1, imm8)
+		}
+		return fmt.Sprintf("%-14s V%d, V%d", opName, dst, src1)
+
+	case OpParseDate, OpFormatF:
+		constVal := ""
+		if int(imm8) < len(constants) {
+			constVal = asmConst(constants[imm8])
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, constVal)
+
+	case OpDatePart:
+		part := "year"
+		switch imm8 {
+		case DatePartMonth:
+			part = "month"
+		case DatePartDay:
+			part = "day"
+		case DatePartHour:
+			part = "hour"
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %q", opName, dst, src1, part)
+
+	// Window ops
+	case OpShift:
+		return fmt.Sprintf("%-14s V%d, V%d, %d", opName, dst, src1, int8(imm8))
+
+	case OpClampF:
+		bounds := ""
+		if int(imm8)+1 < len(floatConsts) {
+			bounds = asmFloat(floatConsts[imm8]) + ", " + asmFloat(floatConsts[imm8+1])
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, bounds)
+
+	case OpBinF:
+		if int(imm8)+2 < len(floatConsts) {
+			nbins, lo, hi := int64(floatConsts[imm8]), floatConsts[imm8+1], floatConsts[imm8+2]
+			if math.IsNaN(lo) || math.IsNaN(hi) {
+				return fmt.Sprintf("%-14s V%d, V%d, %d", opName, dst, src1, nbins)
+			}
+			return fmt.Sprintf("%-14s V%d, V%d, %d, %s, %s", opName, dst, src1, nbins, asmFloat(lo), asmFloat(hi))
+		}
+		return fmt.Sprintf("%-14s V%d, V%d", opName, dst, src1)
+
+	case OpRankF:
+		ties := "average"
+		switch imm8 &^ RankDescending {
+		case RankMin:
+			ties = "min"
+		case RankDense:
+			ties = "dense"
+		}
+		order := "asc"
+		if imm8&RankDescending != 0 {
+			order = "desc"
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %q, %q", opName, dst, src1, ties, order)
+
+	// Null handling ops
+	case OpFillNa:
 		constVal := ""
 		if int(imm8) < len(constants) {
-			constVal = fmt.Sprintf("%q", constants[imm8])
+			constVal = asmConst(constants[imm8])
 		}
 		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, constVal)
 
+	case OpStrSubstr:
+		bounds := ""
+		if int(imm8)+1 < len(constants) {
+			bounds = asmConsts(constants[imm8 : imm8+2]...)
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, bounds)
+
+	case OpStrPadLeft, OpStrPadRight:
+		args := ""
+		if int(imm8)+1 < len(constants) {
+			args = asmConsts(constants[imm8 : imm8+2]...)
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, args)
+
+	case OpInSet:
+		var values []string
+		if int(imm8) < len(constants) {
+			if count, ok := constants[imm8].(int64); ok {
+				for i := 1; i <= int(count) && int(imm8)+i < len(constants); i++ {
+					values = append(values, asmConst(constants[int(imm8)+i]))
+				}
+			}
+		}
+		return fmt.Sprintf("%-14s V%d, V%d, %s", opName, dst, src1, strings.Join(values, ", "))
+
+	case OpDropNa:
+		var names []string
+		if int(imm8) < len(constants) {
+			if count, ok := constants[imm8].(int64); ok {
+				for i := 1; i <= int(count) && int(imm8)+i < len(constants); i++ {
+					names = append(names, asmConst(constants[int(imm8)+i]))
+				}
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Sprintf("%-14s R%d, R%d", opName, dst, src1)
+		}
+		return fmt.Sprintf("%-14s R%d, R%d, %s", opName, dst, src1, strings.Join(names, ", "))
+
+	// Reshaping ops
+	case OpPivot:
+		names := ""
+		if int(imm8)+2 < len(constants) {
+			names = asmConsts(constants[imm8 : imm8+3]...)
+		}
+		return fmt.Sprintf("%-14s R%d, R%d, %s", opName, dst, src1, names)
+
+	case OpConcat, OpJoinCross:
+		return fmt.Sprintf("%-14s R%d, R%d, R%d", opName, dst, src1, src2)
+
 	// Control flow
 	case OpNop:
 		return opName
 
-	case OpHalt:
+	case OpWide:
+		return fmt.Sprintf("%-14s %d", opName, imm16)
+
+	case OpAssertEq:
+		switch imm8 {
+		case RegInt:
+			return fmt.Sprintf("%-14s R%d, R%d, \"int\"", opName, src1, src2)
+		case RegFloat:
+			return fmt.Sprintf("%-14s F%d, F%d", opName, src1, src2)
+		case RegVector:
+			return fmt.Sprintf("%-14s V%d, V%d", opName, src1, src2)
+		}
+		return fmt.Sprintf("%-14s R%d, R%d", opName, src1, src2)
+
+	case OpPrint:
+		switch imm8 {
+		case RegInt:
+			return fmt.Sprintf("%-14s R%d, \"int\"", opName, src1)
+		case RegFloat:
+			return fmt.Sprintf("%-14s F%d", opName, src1)
+		case RegVector:
+			return fmt.Sprintf("%-14s V%d", opName, src1)
+		}
+		return fmt.Sprintf("%-14s R%d", opName, src1)
+
+	case OpSetResult:
+		name := ""
+		if int(imm8) < len(constants) {
+			name = asmConst(constants[imm8])
+		}
+		switch inst.Modifier() {
+		case RegInt:
+			return fmt.Sprintf("%-14s %s, R%d, \"int\"", opName, name, src1)
+		case RegFloat:
+			return fmt.Sprintf("%-14s %s, F%d", opName, name, src1)
+		case RegVector:
+			return fmt.Sprintf("%-14s %s, V%d", opName, name, src1)
+		}
+		return fmt.Sprintf("%-14s %s, R%d", opName, name, src1)
+
+	case OpHalt, OpHaltStr, OpHaltFrame:
 		return fmt.Sprintf("%-14s R%d", opName, dst)
 
 	case OpHaltF:
 		return fmt.Sprintf("%-14s F%d", opName, dst)
 
+	case OpHaltV:
+		return fmt.Sprintf("%-14s V%d", opName, dst)
+
 	default:
 		return fmt.Sprintf("%-14s 0x%08X", opName, uint64(inst))
 	}
@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -195,6 +197,69 @@ func TestDisassemble_Simple(t *testing.T) {
 	}
 }
 
+func TestDisassembleAnnotated(t *testing.T) {
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpLoadConst, 0, 2, 0, 0, 2),
+			EncodeInstruction(OpLoadConstF, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpReduceSum, 0, 3, 1, 0, 0),
+			EncodeInstruction(OpHalt, 0, 3, 0, 0, 0),
+		},
+		Constants:      []any{"sales", "amount", int64(42)},
+		FloatConstants: []float64{2.5},
+	}
+
+	asm := DisassembleAnnotated(program)
+
+	for _, want := range []string{
+		`LOAD_FRAME     R0, "sales"`,
+		`; const[0]; dst R0`,
+		`SELECT_COL     V1, R0, "amount"`,
+		`; const[1]; dst V1; src R0`,
+		`LOAD_CONST     R2, 42`,
+		`; const[2]; dst R2`,
+		`LOAD_CONST_F   F0, 2.5`,
+		`; fconst[0]; dst F0`,
+		`; dst R3; src V1`,
+		`HALT           R3`,
+	} {
+		if !strings.Contains(asm, want) {
+			t.Errorf("expected %q in annotated output:\n%s", want, asm)
+		}
+	}
+	if !strings.Contains(asm, "; src R3\n") {
+		t.Errorf("expected HALT to only read R3:\n%s", asm)
+	}
+
+	// The plain form carries no annotations
+	if strings.Contains(Disassemble(program), "const[") {
+		t.Error("plain disassembly should not be annotated")
+	}
+}
+
+func TestDisassembleAnnotated_Imm16Index(t *testing.T) {
+	// Index 300 sets the bits shared with src2, which must not switch
+	// LOAD_CONST over to imm8
+	constants := make([]any, 301)
+	for i := range constants {
+		constants[i] = int64(i)
+	}
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 300),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: constants,
+	}
+
+	asm := DisassembleAnnotated(program)
+	if !strings.Contains(asm, "; const[300]; dst R0") {
+		t.Errorf("expected const[300] annotation:\n%s", asm)
+	}
+}
+
 func TestDisassemble_AllOpcodes(t *testing.T) {
 	// Test disassembly of various opcode types
 	tests := []struct {
@@ -318,6 +383,72 @@ func TestDisassemble_StringOps(t *testing.T) {
 	}
 }
 
+func TestValidateProgram(t *testing.T) {
+	valid := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpReduceQuantileF, 0, 0, 1, 0, 0),
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants:      []any{"data", "price"},
+		FloatConstants: []float64{0.5},
+	}
+	if err := ValidateProgram(valid); err != nil {
+		t.Fatalf("expected valid program, got %v", err)
+	}
+
+	// Full constant runs of the right types pass
+	runs := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpTopN, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 2, 1, 0, 1),
+			EncodeInstruction(OpInSet, 0, 3, 2, 0, 4),
+			EncodeInstruction(OpHaltV, 0, 3, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", int64(5), "desc", int64(2), 1.5, 2.5},
+	}
+	if err := ValidateProgram(runs); err != nil {
+		t.Fatalf("expected valid program, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		program *Program
+		wantErr error
+	}{
+		{"nil", nil, ErrInvalidInstruction},
+		{"unknown opcode", &Program{Code: []Instruction{Instruction(0xEF << 24), EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}}, ErrInvalidInstruction},
+		{"no halt", &Program{Code: []Instruction{EncodeInstruction(OpNop, 0, 0, 0, 0, 0)}}, ErrNoHalt},
+		{"vector register", &Program{Code: []Instruction{EncodeInstruction(OpHaltV, 0, 9, 0, 0, 0)}}, ErrInvalidRegister},
+		{"constant index", &Program{Code: []Instruction{EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 3), EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}, Constants: []any{int64(1)}}, nil},
+		{"float constant index", &Program{Code: []Instruction{EncodeInstruction(OpClampF, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}}, nil},
+		{"wide constant index", &Program{Code: []Instruction{EncodeInstruction(OpWide, 0, 0, 0, 0, 1), EncodeInstruction(OpSelectCol, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, Constants: []any{"price"}}, nil},
+		{"wide before imm16", &Program{Code: []Instruction{EncodeInstruction(OpWide, 0, 0, 0, 0, 1), EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0), EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}, Constants: []any{int64(1)}}, ErrInvalidInstruction},
+		{"imm16 index past 255", &Program{Code: []Instruction{EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 300), EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}, Constants: make([]any, 45)}, nil},
+		{"in_set run", &Program{Code: []Instruction{EncodeInstruction(OpInSet, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, Constants: []any{int64(3), int64(1), int64(2)}}, nil},
+		{"top_n run", &Program{Code: []Instruction{EncodeInstruction(OpTopN, 0, 1, 2, 0, 0), EncodeInstruction(OpHalt, 0, 1, 0, 0, 0)}, Constants: []any{"price", int64(5)}}, nil},
+		{"bin_f run", &Program{Code: []Instruction{EncodeInstruction(OpBinF, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, FloatConstants: []float64{4, 0}}, nil},
+		{"substr run", &Program{Code: []Instruction{EncodeInstruction(OpStrSubstr, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, Constants: []any{int64(0)}}, nil},
+		{"constant type", &Program{Code: []Instruction{EncodeInstruction(OpSelectCol, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, Constants: []any{int64(7)}}, ErrTypeMismatch},
+		{"in_set count type", &Program{Code: []Instruction{EncodeInstruction(OpInSet, 0, 1, 2, 0, 0), EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0)}, Constants: []any{"a", "b"}}, ErrTypeMismatch},
+		{"vector src register", &Program{Code: []Instruction{EncodeInstruction(OpReduceSum, 0, 0, 12, 0, 0), EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}}, ErrInvalidRegister},
+		{"trailing wide", &Program{Code: []Instruction{EncodeInstruction(OpHalt, 0, 0, 0, 0, 0), EncodeInstruction(OpWide, 0, 0, 0, 0, 1)}}, ErrInvalidInstruction},
+	}
+
+	for _, tt := range tests {
+		err := ValidateProgram(tt.program)
+		if err == nil {
+			t.Errorf("%s: expected error", tt.name)
+			continue
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
@@ -13,6 +13,9 @@ package vm
 // │ opcode  │ modifier │ dst │       imm16          │
 // │ 8 bits  │  4 bits  │4 bit│       16 bits        │
 // └─────────┴──────────┴─────┴──────────────────────┘
+//
+// A constant index kept in imm8 can exceed 255 by preceding the instruction
+// with OpWide, whose imm16 holds bits 8-23 of the index.
 type Instruction uint32
 
 // EncodeInstruction creates an instruction from its components.
@@ -14,6 +14,13 @@ const (
 	OpLoadFrame   Opcode = 0x06 // R[dst] = predeclared_frames[constants[imm16]]
 	OpLoadJSON    Opcode = 0x07 // R[dst] = load_json(constants[imm16])
 	OpLoadParquet Opcode = 0x08 // R[dst] = load_parquet(constants[imm16])
+	OpLoadCSVOpts Opcode = 0x09 // R[dst] = load_csv(constants[imm16], options constants[imm16+1])
+	OpLoadJSONL   Opcode = 0x0A // R[dst] = load_jsonl(constants[imm16])
+	OpLoadHTTP    Opcode = 0x0B // R[dst] = load_url(constants[imm16])
+	OpFillI       Opcode = 0x0C // V[dst] = int64 constants[imm8] repeated; see FillLikeVector
+	OpFillF       Opcode = 0x0D // V[dst] = float64 constants[imm8] repeated; see FillLikeVector
+	OpFillStr     Opcode = 0x0E // V[dst] = string constants[imm8] repeated; see FillLikeVector
+	OpRange       Opcode = 0x0F // V[dst] = [start, start+step, ...) up to end; constants[imm8..imm8+2] = start, end, step
 
 	// ===== Vector Arithmetic (0x10-0x1F) =====
 	OpVecAddI Opcode = 0x10 // V[dst] = V[src1] + V[src2] (int64)
@@ -25,19 +32,29 @@ const (
 	OpVecSubF Opcode = 0x16 // V[dst] = V[src1] - V[src2] (float64)
 	OpVecMulF Opcode = 0x17 // V[dst] = V[src1] * V[src2] (float64)
 	OpVecDivF Opcode = 0x18 // V[dst] = V[src1] / V[src2] (float64)
+	OpVecMinI Opcode = 0x19 // V[dst] = min(V[src1], V[src2]) elementwise (int64)
+	OpVecMaxI Opcode = 0x1A // V[dst] = max(V[src1], V[src2]) elementwise (int64)
+	OpVecMinF Opcode = 0x1B // V[dst] = min(V[src1], V[src2]) elementwise (float64)
+	OpVecMaxF Opcode = 0x1C // V[dst] = max(V[src1], V[src2]) elementwise (float64)
+	OpClampF  Opcode = 0x1D // V[dst] = clamp(V[src1], floatConsts[imm8], floatConsts[imm8+1])
+	OpVecNegF Opcode = 0x1E // V[dst] = -V[src1] (float64)
 
 	// ===== Comparison (0x20-0x2F) =====
-	OpCmpEQ Opcode = 0x20 // V[dst] = V[src1] == V[src2] (bool column)
-	OpCmpNE Opcode = 0x21 // V[dst] = V[src1] != V[src2]
-	OpCmpLT Opcode = 0x22 // V[dst] = V[src1] < V[src2]
-	OpCmpLE Opcode = 0x23 // V[dst] = V[src1] <= V[src2]
-	OpCmpGT Opcode = 0x24 // V[dst] = V[src1] > V[src2]
-	OpCmpGE Opcode = 0x25 // V[dst] = V[src1] >= V[src2]
+	OpCmpEQ     Opcode = 0x20 // V[dst] = V[src1] == V[src2] (bool column)
+	OpCmpNE     Opcode = 0x21 // V[dst] = V[src1] != V[src2]
+	OpCmpLT     Opcode = 0x22 // V[dst] = V[src1] < V[src2]
+	OpCmpLE     Opcode = 0x23 // V[dst] = V[src1] <= V[src2]
+	OpCmpGT     Opcode = 0x24 // V[dst] = V[src1] > V[src2]
+	OpCmpGE     Opcode = 0x25 // V[dst] = V[src1] >= V[src2]
+	OpInSet     Opcode = 0x26 // V[dst] = V[src1] in set at constants[imm8] (count, then values)
+	OpCmpEqEpsF Opcode = 0x27 // V[dst] = |V[src1] - V[src2]| <= floatConsts[imm8]
 
 	// ===== Logical (0x30-0x3F) =====
-	OpAnd Opcode = 0x30 // V[dst] = V[src1] AND V[src2] (bool columns)
-	OpOr  Opcode = 0x31 // V[dst] = V[src1] OR V[src2]
-	OpNot Opcode = 0x32 // V[dst] = NOT V[src1]
+	OpAnd          Opcode = 0x30 // V[dst] = V[src1] AND V[src2] (bool columns)
+	OpOr           Opcode = 0x31 // V[dst] = V[src1] OR V[src2]
+	OpNot          Opcode = 0x32 // V[dst] = NOT V[src1]
+	OpXor          Opcode = 0x33 // V[dst] = V[src1] XOR V[src2]
+	OpMaskedSelect Opcode = 0x34 // V[dst] = V[src2] spread over the rows where V[src1] is true (false elsewhere)
 
 	// ===== Filtering (0x40-0x4F) =====
 	OpFilter Opcode = 0x40 // V[dst] = filter(V[src1], V[src2] as bool mask)
@@ -52,20 +69,38 @@ const (
 	OpReduceMinF  Opcode = 0x55 // F[dst] = min(V[src1])
 	OpReduceMaxF  Opcode = 0x56 // F[dst] = max(V[src1])
 	OpReduceMean  Opcode = 0x57 // F[dst] = mean(V[src1])
+	OpReduceAny   Opcode = 0x58 // R[dst] = any(V[src1]) (bool column, 0/1)
+	OpReduceAll   Opcode = 0x59 // R[dst] = all(V[src1]) (bool column, 0/1)
+
+	OpReduceQuantileF Opcode = 0x5A // F[dst] = quantile(V[src1], floatConsts[imm8]) (linear interpolation)
+	OpCorrF           Opcode = 0x5B // F[dst] = pearson_corr(V[src1], V[src2])
+	OpCovF            Opcode = 0x5C // F[dst] = sample_cov(V[src1], V[src2])
+	OpReduceWMeanF    Opcode = 0x5D // F[dst] = sum(V[src1]*V[src2]) / sum(V[src2])
+	OpReduceProd      Opcode = 0x5E // R[dst] = product(V[src1]), 1 when empty
+	OpReduceProdF     Opcode = 0x5F // F[dst] = product(V[src1]) (float), 1 when empty
 
 	// ===== Scalar Operations (0x60-0x6F) =====
-	OpMoveR Opcode = 0x60 // R[dst] = R[src1]
-	OpMoveF Opcode = 0x61 // F[dst] = F[src1]
-	OpAddR  Opcode = 0x62 // R[dst] = R[src1] + R[src2]
-	OpSubR  Opcode = 0x63 // R[dst] = R[src1] - R[src2]
-	OpMulR  Opcode = 0x64 // R[dst] = R[src1] * R[src2]
-	OpDivR  Opcode = 0x65 // R[dst] = R[src1] / R[src2]
+	OpMoveR        Opcode = 0x60 // R[dst] = R[src1]
+	OpMoveF        Opcode = 0x61 // F[dst] = F[src1]
+	OpAddR         Opcode = 0x62 // R[dst] = R[src1] + R[src2]
+	OpSubR         Opcode = 0x63 // R[dst] = R[src1] - R[src2]
+	OpMulR         Opcode = 0x64 // R[dst] = R[src1] * R[src2]
+	OpDivR         Opcode = 0x65 // R[dst] = R[src1] / R[src2]
+	OpVecIndex     Opcode = 0x66 // R[dst] = V[src1][R[src2]] (int64)
+	OpVecIndexF    Opcode = 0x67 // F[dst] = V[src1][R[src2]] (float64, NaN when null)
+	OpNegI         Opcode = 0x68 // R[dst] = -R[src1]
+	OpNegF         Opcode = 0x69 // F[dst] = -F[src1]
+	OpLoadConstStr Opcode = 0x6A // R[dst] = imm16, the index of string constants[imm16]; see OpHaltStr
 
 	// ===== Frame Operations (0x70-0x7F) =====
 	OpNewFrame Opcode = 0x70 // R[dst] = new empty frame
 	OpAddCol   Opcode = 0x71 // add V[src1] to frame R[dst] with name constants[imm16]
 	OpColCount Opcode = 0x72 // R[dst] = number of columns in frame R[src1]
 	OpRowCount Opcode = 0x73 // R[dst] = number of rows in frame R[src1]
+	OpRowIndex Opcode = 0x74 // V[dst] = [0, 1, ..., n-1] for the rows of frame R[src1]
+	OpTopN     Opcode = 0x75 // R[dst] = first n rows of R[src1] sorted by column; constants[imm8..imm8+2] = column, n, "asc"|"desc"
+	OpSample   Opcode = 0x76 // R[dst] = n random rows of R[src1] without replacement; constants[imm8] = n, see SampleSeeded
+	OpShuffle  Opcode = 0x77 // R[dst] = all rows of R[src1] in random order
 
 	// ===== GroupBy Operations (0x80-0x8F) =====
 	OpGroupBy    Opcode = 0x80 // R[dst] = groupby(R[src1] frame, V[src2] key column) -> returns group indices
@@ -78,30 +113,124 @@ const (
 	OpGroupMaxF  Opcode = 0x87 // V[dst] = max(V[src1]) per group (float)
 	OpGroupMean  Opcode = 0x88 // V[dst] = mean(V[src1]) per group
 	OpGroupKeys  Opcode = 0x89 // V[dst] = unique keys from R[src1] groupby result
+	OpGroupFirst Opcode = 0x8A // V[dst] = first(V[src2]) per group
+	OpGroupLast  Opcode = 0x8B // V[dst] = last(V[src2]) per group
+
+	OpGroupMedianF Opcode = 0x8C // V[dst] = median(V[src2]) per group (float)
+	OpGroupConcat  Opcode = 0x8D // V[dst] = join(V[src2], constants[imm8]) per group (string)
+
+	OpGroupQuantileF Opcode = 0x8E // V[dst] = quantile(V[src2], floatConsts[imm8]) per group
+	OpGroupSizes     Opcode = 0x8F // R[dst] = frame of (key, size) rows, one per group of R[src1], in KeyOrder
 
 	// ===== Join Operations (0x90-0x9F) =====
 	OpJoinInner Opcode = 0x90 // R[dst] = inner_join(R[src1], R[src2]) on columns specified by imm16
 	OpJoinLeft  Opcode = 0x91 // R[dst] = left_join(R[src1], R[src2])
 	OpJoinRight Opcode = 0x92 // R[dst] = right_join(R[src1], R[src2])
 	OpJoinOuter Opcode = 0x93 // R[dst] = outer_join(R[src1], R[src2])
+	OpJoinCross Opcode = 0x94 // R[dst] = every pairing of R[src1] and R[src2] rows (no key)
+
+	OpJoinInnerSorted Opcode = 0x95 // R[dst] = inner_join(R[src1], R[src2]) by merging frames sorted on constants[imm8]
 
 	// ===== String Operations (0xA0-0xAF) =====
-	OpStrLen        Opcode = 0xA0 // V[dst] = strlen(V[src1]) -> int64 column
-	OpStrUpper      Opcode = 0xA1 // V[dst] = upper(V[src1])
-	OpStrLower      Opcode = 0xA2 // V[dst] = lower(V[src1])
-	OpStrConcat     Opcode = 0xA3 // V[dst] = concat(V[src1], V[src2])
-	OpStrContains   Opcode = 0xA4 // V[dst] = contains(V[src1], constants[imm16]) -> bool column
-	OpStrStartsWith Opcode = 0xA5 // V[dst] = starts_with(V[src1], constants[imm16]) -> bool
-	OpStrEndsWith   Opcode = 0xA6 // V[dst] = ends_with(V[src1], constants[imm16]) -> bool
-	OpStrTrim       Opcode = 0xA7 // V[dst] = trim(V[src1])
-	OpStrSplit      Opcode = 0xA8 // V[dst] = split(V[src1], constants[imm16]) -> first part
-	OpStrReplace    Opcode = 0xA9 // V[dst] = replace(V[src1], old, new) using constants
+	OpStrLen          Opcode = 0xA0 // V[dst] = strlen(V[src1]) -> int64 column
+	OpStrUpper        Opcode = 0xA1 // V[dst] = upper(V[src1])
+	OpStrLower        Opcode = 0xA2 // V[dst] = lower(V[src1])
+	OpStrConcat       Opcode = 0xA3 // V[dst] = concat(V[src1], V[src2])
+	OpStrContains     Opcode = 0xA4 // V[dst] = contains(V[src1], constants[imm16]) -> bool column
+	OpStrStartsWith   Opcode = 0xA5 // V[dst] = starts_with(V[src1], constants[imm16]) -> bool
+	OpStrEndsWith     Opcode = 0xA6 // V[dst] = ends_with(V[src1], constants[imm16]) -> bool
+	OpStrTrim         Opcode = 0xA7 // V[dst] = trim(V[src1])
+	OpStrSplit        Opcode = 0xA8 // V[dst] = split(V[src1], constants[imm16]) -> first part
+	OpStrReplace      Opcode = 0xA9 // V[dst] = replace(V[src1], old, new) using constants
+	OpStrContainsCI   Opcode = 0xAA // V[dst] = contains(lower(V[src1]), lower(constants[imm16])) -> bool
+	OpStrRegexMatch   Opcode = 0xAB // V[dst] = regexp(constants[imm16]).match(V[src1]) -> bool
+	OpStrRegexExtract Opcode = 0xAC // V[dst] = first capture group (or match) of regexp(constants[imm16]) in V[src1]
+	OpStrSubstr       Opcode = 0xAD // V[dst] = substr(V[src1], constants[imm8], constants[imm8+1]) in runes
+	OpStrPadLeft      Opcode = 0xAE // V[dst] = V[src1] left-padded to width constants[imm8] with constants[imm8+1]
+	OpStrPadRight     Opcode = 0xAF // V[dst] = V[src1] right-padded to width constants[imm8] with constants[imm8+1]
+
+	// ===== Window Operations (0xB0-0xBF) =====
+	OpShift Opcode = 0xB0 // V[dst] = shift(V[src1], int8(imm8)); positive = lag, negative = lead
+	OpBinF  Opcode = 0xB1 // V[dst] = bin(V[src1]) using floatConsts[imm8..imm8+2] = nbins, min, max
+	OpRankF Opcode = 0xB2 // V[dst] = rank(V[src1]) with tie strategy and direction flags in imm8
+
+	// ===== Null Handling (0xC0-0xCF) =====
+	OpFillNa    Opcode = 0xC0 // V[dst] = V[src1] with nil cells replaced by constants[imm8]
+	OpDropNa    Opcode = 0xC1 // R[dst] = frame R[src1] without rows holding nil in the columns listed at constants[imm8] (count, then names; 0 = all)
+	OpIsNull    Opcode = 0xC2 // V[dst] = isnull(V[src1]) as bool mask
+	OpIsNotNull Opcode = 0xC3 // V[dst] = !isnull(V[src1]) as bool mask
+	OpIsNaNF    Opcode = 0xC4 // V[dst] = isnan(V[src1]) as bool mask
+	OpIsInfF    Opcode = 0xC5 // V[dst] = isinf(V[src1]) as bool mask
+	OpCoalesce  Opcode = 0xC6 // V[dst] = V[src1] where not nil, else V[src2]
+
+	// ===== Hashing (0xD0-0xD7) =====
+	OpHashStr Opcode = 0xD0 // V[dst] = fnv1a64(V[src1]) as int64, modulo imm8 buckets when imm8 > 0
+
+	// ===== Date Operations (0xD8-0xDF) =====
+	OpParseDate    Opcode = 0xD8 // V[dst] = Unix seconds of V[src1] parsed with layout constants[imm8]; nil if unparseable
+	OpDatePart     Opcode = 0xD9 // V[dst] = UTC year, month, day or hour (imm8) of Unix seconds V[src1]
+	OpDateAddDays  Opcode = 0xDA // V[dst] = Unix seconds V[src1] + V[src2] days
+	OpDateDiffDays Opcode = 0xDB // V[dst] = whole days from V[src2] to V[src1] (Unix seconds), truncated toward zero
+
+	// ===== Reshaping Operations (0xE0-0xE7) =====
+	OpPivot  Opcode = 0xE0 // R[dst] = pivot(R[src1]) with index, key, value column names at constants[imm8..imm8+2]
+	OpConcat Opcode = 0xE1 // R[dst] = rows of R[src1] followed by rows of R[src2] (schemas must match)
+
+	// ===== More String Operations (0xE8-0xEF) =====
+	OpFormatF       Opcode = 0xE8 // V[dst] = V[src1] formatted as strings; constants[imm8] is a decimal count or a printf format
+	OpStrTitle      Opcode = 0xE9 // V[dst] = title(V[src1]): each word's first letter upper, the rest lower
+	OpStrCapitalize Opcode = 0xEA // V[dst] = V[src1] with its first letter uppercased
+	OpStrTrimLeft   Opcode = 0xEB // V[dst] = V[src1] without leading whitespace
+	OpStrTrimRight  Opcode = 0xEC // V[dst] = V[src1] without trailing whitespace
+	OpStrTrimChars  Opcode = 0xED // V[dst] = V[src1] without leading or trailing runes in cutset constants[imm8]
 
 	// ===== Control Flow (0xF0-0xFF) =====
-	OpNop   Opcode = 0xF0 // No operation
-	OpHaltV Opcode = 0xFD // Stop execution, V[dst] is return value (vector/column)
-	OpHalt  Opcode = 0xFE // Stop execution, R[dst] is return value (int64)
-	OpHaltF Opcode = 0xFF // Stop execution, F[dst] is return value (float64)
+	OpNop       Opcode = 0xF0 // No operation
+	OpAssertEq  Opcode = 0xF1 // Fail with ErrAssertionFailed unless src1 == src2; imm8 selects frames, ints, floats or vectors
+	OpPrint     Opcode = 0xF2 // Write src1 (kind in imm8) to the output writer
+	OpSetResult Opcode = 0xF3 // Store src1 (kind in modifier) as the named result constants[imm8]; see VM.Results
+	OpHaltStr   Opcode = 0xF4 // Stop execution, string constants[R[dst]] is return value
+	OpWide      Opcode = 0xF5 // Prefix: imm16 supplies bits 8-23 of the next instruction's imm8 constant index
+	OpHaltFrame Opcode = 0xF6 // Stop execution, frame R[dst] is return value
+	OpHaltV     Opcode = 0xFD // Stop execution, V[dst] is return value (vector/column)
+	OpHalt      Opcode = 0xFE // Stop execution, R[dst] is return value (int64)
+	OpHaltF     Opcode = 0xFF // Stop execution, F[dst] is return value (float64)
+)
+
+// Parts for OpDatePart's imm8.
+const (
+	DatePartYear  uint8 = 0x00
+	DatePartMonth uint8 = 0x01
+	DatePartDay   uint8 = 0x02
+	DatePartHour  uint8 = 0x03
+)
+
+// Modifier for OpFillI, OpFillF and OpFillStr. The length is the int64 at
+// constants[imm8+1] unless FillLikeVector is set, in which case the result
+// has as many rows as V[src1].
+const FillLikeVector uint8 = 0x01
+
+// Modifier for OpSample. When set, the int64 at constants[imm8+1] seeds the
+// instruction's own RNG instead of drawing from the VM's (see VM.SetSeed).
+const SampleSeeded uint8 = 0x01
+
+// Flags for OpRankF's imm8. The low bits select how ties are ranked and
+// RankDescending ranks the largest value first.
+const (
+	RankAverage    uint8 = 0x00 // ties share the mean of their positions
+	RankMin        uint8 = 0x01 // ties share their lowest position (1, 2, 2, 4)
+	RankDense      uint8 = 0x02 // ties share a rank with no gaps (1, 2, 2, 3)
+	RankDescending uint8 = 0x80
+)
+
+// Operand kinds for the imm8 of OpAssertEq and OpPrint and the modifier of
+// OpSetResult. Frames and int scalars both live in R registers, so the kind
+// tells them apart.
+const (
+	RegFrame  uint8 = 0x00 // R registers referencing frames
+	RegInt    uint8 = 0x01 // R registers holding int64 scalars
+	RegFloat  uint8 = 0x02 // F registers
+	RegVector uint8 = 0x03 // V registers
 )
 
 // String returns the string representation of an opcode.
@@ -126,6 +255,20 @@ func (o Opcode) String() string {
 		return "LOAD_JSON"
 	case OpLoadParquet:
 		return "LOAD_PARQUET"
+	case OpLoadCSVOpts:
+		return "LOAD_CSV_OPTS"
+	case OpLoadJSONL:
+		return "LOAD_JSONL"
+	case OpLoadHTTP:
+		return "LOAD_HTTP"
+	case OpFillI:
+		return "FILL_I"
+	case OpFillF:
+		return "FILL_F"
+	case OpFillStr:
+		return "FILL_STR"
+	case OpRange:
+		return "RANGE"
 
 	// Vector Arithmetic
 	case OpVecAddI:
@@ -146,10 +289,24 @@ func (o Opcode) String() string {
 		return "VEC_MUL_F"
 	case OpVecDivF:
 		return "VEC_DIV_F"
+	case OpVecMinI:
+		return "VEC_MIN_I"
+	case OpVecMaxI:
+		return "VEC_MAX_I"
+	case OpVecMinF:
+		return "VEC_MIN_F"
+	case OpVecMaxF:
+		return "VEC_MAX_F"
+	case OpClampF:
+		return "CLAMP_F"
+	case OpVecNegF:
+		return "VEC_NEG_F"
 
 	// Comparison
 	case OpCmpEQ:
 		return "CMP_EQ"
+	case OpCmpEqEpsF:
+		return "CMP_EQ_EPS_F"
 	case OpCmpNE:
 		return "CMP_NE"
 	case OpCmpLT:
@@ -160,6 +317,8 @@ func (o Opcode) String() string {
 		return "CMP_GT"
 	case OpCmpGE:
 		return "CMP_GE"
+	case OpInSet:
+		return "IN_SET"
 
 	// Logical
 	case OpAnd:
@@ -168,6 +327,10 @@ func (o Opcode) String() string {
 		return "OR"
 	case OpNot:
 		return "NOT"
+	case OpXor:
+		return "XOR"
+	case OpMaskedSelect:
+		return "MASKED_SELECT"
 
 	// Filtering
 	case OpFilter:
@@ -192,6 +355,22 @@ func (o Opcode) String() string {
 		return "REDUCE_MAX_F"
 	case OpReduceMean:
 		return "REDUCE_MEAN"
+	case OpReduceAny:
+		return "REDUCE_ANY"
+	case OpReduceAll:
+		return "REDUCE_ALL"
+	case OpReduceQuantileF:
+		return "REDUCE_QUANTILE_F"
+	case OpCorrF:
+		return "CORR_F"
+	case OpCovF:
+		return "COV_F"
+	case OpReduceWMeanF:
+		return "REDUCE_WMEAN_F"
+	case OpReduceProd:
+		return "REDUCE_PROD"
+	case OpReduceProdF:
+		return "REDUCE_PROD_F"
 
 	// Scalar Operations
 	case OpMoveR:
@@ -206,6 +385,16 @@ func (o Opcode) String() string {
 		return "MUL_R"
 	case OpDivR:
 		return "DIV_R"
+	case OpVecIndex:
+		return "VEC_INDEX"
+	case OpVecIndexF:
+		return "VEC_INDEX_F"
+	case OpNegI:
+		return "NEG_I"
+	case OpNegF:
+		return "NEG_F"
+	case OpLoadConstStr:
+		return "LOAD_CONST_STR"
 
 	// Frame Operations
 	case OpNewFrame:
@@ -216,6 +405,14 @@ func (o Opcode) String() string {
 		return "COL_COUNT"
 	case OpRowCount:
 		return "ROW_COUNT"
+	case OpRowIndex:
+		return "ROW_INDEX"
+	case OpTopN:
+		return "TOP_N"
+	case OpSample:
+		return "SAMPLE"
+	case OpShuffle:
+		return "SHUFFLE"
 
 	// GroupBy Operations
 	case OpGroupBy:
@@ -238,6 +435,18 @@ func (o Opcode) String() string {
 		return "GROUP_MEAN"
 	case OpGroupKeys:
 		return "GROUP_KEYS"
+	case OpGroupFirst:
+		return "GROUP_FIRST"
+	case OpGroupLast:
+		return "GROUP_LAST"
+	case OpGroupMedianF:
+		return "GROUP_MEDIAN_F"
+	case OpGroupConcat:
+		return "GROUP_CONCAT"
+	case OpGroupQuantileF:
+		return "GROUP_QUANTILE_F"
+	case OpGroupSizes:
+		return "GROUP_SIZES"
 
 	// Join Operations
 	case OpJoinInner:
@@ -248,6 +457,10 @@ func (o Opcode) String() string {
 		return "JOIN_RIGHT"
 	case OpJoinOuter:
 		return "JOIN_OUTER"
+	case OpJoinCross:
+		return "JOIN_CROSS"
+	case OpJoinInnerSorted:
+		return "JOIN_INNER_SORTED"
 
 	// String Operations
 	case OpStrLen:
@@ -270,10 +483,80 @@ func (o Opcode) String() string {
 		return "STR_SPLIT"
 	case OpStrReplace:
 		return "STR_REPLACE"
+	case OpStrContainsCI:
+		return "STR_CONTAINS_CI"
+	case OpStrRegexMatch:
+		return "STR_REGEX_MATCH"
+	case OpStrRegexExtract:
+		return "STR_REGEX_EXTRACT"
+	case OpStrSubstr:
+		return "STR_SUBSTR"
+	case OpStrPadLeft:
+		return "STR_PAD_LEFT"
+	case OpStrPadRight:
+		return "STR_PAD_RIGHT"
+	case OpShift:
+		return "SHIFT"
+	case OpBinF:
+		return "BIN_F"
+	case OpRankF:
+		return "RANK_F"
+	case OpFillNa:
+		return "FILL_NA"
+	case OpDropNa:
+		return "DROP_NA"
+	case OpIsNull:
+		return "IS_NULL"
+	case OpIsNotNull:
+		return "IS_NOT_NULL"
+	case OpIsNaNF:
+		return "IS_NAN_F"
+	case OpIsInfF:
+		return "IS_INF_F"
+	case OpCoalesce:
+		return "COALESCE"
+	case OpHashStr:
+		return "HASH_STR"
+	case OpParseDate:
+		return "PARSE_DATE"
+	case OpDatePart:
+		return "DATE_PART"
+	case OpDateAddDays:
+		return "DATE_ADD_DAYS"
+	case OpDateDiffDays:
+		return "DATE_DIFF_DAYS"
+	case OpPivot:
+		return "PIVOT"
+	case OpConcat:
+		return "CONCAT"
+	case OpFormatF:
+		return "FORMAT_F"
+	case OpStrTitle:
+		return "STR_TITLE"
+	case OpStrCapitalize:
+		return "STR_CAPITALIZE"
+	case OpStrTrimLeft:
+		return "STR_TRIM_LEFT"
+	case OpStrTrimRight:
+		return "STR_TRIM_RIGHT"
+	case OpStrTrimChars:
+		return "STR_TRIM_CHARS"
 
 	// Control Flow
 	case OpNop:
 		return "NOP"
+	case OpAssertEq:
+		return "ASSERT_EQ"
+	case OpPrint:
+		return "PRINT"
+	case OpSetResult:
+		return "SET_RESULT"
+	case OpHaltStr:
+		return "HALT_STR"
+	case OpWide:
+		return "WIDE"
+	case OpHaltFrame:
+		return "HALT_FRAME"
 	case OpHaltV:
 		return "HALT_V"
 	case OpHalt:
@@ -308,6 +591,20 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpLoadJSON, true
 	case "LOAD_PARQUET":
 		return OpLoadParquet, true
+	case "LOAD_CSV_OPTS":
+		return OpLoadCSVOpts, true
+	case "LOAD_JSONL":
+		return OpLoadJSONL, true
+	case "LOAD_HTTP":
+		return OpLoadHTTP, true
+	case "FILL_I":
+		return OpFillI, true
+	case "FILL_F":
+		return OpFillF, true
+	case "FILL_STR":
+		return OpFillStr, true
+	case "RANGE":
+		return OpRange, true
 
 	// Vector Arithmetic
 	case "VEC_ADD_I":
@@ -328,10 +625,24 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpVecMulF, true
 	case "VEC_DIV_F":
 		return OpVecDivF, true
+	case "VEC_MIN_I":
+		return OpVecMinI, true
+	case "VEC_MAX_I":
+		return OpVecMaxI, true
+	case "VEC_MIN_F":
+		return OpVecMinF, true
+	case "VEC_MAX_F":
+		return OpVecMaxF, true
+	case "CLAMP_F":
+		return OpClampF, true
+	case "VEC_NEG_F":
+		return OpVecNegF, true
 
 	// Comparison
 	case "CMP_EQ":
 		return OpCmpEQ, true
+	case "CMP_EQ_EPS_F":
+		return OpCmpEqEpsF, true
 	case "CMP_NE":
 		return OpCmpNE, true
 	case "CMP_LT":
@@ -342,6 +653,8 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpCmpGT, true
 	case "CMP_GE":
 		return OpCmpGE, true
+	case "IN_SET":
+		return OpInSet, true
 
 	// Logical
 	case "AND":
@@ -350,6 +663,10 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpOr, true
 	case "NOT":
 		return OpNot, true
+	case "XOR":
+		return OpXor, true
+	case "MASKED_SELECT":
+		return OpMaskedSelect, true
 
 	// Filtering
 	case "FILTER":
@@ -374,6 +691,22 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpReduceMaxF, true
 	case "REDUCE_MEAN":
 		return OpReduceMean, true
+	case "REDUCE_ANY":
+		return OpReduceAny, true
+	case "REDUCE_ALL":
+		return OpReduceAll, true
+	case "REDUCE_QUANTILE_F":
+		return OpReduceQuantileF, true
+	case "CORR_F":
+		return OpCorrF, true
+	case "COV_F":
+		return OpCovF, true
+	case "REDUCE_WMEAN_F":
+		return OpReduceWMeanF, true
+	case "REDUCE_PROD":
+		return OpReduceProd, true
+	case "REDUCE_PROD_F":
+		return OpReduceProdF, true
 
 	// Scalar Operations
 	case "MOVE_R":
@@ -388,6 +721,16 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpMulR, true
 	case "DIV_R":
 		return OpDivR, true
+	case "VEC_INDEX":
+		return OpVecIndex, true
+	case "VEC_INDEX_F":
+		return OpVecIndexF, true
+	case "NEG_I":
+		return OpNegI, true
+	case "NEG_F":
+		return OpNegF, true
+	case "LOAD_CONST_STR":
+		return OpLoadConstStr, true
 
 	// Frame Operations
 	case "NEW_FRAME":
@@ -398,6 +741,14 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpColCount, true
 	case "ROW_COUNT":
 		return OpRowCount, true
+	case "ROW_INDEX":
+		return OpRowIndex, true
+	case "TOP_N":
+		return OpTopN, true
+	case "SAMPLE":
+		return OpSample, true
+	case "SHUFFLE":
+		return OpShuffle, true
 
 	// GroupBy Operations
 	case "GROUP_BY":
@@ -420,6 +771,18 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpGroupMean, true
 	case "GROUP_KEYS":
 		return OpGroupKeys, true
+	case "GROUP_FIRST":
+		return OpGroupFirst, true
+	case "GROUP_LAST":
+		return OpGroupLast, true
+	case "GROUP_MEDIAN_F":
+		return OpGroupMedianF, true
+	case "GROUP_CONCAT":
+		return OpGroupConcat, true
+	case "GROUP_QUANTILE_F":
+		return OpGroupQuantileF, true
+	case "GROUP_SIZES":
+		return OpGroupSizes, true
 
 	// Join Operations
 	case "JOIN_INNER":
@@ -430,6 +793,10 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpJoinRight, true
 	case "JOIN_OUTER":
 		return OpJoinOuter, true
+	case "JOIN_CROSS":
+		return OpJoinCross, true
+	case "JOIN_INNER_SORTED":
+		return OpJoinInnerSorted, true
 
 	// String Operations
 	case "STR_LEN":
@@ -452,10 +819,80 @@ func OpcodeFromString(s string) (Opcode, bool) {
 		return OpStrSplit, true
 	case "STR_REPLACE":
 		return OpStrReplace, true
+	case "STR_CONTAINS_CI":
+		return OpStrContainsCI, true
+	case "STR_REGEX_MATCH":
+		return OpStrRegexMatch, true
+	case "STR_REGEX_EXTRACT":
+		return OpStrRegexExtract, true
+	case "STR_SUBSTR":
+		return OpStrSubstr, true
+	case "STR_PAD_LEFT":
+		return OpStrPadLeft, true
+	case "STR_PAD_RIGHT":
+		return OpStrPadRight, true
+	case "SHIFT":
+		return OpShift, true
+	case "BIN_F":
+		return OpBinF, true
+	case "RANK_F":
+		return OpRankF, true
+	case "FILL_NA":
+		return OpFillNa, true
+	case "DROP_NA":
+		return OpDropNa, true
+	case "IS_NULL":
+		return OpIsNull, true
+	case "IS_NOT_NULL":
+		return OpIsNotNull, true
+	case "IS_NAN_F":
+		return OpIsNaNF, true
+	case "IS_INF_F":
+		return OpIsInfF, true
+	case "COALESCE":
+		return OpCoalesce, true
+	case "HASH_STR":
+		return OpHashStr, true
+	case "PARSE_DATE":
+		return OpParseDate, true
+	case "DATE_PART":
+		return OpDatePart, true
+	case "DATE_ADD_DAYS":
+		return OpDateAddDays, true
+	case "DATE_DIFF_DAYS":
+		return OpDateDiffDays, true
+	case "PIVOT":
+		return OpPivot, true
+	case "CONCAT":
+		return OpConcat, true
+	case "FORMAT_F":
+		return OpFormatF, true
+	case "STR_TITLE":
+		return OpStrTitle, true
+	case "STR_CAPITALIZE":
+		return OpStrCapitalize, true
+	case "STR_TRIM_LEFT":
+		return OpStrTrimLeft, true
+	case "STR_TRIM_RIGHT":
+		return OpStrTrimRight, true
+	case "STR_TRIM_CHARS":
+		return OpStrTrimChars, true
 
 	// Control Flow
 	case "NOP":
 		return OpNop, true
+	case "ASSERT_EQ":
+		return OpAssertEq, true
+	case "PRINT":
+		return OpPrint, true
+	case "SET_RESULT":
+		return OpSetResult, true
+	case "HALT_STR":
+		return OpHaltStr, true
+	case "WIDE":
+		return OpWide, true
+	case "HALT_FRAME":
+		return OpHaltFrame, true
 	case "HALT_V":
 		return OpHaltV, true
 	case "HALT":
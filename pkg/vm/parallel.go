@@ -0,0 +1,112 @@
+package vm
+
+import "sync"
+
+// parallelThreshold is the minimum vector length at which element-wise
+// operations are split across workers. Below it the goroutine overhead
+// outweighs the gain.
+const parallelThreshold = 64 * 1024
+
+// cancelCheckRows is how many rows a vector operation processes between
+// checks of the VM's context, so that one huge arithmetic op, comparison,
+// filter, string op, reduction or join can still be cancelled part way
+// through.
+const cancelCheckRows = 16 * 1024
+
+// parallelGroupThreshold is the minimum number of groups at which group
+// aggregates are split across workers.
+const parallelGroupThreshold = 1024
+
+// SetParallelism sets the number of workers used for element-wise vector
+// operations on vectors of at least parallelThreshold rows, and for group
+// aggregates over at least parallelGroupThreshold groups. Values below 2 (the
+// default) keep execution serial. Results are identical either way since every
+// row, and every group, is computed independently.
+func (vm *VM) SetParallelism(n int) {
+	vm.parallelism = n
+}
+
+// forEachChunk calls fn over [0, length) in contiguous, non-overlapping
+// chunks, concurrently when parallelism is enabled and the vector is large
+// enough. If several chunks fail, the error from the lowest chunk is returned
+// so errors match the serial path. The VM's context is checked every
+// cancelCheckRows rows, and its error returned once it is done.
+func (vm *VM) forEachChunk(length int, fn func(lo, hi int) error) error {
+	return vm.splitChunks(length, parallelThreshold, vm.cancellable(fn))
+}
+
+// cancellable wraps fn so that each call works through its range
+// cancelCheckRows rows at a time, stopping with the context's error if the
+// VM's context is done before a step.
+func (vm *VM) cancellable(fn func(lo, hi int) error) func(lo, hi int) error {
+	if vm.ctx == nil {
+		return fn
+	}
+	return func(lo, hi int) error {
+		for lo < hi {
+			if err := vm.ctx.Err(); err != nil {
+				return err
+			}
+			end := min(lo+cancelCheckRows, hi)
+			if err := fn(lo, end); err != nil {
+				return err
+			}
+			lo = end
+		}
+		return nil
+	}
+}
+
+// checkContext returns the VM's context error once it is done, consulting
+// the context only when row is a multiple of cancelCheckRows. Loops that
+// must run rows in order, such as reductions and joins, call it once per row
+// instead of going through forEachChunk.
+func (vm *VM) checkContext(row int) error {
+	if vm.ctx == nil || row%cancelCheckRows != 0 {
+		return nil
+	}
+	return vm.ctx.Err()
+}
+
+// forEachGroupChunk calls fn over contiguous ranges of indices into
+// gb.KeyOrder, concurrently when parallelism is enabled and there are at least
+// parallelGroupThreshold groups. Each group is still aggregated by a single
+// worker in row order, so results match the serial path exactly.
+func (vm *VM) forEachGroupChunk(gb *GroupByResult, fn func(lo, hi int)) {
+	vm.splitChunks(len(gb.KeyOrder), parallelGroupThreshold, func(lo, hi int) error {
+		fn(lo, hi)
+		return nil
+	})
+}
+
+// splitChunks is forEachChunk with an explicit serial cutoff.
+func (vm *VM) splitChunks(length, threshold int, fn func(lo, hi int) error) error {
+	workers := vm.parallelism
+	if workers < 2 || length < threshold {
+		return fn(0, length)
+	}
+
+	chunk := (length + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo := w * chunk
+		if lo >= length {
+			break
+		}
+		hi := min(lo+chunk, length)
+		wg.Add(1)
+		go func(w, lo, hi int) {
+			defer wg.Done()
+			errs[w] = fn(lo, hi)
+		}(w, lo, hi)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
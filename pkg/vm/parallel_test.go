@@ -0,0 +1,190 @@
+package vm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func parallelTestColumns(n int) (dataframe.Series, dataframe.Series) {
+	a := make([]float64, n)
+	b := make([]float64, n)
+	for i := range a {
+		a[i] = float64(i)*1.1 + 0.3
+		b[i] = float64(i%97) - 3.7
+	}
+	return newFloat64Series("a", a), newFloat64Series("b", b)
+}
+
+func TestVM_Parallelism_MatchesSerial(t *testing.T) {
+	n := parallelThreshold*3 + 17 // Uneven chunks
+	a, b := parallelTestColumns(n)
+
+	ops := []struct {
+		name string
+		op   func(vm *VM) (dataframe.Series, error)
+	}{
+		{"add", func(vm *VM) (dataframe.Series, error) { return vm.vectorAddFloat64(a, b) }},
+		{"sub", func(vm *VM) (dataframe.Series, error) { return vm.vectorSubFloat64(a, b) }},
+		{"mul", func(vm *VM) (dataframe.Series, error) { return vm.vectorMulFloat64(a, b) }},
+		{"div", func(vm *VM) (dataframe.Series, error) { return vm.vectorDivFloat64(a, b) }},
+	}
+
+	serial := NewVM()
+	parallel := NewVM()
+	parallel.SetParallelism(4)
+
+	for _, tt := range ops {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tt.op(serial)
+			if err != nil {
+				t.Fatalf("serial failed: %v", err)
+			}
+			got, err := tt.op(parallel)
+			if err != nil {
+				t.Fatalf("parallel failed: %v", err)
+			}
+
+			wantVals := want.(*dataframe.SeriesFloat64).Values
+			gotVals := got.(*dataframe.SeriesFloat64).Values
+			if len(gotVals) != n {
+				t.Fatalf("expected %d rows, got %d", n, len(gotVals))
+			}
+			var wantSum, gotSum float64
+			for i := range wantVals {
+				if math.Float64bits(gotVals[i]) != math.Float64bits(wantVals[i]) {
+					t.Fatalf("row %d: expected %v, got %v", i, wantVals[i], gotVals[i])
+				}
+				wantSum += wantVals[i]
+				gotSum += gotVals[i]
+			}
+			if math.Float64bits(gotSum) != math.Float64bits(wantSum) {
+				t.Errorf("expected sum %v, got %v", wantSum, gotSum)
+			}
+		})
+	}
+}
+
+func TestVM_Parallelism_FirstError(t *testing.T) {
+	n := parallelThreshold * 4
+	a := make([]int64, n)
+	b := make([]int64, n)
+	for i := range a {
+		a[i], b[i] = 1, 1
+	}
+	// Overflows land in two different chunks; the lower row must be reported
+	a[n-10], a[n/4+5] = math.MaxInt64, math.MaxInt64
+
+	vm := NewVM()
+	vm.SetParallelism(4)
+	vm.SetCheckedIntArith(true)
+	_, err := vm.vectorAddInt64(newInt64Series("a", a), newInt64Series("b", b))
+	if !errors.Is(err, ErrIntegerOverflow) {
+		t.Fatalf("expected ErrIntegerOverflow, got %v", err)
+	}
+
+	serial := NewVM()
+	serial.SetCheckedIntArith(true)
+	_, want := serial.vectorAddInt64(newInt64Series("a", a), newInt64Series("b", b))
+	if err.Error() != want.Error() {
+		t.Errorf("expected %q, got %q", want, err)
+	}
+}
+
+func BenchmarkVecAddF_1M(b *testing.B) {
+	x, y := parallelTestColumns(1_000_000)
+	for _, workers := range []int{1, 4} {
+		vm := NewVM()
+		vm.SetParallelism(workers)
+		name := "serial"
+		if workers > 1 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vm.vectorAddFloat64(x, y)
+			}
+		})
+	}
+}
+
+// parallelTestGroups builds a key column with the given number of groups,
+// in a shuffled first-seen order, and an int and a float value column.
+func parallelTestGroups(groups, rowsPerGroup int) (keys, ints, floats dataframe.Series) {
+	n := groups * rowsPerGroup
+	k := make([]int64, n)
+	iv := make([]int64, n)
+	fv := make([]float64, n)
+	for i := 0; i < n; i++ {
+		k[i] = int64((i * 7919) % groups)
+		iv[i] = int64(i%1000) - 300
+		fv[i] = float64(i)*0.1 + 1e-7
+	}
+	return newInt64Series("k", k), newInt64Series("v", iv), newFloat64Series("f", fv)
+}
+
+func TestVM_Parallelism_GroupAggregates(t *testing.T) {
+	groups := parallelGroupThreshold*4 + 3 // Uneven chunks
+	keys, ints, floats := parallelTestGroups(groups, 5)
+
+	aggs := []struct {
+		name string
+		agg  func(vm *VM, gb *GroupByResult) dataframe.Series
+	}{
+		{"sum", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupSum(gb, ints) }},
+		{"sum_f", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupSumF(gb, floats) }},
+		{"mean", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMean(gb, floats) }},
+		{"min", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMin(gb, ints) }},
+		{"max", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMax(gb, ints) }},
+		{"min_f", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMinF(gb, floats) }},
+		{"max_f", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMaxF(gb, floats) }},
+	}
+
+	serial := NewVM()
+	parallel := NewVM()
+	parallel.SetParallelism(4)
+	gb := serial.groupBy(keys)
+	if len(gb.KeyOrder) != groups {
+		t.Fatalf("expected %d groups, got %d", groups, len(gb.KeyOrder))
+	}
+
+	for _, tt := range aggs {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.agg(serial, gb)
+			got := tt.agg(parallel, gb)
+			if got.NRows() != groups {
+				t.Fatalf("expected %d rows, got %d", groups, got.NRows())
+			}
+			for i := 0; i < groups; i++ {
+				w, g := want.Value(i), got.Value(i)
+				if wf, ok := w.(float64); ok {
+					if math.Float64bits(g.(float64)) != math.Float64bits(wf) {
+						t.Fatalf("group %v: expected %v, got %v", gb.KeyOrder[i], w, g)
+					}
+				} else if g != w {
+					t.Fatalf("group %v: expected %v, got %v", gb.KeyOrder[i], w, g)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGroupSum_100KGroups(b *testing.B) {
+	keys, ints, _ := parallelTestGroups(100_000, 10)
+	gb := NewVM().groupBy(keys)
+	for _, workers := range []int{1, 4} {
+		vm := NewVM()
+		vm.SetParallelism(workers)
+		name := "serial"
+		if workers > 1 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vm.groupSum(gb, ints)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"errors"
+	"regexp/syntax"
 	"testing"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
@@ -129,6 +131,205 @@ func TestVM_GroupBy_Mean(t *testing.T) {
 	}
 }
 
+func TestVM_GroupBy_FirstLast(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "A", "B", "A", "B", "A"),
+		dataframe.NewSeriesInt64("amount", nil, 10, 20, 30, 40, 50),
+	)
+
+	tests := []struct {
+		name     string
+		op       Opcode
+		expected []int64
+	}{
+		{"first", OpGroupFirst, []int64{10, 20}},
+		{"last", OpGroupLast, []int64{50, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0), // R0 = frame
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = category
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2), // V1 = amount
+					EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),   // R1 = groupby(R0, V0)
+					EncodeInstruction(tt.op, 0, 2, 1, 1, 0),       // V2 = first/last(V1) per group
+					EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+				},
+				Constants: []any{"data", "category", "amount"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if _, err := vm.Execute(); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			result := vm.registers.V[2]
+			if getSeriesType(result) != TypeInt64 {
+				t.Fatalf("expected int64 series, got type %v", getSeriesType(result))
+			}
+			if getSeriesLength(result) != len(tt.expected) {
+				t.Fatalf("expected %d groups, got %d", len(tt.expected), getSeriesLength(result))
+			}
+			for i, want := range tt.expected {
+				if got, _ := getInt64Value(result, i); got != want {
+					t.Errorf("group %d: expected %d, got %d", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_GroupBy_MedianF(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "A", "B", "A", "B", "A", "B", "B"),
+		dataframe.NewSeriesFloat64("value", nil, 50.0, 60.0, 10.0, 5.0, 30.0, 20.0, 100.0),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),    // R0 = frame
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),    // V0 = category
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),    // V1 = value
+			EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),      // R1 = groupby(R0, V0)
+			EncodeInstruction(OpGroupMedianF, 0, 2, 1, 1, 0), // V2 = median(V1) per group
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "category", "value"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// A: {10, 30, 50} -> 30 (odd count), B: {5, 20, 60, 100} -> (20+60)/2 = 40 (even count)
+	expected := []float64{30.0, 40.0}
+	result := vm.registers.V[2]
+	if getSeriesLength(result) != len(expected) {
+		t.Fatalf("expected %d groups, got %d", len(expected), getSeriesLength(result))
+	}
+	for i, want := range expected {
+		got, ok := getFloat64Value(result, i)
+		if !ok {
+			t.Fatalf("group %d: expected float value", i)
+		}
+		if got < want-0.001 || got > want+0.001 {
+			t.Errorf("group %d: expected %.2f, got %.2f", i, want, got)
+		}
+	}
+}
+
+func TestVM_GroupBy_QuantileF(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "A", "B", "A", "B", "A", "B", "B"),
+		dataframe.NewSeriesFloat64("value", nil, 50.0, 60.0, 10.0, 5.0, 30.0, 20.0, 100.0),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),      // R0 = frame
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),      // V0 = category
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),      // V1 = value
+			EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),        // R1 = groupby(R0, V0)
+			EncodeInstruction(OpGroupQuantileF, 0, 2, 1, 1, 0), // V2 = p90(V1) per group
+			EncodeInstruction(OpGroupQuantileF, 0, 3, 1, 1, 1), // V3 = p50(V1) per group
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants:      []any{"data", "category", "value"},
+		FloatConstants: []float64{0.9, 0.5},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// A: {10, 30, 50}, p90 at position 1.8 -> 30 + 0.8*(50-30) = 46
+	// B: {5, 20, 60, 100}, p90 at position 2.7 -> 60 + 0.7*(100-60) = 88
+	// p50 matches GROUP_MEDIAN_F: A -> 30, B -> 40
+	for reg, expected := range map[int][]float64{2: {46.0, 88.0}, 3: {30.0, 40.0}} {
+		result := vm.registers.V[reg]
+		if getSeriesLength(result) != len(expected) {
+			t.Fatalf("V%d: expected %d groups, got %d", reg, len(expected), getSeriesLength(result))
+		}
+		for i, want := range expected {
+			got, ok := getFloat64Value(result, i)
+			if !ok {
+				t.Fatalf("V%d group %d: expected float value", reg, i)
+			}
+			if got < want-0.001 || got > want+0.001 {
+				t.Errorf("V%d group %d: expected %.2f, got %.2f", reg, i, want, got)
+			}
+		}
+	}
+}
+
+func TestVM_GroupBy_Concat(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "fruit", "veg", "fruit", "nut", "fruit"),
+		dataframe.NewSeriesString("name", nil, "apple", "kale", "pear", "almond", nil),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),   // R0 = frame
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),   // V0 = category
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),   // V1 = name
+			EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),     // R1 = groupby(R0, V0)
+			EncodeInstruction(OpGroupConcat, 0, 2, 1, 1, 3), // V2 = join(V1, ", ") per group
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "category", "name", ", "},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Nil names are skipped; single-member groups yield just that value
+	expected := map[any]string{
+		"fruit": "apple, pear",
+		"veg":   "kale",
+		"nut":   "almond",
+	}
+	gb := vm.groupbys[1]
+	result := vm.registers.V[2]
+	if getSeriesLength(result) != len(gb.KeyOrder) {
+		t.Fatalf("expected %d groups, got %d", len(gb.KeyOrder), getSeriesLength(result))
+	}
+	for i, key := range gb.KeyOrder {
+		if got, _ := getStringValue(result, i); got != expected[key] {
+			t.Errorf("group %v: expected %q, got %q", key, expected[key], got)
+		}
+	}
+}
+
 // ===== String Operation Tests =====
 
 func TestVM_StrLen(t *testing.T) {
@@ -240,6 +441,46 @@ func TestVM_StrLower(t *testing.T) {
 	}
 }
 
+func TestVM_StrTitleCapitalize(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "hello world", "hELLO  wORLD", "élan vital", ""),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpStrTitle, 0, 1, 0, 0, 0),
+			EncodeInstruction(OpStrCapitalize, 0, 2, 0, 0, 0),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "name"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for i, want := range []string{"Hello World", "Hello  World", "Élan Vital", ""} {
+		if got, _ := getStringValue(vm.registers.V[1], i); got != want {
+			t.Errorf("title row %d: expected %q, got %q", i, want, got)
+		}
+	}
+	for i, want := range []string{"Hello world", "HELLO  wORLD", "Élan vital", ""} {
+		if got, _ := getStringValue(vm.registers.V[2], i); got != want {
+			t.Errorf("capitalize row %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
 func TestVM_StrConcat(t *testing.T) {
 	vm := NewVM()
 
@@ -314,6 +555,260 @@ func TestVM_StrContains(t *testing.T) {
 	}
 }
 
+func TestVM_StrContainsCI(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("text", nil, "Hello"),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),     // V0 = text
+			EncodeInstruction(OpStrContainsCI, 0, 1, 0, 0, 2), // V1 = icontains(V0, "hello")
+			EncodeInstruction(OpStrContains, 0, 2, 0, 0, 2),   // V2 = contains(V0, "hello")
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "text", "hello"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got, _ := getBoolValue(vm.registers.V[1], 0); !got {
+		t.Error("expected case-insensitive match of \"Hello\" against \"hello\"")
+	}
+	if got, _ := getBoolValue(vm.registers.V[2], 0); got {
+		t.Error("expected case-sensitive contains to reject \"Hello\" against \"hello\"")
+	}
+}
+
+func TestVM_StrRegexMatch(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("email", nil, "alice@example.com", "not an email", "bob@test.org", nil),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),     // V0 = email
+			EncodeInstruction(OpStrRegexMatch, 0, 1, 0, 0, 2), // V1 = matches(V0, pattern)
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "email", `^[\w.]+@\w+\.\w+$`},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for i, want := range []bool{true, false, true, false} {
+		if got, _ := getBoolValue(vm.registers.V[1], i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_StrRegexMatchInvalidPattern(t *testing.T) {
+	vm := NewVM()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("text", nil, "abc"),
+	)
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpStrRegexMatch, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "text", "(unclosed"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	_, err := vm.Execute()
+	if !errors.Is(err, ErrInvalidPattern) {
+		t.Fatalf("expected ErrInvalidPattern, got %v", err)
+	}
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected wrapped regexp syntax error, got %v", err)
+	}
+}
+
+func TestVM_StrRegexExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{"capture group", `order-(\d+)`, []string{"123", "", "7"}},
+		{"whole match", `\d+`, []string{"123", "", "7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "order-123", "no digits", "order-7"),
+			)
+
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),       // V0 = id
+					EncodeInstruction(OpStrRegexExtract, 0, 1, 0, 0, 2), // V1 = extract(V0, pattern)
+					EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0),
+				},
+				Constants: []any{"data", "id", tt.pattern},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			series := result.(dataframe.Series)
+			for i, want := range tt.expected {
+				if got, _ := getStringValue(series, i); got != want {
+					t.Errorf("row %d: expected %q, got %q", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_StrSubstr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		start    int64
+		length   int64
+		expected string
+	}{
+		{"ascii", "hello", 1, 3, "ell"},
+		{"runes not bytes", "héllo wörld", 1, 4, "éllo"},
+		{"length past end", "hello", 3, 10, "lo"},
+		{"start past end", "hello", 9, 2, ""},
+		{"negative start", "hello", -2, 2, "he"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesString("text", nil, tt.input),
+			)
+
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = text
+					EncodeInstruction(OpStrSubstr, 0, 1, 0, 0, 2), // V1 = substr(V0, start, length)
+					EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0),
+				},
+				Constants: []any{"data", "text", tt.start, tt.length},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if got, _ := getStringValue(result.(dataframe.Series), 0); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestVM_StrPad(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       Opcode
+		input    string
+		width    int64
+		pad      string
+		expected string
+	}{
+		{"left zeros", OpStrPadLeft, "42", 5, "0", "00042"},
+		{"right spaces", OpStrPadRight, "ab", 4, " ", "ab  "},
+		{"already wider", OpStrPadLeft, "123456", 5, "0", "123456"},
+		{"counts runes", OpStrPadRight, "é", 3, ".", "é.."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesString("text", nil, tt.input),
+			)
+
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = text
+					EncodeInstruction(tt.op, 0, 1, 0, 0, 2),       // V1 = pad(V0, width, pad)
+					EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0),
+				},
+				Constants: []any{"data", "text", tt.width, tt.pad},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if got, _ := getStringValue(result.(dataframe.Series), 0); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestVM_StrTrim(t *testing.T) {
 	vm := NewVM()
 
@@ -351,6 +846,53 @@ func TestVM_StrTrim(t *testing.T) {
 	}
 }
 
+func TestVM_StrTrimVariants(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "xxabcxx", "  hi  ", "   "),
+	)
+
+	tests := []struct {
+		name     string
+		op       Opcode
+		expected []string
+	}{
+		{"left", OpStrTrimLeft, []string{"xxabcxx", "hi  ", ""}},
+		{"right", OpStrTrimRight, []string{"xxabcxx", "  hi", ""}},
+		{"chars", OpStrTrimChars, []string{"abc", "  hi  ", "   "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+					EncodeInstruction(tt.op, 0, 1, 0, 0, 2),
+					EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+				},
+				Constants: []any{"data", "name", "x"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if _, err := vm.Execute(); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			for i, want := range tt.expected {
+				got, ok := getStringValue(vm.registers.V[1], i)
+				if !ok || got != want {
+					t.Errorf("row %d: expected %q, got %q", i, want, got)
+				}
+			}
+		})
+	}
+}
+
 // ===== Join Tests =====
 
 func TestVM_JoinInner(t *testing.T) {
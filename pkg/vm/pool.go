@@ -0,0 +1,50 @@
+package vm
+
+// bufferPool recycles the scratch slices that vector operations compute into.
+// Series constructors copy their input, so a scratch buffer is never visible
+// through a register and can be handed to the next operation of the same
+// type and length, even when that operation's source and destination
+// registers alias. The pool belongs to a single VM and is not safe for
+// concurrent use.
+type bufferPool struct {
+	float64s map[int][][]float64 // Keyed by length
+	int64s   map[int][][]int64   // Keyed by length
+}
+
+// getFloat64s returns a float64 buffer of length n. Its contents are
+// unspecified; callers must overwrite every element.
+func (p *bufferPool) getFloat64s(n int) []float64 {
+	if free := p.float64s[n]; len(free) > 0 {
+		buf := free[len(free)-1]
+		p.float64s[n] = free[:len(free)-1]
+		return buf
+	}
+	return make([]float64, n)
+}
+
+// putFloat64s returns a buffer obtained from getFloat64s to the pool.
+func (p *bufferPool) putFloat64s(buf []float64) {
+	if p.float64s == nil {
+		p.float64s = make(map[int][][]float64)
+	}
+	p.float64s[len(buf)] = append(p.float64s[len(buf)], buf)
+}
+
+// getInt64s returns an int64 buffer of length n. Its contents are
+// unspecified; callers must overwrite every element.
+func (p *bufferPool) getInt64s(n int) []int64 {
+	if free := p.int64s[n]; len(free) > 0 {
+		buf := free[len(free)-1]
+		p.int64s[n] = free[:len(free)-1]
+		return buf
+	}
+	return make([]int64, n)
+}
+
+// putInt64s returns a buffer obtained from getInt64s to the pool.
+func (p *bufferPool) putInt64s(buf []int64) {
+	if p.int64s == nil {
+		p.int64s = make(map[int][][]int64)
+	}
+	p.int64s[len(buf)] = append(p.int64s[len(buf)], buf)
+}
@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func TestVM_BufferPool_AliasedRegisters(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("a", nil, 1.0, 2.0, 3.0),
+		dataframe.NewSeriesFloat64("b", nil, 10.0, 20.0, 30.0),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpVecAddF, 0, 0, 0, 1, 0), // V0 = V0 + V1
+			EncodeInstruction(OpVecAddF, 0, 0, 0, 1, 0), // V0 = V0 + V1, reusing the first buffer
+			EncodeInstruction(OpVecMulF, 0, 1, 1, 0, 0), // V1 = V1 * V0
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "a", "b"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	sums := result.(*dataframe.SeriesFloat64).Values
+	products := vm.registers.V[1].(*dataframe.SeriesFloat64).Values
+	for i, want := range []float64{21, 42, 63} {
+		if sums[i] != want {
+			t.Errorf("row %d: expected sum %v, got %v", i, want, sums[i])
+		}
+		if b := 10 * float64(i+1); products[i] != b*want {
+			t.Errorf("row %d: expected product %v, got %v", i, b*want, products[i])
+		}
+	}
+
+	// The source frame must be untouched by in-place-style updates
+	if got, _ := getFloat64Value(frame.Series[0], 0); got != 1 {
+		t.Errorf("expected source column to be unchanged, got %v", got)
+	}
+}
+
+func BenchmarkVecAddF_Pooled(b *testing.B) {
+	x, y := parallelTestColumns(100_000)
+	vm := NewVM()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		vm.vectorAddFloat64(x, y)
+	}
+}
@@ -0,0 +1,122 @@
+package vm
+
+import (
+	"fmt"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// ResultKind identifies the type of value a program returned.
+type ResultKind uint8
+
+const (
+	ResultNone   ResultKind = iota // No value (e.g. HALT_V on an empty register)
+	ResultInt                      // int64 from HALT
+	ResultFloat                    // float64 from HALT_F
+	ResultSeries                   // dataframe.Series from HALT_V
+	ResultFrame                    // *dataframe.DataFrame from HALT_FRAME
+	ResultString                   // string from HALT_STR
+)
+
+// String returns the kind's name.
+func (k ResultKind) String() string {
+	switch k {
+	case ResultNone:
+		return "none"
+	case ResultInt:
+		return "int"
+	case ResultFloat:
+		return "float"
+	case ResultSeries:
+		return "series"
+	case ResultFrame:
+		return "frame"
+	case ResultString:
+		return "string"
+	}
+	return fmt.Sprintf("ResultKind(%d)", uint8(k))
+}
+
+// Result wraps the value returned by a program so callers can use typed
+// accessors instead of a type switch.
+type Result struct {
+	value any
+}
+
+// NewResult wraps a value returned by Execute.
+func NewResult(value any) Result {
+	return Result{value: value}
+}
+
+// Kind reports the type of the wrapped value.
+func (r Result) Kind() ResultKind {
+	switch v := r.value.(type) {
+	case int64:
+		return ResultInt
+	case float64:
+		return ResultFloat
+	case string:
+		return ResultString
+	case dataframe.Series:
+		if v != nil {
+			return ResultSeries
+		}
+	case *dataframe.DataFrame:
+		if v != nil {
+			return ResultFrame
+		}
+	}
+	return ResultNone
+}
+
+// Value returns the wrapped value as Execute would have returned it.
+func (r Result) Value() any {
+	return r.value
+}
+
+// AsInt returns the result of a HALT instruction.
+func (r Result) AsInt() (int64, error) {
+	v, ok := r.value.(int64)
+	if !ok {
+		return 0, r.kindError(ResultInt)
+	}
+	return v, nil
+}
+
+// AsFloat returns the result of a HALT_F instruction.
+func (r Result) AsFloat() (float64, error) {
+	v, ok := r.value.(float64)
+	if !ok {
+		return 0, r.kindError(ResultFloat)
+	}
+	return v, nil
+}
+
+// AsSeries returns the result of a HALT_V instruction.
+func (r Result) AsSeries() (dataframe.Series, error) {
+	if r.Kind() != ResultSeries {
+		return nil, r.kindError(ResultSeries)
+	}
+	return r.value.(dataframe.Series), nil
+}
+
+// AsFrame returns the result of a HALT_FRAME instruction.
+func (r Result) AsFrame() (*dataframe.DataFrame, error) {
+	if r.Kind() != ResultFrame {
+		return nil, r.kindError(ResultFrame)
+	}
+	return r.value.(*dataframe.DataFrame), nil
+}
+
+// AsString returns the result of a HALT_STR instruction.
+func (r Result) AsString() (string, error) {
+	v, ok := r.value.(string)
+	if !ok {
+		return "", r.kindError(ResultString)
+	}
+	return v, nil
+}
+
+func (r Result) kindError(want ResultKind) error {
+	return fmt.Errorf("%w: result is %s, not %s", ErrResultKind, r.Kind(), want)
+}
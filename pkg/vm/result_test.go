@@ -0,0 +1,116 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func TestResult_Accessors(t *testing.T) {
+	series := dataframe.NewSeriesInt64("x", nil, int64(1), int64(2))
+	frame := dataframe.NewDataFrame(series)
+
+	r := NewResult(int64(7))
+	if r.Kind() != ResultInt {
+		t.Errorf("Kind = %s, want int", r.Kind())
+	}
+	if v, err := r.AsInt(); err != nil || v != 7 {
+		t.Errorf("AsInt = %d, %v", v, err)
+	}
+
+	r = NewResult(2.5)
+	if r.Kind() != ResultFloat {
+		t.Errorf("Kind = %s, want float", r.Kind())
+	}
+	if v, err := r.AsFloat(); err != nil || v != 2.5 {
+		t.Errorf("AsFloat = %g, %v", v, err)
+	}
+
+	r = NewResult(dataframe.Series(series))
+	if r.Kind() != ResultSeries {
+		t.Errorf("Kind = %s, want series", r.Kind())
+	}
+	if v, err := r.AsSeries(); err != nil || v != series {
+		t.Errorf("AsSeries = %v, %v", v, err)
+	}
+
+	r = NewResult(frame)
+	if r.Kind() != ResultFrame {
+		t.Errorf("Kind = %s, want frame", r.Kind())
+	}
+	if v, err := r.AsFrame(); err != nil || v != frame {
+		t.Errorf("AsFrame = %v, %v", v, err)
+	}
+	if r.Value() != any(frame) {
+		t.Error("Value did not return the wrapped frame")
+	}
+
+	r = NewResult("ok")
+	if r.Kind() != ResultString {
+		t.Errorf("Kind = %s, want string", r.Kind())
+	}
+	if v, err := r.AsString(); err != nil || v != "ok" {
+		t.Errorf("AsString = %q, %v", v, err)
+	}
+
+	var empty dataframe.Series
+	if k := NewResult(empty).Kind(); k != ResultNone {
+		t.Errorf("nil series Kind = %s, want none", k)
+	}
+}
+
+func TestResult_KindMismatch(t *testing.T) {
+	r := NewResult(int64(1))
+
+	if _, err := r.AsFloat(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsFloat on int: expected ErrResultKind, got %v", err)
+	} else if err.Error() != "result kind mismatch: result is int, not float" {
+		t.Errorf("unexpected message: %v", err)
+	}
+	if _, err := r.AsSeries(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsSeries on int: expected ErrResultKind, got %v", err)
+	}
+	if _, err := r.AsFrame(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsFrame on int: expected ErrResultKind, got %v", err)
+	}
+	if _, err := r.AsString(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsString on int: expected ErrResultKind, got %v", err)
+	}
+	if _, err := NewResult(1.5).AsInt(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsInt on float: expected ErrResultKind, got %v", err)
+	}
+	if _, err := NewResult(nil).AsSeries(); !errors.Is(err, ErrResultKind) {
+		t.Errorf("AsSeries on none: expected ErrResultKind, got %v", err)
+	}
+}
+
+func TestVM_ExecuteResult(t *testing.T) {
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConstF, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		FloatConstants: []float64{3.25},
+	}
+
+	vm := NewVM()
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := vm.ExecuteResult()
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if v, err := result.AsFloat(); err != nil || v != 3.25 {
+		t.Errorf("AsFloat = %g, %v; want 3.25", v, err)
+	}
+
+	vm = NewVM()
+	if err := vm.Load(&Program{Code: []Instruction{EncodeInstruction(OpNop, 0, 0, 0, 0, 0)}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.ExecuteResult(); !errors.Is(err, ErrNoHalt) {
+		t.Errorf("expected ErrNoHalt, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"fmt"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// frameColumns is the column set CheckColumns tracks for one frame.
+type frameColumns struct {
+	name string
+	cols map[string]bool
+}
+
+// CheckColumns statically checks p's column references against frames, the
+// predeclared frames it will run with, without executing it. It follows the
+// frames LOAD_FRAME puts in R registers, including columns added by ADD_COL
+// and the frames TOP_N, SAMPLE and SHUFFLE derive from them, and reports every
+// LOAD_FRAME of an unknown frame (ErrFrameNotFound) and every SELECT_COL of a
+// column its frame lacks (ErrColumnNotFound), each with the index of the
+// offending instruction. Frames from any other source, such as LOAD_CSV or a
+// join, are not checked. It returns nil when nothing is missing.
+func CheckColumns(p *Program, frames map[string]*dataframe.DataFrame) []error {
+	var errs []error
+	constant := func(idx int) string {
+		if idx < len(p.Constants) {
+			s, _ := p.Constants[idx].(string)
+			return s
+		}
+		return ""
+	}
+
+	// Keyed by frame name so ADD_COL is seen through every register that
+	// holds the same predeclared frame
+	byName := make(map[string]*frameColumns)
+	regs := make(map[uint8]*frameColumns)
+
+	for i, inst := range p.Code {
+		dst, src := inst.Dst(), inst.Src1()
+		switch inst.Opcode() {
+		case OpLoadFrame:
+			name := constant(int(inst.Imm16()))
+			fc, ok := byName[name]
+			if !ok {
+				df, found := frames[name]
+				if !found {
+					errs = append(errs, fmt.Errorf("instruction %d: %w: %s", i, ErrFrameNotFound, name))
+					delete(regs, dst)
+					continue
+				}
+				fc = &frameColumns{name: name, cols: make(map[string]bool)}
+				if df != nil {
+					for _, s := range df.Series {
+						fc.cols[s.Name()] = true
+					}
+				}
+				byName[name] = fc
+			}
+			regs[dst] = fc
+
+		case OpSelectCol:
+			fc := regs[src]
+			if fc == nil {
+				continue
+			}
+			col := constant(int(inst.Imm8()) | wideOffset(p.Code, i))
+			if !fc.cols[col] {
+				errs = append(errs, fmt.Errorf("instruction %d: %w: %s (frame %q)", i, ErrColumnNotFound, col, fc.name))
+			}
+
+		case OpAddCol:
+			if fc := regs[dst]; fc != nil {
+				fc.cols[constant(int(inst.Imm8())|wideOffset(p.Code, i))] = true
+			}
+
+		case OpTopN, OpSample, OpShuffle:
+			fc := regs[src]
+			if fc == nil {
+				delete(regs, dst)
+				continue
+			}
+			derived := &frameColumns{name: fc.name, cols: make(map[string]bool, len(fc.cols))}
+			for col := range fc.cols {
+				derived.cols[col] = true
+			}
+			regs[dst] = derived
+
+		default:
+			if writesFrameRegister(inst.Opcode()) {
+				delete(regs, dst)
+			}
+		}
+	}
+	return errs
+}
+
+// writesFrameRegister reports whether op stores its result in R[dst], the
+// register file CheckColumns tracks frames in, rather than in F or V.
+func writesFrameRegister(op Opcode) bool {
+	switch op {
+	case OpLoadCSV, OpLoadCSVOpts, OpLoadFrame, OpLoadJSON, OpLoadJSONL, OpLoadHTTP, OpLoadParquet,
+		OpLoadConst, OpLoadConstStr,
+		OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax, OpReduceAny, OpReduceAll, OpReduceProd,
+		OpMoveR, OpNegI, OpAddR, OpSubR, OpMulR, OpDivR, OpVecIndex, OpRowCount, OpColCount,
+		OpNewFrame, OpTopN, OpSample, OpShuffle, OpDropNa, OpPivot, OpConcat,
+		OpGroupBy, OpGroupSizes,
+		OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter, OpJoinInnerSorted, OpJoinCross:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func schemaTestFrames() map[string]*dataframe.DataFrame {
+	return map[string]*dataframe.DataFrame{
+		"sales": dataframe.NewDataFrame(
+			dataframe.NewSeriesFloat64("price", nil, 1.0, 2.0),
+			dataframe.NewSeriesInt64("qty", nil, 3, 4),
+		),
+	}
+}
+
+func TestCheckColumns_Missing(t *testing.T) {
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 3),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"sales", "price", "discount", "returns"},
+	}
+
+	errs := CheckColumns(program, schemaTestFrames())
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !errors.Is(errs[0], ErrColumnNotFound) || !strings.Contains(errs[0].Error(), "instruction 2") ||
+		!strings.Contains(errs[0].Error(), "discount") {
+		t.Errorf("expected missing column discount at instruction 2, got %v", errs[0])
+	}
+	if !errors.Is(errs[1], ErrFrameNotFound) || !strings.Contains(errs[1].Error(), "instruction 3") {
+		t.Errorf("expected missing frame at instruction 3, got %v", errs[1])
+	}
+}
+
+func TestCheckColumns_Clean(t *testing.T) {
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 3),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpVecMulF, 0, 2, 0, 0, 0),
+			EncodeInstruction(OpAddCol, 0, 0, 2, 0, 2),
+			EncodeInstruction(OpTopN, 0, 1, 0, 0, 3),
+			EncodeInstruction(OpSelectCol, 0, 3, 1, 0, 2),
+			EncodeInstruction(OpHaltV, 0, 3, 0, 0, 0),
+		},
+		Constants: []any{"sales", "qty", "total", "price", int64(1), "desc"},
+	}
+
+	frames := schemaTestFrames()
+	if errs := CheckColumns(program, frames); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	// The program really does run against these frames
+	vm := NewVM()
+	vm.SetPredeclaredFrames(frames)
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+func TestCheckColumns_UntrackedFrames(t *testing.T) {
+	// R0 is overwritten by LOAD_CSV, whose columns are unknown until run time
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadCSV, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 2),
+			EncodeInstruction(OpSelectCol, 0, 1, 1, 0, 2),
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"sales", "other.csv", "region"},
+	}
+
+	if errs := CheckColumns(program, schemaTestFrames()); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestCheckColumns_Wide(t *testing.T) {
+	constants := []any{"sales"}
+	for i := len(constants); i < 300; i++ {
+		constants = append(constants, fmt.Sprintf("c%d", i))
+	}
+	constants[299] = "qty"
+	constants[298] = "missing"
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpWide, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 299-256),
+			EncodeInstruction(OpWide, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 298-256),
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: constants,
+	}
+
+	errs := CheckColumns(program, schemaTestFrames())
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "instruction 4: column not found: missing") {
+		t.Errorf("expected missing column at instruction 4, got %v", errs)
+	}
+}
+
+func TestWritesFrameRegister_MatchesDisassembly(t *testing.T) {
+	for code := 0; code < 256; code++ {
+		op := Opcode(code)
+		if op.String() == "UNKNOWN" || !writesRegister(op) {
+			continue
+		}
+		text := disassembleInstruction(EncodeInstruction(op, 0, 1, 2, 3, 0), nil, nil)
+		_, operands, _ := strings.Cut(text, " ")
+		wantR := strings.HasPrefix(strings.TrimSpace(operands), "R")
+		if got := writesFrameRegister(op); got != wantR {
+			t.Errorf("%s: writesFrameRegister = %v, disassembly %q", op, got, text)
+		}
+	}
+}
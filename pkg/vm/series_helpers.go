@@ -2,6 +2,8 @@ package vm
 
 import (
 	"context"
+	"math"
+	"reflect"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
@@ -108,6 +110,13 @@ func getFloat64Value(s dataframe.Series, i int) (float64, bool) {
 	if s == nil || i < 0 || i >= s.NRows() {
 		return 0, false
 	}
+	// Fast path: read the backing slice directly to avoid boxing the value
+	if sf, ok := s.(*dataframe.SeriesFloat64); ok {
+		if v := sf.Values[i]; !math.IsNaN(v) {
+			return v, true
+		}
+		return 0, false
+	}
 	v := s.Value(i)
 	if v == nil {
 		return 0, false
@@ -158,21 +167,14 @@ func getBoolValue(s dataframe.Series, i int) (bool, bool) {
 
 // newInt64Series creates a new SeriesInt64 with the given name and data.
 func newInt64Series(name string, data []int64) *dataframe.SeriesInt64 {
-	// Convert []int64 to []interface{} for the constructor
-	vals := make([]interface{}, len(data))
-	for i, v := range data {
-		vals[i] = v
-	}
-	return dataframe.NewSeriesInt64(name, nil, vals...)
+	// The constructor copies a []int64 first argument without boxing
+	return dataframe.NewSeriesInt64(name, &dataframe.SeriesInit{Capacity: len(data)}, data)
 }
 
 // newFloat64Series creates a new SeriesFloat64 with the given name and data.
 func newFloat64Series(name string, data []float64) *dataframe.SeriesFloat64 {
-	vals := make([]interface{}, len(data))
-	for i, v := range data {
-		vals[i] = v
-	}
-	return dataframe.NewSeriesFloat64(name, nil, vals...)
+	// The constructor copies a []float64 first argument without boxing
+	return dataframe.NewSeriesFloat64(name, &dataframe.SeriesInit{Capacity: len(data)}, data)
 }
 
 // newStringSeries creates a new SeriesString with the given name and data.
@@ -230,7 +232,7 @@ func createEmptySeries(s dataframe.Series) dataframe.Series {
 	default:
 		// For bool or other generic types
 		if sg, ok := s.(*dataframe.SeriesGeneric); ok {
-			return dataframe.NewSeriesGeneric(name, sg.Value(0), nil)
+			return dataframe.NewSeriesGeneric(name, genericZero(sg), nil)
 		}
 		return dataframe.NewSeriesGeneric(name, nil, nil)
 	}
@@ -249,13 +251,23 @@ func createSeriesWithValues(s dataframe.Series, vals []interface{}) dataframe.Se
 	default:
 		// For bool or other generic types
 		if sg, ok := s.(*dataframe.SeriesGeneric); ok {
-			concreteType := sg.Value(0)
-			return dataframe.NewSeriesGeneric(name, concreteType, nil, vals...)
+			return dataframe.NewSeriesGeneric(name, genericZero(sg), nil, vals...)
 		}
 		return dataframe.NewSeriesGeneric(name, nil, nil, vals...)
 	}
 }
 
+// genericZero returns the zero value of a generic series' element type, which
+// NewSeriesGeneric requires as its concrete type argument.
+func genericZero(sg *dataframe.SeriesGeneric) interface{} {
+	for i := 0; i < sg.NRows(); i++ {
+		if v := sg.Value(i); v != nil {
+			return reflect.Zero(reflect.TypeOf(v)).Interface()
+		}
+	}
+	return false
+}
+
 // cloneSeries creates a copy of a series.
 func cloneSeries(s dataframe.Series) dataframe.Series {
 	if s == nil {
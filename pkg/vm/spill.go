@@ -0,0 +1,198 @@
+package vm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// defaultSpillThreshold is the row index size above which GROUP_BY spills
+// when a spill directory is set but no threshold is.
+const defaultSpillThreshold = 64 << 20
+
+// spillRecordSize is the size of one (group ordinal, row) record on disk.
+const spillRecordSize = 16
+
+// maxSpillPartitions bounds the number of files a single GROUP_BY writes.
+const maxSpillPartitions = 256
+
+// SetSpillDir enables spilling of large GROUP_BY row indices to temporary
+// files under dir. A spilled group-by keeps only its keys in memory, and each
+// group aggregate loads one partition of the index at a time. Results are
+// identical to the in-memory path. An empty dir (the default) disables
+// spilling.
+func (vm *VM) SetSpillDir(dir string) {
+	vm.spillDir = dir
+}
+
+// SetSpillThreshold sets the estimated GROUP_BY row index size, in bytes,
+// above which it spills once SetSpillDir is set. Values <= 0 use the default
+// of 64 MiB.
+func (vm *VM) SetSpillThreshold(bytes int64) {
+	vm.spillThreshold = bytes
+}
+
+// groupSpill is the on-disk row index of a spilled GROUP_BY. Each file holds
+// the (group ordinal, row) records of the groups whose ordinal modulo
+// len(files) is its position, in row order.
+type groupSpill struct {
+	files []string
+}
+
+// spillLimit returns the effective spill threshold in bytes.
+func (vm *VM) spillLimit() int64 {
+	if vm.spillThreshold > 0 {
+		return vm.spillThreshold
+	}
+	return defaultSpillThreshold
+}
+
+// shouldSpill reports whether a GROUP_BY over rows keys should spill to disk.
+func (vm *VM) shouldSpill(rows int) bool {
+	return vm.spillDir != "" && int64(rows)*8 > vm.spillLimit()
+}
+
+// spillGroupBy is groupBy for key columns whose row index is too large to
+// keep in memory. It assigns group ordinals in first-seen order like groupBy,
+// but writes each row to a partition file instead of the Groups map.
+func (vm *VM) spillGroupBy(keyCol dataframe.Series) (*GroupByResult, error) {
+	if vm.spillTemp == "" {
+		dir, err := os.MkdirTemp(vm.spillDir, "dasm-spill-")
+		if err != nil {
+			return nil, fmt.Errorf("spilling group-by: %w", err)
+		}
+		vm.spillTemp = dir
+	}
+
+	n := getSeriesLength(keyCol)
+	parts := int((int64(n)*spillRecordSize + vm.spillLimit() - 1) / vm.spillLimit())
+	parts = min(max(parts, 2), maxSpillPartitions)
+
+	spill := &groupSpill{files: make([]string, parts)}
+	files := make([]*os.File, parts)
+	writers := make([]*bufio.Writer, parts)
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+	for p := range files {
+		f, err := os.CreateTemp(vm.spillTemp, "groupby-*.bin")
+		if err != nil {
+			return nil, fmt.Errorf("spilling group-by: %w", err)
+		}
+		files[p] = f
+		writers[p] = bufio.NewWriter(f)
+		spill.files[p] = f.Name()
+	}
+
+	ordinals := make(map[any]int)
+	var keyOrder []any
+	var rec [spillRecordSize]byte
+	for i := 0; i < n; i++ {
+		key := keyCol.Value(i)
+		g, ok := ordinals[key]
+		if !ok {
+			g = len(keyOrder)
+			ordinals[key] = g
+			keyOrder = append(keyOrder, key)
+		}
+		binary.LittleEndian.PutUint64(rec[:8], uint64(g))
+		binary.LittleEndian.PutUint64(rec[8:], uint64(i))
+		if _, err := writers[g%parts].Write(rec[:]); err != nil {
+			return nil, fmt.Errorf("spilling group-by: %w", err)
+		}
+	}
+	for p, w := range writers {
+		if err := w.Flush(); err != nil {
+			return nil, fmt.Errorf("spilling group-by: %w", err)
+		}
+		if err := files[p].Close(); err != nil {
+			return nil, fmt.Errorf("spilling group-by: %w", err)
+		}
+		files[p] = nil
+	}
+
+	return &GroupByResult{
+		Keys:      vm.buildKeysSeries(keyCol, keyOrder),
+		KeyOrder:  keyOrder,
+		SourceCol: keyCol,
+		spill:     spill,
+	}, nil
+}
+
+// loadPartition reads partition p of a spilled group-by back into an
+// in-memory GroupByResult covering only that partition's groups, in key
+// order. It also returns each of those groups' ordinal in gb.KeyOrder.
+func (gb *GroupByResult) loadPartition(p int) (*GroupByResult, []int, error) {
+	data, err := os.ReadFile(gb.spill.files[p])
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading spilled group-by: %w", err)
+	}
+
+	rows := make(map[int][]int)
+	var ordinals []int
+	for off := 0; off+spillRecordSize <= len(data); off += spillRecordSize {
+		g := int(binary.LittleEndian.Uint64(data[off:]))
+		if _, ok := rows[g]; !ok {
+			ordinals = append(ordinals, g)
+		}
+		rows[g] = append(rows[g], int(binary.LittleEndian.Uint64(data[off+8:])))
+	}
+	sort.Ints(ordinals)
+
+	part := &GroupByResult{
+		Groups:    make(map[any][]int, len(ordinals)),
+		KeyOrder:  make([]any, len(ordinals)),
+		SourceCol: gb.SourceCol,
+	}
+	for k, g := range ordinals {
+		key := gb.KeyOrder[g]
+		part.KeyOrder[k] = key
+		part.Groups[key] = rows[g]
+	}
+	return part, ordinals, nil
+}
+
+// aggregate applies agg to gb. For a spilled group-by it runs agg on one
+// partition at a time and scatters the results back into key order.
+func (vm *VM) aggregate(gb *GroupByResult, agg func(*GroupByResult) dataframe.Series) (dataframe.Series, error) {
+	if gb == nil || gb.spill == nil {
+		return agg(gb), nil
+	}
+
+	vals := make([]interface{}, len(gb.KeyOrder))
+	var proto dataframe.Series
+	for p := range gb.spill.files {
+		part, ordinals, err := gb.loadPartition(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(ordinals) == 0 {
+			continue
+		}
+		result := agg(part)
+		for k, g := range ordinals {
+			vals[g] = result.Value(k)
+		}
+		proto = result
+	}
+	if proto == nil {
+		return agg(&GroupByResult{SourceCol: gb.SourceCol}), nil
+	}
+	return createSeriesWithValues(proto, vals), nil
+}
+
+// removeSpill deletes the spill files written since the last cleanup.
+func (vm *VM) removeSpill() {
+	if vm.spillTemp != "" {
+		os.RemoveAll(vm.spillTemp)
+		vm.spillTemp = ""
+	}
+}
@@ -0,0 +1,200 @@
+package vm
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// spillTestVM returns a VM that spills every group-by of more than 16 rows
+// into a fresh directory.
+func spillTestVM(t *testing.T) (*VM, string) {
+	t.Helper()
+	dir := t.TempDir()
+	vm := NewVM()
+	vm.SetSpillDir(dir)
+	vm.SetSpillThreshold(128)
+	return vm, dir
+}
+
+func assertSpillDirEmpty(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected spill files to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestVM_SpillGroupBy_MatchesInMemory(t *testing.T) {
+	keys, ints, floats := parallelTestGroups(300, 7)
+	strs := make([]string, ints.NRows())
+	for i := range strs {
+		strs[i] = string(rune('a' + i%26))
+	}
+	strCol := newStringSeries("s", strs)
+
+	aggs := []struct {
+		name string
+		agg  func(vm *VM, gb *GroupByResult) dataframe.Series
+	}{
+		{"count", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupCount(gb) }},
+		{"sum", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupSum(gb, ints) }},
+		{"sum_f", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupSumF(gb, floats) }},
+		{"mean", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMean(gb, floats) }},
+		{"min", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMin(gb, ints) }},
+		{"max_f", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMaxF(gb, floats) }},
+		{"first", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupFirst(gb, strCol) }},
+		{"last", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupLast(gb, ints) }},
+		{"concat", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupConcat(gb, strCol, ",") }},
+		{"median", func(vm *VM, gb *GroupByResult) dataframe.Series { return vm.groupMedianF(gb, floats) }},
+	}
+
+	memory := NewVM()
+	want := memory.groupBy(keys)
+
+	vm, dir := spillTestVM(t)
+	if !vm.shouldSpill(keys.NRows()) {
+		t.Fatal("expected the test threshold to force a spill")
+	}
+	gb, err := vm.spillGroupBy(keys)
+	if err != nil {
+		t.Fatalf("spillGroupBy failed: %v", err)
+	}
+	if gb.Groups != nil || len(gb.spill.files) < 2 {
+		t.Fatalf("expected row index on disk in several partitions, got %d", len(gb.spill.files))
+	}
+	for i := range want.KeyOrder {
+		if gb.KeyOrder[i] != want.KeyOrder[i] || gb.Keys.Value(i) != want.Keys.Value(i) {
+			t.Fatalf("key %d: expected %v, got %v", i, want.KeyOrder[i], gb.KeyOrder[i])
+		}
+	}
+
+	for _, tt := range aggs {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.agg(memory, want)
+			g, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series { return tt.agg(vm, gb) })
+			if err != nil {
+				t.Fatalf("aggregate failed: %v", err)
+			}
+			if g.Name() != w.Name() || g.Type() != w.Type() {
+				t.Fatalf("expected %s %s, got %s %s", w.Name(), w.Type(), g.Name(), g.Type())
+			}
+			if g.NRows() != w.NRows() {
+				t.Fatalf("expected %d rows, got %d", w.NRows(), g.NRows())
+			}
+			for i := 0; i < w.NRows(); i++ {
+				wv, gv := w.Value(i), g.Value(i)
+				if wf, ok := wv.(float64); ok {
+					if math.Float64bits(gv.(float64)) != math.Float64bits(wf) {
+						t.Fatalf("group %d: expected %v, got %v", i, wv, gv)
+					}
+				} else if gv != wv {
+					t.Fatalf("group %d: expected %v, got %v", i, wv, gv)
+				}
+			}
+		})
+	}
+
+	vm.removeSpill()
+	assertSpillDirEmpty(t, dir)
+}
+
+func spillTestProgram(code ...Instruction) *Program {
+	prefix := []Instruction{
+		EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+		EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+		EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+		EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),
+	}
+	return &Program{
+		Code:      append(prefix, code...),
+		Constants: []any{"data", "k", "v"},
+	}
+}
+
+func spillTestFrame() *dataframe.DataFrame {
+	keys, ints, _ := parallelTestGroups(50, 4)
+	return dataframe.NewDataFrame(keys, ints)
+}
+
+func TestVM_SpillGroupBy_Execute(t *testing.T) {
+	frame := spillTestFrame()
+	program := spillTestProgram(
+		EncodeInstruction(OpGroupSum, 0, 2, 1, 1, 0),
+		EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+	)
+
+	run := func(vm *VM) dataframe.Series {
+		t.Helper()
+		vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		result, err := vm.Execute()
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return result.(dataframe.Series)
+	}
+
+	want := run(NewVM())
+	vm, dir := spillTestVM(t)
+	got := run(vm)
+	if vm.groupbys[1].spill == nil {
+		t.Fatal("expected GROUP_BY to spill")
+	}
+	for i := 0; i < want.NRows(); i++ {
+		if got.Value(i) != want.Value(i) {
+			t.Errorf("group %d: expected %v, got %v", i, want.Value(i), got.Value(i))
+		}
+	}
+	assertSpillDirEmpty(t, dir)
+}
+
+func TestVM_SpillGroupBy_Resume(t *testing.T) {
+	vm, dir := spillTestVM(t)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": spillTestFrame()})
+	program := spillTestProgram(
+		EncodeInstruction(OpGroupCount, 0, 2, 1, 0, 0),
+		EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+		EncodeInstruction(OpGroupSum, 0, 3, 1, 1, 0),
+		EncodeInstruction(OpHaltV, 0, 3, 0, 0, 0),
+	)
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// The group-by is still needed after the first HALT
+	if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+		t.Fatal("expected spill files to survive a resumable HALT")
+	}
+	result, err := vm.Resume()
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if n := result.(dataframe.Series).NRows(); n != 50 {
+		t.Errorf("expected 50 groups, got %d", n)
+	}
+	assertSpillDirEmpty(t, dir)
+}
+
+func TestVM_SpillGroupBy_BadDir(t *testing.T) {
+	vm := NewVM()
+	vm.SetSpillDir(os.DevNull + "/missing")
+	vm.SetSpillThreshold(1)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": spillTestFrame()})
+	if err := vm.Load(spillTestProgram(EncodeInstruction(OpHalt, 0, 1, 0, 0, 0))); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err == nil {
+		t.Error("expected error when the spill directory cannot be used")
+	}
+}
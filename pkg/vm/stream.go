@@ -0,0 +1,178 @@
+package vm
+
+import (
+	"fmt"
+	"maps"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+// BatchSource calls fn with successive frames, stopping at and returning the
+// first error fn returns. loader.StreamCSV fits with a closure:
+//
+//	func(fn func(*dataframe.DataFrame) error) error {
+//		return loader.StreamCSV(path, 10000, fn)
+//	}
+type BatchSource func(fn func(*dataframe.DataFrame) error) error
+
+// ExecuteStreaming runs a reduction program once per batch and combines the
+// partial results, so data larger than memory can be aggregated. The program
+// must load a single frame name with LOAD_FRAME, which is bound to each batch
+// in turn, and must end with REDUCE_SUM, REDUCE_SUM_F, REDUCE_COUNT,
+// REDUCE_MIN, REDUCE_MAX, REDUCE_MIN_F, REDUCE_MAX_F or REDUCE_MEAN followed
+// by a HALT of its result. Other programs fail with ErrNotStreamable.
+//
+// Sums and counts are added, minimums and maximums ignore empty batches and
+// means are weighted by each batch's non-null count. Resource limits apply
+// to each batch separately.
+func (vm *VM) ExecuteStreaming(program *Program, batches BatchSource) (any, error) {
+	reduce, err := streamReduction(program)
+	if err != nil {
+		return nil, err
+	}
+	name, err := streamFrameName(program)
+	if err != nil {
+		return nil, err
+	}
+
+	predeclared := vm.predeclared
+	defer func() { vm.predeclared = predeclared }()
+	frames := maps.Clone(predeclared)
+	if frames == nil {
+		frames = make(map[string]*dataframe.DataFrame)
+	}
+	vm.predeclared = frames
+
+	comb := streamCombiner{op: reduce.Opcode()}
+	err = batches(func(df *dataframe.DataFrame) error {
+		frames[name] = df
+		if err := vm.Load(program); err != nil {
+			return err
+		}
+		result, err := vm.Execute()
+		if err != nil {
+			return err
+		}
+		comb.add(result, vm.registers.V[reduce.Src1()])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comb.result(), nil
+}
+
+// streamReduction returns the reduction whose result the program halts with.
+func streamReduction(program *Program) (Instruction, error) {
+	code := program.Code
+	if len(code) < 2 {
+		return 0, fmt.Errorf("%w: expected a reduction followed by HALT", ErrNotStreamable)
+	}
+	reduce, halt := code[len(code)-2], code[len(code)-1]
+
+	var haltOp Opcode
+	switch reduce.Opcode() {
+	case OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax:
+		haltOp = OpHalt
+	case OpReduceSumF, OpReduceMinF, OpReduceMaxF, OpReduceMean:
+		haltOp = OpHaltF
+	default:
+		return 0, fmt.Errorf("%w: %s has no combiner", ErrNotStreamable, reduce.Opcode())
+	}
+	if halt.Opcode() != haltOp || halt.Dst() != reduce.Dst() {
+		return 0, fmt.Errorf("%w: program must halt with the result of %s", ErrNotStreamable, reduce.Opcode())
+	}
+	return reduce, nil
+}
+
+// streamFrameName returns the frame name the program loads with LOAD_FRAME.
+func streamFrameName(program *Program) (string, error) {
+	var name string
+	for _, inst := range program.Code {
+		if inst.Opcode() != OpLoadFrame {
+			continue
+		}
+		idx := int(inst.Imm16())
+		if idx >= len(program.Constants) {
+			return "", fmt.Errorf("%w: constant index %d out of range", ErrInvalidInstruction, idx)
+		}
+		n, ok := program.Constants[idx].(string)
+		if !ok {
+			return "", fmt.Errorf("%w: LOAD_FRAME constant %d is not a string", ErrInvalidInstruction, idx)
+		}
+		if name != "" && n != name {
+			return "", fmt.Errorf("%w: program loads both %q and %q", ErrNotStreamable, name, n)
+		}
+		name = n
+	}
+	if name == "" {
+		return "", fmt.Errorf("%w: program has no LOAD_FRAME", ErrNotStreamable)
+	}
+	return name, nil
+}
+
+// streamCombiner folds per-batch results of one reduction opcode.
+type streamCombiner struct {
+	op     Opcode
+	i      int64
+	f      float64 // running sum for REDUCE_MEAN
+	weight int     // non-null values seen, for REDUCE_MEAN
+	seen   bool    // a non-null value has contributed, for min and max
+}
+
+// add folds one batch's result; src is the vector the batch reduced.
+func (c *streamCombiner) add(result any, src dataframe.Series) {
+	switch c.op {
+	case OpReduceSum, OpReduceCount:
+		c.i += result.(int64)
+	case OpReduceSumF:
+		c.f += result.(float64)
+	case OpReduceMin, OpReduceMax:
+		// Fold the batch's non-null cells rather than its result, which reads
+		// a leading null as 0; all-null batches then contribute nothing
+		for i := 0; i < getSeriesLength(src); i++ {
+			v, ok := getInt64Value(src, i)
+			if !ok {
+				continue
+			}
+			if !c.seen || (c.op == OpReduceMin && v < c.i) || (c.op == OpReduceMax && v > c.i) {
+				c.i = v
+			}
+			c.seen = true
+		}
+	case OpReduceMinF, OpReduceMaxF:
+		for i := 0; i < getSeriesLength(src); i++ {
+			v, ok := getFloat64Value(src, i)
+			if !ok {
+				continue
+			}
+			if !c.seen || (c.op == OpReduceMinF && v < c.f) || (c.op == OpReduceMaxF && v > c.f) {
+				c.f = v
+			}
+			c.seen = true
+		}
+	case OpReduceMean:
+		// Keep the running sum rather than weighting each batch's mean,
+		// which would round differently from a single pass
+		for i := 0; i < getSeriesLength(src); i++ {
+			if v, ok := getFloat64Value(src, i); ok {
+				c.f += v
+				c.weight++
+			}
+		}
+	}
+}
+
+// result returns the combined value, typed like the program's HALT result.
+func (c *streamCombiner) result() any {
+	switch c.op {
+	case OpReduceSum, OpReduceCount, OpReduceMin, OpReduceMax:
+		return c.i
+	case OpReduceMean:
+		if c.weight == 0 {
+			return 0.0
+		}
+		return c.f / float64(c.weight)
+	}
+	return c.f
+}
@@ -0,0 +1,161 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	dataframe "github.com/rocketlaunchr/dataframe-go"
+)
+
+func streamBatches(frames ...*dataframe.DataFrame) BatchSource {
+	return func(fn func(*dataframe.DataFrame) error) error {
+		for _, df := range frames {
+			if err := fn(df); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// reduceProgram loads "data", selects column "x" and halts with op's result.
+func reduceProgram(op, halt Opcode) *Program {
+	return &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(op, 0, 0, 0, 0, 0),
+			EncodeInstruction(halt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "x"},
+	}
+}
+
+func TestVM_ExecuteStreaming_MatchesConcatenation(t *testing.T) {
+	first := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 4, 8, nil, 1))
+	second := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 10, 3))
+	whole := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 4, 8, nil, 1, 10, 3))
+
+	tests := []struct {
+		name string
+		op   Opcode
+		halt Opcode
+	}{
+		{"sum", OpReduceSum, OpHalt},
+		{"sum_f", OpReduceSumF, OpHaltF},
+		{"count", OpReduceCount, OpHalt},
+		{"min", OpReduceMin, OpHalt},
+		{"max", OpReduceMax, OpHalt},
+		{"min_f", OpReduceMinF, OpHaltF},
+		{"max_f", OpReduceMaxF, OpHaltF},
+		{"mean", OpReduceMean, OpHaltF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := reduceProgram(tt.op, tt.halt)
+
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": whole})
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			want, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			got, err := NewVM().ExecuteStreaming(program, streamBatches(first, second))
+			if err != nil {
+				t.Fatalf("ExecuteStreaming failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("streaming result %v (%T), want %v (%T)", got, got, want, want)
+			}
+		})
+	}
+}
+
+func TestVM_ExecuteStreaming_SkipsEmptyBatches(t *testing.T) {
+	empty := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil))
+	data := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 5, 7))
+
+	got, err := NewVM().ExecuteStreaming(reduceProgram(OpReduceMax, OpHalt), streamBatches(empty, data, empty))
+	if err != nil {
+		t.Fatalf("ExecuteStreaming failed: %v", err)
+	}
+	if got != int64(7) {
+		t.Errorf("expected max 7, got %v", got)
+	}
+
+	got, err = NewVM().ExecuteStreaming(reduceProgram(OpReduceSum, OpHalt), streamBatches())
+	if err != nil {
+		t.Fatalf("ExecuteStreaming failed: %v", err)
+	}
+	if got != int64(0) {
+		t.Errorf("expected sum 0 over no batches, got %v", got)
+	}
+}
+
+func TestVM_ExecuteStreaming_SkipsNullBatches(t *testing.T) {
+	nulls := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, nil, nil))
+	leadingNull := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, nil, 9))
+	data := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 5, 7))
+
+	tests := []struct {
+		op   Opcode
+		halt Opcode
+		want any
+	}{
+		{OpReduceMin, OpHalt, int64(5)},
+		{OpReduceMax, OpHalt, int64(9)},
+		{OpReduceMinF, OpHaltF, 5.0},
+		{OpReduceMaxF, OpHaltF, 9.0},
+	}
+	for _, tt := range tests {
+		got, err := NewVM().ExecuteStreaming(reduceProgram(tt.op, tt.halt), streamBatches(nulls, leadingNull, data))
+		if err != nil {
+			t.Fatalf("%s: ExecuteStreaming failed: %v", tt.op, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.op, tt.want, got)
+		}
+	}
+}
+
+func TestVM_ExecuteStreaming_Errors(t *testing.T) {
+	data := dataframe.NewDataFrame(dataframe.NewSeriesInt64("x", nil, 1))
+
+	notStreamable := []*Program{
+		reduceProgram(OpReduceProd, OpHalt),
+		reduceProgram(OpReduceSum, OpHaltF),
+		{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+			},
+			Constants: []any{int64(1)},
+		},
+	}
+	for _, program := range notStreamable {
+		if _, err := NewVM().ExecuteStreaming(program, streamBatches(data)); !errors.Is(err, ErrNotStreamable) {
+			t.Errorf("expected ErrNotStreamable, got %v", err)
+		}
+	}
+
+	stop := errors.New("stop")
+	source := func(fn func(*dataframe.DataFrame) error) error {
+		if err := fn(data); err != nil {
+			return err
+		}
+		return stop
+	}
+	if _, err := NewVM().ExecuteStreaming(reduceProgram(OpReduceSum, OpHalt), source); !errors.Is(err, stop) {
+		t.Errorf("expected source error, got %v", err)
+	}
+
+	missing := dataframe.NewDataFrame(dataframe.NewSeriesInt64("y", nil, 1))
+	if _, err := NewVM().ExecuteStreaming(reduceProgram(OpReduceSum, OpHalt), streamBatches(data, missing)); !errors.Is(err, ErrColumnNotFound) {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
@@ -21,12 +21,21 @@
 package vm
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 
@@ -44,11 +53,22 @@ var (
 	ErrTypeMismatch       = errors.New("type mismatch")
 	ErrDivisionByZero     = errors.New("division by zero")
 	ErrInvalidRegister    = errors.New("invalid register")
+	ErrSchemaMismatch     = errors.New("schema mismatch")
+	ErrInvalidPattern     = errors.New("invalid regex pattern")
+	ErrIntegerOverflow    = errors.New("integer overflow")
+	ErrAssertionFailed    = errors.New("assertion failed")
+	ErrNotStreamable      = errors.New("program cannot be streamed")
+	ErrInvalidRange       = errors.New("invalid range")
+	ErrResultKind         = errors.New("result kind mismatch")
+	ErrIndexOutOfRange    = errors.New("index out of range")
+	ErrNotHalted          = errors.New("program is not stopped at a HALT")
 
 	// Resource limit errors (exported for embed package)
 	ErrInstructionLimit = errors.New("instruction limit exceeded")
 	ErrMemoryLimit      = errors.New("memory limit exceeded")
 	ErrFileAccessDenied = errors.New("file access denied in sandbox mode")
+	ErrURLAccessDenied  = errors.New("URL access denied in sandbox mode")
+	ErrProgramTooLarge  = errors.New("program too large")
 )
 
 // Program represents a compiled DFL program.
@@ -64,18 +84,72 @@ type ExecutionStats struct {
 	ExecutionTimeNs int64          // Execution time in nanoseconds
 	FramesLoaded    int            // Number of frames loaded
 	RowsProcessed   int64          // Approximate rows processed
-	PeakRegisters   int            // Peak number of registers used
+	PeakRegisters   int            // Highest destination register index written, plus one
 	OpCounts        map[string]int // Count of each opcode executed
 }
 
+// OpCount pairs an opcode name with how many times it executed.
+type OpCount struct {
+	Op    string
+	Count int
+}
+
+// Hotspots returns the n most-executed opcodes, most frequent first, with ties
+// broken by name. n <= 0 returns every opcode.
+func (s *ExecutionStats) Hotspots(n int) []OpCount {
+	counts := make([]OpCount, 0, len(s.OpCounts))
+	for op, count := range s.OpCounts {
+		counts = append(counts, OpCount{Op: op, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Op < counts[j].Op
+	})
+	if n > 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// String summarizes the stats on one line, listing the top five opcodes.
+func (s *ExecutionStats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "steps=%d time=%s frames=%d rows=%d peak_registers=%d",
+		s.StepsExecuted, time.Duration(s.ExecutionTimeNs), s.FramesLoaded, s.RowsProcessed, s.PeakRegisters)
+	if hot := s.Hotspots(5); len(hot) > 0 {
+		b.WriteString(" top=")
+		for i, h := range hot {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "%s:%d", h.Op, h.Count)
+		}
+	}
+	return b.String()
+}
+
 // GroupByResult holds the result of a GROUP_BY operation.
 type GroupByResult struct {
 	Keys      dataframe.Series // Unique keys
 	Groups    map[any][]int    // Key -> row indices in original frame
 	KeyOrder  []any            // Order of keys for deterministic iteration
 	SourceCol dataframe.Series // Original key column for type info
+
+	spill *groupSpill // On-disk row indices when Groups was spilled (nil otherwise)
 }
 
+// DivByZeroMode selects what VEC_DIV_F produces when a denominator is zero.
+type DivByZeroMode uint8
+
+const (
+	DivByZeroInf   DivByZeroMode = iota // +Inf (default)
+	DivByZeroNaN                        // NaN
+	DivByZeroError                      // fail with ErrDivisionByZero
+	DivByZeroZero                       // 0
+)
+
 // VM represents the virtual machine.
 type VM struct {
 	registers   RegisterFile
@@ -86,12 +160,16 @@ type VM struct {
 	predeclared map[string]*dataframe.DataFrame // Pre-declared frames for embedding
 	groupbys    map[int]*GroupByResult          // GroupBy results (keyed by register)
 	ip          int                             // Instruction pointer
+	wide        int                             // High bits of the current imm8 constant index, from OpWide
+	halted      bool                            // Execution stopped at the HALT at ip; see Resume
 
 	// Resource limits (Starlark-style)
 	maxSteps  int64
 	stepCount int64
 	maxAlloc  int64
 	// allocCount is reserved for future memory limit tracking
+	maxProgramSize int // Instructions accepted by Load (0 = unlimited)
+	maxConstants   int // Entries per constant pool accepted by Load (0 = unlimited)
 
 	// Context for cancellation
 	ctx context.Context
@@ -99,6 +177,33 @@ type VM struct {
 	// Sandbox mode
 	sandbox      bool
 	allowedPaths []string
+	allowedURLs  []string
+
+	// Serve LOAD_CSV from the loader's frame cache
+	frameCache bool
+
+	// Spilling of large GROUP_BY row indices to disk
+	spillDir       string
+	spillThreshold int64
+	spillTemp      string // Directory holding this execution's spill files
+
+	// Arithmetic behavior
+	divByZeroMode   DivByZeroMode
+	checkedIntArith bool
+	parallelism     int // Workers for element-wise vector ops (< 2 = serial)
+	buffers         bufferPool
+
+	// Destination for PRINT (io.Discard unless set with SetOutput)
+	output io.Writer
+
+	// Named outputs stored by SET_RESULT (nil until the first one)
+	results map[string]any
+
+	// Random source for SAMPLE and SHUFFLE. With seeded set, Load rewinds rng to seed so
+	// every run of a program draws the same rows.
+	rng    *rand.Rand
+	seed   int64
+	seeded bool
 
 	// Observability - execution statistics
 	stats        ExecutionStats
@@ -112,27 +217,71 @@ func NewVM() *VM {
 		frames:      make(map[int]*dataframe.DataFrame),
 		predeclared: make(map[string]*dataframe.DataFrame),
 		groupbys:    make(map[int]*GroupByResult),
+		output:      io.Discard,
 	}
 }
 
-// Load loads a program into the VM.
+// Load loads a program into the VM. Programs exceeding the limits set by
+// SetMaxProgramSize or SetMaxConstants are rejected with ErrProgramTooLarge.
 func (vm *VM) Load(program *Program) error {
+	if vm.maxProgramSize > 0 && len(program.Code) > vm.maxProgramSize {
+		return fmt.Errorf("%w: %d instructions (limit %d)", ErrProgramTooLarge, len(program.Code), vm.maxProgramSize)
+	}
+	if vm.maxConstants > 0 {
+		if len(program.Constants) > vm.maxConstants {
+			return fmt.Errorf("%w: %d constants (limit %d)", ErrProgramTooLarge, len(program.Constants), vm.maxConstants)
+		}
+		if len(program.FloatConstants) > vm.maxConstants {
+			return fmt.Errorf("%w: %d float constants (limit %d)", ErrProgramTooLarge, len(program.FloatConstants), vm.maxConstants)
+		}
+	}
+
 	vm.code = program.Code
 	vm.constants = program.Constants
 	vm.floatConsts = program.FloatConstants
+	vm.Reset()
+	return nil
+}
+
+// Reset returns the VM to the state Load leaves it in, so the loaded program
+// can run again from the start: registers, frames, group-bys, results, the
+// instruction pointer and the step count are cleared, and a seeded random
+// source is reseeded. The program, its constants, predeclared frames and all
+// settings are kept.
+func (vm *VM) Reset() {
 	vm.ip = 0
+	vm.wide = 0
+	vm.halted = false
 	vm.stepCount = 0
+	if vm.seeded {
+		vm.rng = rand.New(rand.NewSource(vm.seed))
+	}
 	vm.registers.Reset()
 	vm.frames = make(map[int]*dataframe.DataFrame)
 	vm.groupbys = make(map[int]*GroupByResult)
-	return nil
+	vm.results = nil
+	vm.removeSpill()
 }
 
-// SetMaxSteps sets the maximum number of execution steps.
+// SetMaxSteps sets the maximum number of execution steps. Most instructions
+// cost one step; joins, group-bys and sorts also cost one step per 1000 input
+// rows.
 func (vm *VM) SetMaxSteps(n int64) {
 	vm.maxSteps = n
 }
 
+// SetMaxProgramSize sets the maximum number of instructions Load accepts.
+// Zero (the default) means unlimited.
+func (vm *VM) SetMaxProgramSize(n int) {
+	vm.maxProgramSize = n
+}
+
+// SetMaxConstants sets the maximum number of entries Load accepts in each of
+// the constant and float constant pools. Zero (the default) means unlimited.
+func (vm *VM) SetMaxConstants(n int) {
+	vm.maxConstants = n
+}
+
 // SetMaxAlloc sets the maximum memory allocation.
 func (vm *VM) SetMaxAlloc(bytes int64) {
 	vm.maxAlloc = bytes
@@ -148,6 +297,48 @@ func (vm *VM) SetMemoryLimit(bytes int64) {
 	vm.maxAlloc = bytes
 }
 
+// SetFloatDivByZeroMode sets how VEC_DIV_F handles a zero denominator.
+func (vm *VM) SetFloatDivByZeroMode(mode DivByZeroMode) {
+	vm.divByZeroMode = mode
+}
+
+// SetCheckedIntArith enables overflow detection in VEC_ADD_I, VEC_SUB_I and
+// VEC_MUL_I. When disabled (the default) results wrap around silently.
+func (vm *VM) SetCheckedIntArith(enabled bool) {
+	vm.checkedIntArith = enabled
+}
+
+// SetFrameCache makes LOAD_CSV reuse frames already parsed from the same
+// unchanged file (see loader.LoadCSVCached). It is disabled by default.
+func (vm *VM) SetFrameCache(enabled bool) {
+	vm.frameCache = enabled
+}
+
+// SetOutput sets the writer PRINT writes to. Output is discarded by default,
+// and a nil writer restores that default.
+func (vm *VM) SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	vm.output = w
+}
+
+// Results returns the named outputs stored by SET_RESULT during the last
+// execution, keyed by name: int64, float64, dataframe.Series or
+// *dataframe.DataFrame values. It is nil if the program stored none, and is
+// cleared by Load.
+func (vm *VM) Results() map[string]any {
+	return vm.results
+}
+
+// SetSeed makes SAMPLE and SHUFFLE reproducible: each program loaded afterwards draws
+// from a fresh RNG seeded with seed. Without it the RNG is seeded from the
+// clock.
+func (vm *VM) SetSeed(seed int64) {
+	vm.seed, vm.seeded = seed, true
+	vm.rng = rand.New(rand.NewSource(seed))
+}
+
 // SetContext sets the context for cancellation/timeout.
 func (vm *VM) SetContext(ctx context.Context) {
 	vm.ctx = ctx
@@ -159,6 +350,11 @@ func (vm *VM) SetSandbox(enabled bool, allowedPaths []string) {
 	vm.allowedPaths = allowedPaths
 }
 
+// SetAllowedURLs sets URL prefixes that can be fetched in sandbox mode.
+func (vm *VM) SetAllowedURLs(urls []string) {
+	vm.allowedURLs = urls
+}
+
 // EnableStats enables execution statistics collection.
 // When enabled, the VM tracks metrics like steps executed, timing, and opcode counts.
 func (vm *VM) EnableStats() {
@@ -192,7 +388,46 @@ func (vm *VM) isPathAllowed(path string) bool {
 	return false
 }
 
-// Execute runs the loaded program and returns the result.
+// urlOptions returns how LOAD_URL fetches: under the VM's context, with the
+// body capped at the memory limit and, in sandbox mode, every redirect target
+// held to the same allow-list as the original URL.
+func (vm *VM) urlOptions() loader.URLOptions {
+	opts := loader.URLOptions{Context: vm.ctx, MaxBytes: vm.maxAlloc}
+	if vm.sandbox {
+		opts.CheckURL = func(url string) error {
+			if !vm.isURLAllowed(url) {
+				return fmt.Errorf("%w: %s", ErrURLAccessDenied, url)
+			}
+			return nil
+		}
+	}
+	return opts
+}
+
+// isURLAllowed checks if a URL is allowed in sandbox mode.
+func (vm *VM) isURLAllowed(url string) bool {
+	for _, allowed := range vm.allowedURLs {
+		prefix := strings.TrimSuffix(allowed, "/")
+		if url == allowed || url == prefix || strings.HasPrefix(url, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteResult runs the loaded program like Execute and wraps its return
+// value in a Result with typed accessors.
+func (vm *VM) ExecuteResult() (Result, error) {
+	value, err := vm.Execute()
+	if err != nil {
+		return Result{}, err
+	}
+	return NewResult(value), nil
+}
+
+// Execute runs the loaded program and returns the result: an int64 from
+// HALT, a float64 from HALT_F, a dataframe.Series from HALT_V or a
+// *dataframe.DataFrame from HALT_FRAME.
 func (vm *VM) Execute() (any, error) {
 	// Start timing if stats enabled
 	var startTime time.Time
@@ -201,7 +436,15 @@ func (vm *VM) Execute() (any, error) {
 		vm.stats.StepsExecuted = 0
 		vm.stats.FramesLoaded = 0
 		vm.stats.RowsProcessed = 0
+		vm.stats.PeakRegisters = 0
 	}
+	vm.halted = false
+	defer func() {
+		// Keep spill files while Resume can still continue the program
+		if !vm.halted || vm.ip+1 >= len(vm.code) {
+			vm.removeSpill()
+		}
+	}()
 
 	for vm.ip < len(vm.code) {
 		// Context cancellation check
@@ -213,20 +456,28 @@ func (vm *VM) Execute() (any, error) {
 			}
 		}
 
-		// Resource limit check
-		vm.stepCount++
-		if vm.maxSteps > 0 && vm.stepCount > vm.maxSteps {
-			return nil, ErrInstructionLimit
-		}
-
 		inst := vm.code[vm.ip]
 		op := inst.Opcode()
 
+		// Resource limit check. Row-heavy opcodes are charged before they
+		// run so an oversized join is rejected without doing the work.
+		vm.stepCount++
+		if vm.maxSteps > 0 {
+			vm.stepCount += vm.extraCost(inst)
+			if vm.stepCount > vm.maxSteps {
+				return nil, ErrInstructionLimit
+			}
+		}
+
 		// Track opcode execution if stats enabled
 		if vm.statsEnabled {
 			vm.stats.StepsExecuted++
 			opName := op.String()
 			vm.stats.OpCounts[opName]++
+			vm.stats.RowsProcessed += int64(vm.inputRows(inst))
+			if writesRegister(op) && int(inst.Dst())+1 > vm.stats.PeakRegisters {
+				vm.stats.PeakRegisters = int(inst.Dst()) + 1
+			}
 		}
 
 		switch op {
@@ -241,7 +492,33 @@ func (vm *VM) Execute() (any, error) {
 				return nil, fmt.Errorf("%w: %s", ErrFileAccessDenied, path)
 			}
 
-			frame, err := loader.LoadCSV(path)
+			load := loader.LoadCSV
+			if vm.frameCache {
+				load = loader.LoadCSVCached
+			}
+			frame, err := load(path)
+			if err != nil {
+				return nil, fmt.Errorf("loading CSV %s: %w", path, err)
+			}
+			vm.frames[int(dst)] = frame
+			vm.registers.R[dst] = int64(dst)
+
+		case OpLoadCSVOpts:
+			dst := inst.Dst()
+			pathIdx := inst.Imm16()
+			path := vm.constants[pathIdx].(string)
+			spec := vm.constants[pathIdx+1].(string)
+
+			// Sandbox check
+			if vm.sandbox && !vm.isPathAllowed(path) {
+				return nil, fmt.Errorf("%w: %s", ErrFileAccessDenied, path)
+			}
+
+			opts, err := loader.ParseCSVOptions(spec)
+			if err != nil {
+				return nil, fmt.Errorf("loading CSV %s: %w", path, err)
+			}
+			frame, err := loader.LoadCSVWithOptions(path, opts)
 			if err != nil {
 				return nil, fmt.Errorf("loading CSV %s: %w", path, err)
 			}
@@ -265,6 +542,23 @@ func (vm *VM) Execute() (any, error) {
 			vm.frames[int(dst)] = frame
 			vm.registers.R[dst] = int64(dst)
 
+		case OpLoadJSONL:
+			dst := inst.Dst()
+			pathIdx := inst.Imm16()
+			path := vm.constants[pathIdx].(string)
+
+			// Sandbox check
+			if vm.sandbox && !vm.isPathAllowed(path) {
+				return nil, fmt.Errorf("%w: %s", ErrFileAccessDenied, path)
+			}
+
+			frame, err := loader.LoadJSONLines(path)
+			if err != nil {
+				return nil, fmt.Errorf("loading JSON lines %s: %w", path, err)
+			}
+			vm.frames[int(dst)] = frame
+			vm.registers.R[dst] = int64(dst)
+
 		case OpLoadParquet:
 			dst := inst.Dst()
 			pathIdx := inst.Imm16()
@@ -282,6 +576,26 @@ func (vm *VM) Execute() (any, error) {
 			vm.frames[int(dst)] = frame
 			vm.registers.R[dst] = int64(dst)
 
+		case OpLoadHTTP:
+			dst := inst.Dst()
+			urlIdx := inst.Imm16()
+			url := vm.constants[urlIdx].(string)
+
+			// Sandbox check
+			if vm.sandbox && !vm.isURLAllowed(url) {
+				return nil, fmt.Errorf("%w: %s", ErrURLAccessDenied, url)
+			}
+
+			frame, err := loader.LoadURLWithOptions(url, vm.urlOptions())
+			if errors.Is(err, loader.ErrResponseTooLarge) {
+				return nil, fmt.Errorf("%w: loading URL %s: %w", ErrMemoryLimit, url, err)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("loading URL %s: %w", url, err)
+			}
+			vm.frames[int(dst)] = frame
+			vm.registers.R[dst] = int64(dst)
+
 		case OpLoadConst:
 			dst := inst.Dst()
 			constIdx := inst.Imm16()
@@ -306,7 +620,7 @@ func (vm *VM) Execute() (any, error) {
 		case OpSelectCol:
 			dst := inst.Dst()
 			frameSrc := inst.Src1()
-			nameIdx := inst.Imm8() // Use Imm8 since Src1 is used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
 			colName := vm.constants[nameIdx].(string)
 			frame := vm.frames[int(vm.registers.R[frameSrc])]
 			col, ok := getDataFrameColumn(frame, colName)
@@ -339,20 +653,44 @@ func (vm *VM) Execute() (any, error) {
 			}
 			vm.registers.V[dst] = newFloat64Series("broadcast", data)
 
+		case OpFillI, OpFillF, OpFillStr:
+			result, err := vm.fill(inst)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[inst.Dst()] = result
+
+		case OpRange:
+			dst, idx := inst.Dst(), vm.imm8Index(inst)
+			result, err := vm.rangeSeries(vm.constants[idx], vm.constants[idx+1], vm.constants[idx+2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
 		// ===== Vector Arithmetic =====
 		case OpVecAddI:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorAddInt64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorAddInt64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpVecSubI:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorSubInt64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorSubInt64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpVecMulI:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorMulInt64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorMulInt64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpVecDivI:
@@ -373,53 +711,129 @@ func (vm *VM) Execute() (any, error) {
 
 		case OpVecAddF:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorAddFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorAddFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpVecSubF:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorSubFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorSubFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpVecMulF:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorMulFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorMulFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
+		case OpVecMinI:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.vectorMinInt64(vm.registers.V[src1], vm.registers.V[src2])
+
+		case OpVecMaxI:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.vectorMaxInt64(vm.registers.V[src1], vm.registers.V[src2])
+
+		case OpVecMinF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.vectorMinFloat64(vm.registers.V[src1], vm.registers.V[src2])
+
+		case OpVecMaxF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.vectorMaxFloat64(vm.registers.V[src1], vm.registers.V[src2])
+
+		case OpClampF:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			lo, hi := vm.floatConsts[base], vm.floatConsts[base+1]
+			vm.registers.V[dst] = vm.clampFloat64(vm.registers.V[src], lo, hi)
+
+		case OpVecNegF:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.negFloat64(vm.registers.V[src])
+
 		case OpVecDivF:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorDivFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorDivFloat64(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		// ===== Comparison =====
 		case OpCmpEQ:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpEQ(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpEQ(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpCmpNE:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpNE(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpNE(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpCmpLT:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpLT(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpLT(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpCmpLE:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpLE(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpLE(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpCmpGT:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpGT(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpGT(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpCmpEqEpsF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			eps := vm.floatConsts[vm.imm8Index(inst)] // Use Imm8 since Src1 and Src2 are used
+			result, err := vm.vectorCmpEqEpsF(vm.registers.V[src1], vm.registers.V[src2], eps)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpInSet:
+			dst, src1 := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			count := int(vm.constants[base].(int64))
+			values := vm.constants[base+1 : base+1+count]
+			result, err := vm.inSet(vm.registers.V[src1], values)
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpCmpGE:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			result := vm.vectorCmpGE(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.vectorCmpGE(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		// ===== Logical =====
@@ -438,12 +852,25 @@ func (vm *VM) Execute() (any, error) {
 			result := vm.vectorNot(vm.registers.V[src1])
 			vm.registers.V[dst] = result
 
+		case OpXor:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			result := vm.vectorXor(vm.registers.V[src1], vm.registers.V[src2])
+			vm.registers.V[dst] = result
+
+		case OpMaskedSelect:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			result := vm.maskedSelect(vm.registers.V[src1], vm.registers.V[src2])
+			vm.registers.V[dst] = result
+
 		// ===== Filtering =====
 		case OpFilter:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
 			data := vm.registers.V[src1]
 			mask := vm.registers.V[src2]
-			result := vm.filterSeriesWithMask(data, mask)
+			result, err := vm.filterSeriesWithMask(data, mask)
+			if err != nil {
+				return nil, err
+			}
 			vm.registers.V[dst] = result
 
 		case OpTake:
@@ -456,52 +883,149 @@ func (vm *VM) Execute() (any, error) {
 		// ===== Aggregations =====
 		case OpReduceSum:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.R[dst] = vm.reduceSum(vm.registers.V[src])
+			result, err := vm.reduceSum(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
 		case OpReduceSumF:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.F[dst] = vm.reduceSumF(vm.registers.V[src])
+			result, err := vm.reduceSumF(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
+
+		case OpReduceProd:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.reduceProd(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
+
+		case OpReduceProdF:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.reduceProdF(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
 
 		case OpReduceCount:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.R[dst] = vm.reduceCount(vm.registers.V[src])
+			result, err := vm.reduceCount(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
 		case OpReduceMin:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.R[dst] = vm.reduceMin(vm.registers.V[src])
+			result, err := vm.reduceMin(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
 		case OpReduceMax:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.R[dst] = vm.reduceMax(vm.registers.V[src])
+			result, err := vm.reduceMax(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
 		case OpReduceMinF:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.F[dst] = vm.reduceMinF(vm.registers.V[src])
+			result, err := vm.reduceMinF(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
 
 		case OpReduceMaxF:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.F[dst] = vm.reduceMaxF(vm.registers.V[src])
+			result, err := vm.reduceMaxF(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
 
 		case OpReduceMean:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.F[dst] = vm.reduceMean(vm.registers.V[src])
+			result, err := vm.reduceMean(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
 
-		// ===== Scalar Operations =====
-		case OpMoveR:
+		case OpReduceAny:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.R[dst] = vm.registers.R[src]
+			result, err := vm.reduceAny(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
-		case OpMoveF:
+		case OpReduceAll:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.F[dst] = vm.registers.F[src]
+			result, err := vm.reduceAll(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = result
 
-		case OpAddR:
-			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			vm.registers.R[dst] = vm.registers.R[src1] + vm.registers.R[src2]
+		case OpReduceQuantileF:
+			dst, src := inst.Dst(), inst.Src1()
+			q := vm.floatConsts[vm.imm8Index(inst)] // Use Imm8 since Src1 is used
+			result, err := vm.reduceQuantileF(vm.registers.V[src], q)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
 
-		case OpSubR:
+		case OpCorrF:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			vm.registers.R[dst] = vm.registers.R[src1] - vm.registers.R[src2]
+			result, err := vm.corrF(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
+
+		case OpCovF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			result, err := vm.covF(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
+
+		case OpReduceWMeanF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			result, err := vm.wmeanF(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = result
+
+		// ===== Scalar Operations =====
+		case OpMoveR:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.R[dst] = vm.registers.R[src]
+
+		case OpMoveF:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.F[dst] = vm.registers.F[src]
+
+		case OpAddR:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.R[dst] = vm.registers.R[src1] + vm.registers.R[src2]
+
+		case OpSubR:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.R[dst] = vm.registers.R[src1] - vm.registers.R[src2]
 
 		case OpMulR:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
@@ -514,6 +1038,37 @@ func (vm *VM) Execute() (any, error) {
 			}
 			vm.registers.R[dst] = vm.registers.R[src1] / vm.registers.R[src2]
 
+		case OpVecIndex:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			v, err := vm.vecIndex(vm.registers.V[src1], vm.registers.R[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = v
+
+		case OpVecIndexF:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			v, err := vm.vecIndexF(vm.registers.V[src1], vm.registers.R[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.F[dst] = v
+
+		case OpNegI:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.R[dst] = -vm.registers.R[src]
+
+		case OpNegF:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.F[dst] = -vm.registers.F[src]
+
+		case OpLoadConstStr:
+			dst, constIdx := inst.Dst(), inst.Imm16()
+			if _, err := vm.stringConst(int64(constIdx)); err != nil {
+				return nil, err
+			}
+			vm.registers.R[dst] = int64(constIdx)
+
 		// ===== Frame Operations =====
 		case OpNewFrame:
 			dst := inst.Dst()
@@ -523,7 +1078,7 @@ func (vm *VM) Execute() (any, error) {
 		case OpAddCol:
 			dst := inst.Dst()
 			src := inst.Src1()
-			nameIdx := inst.Imm8() // Use Imm8 since Src1 is used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
 			colName := vm.constants[nameIdx].(string)
 			col := vm.registers.V[src]
 			// Clone and rename the series
@@ -549,167 +1104,679 @@ func (vm *VM) Execute() (any, error) {
 			frame := vm.frames[int(vm.registers.R[src])]
 			vm.registers.R[dst] = int64(getDataFrameLength(frame))
 
+		case OpRowIndex:
+			dst, src := inst.Dst(), inst.Src1()
+			frame := vm.frames[int(vm.registers.R[src])]
+			if frame == nil {
+				return nil, ErrFrameNotFound
+			}
+			data := make([]int64, getDataFrameLength(frame))
+			for i := range data {
+				data[i] = int64(i)
+			}
+			vm.registers.V[dst] = newInt64Series("row_index", data)
+
+		case OpTopN:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			colName := vm.constants[base].(string)
+			n := vm.constants[base+1].(int64)
+			desc := vm.constants[base+2].(string) == "desc"
+			result, err := vm.topN(vm.frames[int(vm.registers.R[src])], colName, int(n), desc)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		case OpSample:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			n := vm.constants[base].(int64)
+			rng := vm.random()
+			if inst.Modifier()&SampleSeeded != 0 {
+				rng = rand.New(rand.NewSource(vm.constants[base+1].(int64)))
+			}
+			result, err := vm.sample(vm.frames[int(vm.registers.R[src])], int(n), rng)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		case OpShuffle:
+			dst, src := inst.Dst(), inst.Src1()
+			df := vm.frames[int(vm.registers.R[src])]
+			result, err := vm.sample(df, getDataFrameLength(df), vm.random())
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
 		// ===== GroupBy Operations =====
 		case OpGroupBy:
 			dst, src := inst.Dst(), inst.Src1()
 			keyCol := vm.registers.V[src]
-			vm.groupbys[int(dst)] = vm.groupBy(keyCol)
+			if vm.shouldSpill(getSeriesLength(keyCol)) {
+				gb, err := vm.spillGroupBy(keyCol)
+				if err != nil {
+					return nil, err
+				}
+				vm.groupbys[int(dst)] = gb
+			} else {
+				vm.groupbys[int(dst)] = vm.groupBy(keyCol)
+			}
 			vm.registers.R[dst] = int64(dst)
 
 		case OpGroupCount:
 			dst, src := inst.Dst(), inst.Src1()
 			gb := vm.groupbys[int(vm.registers.R[src])]
-			vm.registers.V[dst] = vm.groupCount(gb)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupCount(gb)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupSum:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupSum(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupSum(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupSumF:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupSumF(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupSumF(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupMin:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupMin(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMin(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupMax:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupMax(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMax(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupMinF:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupMinF(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMinF(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupMaxF:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupMaxF(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMaxF(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupMean:
 			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
 			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
 			valCol := vm.registers.V[valSrc]
-			vm.registers.V[dst] = vm.groupMean(gb, valCol)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMean(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
+
+		case OpGroupFirst:
+			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
+			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
+			valCol := vm.registers.V[valSrc]
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupFirst(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
+
+		case OpGroupLast:
+			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
+			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
+			valCol := vm.registers.V[valSrc]
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupLast(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
+
+		case OpGroupConcat:
+			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
+			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
+			sep := vm.constants[vm.imm8Index(inst)].(string)
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupConcat(gb, vm.registers.V[valSrc], sep)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
+
+		case OpGroupMedianF:
+			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
+			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
+			valCol := vm.registers.V[valSrc]
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupMedianF(gb, valCol)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
+
+		case OpGroupQuantileF:
+			dst, gbSrc, valSrc := inst.Dst(), inst.Src1(), inst.Src2()
+			gb := vm.groupbys[int(vm.registers.R[gbSrc])]
+			q := vm.floatConsts[vm.imm8Index(inst)] // Use Imm8 since Src1 and Src2 are used
+			col, err := vm.aggregate(gb, func(gb *GroupByResult) dataframe.Series {
+				return vm.groupQuantileF(gb, vm.registers.V[valSrc], q)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = col
 
 		case OpGroupKeys:
 			dst, src := inst.Dst(), inst.Src1()
 			gb := vm.groupbys[int(vm.registers.R[src])]
 			vm.registers.V[dst] = gb.Keys
 
+		case OpGroupSizes:
+			dst, src := inst.Dst(), inst.Src1()
+			gb := vm.groupbys[int(vm.registers.R[src])]
+			if gb == nil {
+				return nil, fmt.Errorf("%w: R%d holds no GROUP_BY result", ErrInvalidRegister, src)
+			}
+			sizes, err := vm.aggregate(gb, vm.groupCount)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = groupSizesFrame(gb, sizes)
+			vm.registers.R[dst] = int64(dst)
+
 		// ===== Join Operations =====
 		case OpJoinInner:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			nameIdx := inst.Imm8() // Use Imm8 since src1/src2 are used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since src1/src2 are used
+			keyName := vm.constants[nameIdx].(string)
+			left := vm.frames[int(vm.registers.R[src1])]
+			right := vm.frames[int(vm.registers.R[src2])]
+			result, err := vm.joinInner(left, right, keyName)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		case OpJoinInnerSorted:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since src1/src2 are used
 			keyName := vm.constants[nameIdx].(string)
 			left := vm.frames[int(vm.registers.R[src1])]
 			right := vm.frames[int(vm.registers.R[src2])]
-			result := vm.joinInner(left, right, keyName)
+			result, err := vm.joinInnerSorted(left, right, keyName)
+			if err != nil {
+				return nil, err
+			}
 			vm.frames[int(dst)] = result
 			vm.registers.R[dst] = int64(dst)
 
 		case OpJoinLeft:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			nameIdx := inst.Imm8() // Use Imm8 since src1/src2 are used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since src1/src2 are used
 			keyName := vm.constants[nameIdx].(string)
 			left := vm.frames[int(vm.registers.R[src1])]
 			right := vm.frames[int(vm.registers.R[src2])]
-			result := vm.joinLeft(left, right, keyName)
+			result, err := vm.joinLeft(left, right, keyName)
+			if err != nil {
+				return nil, err
+			}
 			vm.frames[int(dst)] = result
 			vm.registers.R[dst] = int64(dst)
 
 		case OpJoinRight:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			nameIdx := inst.Imm8() // Use Imm8 since src1/src2 are used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since src1/src2 are used
 			keyName := vm.constants[nameIdx].(string)
 			left := vm.frames[int(vm.registers.R[src1])]
 			right := vm.frames[int(vm.registers.R[src2])]
-			result := vm.joinRight(left, right, keyName)
+			result, err := vm.joinRight(left, right, keyName)
+			if err != nil {
+				return nil, err
+			}
 			vm.frames[int(dst)] = result
 			vm.registers.R[dst] = int64(dst)
 
 		case OpJoinOuter:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			nameIdx := inst.Imm8() // Use Imm8 since src1/src2 are used
+			nameIdx := vm.imm8Index(inst) // Use Imm8 since src1/src2 are used
 			keyName := vm.constants[nameIdx].(string)
 			left := vm.frames[int(vm.registers.R[src1])]
 			right := vm.frames[int(vm.registers.R[src2])]
-			result := vm.joinOuter(left, right, keyName)
+			result, err := vm.joinOuter(left, right, keyName)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		case OpJoinCross:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			left := vm.frames[int(vm.registers.R[src1])]
+			right := vm.frames[int(vm.registers.R[src2])]
+			result, err := vm.joinCross(left, right)
+			if err != nil {
+				return nil, err
+			}
 			vm.frames[int(dst)] = result
 			vm.registers.R[dst] = int64(dst)
 
 		// ===== String Operations =====
 		case OpStrLen:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.V[dst] = vm.strLen(vm.registers.V[src])
+			result, err := vm.strLen(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
 		case OpStrUpper:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.V[dst] = vm.strUpper(vm.registers.V[src])
+			result, err := vm.strUpper(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
 		case OpStrLower:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.V[dst] = vm.strLower(vm.registers.V[src])
+			result, err := vm.strLower(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrTitle:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.strTitle(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrCapitalize:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.strCapitalize(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
 		case OpStrConcat:
 			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
-			vm.registers.V[dst] = vm.strConcat(vm.registers.V[src1], vm.registers.V[src2])
+			result, err := vm.strConcat(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
 		case OpStrContains:
 			dst, src := inst.Dst(), inst.Src1()
-			patternIdx := inst.Imm8() // Use Imm8 since Src1 is used
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
 			pattern := vm.constants[patternIdx].(string)
-			vm.registers.V[dst] = vm.strContains(vm.registers.V[src], pattern)
+			result, err := vm.strContains(vm.registers.V[src], pattern)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
-		case OpStrStartsWith:
+		case OpStrRegexMatch:
 			dst, src := inst.Dst(), inst.Src1()
-			patternIdx := inst.Imm8() // Use Imm8 since Src1 is used
-			pattern := vm.constants[patternIdx].(string)
-			vm.registers.V[dst] = vm.strStartsWith(vm.registers.V[src], pattern)
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			re, err := compileRegex(vm.constants[patternIdx].(string))
+			if err != nil {
+				return nil, err
+			}
+			result, err := vm.strRegexMatch(vm.registers.V[src], re)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
-		case OpStrEndsWith:
+		case OpStrRegexExtract:
 			dst, src := inst.Dst(), inst.Src1()
-			patternIdx := inst.Imm8() // Use Imm8 since Src1 is used
-			pattern := vm.constants[patternIdx].(string)
-			vm.registers.V[dst] = vm.strEndsWith(vm.registers.V[src], pattern)
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			re, err := compileRegex(vm.constants[patternIdx].(string))
+			if err != nil {
+				return nil, err
+			}
+			result, err := vm.strRegexExtract(vm.registers.V[src], re)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
-		case OpStrTrim:
+		case OpStrSubstr:
 			dst, src := inst.Dst(), inst.Src1()
-			vm.registers.V[dst] = vm.strTrim(vm.registers.V[src])
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			start := vm.constants[base].(int64)
+			length := vm.constants[base+1].(int64)
+			result, err := vm.strSubstr(vm.registers.V[src], start, length)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
-		case OpStrSplit:
+		case OpStrPadLeft, OpStrPadRight:
 			dst, src := inst.Dst(), inst.Src1()
-			delimIdx := inst.Imm8() // Use Imm8 since Src1 is used
-			delim := vm.constants[delimIdx].(string)
-			vm.registers.V[dst] = vm.strSplit(vm.registers.V[src], delim)
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			width := vm.constants[base].(int64)
+			pad := vm.constants[base+1].(string)
+			result, err := vm.strPad(vm.registers.V[src], width, pad, inst.Opcode() == OpStrPadLeft)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
 
-		case OpStrReplace:
+		case OpFormatF:
+			dst, src := inst.Dst(), inst.Src1()
+			specIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			result, err := vm.formatF(vm.registers.V[src], vm.constants[specIdx])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrContainsCI:
 			dst, src := inst.Dst(), inst.Src1()
-			patternIdx := inst.Imm8() // Use Imm8 since Src1 is used
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
 			pattern := vm.constants[patternIdx].(string)
-			vm.registers.V[dst] = vm.strReplace(vm.registers.V[src], pattern)
+			result, err := vm.strContainsCI(vm.registers.V[src], pattern)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrStartsWith:
+			dst, src := inst.Dst(), inst.Src1()
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			pattern := vm.constants[patternIdx].(string)
+			result, err := vm.strStartsWith(vm.registers.V[src], pattern)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrEndsWith:
+			dst, src := inst.Dst(), inst.Src1()
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			pattern := vm.constants[patternIdx].(string)
+			result, err := vm.strEndsWith(vm.registers.V[src], pattern)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrTrim:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.strTrim(vm.registers.V[src])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrTrimLeft:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.strMap(vm.registers.V[src], "ltrim", func(v string) string {
+				return strings.TrimLeftFunc(v, unicode.IsSpace)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrTrimRight:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.strMap(vm.registers.V[src], "rtrim", func(v string) string {
+				return strings.TrimRightFunc(v, unicode.IsSpace)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrTrimChars:
+			dst, src := inst.Dst(), inst.Src1()
+			cutsetIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			cutset := vm.constants[cutsetIdx].(string)
+			result, err := vm.strMap(vm.registers.V[src], "trim", func(v string) string {
+				return strings.Trim(v, cutset)
+			})
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrSplit:
+			dst, src := inst.Dst(), inst.Src1()
+			delimIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			delim := vm.constants[delimIdx].(string)
+			result, err := vm.strSplit(vm.registers.V[src], delim)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpStrReplace:
+			dst, src := inst.Dst(), inst.Src1()
+			patternIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			pattern := vm.constants[patternIdx].(string)
+			result, err := vm.strReplace(vm.registers.V[src], pattern)
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		// ===== Window Operations =====
+		case OpShift:
+			dst, src := inst.Dst(), inst.Src1()
+			n := int(int8(inst.Imm8())) // Signed offset in Imm8 since Src1 is used
+			vm.registers.V[dst] = vm.shift(vm.registers.V[src], n)
+
+		case OpBinF:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			nbins := int(vm.floatConsts[base])
+			lo, hi := vm.floatConsts[base+1], vm.floatConsts[base+2]
+			vm.registers.V[dst] = vm.binF(vm.registers.V[src], nbins, lo, hi)
+
+		case OpRankF:
+			dst, src := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.rankF(vm.registers.V[src], inst.Imm8())
+
+		// ===== Null Handling =====
+		case OpFillNa:
+			dst, src := inst.Dst(), inst.Src1()
+			valueIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			result, err := vm.fillNa(vm.registers.V[src], vm.constants[valueIdx])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpCoalesce:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			result, err := vm.coalesce(vm.registers.V[src1], vm.registers.V[src2])
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpIsNull:
+			dst, src1 := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.isNullMask(vm.registers.V[src1], true)
+
+		case OpIsNotNull:
+			dst, src1 := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.isNullMask(vm.registers.V[src1], false)
+
+		case OpIsNaNF:
+			dst, src1 := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.floatMask(vm.registers.V[src1], math.IsNaN)
+
+		case OpIsInfF:
+			dst, src1 := inst.Dst(), inst.Src1()
+			vm.registers.V[dst] = vm.floatMask(vm.registers.V[src1], func(f float64) bool { return math.IsInf(f, 0) })
+
+		case OpDropNa:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			count := int(vm.constants[base].(int64))
+			columns := make([]string, count)
+			for i := range columns {
+				columns[i] = vm.constants[base+1+i].(string)
+			}
+			result, err := vm.dropNa(vm.frames[int(vm.registers.R[src])], columns)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		// ===== Hashing =====
+		case OpHashStr:
+			dst, src := inst.Dst(), inst.Src1()
+			buckets := inst.Imm8() // Use Imm8 since Src1 is used
+			vm.registers.V[dst] = vm.hashStr(vm.registers.V[src], buckets)
+
+		// ===== Date Operations =====
+		case OpParseDate:
+			dst, src := inst.Dst(), inst.Src1()
+			layoutIdx := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			layout := vm.constants[layoutIdx].(string)
+			vm.registers.V[dst] = vm.parseDate(vm.registers.V[src], layout)
+
+		case OpDatePart:
+			dst, src := inst.Dst(), inst.Src1()
+			result, err := vm.datePart(vm.registers.V[src], inst.Imm8())
+			if err != nil {
+				return nil, err
+			}
+			vm.registers.V[dst] = result
+
+		case OpDateAddDays:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.dateAddDays(vm.registers.V[src1], vm.registers.V[src2])
+
+		case OpDateDiffDays:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			vm.registers.V[dst] = vm.dateDiffDays(vm.registers.V[src1], vm.registers.V[src2])
+
+		// ===== Reshaping Operations =====
+		case OpPivot:
+			dst, src := inst.Dst(), inst.Src1()
+			base := vm.imm8Index(inst) // Use Imm8 since Src1 is used
+			indexName := vm.constants[base].(string)
+			keyName := vm.constants[base+1].(string)
+			valueName := vm.constants[base+2].(string)
+			result, err := vm.pivot(vm.frames[int(vm.registers.R[src])], indexName, keyName, valueName)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
+
+		case OpConcat:
+			dst, src1, src2 := inst.Dst(), inst.Src1(), inst.Src2()
+			top := vm.frames[int(vm.registers.R[src1])]
+			bottom := vm.frames[int(vm.registers.R[src2])]
+			result, err := vm.concat(top, bottom)
+			if err != nil {
+				return nil, err
+			}
+			vm.frames[int(dst)] = result
+			vm.registers.R[dst] = int64(dst)
 
 		// ===== Control Flow =====
 		case OpNop:
 			// Do nothing
 
+		case OpWide:
+			// Applies to the next instruction only, so skip the reset below
+			vm.wide = int(inst.Imm16()) << 8
+			vm.ip++
+			continue
+
+		case OpAssertEq:
+			if err := vm.assertEq(inst.Src1(), inst.Src2(), inst.Imm8()); err != nil {
+				return nil, err
+			}
+
+		case OpPrint:
+			if err := vm.print(inst.Src1(), inst.Imm8()); err != nil {
+				return nil, err
+			}
+
+		case OpSetResult:
+			if err := vm.setResult(vm.constants[vm.imm8Index(inst)].(string), inst.Src1(), inst.Modifier()); err != nil {
+				return nil, err
+			}
+
 		case OpHalt:
 			dst := inst.Dst()
 			if vm.statsEnabled {
 				vm.stats.ExecutionTimeNs = time.Since(startTime).Nanoseconds()
 				vm.stats.FramesLoaded = len(vm.frames)
 			}
+			vm.halted = true
 			return vm.registers.R[dst], nil
 
 		case OpHaltF:
@@ -718,59 +1785,379 @@ func (vm *VM) Execute() (any, error) {
 				vm.stats.ExecutionTimeNs = time.Since(startTime).Nanoseconds()
 				vm.stats.FramesLoaded = len(vm.frames)
 			}
+			vm.halted = true
 			return vm.registers.F[dst], nil
 
+		case OpHaltStr:
+			s, err := vm.stringConst(vm.registers.R[inst.Dst()])
+			if err != nil {
+				return nil, err
+			}
+			if vm.statsEnabled {
+				vm.stats.ExecutionTimeNs = time.Since(startTime).Nanoseconds()
+				vm.stats.FramesLoaded = len(vm.frames)
+			}
+			vm.halted = true
+			return s, nil
+
 		case OpHaltV:
 			dst := inst.Dst()
 			if vm.statsEnabled {
 				vm.stats.ExecutionTimeNs = time.Since(startTime).Nanoseconds()
 				vm.stats.FramesLoaded = len(vm.frames)
 			}
+			vm.halted = true
 			return vm.registers.V[dst], nil
 
+		case OpHaltFrame:
+			dst := inst.Dst()
+			if vm.statsEnabled {
+				vm.stats.ExecutionTimeNs = time.Since(startTime).Nanoseconds()
+				vm.stats.FramesLoaded = len(vm.frames)
+			}
+			vm.halted = true
+			return vm.frames[int(vm.registers.R[dst])], nil
+
 		default:
 			return nil, fmt.Errorf("%w: opcode 0x%02X", ErrInvalidInstruction, op)
 		}
 
+		vm.wide = 0
 		vm.ip++
 	}
 
 	return nil, ErrNoHalt
 }
 
+// Resume continues a program that an Execute or Resume call stopped at a
+// HALT, running from the next instruction with registers, frames and
+// results intact, and returns the value of the next HALT. It fails with
+// ErrNotHalted unless the program is stopped at a HALT.
+func (vm *VM) Resume() (any, error) {
+	if !vm.halted {
+		return nil, ErrNotHalted
+	}
+	vm.ip++
+	return vm.Execute()
+}
+
+// rowsPerStep is how many input rows a row-heavy opcode may process for each
+// step of budget it consumes beyond its base cost of one.
+const rowsPerStep = 1000
+
+// extraCost returns the steps inst consumes beyond the base cost of one.
+// Joins, group-bys and sorting opcodes (TOP_N, RANK_F, PIVOT) are charged one
+// step per rowsPerStep input rows, and JOIN_CROSS one per rowsPerStep output
+// rows; every other opcode costs nothing extra.
+func (vm *VM) extraCost(inst Instruction) int64 {
+	var rows int
+	switch inst.Opcode() {
+	case OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter, OpJoinInnerSorted:
+		rows = vm.frameRows(inst.Src1()) + vm.frameRows(inst.Src2())
+	case OpJoinCross:
+		rows = crossRows(vm.frameRows(inst.Src1()), vm.frameRows(inst.Src2()))
+	case OpTopN, OpPivot:
+		rows = vm.frameRows(inst.Src1())
+	case OpGroupBy, OpRankF:
+		rows = vm.vectorRows(inst.Src1())
+	}
+	return int64(rows / rowsPerStep)
+}
+
+// inputRows returns the number of rows inst reads for ExecutionStats:
+// the length of its input vector for vector ops, reductions, filters and
+// group aggregations, and the input frame sizes for joins and frame ops.
+// Scalar, load and control flow instructions process no rows.
+func (vm *VM) inputRows(inst Instruction) int {
+	switch inst.Opcode() {
+	case OpVecAddI, OpVecSubI, OpVecMulI, OpVecDivI, OpVecModI,
+		OpVecAddF, OpVecSubF, OpVecMulF, OpVecDivF,
+		OpVecMinI, OpVecMaxI, OpVecMinF, OpVecMaxF, OpClampF, OpVecNegF,
+		OpCmpEQ, OpCmpNE, OpCmpLT, OpCmpLE, OpCmpGT, OpCmpGE, OpCmpEqEpsF, OpInSet,
+		OpAnd, OpOr, OpNot, OpXor, OpMaskedSelect, OpFilter, OpTake,
+		OpReduceSum, OpReduceSumF, OpReduceCount, OpReduceMin, OpReduceMax,
+		OpReduceMinF, OpReduceMaxF, OpReduceMean, OpReduceAny, OpReduceAll,
+		OpReduceProd, OpReduceProdF, OpReduceQuantileF, OpCorrF, OpCovF, OpReduceWMeanF,
+		OpGroupBy,
+		OpStrLen, OpStrUpper, OpStrLower, OpStrTitle, OpStrCapitalize, OpStrConcat,
+		OpStrTrim, OpStrTrimLeft, OpStrTrimRight, OpStrTrimChars,
+		OpStrContains, OpStrContainsCI, OpStrRegexMatch, OpStrRegexExtract,
+		OpStrStartsWith, OpStrEndsWith, OpStrSplit, OpStrReplace,
+		OpStrSubstr, OpStrPadLeft, OpStrPadRight, OpFormatF,
+		OpShift, OpBinF, OpRankF, OpFillNa, OpCoalesce, OpIsNull, OpIsNotNull, OpIsNaNF, OpIsInfF,
+		OpHashStr, OpParseDate, OpDatePart, OpDateAddDays, OpDateDiffDays:
+		return vm.vectorRows(inst.Src1())
+	case OpGroupSum, OpGroupSumF, OpGroupMin, OpGroupMax, OpGroupMinF, OpGroupMaxF,
+		OpGroupMean, OpGroupFirst, OpGroupLast, OpGroupMedianF, OpGroupConcat, OpGroupQuantileF:
+		return vm.vectorRows(inst.Src2())
+	case OpJoinInner, OpJoinLeft, OpJoinRight, OpJoinOuter, OpJoinCross, OpJoinInnerSorted, OpConcat:
+		return vm.frameRows(inst.Src1()) + vm.frameRows(inst.Src2())
+	case OpTopN, OpSample, OpShuffle, OpPivot, OpDropNa:
+		return vm.frameRows(inst.Src1())
+	case OpAssertEq, OpPrint:
+		switch inst.Imm8() {
+		case RegFrame:
+			return vm.frameRows(inst.Src1())
+		case RegVector:
+			return vm.vectorRows(inst.Src1())
+		}
+	case OpSetResult:
+		switch inst.Modifier() {
+		case RegFrame:
+			return vm.frameRows(inst.Src1())
+		case RegVector:
+			return vm.vectorRows(inst.Src1())
+		}
+	}
+	return 0
+}
+
+// imm8Index returns the constant index inst keeps in imm8, extended by the
+// high bits of a preceding OpWide.
+func (vm *VM) imm8Index(inst Instruction) int {
+	return vm.wide | int(inst.Imm8())
+}
+
+// writesRegister reports whether op stores a result in its dst register.
+func writesRegister(op Opcode) bool {
+	switch op {
+	case OpNop, OpWide, OpHalt, OpHaltF, OpHaltV, OpHaltStr, OpHaltFrame, OpAddCol, OpAssertEq, OpPrint, OpSetResult:
+		return false
+	}
+	return true
+}
+
+// vectorRows returns the length of V[reg], or 0 if it is empty or invalid.
+func (vm *VM) vectorRows(reg uint8) int {
+	if reg < NumVectorRegs && vm.registers.V[reg] != nil {
+		return vm.registers.V[reg].NRows()
+	}
+	return 0
+}
+
+// frameRows returns the row count of the frame referenced by R[reg], or 0.
+func (vm *VM) frameRows(reg uint8) int {
+	if df := vm.frames[int(vm.registers.R[reg])]; df != nil {
+		return df.NRows()
+	}
+	return 0
+}
+
+// ===== Data Loading =====
+
+// fill builds the vector for OpFillI, OpFillF and OpFillStr: the constant at
+// constants[imm8] repeated either as many times as V[src1] has rows or, by
+// default, the int64 count at constants[imm8+1].
+func (vm *VM) fill(inst Instruction) (dataframe.Series, error) {
+	idx := vm.imm8Index(inst)
+	var n int
+	if inst.Modifier()&FillLikeVector != 0 {
+		n = getSeriesLength(vm.registers.V[inst.Src1()])
+	} else {
+		count, ok := vm.constants[idx+1].(int64)
+		if !ok || count < 0 {
+			return nil, fmt.Errorf("%w: %s length must be a non-negative int, got %v", ErrTypeMismatch, inst.Opcode(), vm.constants[idx+1])
+		}
+		n = int(count)
+	}
+
+	value := vm.constants[idx]
+	switch inst.Opcode() {
+	case OpFillI:
+		v, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("%w: FILL_I value must be an int, got %T", ErrTypeMismatch, value)
+		}
+		data := make([]int64, n)
+		for i := range data {
+			data[i] = v
+		}
+		return newInt64Series("fill", data), nil
+	case OpFillF:
+		v, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: FILL_F value must be a float, got %T", ErrTypeMismatch, value)
+		}
+		data := make([]float64, n)
+		for i := range data {
+			data[i] = v
+		}
+		return newFloat64Series("fill", data), nil
+	default:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: FILL_STR value must be a string, got %T", ErrTypeMismatch, value)
+		}
+		data := make([]string, n)
+		for i := range data {
+			data[i] = v
+		}
+		return newStringSeries("fill", data), nil
+	}
+}
+
+// rangeSeries builds the vector for OpRange: start, start+step, ... while
+// the value is before end (below it for a positive step, above it for a
+// negative one), so end itself is excluded.
+func (vm *VM) rangeSeries(startVal, endVal, stepVal any) (dataframe.Series, error) {
+	start, ok1 := startVal.(int64)
+	end, ok2 := endVal.(int64)
+	step, ok3 := stepVal.(int64)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("%w: RANGE bounds and step must be ints, got %T, %T, %T", ErrTypeMismatch, startVal, endVal, stepVal)
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("%w: step must be non-zero", ErrInvalidRange)
+	}
+
+	// Count in uint64 so spans wider than MaxInt64 do not overflow
+	var n uint64
+	switch {
+	case step > 0 && start < end:
+		n = (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		n = (uint64(start)-uint64(end)-1)/uint64(-step) + 1
+	}
+
+	data := make([]int64, n)
+	v := start
+	for i := range data {
+		data[i] = v
+		v += step
+	}
+	return newInt64Series("range", data), nil
+}
+
 // ===== Vector Operations =====
 
-func (vm *VM) vectorAddInt64(a, b dataframe.Series) dataframe.Series {
+// int64Slice reads the first n cells of s into a pooled []int64, reading nil
+// and missing cells as 0 like getInt64Value. Int64 columns are read under a
+// single lock instead of one per cell; dataframe-go exports only the write
+// side of its RWMutex, so this is the same lock float64Slice takes. Callers
+// return the slice with vm.buffers.putInt64s.
+func (vm *VM) int64Slice(s dataframe.Series, n int) []int64 {
+	out := vm.buffers.getInt64s(n)
+	si, ok := s.(*dataframe.SeriesInt64)
+	if !ok {
+		for i := range out {
+			out[i], _ = getInt64Value(s, i)
+		}
+		return out
+	}
+
+	si.Lock()
+	defer si.Unlock()
+	rows := si.NRows(dataframe.DontLock)
+	for i := range out {
+		out[i] = 0
+		if i < rows {
+			if v, ok := si.Value(i, dataframe.DontLock).(int64); ok {
+				out[i] = v
+			}
+		}
+	}
+	return out
+}
+
+// float64Slice reads the first n cells of s into a pooled []float64, reading
+// nil and missing cells as 0 like getFloat64Value. Float64 columns are copied
+// straight from their backing slice, under the series lock like int64Slice.
+// Callers return the slice with vm.buffers.putFloat64s.
+func (vm *VM) float64Slice(s dataframe.Series, n int) []float64 {
+	out := vm.buffers.getFloat64s(n)
+	sf, ok := s.(*dataframe.SeriesFloat64)
+	if !ok {
+		for i := range out {
+			out[i], _ = getFloat64Value(s, i)
+		}
+		return out
+	}
+
+	sf.Lock()
+	copied := copy(out, sf.Values)
+	sf.Unlock()
+	for i, v := range out[:copied] {
+		if math.IsNaN(v) {
+			out[i] = 0
+		}
+	}
+	clear(out[copied:])
+	return out
+}
+
+func (vm *VM) vectorAddInt64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]int64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getInt64Value(a, i)
-		bv, _ := getInt64Value(b, i)
-		data[i] = av + bv
+	data := vm.buffers.getInt64s(length)
+	defer vm.buffers.putInt64s(data)
+	as, bs := vm.int64Slice(a, length), vm.int64Slice(b, length)
+	defer vm.buffers.putInt64s(as)
+	defer vm.buffers.putInt64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, bv := as[i], bs[i]
+			sum := av + bv
+			// Overflow iff both operands share a sign the sum doesn't have
+			if vm.checkedIntArith && (av^sum)&(bv^sum) < 0 {
+				return fmt.Errorf("%w at row %d", ErrIntegerOverflow, i)
+			}
+			data[i] = sum
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newInt64Series("result", data)
+	return newInt64Series("result", data), nil
 }
 
-func (vm *VM) vectorSubInt64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorSubInt64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]int64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getInt64Value(a, i)
-		bv, _ := getInt64Value(b, i)
-		data[i] = av - bv
+	data := vm.buffers.getInt64s(length)
+	defer vm.buffers.putInt64s(data)
+	as, bs := vm.int64Slice(a, length), vm.int64Slice(b, length)
+	defer vm.buffers.putInt64s(as)
+	defer vm.buffers.putInt64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, bv := as[i], bs[i]
+			diff := av - bv
+			// Overflow iff the operands differ in sign and the result takes b's sign
+			if vm.checkedIntArith && (av^bv)&(av^diff) < 0 {
+				return fmt.Errorf("%w at row %d", ErrIntegerOverflow, i)
+			}
+			data[i] = diff
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newInt64Series("result", data)
+	return newInt64Series("result", data), nil
 }
 
-func (vm *VM) vectorMulInt64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMulInt64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]int64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getInt64Value(a, i)
-		bv, _ := getInt64Value(b, i)
-		data[i] = av * bv
+	data := vm.buffers.getInt64s(length)
+	defer vm.buffers.putInt64s(data)
+	as, bs := vm.int64Slice(a, length), vm.int64Slice(b, length)
+	defer vm.buffers.putInt64s(as)
+	defer vm.buffers.putInt64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, bv := as[i], bs[i]
+			prod := av * bv
+			if vm.checkedIntArith && av != 0 &&
+				(prod/av != bv || (av == -1 && bv == math.MinInt64)) {
+				return fmt.Errorf("%w at row %d", ErrIntegerOverflow, i)
+			}
+			data[i] = prod
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newInt64Series("result", data)
+	return newInt64Series("result", data), nil
 }
 
 func (vm *VM) vectorDivInt64(a, b dataframe.Series) (dataframe.Series, error) {
@@ -801,122 +2188,306 @@ func (vm *VM) vectorModInt64(a, b dataframe.Series) (dataframe.Series, error) {
 	return newInt64Series("result", data), nil
 }
 
-func (vm *VM) vectorAddFloat64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorAddFloat64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]float64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getFloat64Value(a, i)
-		bv, _ := getFloat64Value(b, i)
-		data[i] = av + bv
+	data := vm.buffers.getFloat64s(length)
+	defer vm.buffers.putFloat64s(data)
+	as, bs := vm.float64Slice(a, length), vm.float64Slice(b, length)
+	defer vm.buffers.putFloat64s(as)
+	defer vm.buffers.putFloat64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			data[i] = as[i] + bs[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newFloat64Series("result", data)
+	return newFloat64Series("result", data), nil
 }
 
-func (vm *VM) vectorSubFloat64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorSubFloat64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]float64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getFloat64Value(a, i)
-		bv, _ := getFloat64Value(b, i)
-		data[i] = av - bv
+	data := vm.buffers.getFloat64s(length)
+	defer vm.buffers.putFloat64s(data)
+	as, bs := vm.float64Slice(a, length), vm.float64Slice(b, length)
+	defer vm.buffers.putFloat64s(as)
+	defer vm.buffers.putFloat64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			data[i] = as[i] - bs[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newFloat64Series("result", data)
+	return newFloat64Series("result", data), nil
 }
 
-func (vm *VM) vectorMulFloat64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMulFloat64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]float64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getFloat64Value(a, i)
-		bv, _ := getFloat64Value(b, i)
-		data[i] = av * bv
+	data := vm.buffers.getFloat64s(length)
+	defer vm.buffers.putFloat64s(data)
+	as, bs := vm.float64Slice(a, length), vm.float64Slice(b, length)
+	defer vm.buffers.putFloat64s(as)
+	defer vm.buffers.putFloat64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			data[i] = as[i] * bs[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newFloat64Series("result", data)
+	return newFloat64Series("result", data), nil
 }
 
-func (vm *VM) vectorDivFloat64(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorDivFloat64(a, b dataframe.Series) (dataframe.Series, error) {
 	length := getSeriesLength(a)
-	data := make([]float64, length)
-	for i := 0; i < length; i++ {
-		av, _ := getFloat64Value(a, i)
-		bv, _ := getFloat64Value(b, i)
-		if bv == 0 {
-			data[i] = math.Inf(1)
-		} else {
-			data[i] = av / bv
+	data := vm.buffers.getFloat64s(length)
+	defer vm.buffers.putFloat64s(data)
+	as, bs := vm.float64Slice(a, length), vm.float64Slice(b, length)
+	defer vm.buffers.putFloat64s(as)
+	defer vm.buffers.putFloat64s(bs)
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, bv := as[i], bs[i]
+			if bv != 0 {
+				data[i] = av / bv
+				continue
+			}
+			switch vm.divByZeroMode {
+			case DivByZeroNaN:
+				data[i] = math.NaN()
+			case DivByZeroError:
+				return fmt.Errorf("%w at row %d", ErrDivisionByZero, i)
+			case DivByZeroZero:
+				data[i] = 0
+			default:
+				data[i] = math.Inf(1)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newFloat64Series("result", data)
+	return newFloat64Series("result", data), nil
 }
 
-// ===== Comparison Operations =====
-
-func (vm *VM) vectorCmpEQ(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMinInt64(a, b dataframe.Series) dataframe.Series {
 	length := getSeriesLength(a)
-	data := make([]bool, length)
+	data := make([]int64, length)
 	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) == 0
+		av, _ := getInt64Value(a, i)
+		bv, _ := getInt64Value(b, i)
+		data[i] = min(av, bv)
 	}
-	return newBoolSeries("result", data)
+	return newInt64Series("result", data)
 }
 
-func (vm *VM) vectorCmpNE(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMaxInt64(a, b dataframe.Series) dataframe.Series {
 	length := getSeriesLength(a)
-	data := make([]bool, length)
+	data := make([]int64, length)
 	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) != 0
+		av, _ := getInt64Value(a, i)
+		bv, _ := getInt64Value(b, i)
+		data[i] = max(av, bv)
 	}
-	return newBoolSeries("result", data)
+	return newInt64Series("result", data)
 }
 
-func (vm *VM) vectorCmpLT(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMinFloat64(a, b dataframe.Series) dataframe.Series {
 	length := getSeriesLength(a)
-	data := make([]bool, length)
+	data := make([]float64, length)
 	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) < 0
+		av, _ := getFloat64Value(a, i)
+		bv, _ := getFloat64Value(b, i)
+		data[i] = math.Min(av, bv)
 	}
-	return newBoolSeries("result", data)
+	return newFloat64Series("result", data)
 }
 
-func (vm *VM) vectorCmpLE(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorMaxFloat64(a, b dataframe.Series) dataframe.Series {
 	length := getSeriesLength(a)
-	data := make([]bool, length)
+	data := make([]float64, length)
+	for i := 0; i < length; i++ {
+		av, _ := getFloat64Value(a, i)
+		bv, _ := getFloat64Value(b, i)
+		data[i] = math.Max(av, bv)
+	}
+	return newFloat64Series("result", data)
+}
+
+// clampFloat64 bounds each value to [lo, hi]. NaN and nil pass through.
+func (vm *VM) clampFloat64(s dataframe.Series, lo, hi float64) dataframe.Series {
+	length := getSeriesLength(s)
+	vals := make([]interface{}, length)
+	for i := 0; i < length; i++ {
+		v, ok := getFloat64Value(s, i)
+		if !ok {
+			continue
+		}
+		if !math.IsNaN(v) {
+			v = math.Max(lo, math.Min(hi, v))
+		}
+		vals[i] = v
+	}
+	return dataframe.NewSeriesFloat64("result", nil, vals...)
+}
+
+// negFloat64 negates each element of s as a float64, keeping nulls.
+func (vm *VM) negFloat64(s dataframe.Series) dataframe.Series {
+	length := getSeriesLength(s)
+	vals := make([]interface{}, length)
 	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) <= 0
+		if v, ok := getFloat64Value(s, i); ok {
+			vals[i] = -v
+		}
 	}
-	return newBoolSeries("result", data)
+	return dataframe.NewSeriesFloat64("result", nil, vals...)
+}
+
+// stringConst returns the string constant at idx, the handle LOAD_CONST_STR
+// stores in an R register.
+func (vm *VM) stringConst(idx int64) (string, error) {
+	if idx < 0 || idx >= int64(len(vm.constants)) {
+		return "", fmt.Errorf("%w: string constant %d", ErrInvalidInstruction, idx)
+	}
+	s, ok := vm.constants[idx].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: constant %d is %T, not a string", ErrTypeMismatch, idx, vm.constants[idx])
+	}
+	return s, nil
+}
+
+// ===== Comparison Operations =====
+
+func (vm *VM) vectorCmpEQ(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c == 0 })
+}
+
+func (vm *VM) vectorCmpNE(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c != 0 })
+}
+
+func (vm *VM) vectorCmpLT(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c < 0 })
+}
+
+func (vm *VM) vectorCmpLE(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c <= 0 })
+}
+
+func (vm *VM) vectorCmpGT(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c > 0 })
 }
 
-func (vm *VM) vectorCmpGT(a, b dataframe.Series) dataframe.Series {
+func (vm *VM) vectorCmpGE(a, b dataframe.Series) (dataframe.Series, error) {
+	return vm.vectorCompare(a, b, func(c int) bool { return c >= 0 })
+}
+
+// vectorCmpEqEpsF reports, per row, whether a and b are within eps of each
+// other when read as float64. Rows where either side is nil or NaN compare
+// false.
+func (vm *VM) vectorCmpEqEpsF(a, b dataframe.Series, eps float64) (dataframe.Series, error) {
 	length := getSeriesLength(a)
 	data := make([]bool, length)
-	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) > 0
+	err := vm.forEachChunk(length, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, aok := getFloat64Value(a, i)
+			bv, bok := getFloat64Value(b, i)
+			data[i] = aok && bok && math.Abs(av-bv) <= eps
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return newBoolSeries("result", data)
+	return newBoolSeries("result", data), nil
 }
 
-func (vm *VM) vectorCmpGE(a, b dataframe.Series) dataframe.Series {
+// vectorCompare sets each row of the result to pred applied to the three-way
+// comparison of a and b at that row. Int64 and string column pairs are
+// compared natively so large int64 values keep their full precision; other
+// combinations go through compareValues.
+func (vm *VM) vectorCompare(a, b dataframe.Series, pred func(c int) bool) (dataframe.Series, error) {
 	length := getSeriesLength(a)
 	data := make([]bool, length)
-	for i := 0; i < length; i++ {
-		data[i] = vm.compareValues(a, b, i) >= 0
+	var err error
+	switch ta, tb := getSeriesType(a), getSeriesType(b); {
+	case ta == TypeInt64 && tb == TypeInt64:
+		err = vm.compareInt64s(a, b, data, pred)
+	case ta == TypeString && tb == TypeString:
+		err = vm.compareStrings(a, b, data, pred)
+	default:
+		err = vm.forEachChunk(length, func(lo, hi int) error {
+			for i := lo; i < hi; i++ {
+				data[i] = pred(vm.compareValues(a, b, i))
+			}
+			return nil
+		})
 	}
-	return newBoolSeries("result", data)
+	if err != nil {
+		return nil, err
+	}
+	return newBoolSeries("result", data), nil
+}
+
+// compareInt64s fills data with pred over int64 comparisons of a and b.
+func (vm *VM) compareInt64s(a, b dataframe.Series, data []bool, pred func(c int) bool) error {
+	as, bs := vm.int64Slice(a, len(data)), vm.int64Slice(b, len(data))
+	defer vm.buffers.putInt64s(as)
+	defer vm.buffers.putInt64s(bs)
+	return vm.forEachChunk(len(data), func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			data[i] = pred(cmp.Compare(as[i], bs[i]))
+		}
+		return nil
+	})
+}
+
+// compareStrings fills data with pred over lexicographic comparisons of a
+// and b. Nil cells compare as "".
+func (vm *VM) compareStrings(a, b dataframe.Series, data []bool, pred func(c int) bool) error {
+	return vm.forEachChunk(len(data), func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, _ := getStringValue(a, i)
+			bv, _ := getStringValue(b, i)
+			data[i] = pred(strings.Compare(av, bv))
+		}
+		return nil
+	})
 }
 
-// compareValues compares values at index i, returns -1, 0, or 1
+// compareValues compares values at index i, returns -1, 0, or 1. Strings
+// compare lexicographically and bools order false before true; anything else
+// is compared numerically as float64. Nil cells read as the zero value.
 func (vm *VM) compareValues(a, b dataframe.Series, i int) int {
-	// Handle different types by converting to float64 for comparison
+	switch ta, tb := getSeriesType(a), getSeriesType(b); {
+	case ta == TypeString && tb == TypeString:
+		av, _ := getStringValue(a, i)
+		bv, _ := getStringValue(b, i)
+		return strings.Compare(av, bv)
+	case ta == TypeBool && tb == TypeBool:
+		av, _ := getBoolValue(a, i)
+		bv, _ := getBoolValue(b, i)
+		switch {
+		case av == bv:
+			return 0
+		case bv:
+			return -1
+		default:
+			return 1
+		}
+	}
+
 	av, _ := getFloat64Value(a, i)
 	bv, _ := getFloat64Value(b, i)
-
-	if av < bv {
-		return -1
-	} else if av > bv {
-		return 1
-	}
-	return 0
+	return cmp.Compare(av, bv)
 }
 
 // ===== Logical Operations =====
@@ -943,6 +2514,102 @@ func (vm *VM) vectorOr(a, b dataframe.Series) dataframe.Series {
 	return newBoolSeries("result", data)
 }
 
+func (vm *VM) vectorXor(a, b dataframe.Series) dataframe.Series {
+	length := getSeriesLength(a)
+	data := make([]bool, length)
+	for i := 0; i < length; i++ {
+		av, _ := getBoolValue(a, i)
+		bv, _ := getBoolValue(b, i)
+		data[i] = av != bv
+	}
+	return newBoolSeries("result", data)
+}
+
+// maskedSelect is the inverse of FILTER for boolean results: the k-th value
+// of vals lands on the k-th row where mask is true, and every other row is
+// false. Missing or nil values read as false.
+func (vm *VM) maskedSelect(mask, vals dataframe.Series) dataframe.Series {
+	length := getSeriesLength(mask)
+	data := make([]bool, length)
+	k := 0
+	for i := 0; i < length; i++ {
+		if m, _ := getBoolValue(mask, i); m {
+			data[i], _ = getBoolValue(vals, k)
+			k++
+		}
+	}
+	return newBoolSeries("result", data)
+}
+
+// inSet marks each cell of s that equals one of values. Numeric values match
+// int and float columns alike; nil cells never match. Int columns compare as
+// int64 so values beyond 2^53 stay distinct, and a float value matches them
+// only when it is a whole number.
+func (vm *VM) inSet(s dataframe.Series, values []any) (dataframe.Series, error) {
+	length := getSeriesLength(s)
+	data := make([]bool, length)
+	var err error
+	switch getSeriesType(s) {
+	case TypeInt64:
+		set := make(map[int64]bool, len(values))
+		for _, v := range values {
+			switch x := v.(type) {
+			case int64:
+				set[x] = true
+			case float64:
+				if x == math.Trunc(x) && x >= math.MinInt64 && x < math.MaxInt64 {
+					set[int64(x)] = true
+				}
+			}
+		}
+		err = vm.forEachChunk(length, func(lo, hi int) error {
+			for i := lo; i < hi; i++ {
+				if v, ok := getInt64Value(s, i); ok {
+					data[i] = set[v]
+				}
+			}
+			return nil
+		})
+	case TypeFloat64:
+		set := make(map[float64]bool, len(values))
+		for _, v := range values {
+			switch x := v.(type) {
+			case int64:
+				set[float64(x)] = true
+			case float64:
+				set[x] = true
+			}
+		}
+		err = vm.forEachChunk(length, func(lo, hi int) error {
+			for i := lo; i < hi; i++ {
+				if v, ok := getFloat64Value(s, i); ok {
+					data[i] = set[v]
+				}
+			}
+			return nil
+		})
+	case TypeString:
+		set := make(map[string]bool, len(values))
+		for _, v := range values {
+			if x, ok := v.(string); ok {
+				set[x] = true
+			}
+		}
+		err = vm.forEachChunk(length, func(lo, hi int) error {
+			for i := lo; i < hi; i++ {
+				if v, ok := getStringValue(s, i); ok {
+					data[i] = set[v]
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newBoolSeries("result", data), nil
+}
+
 func (vm *VM) vectorNot(a dataframe.Series) dataframe.Series {
 	length := getSeriesLength(a)
 	data := make([]bool, length)
@@ -955,16 +2622,25 @@ func (vm *VM) vectorNot(a dataframe.Series) dataframe.Series {
 
 // ===== Filter Operations =====
 
-func (vm *VM) filterSeriesWithMask(data, mask dataframe.Series) dataframe.Series {
-	// Convert bool series to bitmap
-	length := getSeriesLength(mask)
-	bitmap := NewBitmap(length)
+func (vm *VM) filterSeriesWithMask(data, mask dataframe.Series) (dataframe.Series, error) {
+	if data == nil {
+		return nil, nil
+	}
+	// Gather the rows where the mask is true in one pass
+	var vals []interface{}
+	length := min(getSeriesLength(mask), data.NRows())
 	for i := 0; i < length; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return nil, err
+		}
 		if v, ok := getBoolValue(mask, i); ok && v {
-			bitmap.Set(i)
+			vals = append(vals, data.Value(i))
 		}
 	}
-	return filterSeries(data, bitmap)
+	if len(vals) == 0 {
+		return createEmptySeries(data), nil
+	}
+	return createSeriesWithValues(data, vals), nil
 }
 
 func (vm *VM) takeSeries(data, indices dataframe.Series) dataframe.Series {
@@ -980,121 +2656,445 @@ func (vm *VM) takeSeries(data, indices dataframe.Series) dataframe.Series {
 
 // ===== Aggregation Operations =====
 
-func (vm *VM) reduceSum(s dataframe.Series) int64 {
+func (vm *VM) reduceSum(s dataframe.Series) (int64, error) {
 	var sum int64
 	n := getSeriesLength(s)
 	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getInt64Value(s, i); ok {
 			sum += v
 		}
 	}
-	return sum
+	return sum, nil
 }
 
-func (vm *VM) reduceSumF(s dataframe.Series) float64 {
+func (vm *VM) reduceSumF(s dataframe.Series) (float64, error) {
 	var sum float64
 	n := getSeriesLength(s)
 	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getFloat64Value(s, i); ok {
 			sum += v
 		}
 	}
-	return sum
+	return sum, nil
+}
+
+// reduceProd multiplies the non-null values of s. An empty or all-null
+// series yields the multiplicative identity 1. Overflow wraps like reduceSum.
+func (vm *VM) reduceProd(s dataframe.Series) (int64, error) {
+	prod := int64(1)
+	n := getSeriesLength(s)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
+		if v, ok := getInt64Value(s, i); ok {
+			prod *= v
+		}
+	}
+	return prod, nil
+}
+
+// reduceProdF multiplies the non-null values of s as floats. An empty or
+// all-null series yields 1.
+func (vm *VM) reduceProdF(s dataframe.Series) (float64, error) {
+	prod := 1.0
+	n := getSeriesLength(s)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
+		if v, ok := getFloat64Value(s, i); ok {
+			prod *= v
+		}
+	}
+	return prod, nil
 }
 
-func (vm *VM) reduceCount(s dataframe.Series) int64 {
+func (vm *VM) reduceCount(s dataframe.Series) (int64, error) {
 	// For bool series, count true values
 	if getSeriesType(s) == TypeBool {
 		var count int64
 		n := getSeriesLength(s)
 		for i := 0; i < n; i++ {
+			if err := vm.checkContext(i); err != nil {
+				return 0, err
+			}
 			if v, ok := getBoolValue(s, i); ok && v {
 				count++
 			}
 		}
-		return count
+		return count, nil
 	}
 	// For other series, count non-nil values
 	var count int64
 	n := getSeriesLength(s)
 	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if !isNil(s, i) {
 			count++
 		}
 	}
-	return count
+	return count, nil
 }
 
-func (vm *VM) reduceMin(s dataframe.Series) int64 {
+func (vm *VM) reduceMin(s dataframe.Series) (int64, error) {
 	n := getSeriesLength(s)
 	if n == 0 {
-		return 0
+		return 0, nil
 	}
 	min, _ := getInt64Value(s, 0)
 	for i := 1; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getInt64Value(s, i); ok && v < min {
 			min = v
 		}
 	}
-	return min
+	return min, nil
 }
 
-func (vm *VM) reduceMax(s dataframe.Series) int64 {
+func (vm *VM) reduceMax(s dataframe.Series) (int64, error) {
 	n := getSeriesLength(s)
 	if n == 0 {
-		return 0
+		return 0, nil
 	}
 	max, _ := getInt64Value(s, 0)
 	for i := 1; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getInt64Value(s, i); ok && v > max {
 			max = v
 		}
 	}
-	return max
+	return max, nil
 }
 
-func (vm *VM) reduceMinF(s dataframe.Series) float64 {
+func (vm *VM) reduceMinF(s dataframe.Series) (float64, error) {
 	n := getSeriesLength(s)
 	if n == 0 {
-		return 0
+		return 0, nil
 	}
 	min, _ := getFloat64Value(s, 0)
 	for i := 1; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getFloat64Value(s, i); ok && v < min {
 			min = v
 		}
 	}
-	return min
+	return min, nil
 }
 
-func (vm *VM) reduceMaxF(s dataframe.Series) float64 {
+func (vm *VM) reduceMaxF(s dataframe.Series) (float64, error) {
 	n := getSeriesLength(s)
 	if n == 0 {
-		return 0
+		return 0, nil
 	}
 	max, _ := getFloat64Value(s, 0)
 	for i := 1; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getFloat64Value(s, i); ok && v > max {
 			max = v
 		}
 	}
-	return max
+	return max, nil
 }
 
-func (vm *VM) reduceMean(s dataframe.Series) float64 {
+func (vm *VM) reduceMean(s dataframe.Series) (float64, error) {
 	var sum float64
 	var count int
 	n := getSeriesLength(s)
 	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
 		if v, ok := getFloat64Value(s, i); ok {
 			sum += v
 			count++
 		}
 	}
 	if count == 0 {
-		return 0
+		return 0, nil
+	}
+	return sum / float64(count), nil
+}
+
+// reduceAny returns 1 if any value in a bool series is true, else 0.
+func (vm *VM) reduceAny(s dataframe.Series) (int64, error) {
+	n := getSeriesLength(s)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
+		if v, ok := getBoolValue(s, i); ok && v {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// reduceAll returns 1 if every value in a bool series is true, else 0.
+// Nil values count as false; an empty series returns 1.
+func (vm *VM) reduceAll(s dataframe.Series) (int64, error) {
+	n := getSeriesLength(s)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
+		if v, ok := getBoolValue(s, i); !ok || !v {
+			return 0, nil
+		}
+	}
+	return 1, nil
+}
+
+// reduceQuantileF returns the q-th quantile (0.0-1.0) of the non-null values,
+// linearly interpolating between the two closest ranks. Returns 0 when empty.
+func (vm *VM) reduceQuantileF(s dataframe.Series, q float64) (float64, error) {
+	n := getSeriesLength(s)
+	vals := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return 0, err
+		}
+		if v, ok := getFloat64Value(s, i); ok {
+			vals = append(vals, v)
+		}
+	}
+	return quantile(vals, q), nil
+}
+
+// pairedFloats returns the rows where both a and b hold numeric values.
+func (vm *VM) pairedFloats(a, b dataframe.Series) (xs, ys []float64, err error) {
+	n := getSeriesLength(a)
+	if m := getSeriesLength(b); m < n {
+		n = m
 	}
-	return sum / float64(count)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return nil, nil, err
+		}
+		x, okX := getFloat64Value(a, i)
+		y, okY := getFloat64Value(b, i)
+		if okX && okY {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	return xs, ys, nil
+}
+
+// moments returns the sums of squared deviations from the mean for xs and ys
+// and the sum of their cross deviations.
+func moments(xs, ys []float64) (sxx, syy, sxy float64) {
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	return sxx, syy, sxy
+}
+
+// corrF returns the Pearson correlation of a and b over rows where both are
+// non-null. Returns NaN when fewer than two pairs exist or either side has
+// zero variance.
+func (vm *VM) corrF(a, b dataframe.Series) (float64, error) {
+	xs, ys, err := vm.pairedFloats(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if len(xs) < 2 {
+		return math.NaN(), nil
+	}
+	sxx, syy, sxy := moments(xs, ys)
+	if sxx == 0 || syy == 0 {
+		return math.NaN(), nil
+	}
+	return sxy / math.Sqrt(sxx*syy), nil
+}
+
+// covF returns the sample covariance (n-1 denominator) of a and b over rows
+// where both are non-null. Returns NaN when fewer than two pairs exist.
+func (vm *VM) covF(a, b dataframe.Series) (float64, error) {
+	xs, ys, err := vm.pairedFloats(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if len(xs) < 2 {
+		return math.NaN(), nil
+	}
+	_, _, sxy := moments(xs, ys)
+	return sxy / float64(len(xs)-1), nil
+}
+
+// wmeanF returns sum(v*w)/sum(w) over rows where both the value and weight
+// are non-null. A zero total weight follows the float divide-by-zero mode:
+// NaN by default (0/0), 0 with DivByZeroZero, or ErrDivisionByZero.
+func (vm *VM) wmeanF(values, weights dataframe.Series) (float64, error) {
+	vs, ws, err := vm.pairedFloats(values, weights)
+	if err != nil {
+		return 0, err
+	}
+	var sum, total float64
+	for i := range vs {
+		sum += vs[i] * ws[i]
+		total += ws[i]
+	}
+	if total != 0 {
+		return sum / total, nil
+	}
+	switch vm.divByZeroMode {
+	case DivByZeroZero:
+		return 0, nil
+	case DivByZeroError:
+		return 0, fmt.Errorf("%w: total weight is zero", ErrDivisionByZero)
+	default:
+		return math.NaN(), nil
+	}
+}
+
+// ===== Scalar Operations =====
+
+// vecIndexRow checks that idx is a row of s.
+func vecIndexRow(s dataframe.Series, idx int64) (int, error) {
+	n := getSeriesLength(s)
+	if idx < 0 || idx >= int64(n) {
+		return 0, fmt.Errorf("%w: %d (length %d)", ErrIndexOutOfRange, idx, n)
+	}
+	return int(idx), nil
+}
+
+// vecIndex returns s[idx] as an int64. Floats are truncated; nulls and
+// strings are a type mismatch since R registers have no null.
+func (vm *VM) vecIndex(s dataframe.Series, idx int64) (int64, error) {
+	row, err := vecIndexRow(s, idx)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := getInt64Value(s, row)
+	if !ok {
+		return 0, fmt.Errorf("%w: row %d is %v, not a number", ErrTypeMismatch, row, s.Value(row))
+	}
+	return v, nil
+}
+
+// vecIndexF returns s[idx] as a float64, with NaN for a null.
+func (vm *VM) vecIndexF(s dataframe.Series, idx int64) (float64, error) {
+	row, err := vecIndexRow(s, idx)
+	if err != nil {
+		return 0, err
+	}
+	if v, ok := getFloat64Value(s, row); ok {
+		return v, nil
+	}
+	switch s.Value(row).(type) {
+	case nil, float64: // null, or a NaN the fast path reports as missing
+		return math.NaN(), nil
+	}
+	return 0, fmt.Errorf("%w: row %d is %v, not a number", ErrTypeMismatch, row, s.Value(row))
+}
+
+// ===== Frame Operations =====
+
+// topN returns the n rows of df with the smallest (or, when desc is set, the
+// largest) values in colName, in that order. Nulls sort last either way and
+// rows with equal values keep their original order.
+func (vm *VM) topN(df *dataframe.DataFrame, colName string, n int, desc bool) (*dataframe.DataFrame, error) {
+	if df == nil {
+		return nil, ErrFrameNotFound
+	}
+	col, ok := getDataFrameColumn(df, colName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, colName)
+	}
+
+	rows := make([]int, getSeriesLength(col))
+	for i := range rows {
+		rows[i] = i
+	}
+	isString := getSeriesType(col) == TypeString
+	sort.SliceStable(rows, func(a, b int) bool {
+		ra, rb := rows[a], rows[b]
+		if nilA, nilB := isNil(col, ra), isNil(col, rb); nilA || nilB {
+			return !nilA && nilB
+		}
+		if isString {
+			sa, _ := getStringValue(col, ra)
+			sb, _ := getStringValue(col, rb)
+			if desc {
+				return sa > sb
+			}
+			return sa < sb
+		}
+		fa, _ := getFloat64Value(col, ra)
+		fb, _ := getFloat64Value(col, rb)
+		if desc {
+			return fa > fb
+		}
+		return fa < fb
+	})
+	if n < len(rows) {
+		rows = rows[:max(n, 0)]
+	}
+
+	series := make([]dataframe.Series, len(df.Series))
+	for i, s := range df.Series {
+		vals := make([]interface{}, len(rows))
+		for j, row := range rows {
+			vals[j] = s.Value(row)
+		}
+		series[i] = createSeriesWithValues(s, vals)
+	}
+	return dataframe.NewDataFrame(series...), nil
+}
+
+// random returns the VM's RNG, seeding it from the clock if SetSeed was
+// never called.
+func (vm *VM) random() *rand.Rand {
+	if vm.rng == nil {
+		vm.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return vm.rng
+}
+
+// sample returns n rows of df drawn without replacement, in the order they
+// were drawn. An n at or above the row count returns every row, shuffled.
+func (vm *VM) sample(df *dataframe.DataFrame, n int, rng *rand.Rand) (*dataframe.DataFrame, error) {
+	if df == nil {
+		return nil, ErrFrameNotFound
+	}
+
+	rows := rng.Perm(getDataFrameLength(df))
+	if n < len(rows) {
+		rows = rows[:max(n, 0)]
+	}
+
+	series := make([]dataframe.Series, len(df.Series))
+	for i, s := range df.Series {
+		series[i] = vm.gatherSeries(s, rows, s.Name())
+	}
+	return dataframe.NewDataFrame(series...), nil
 }
 
 // ===== GroupBy Operations =====
@@ -1159,437 +3159,1594 @@ func (vm *VM) groupCount(gb *GroupByResult) dataframe.Series {
 	return newInt64Series("count", data)
 }
 
-func (vm *VM) groupSum(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
+// groupSizesFrame builds the GROUP_SIZES frame: a "key" column holding
+// gb.Keys and a "size" column holding the row count of each group.
+func groupSizesFrame(gb *GroupByResult, sizes dataframe.Series) *dataframe.DataFrame {
+	keys := cloneSeries(gb.Keys)
+	keys.Rename("key")
+	sizes.Rename("size")
+	return dataframe.NewDataFrame(keys, sizes)
+}
+
+func (vm *VM) groupSum(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]int64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		var sum int64
-		for _, idx := range gb.Groups[key] {
-			if v, ok := getInt64Value(valCol, idx); ok {
-				sum += v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			var sum int64
+			for _, idx := range gb.Groups[key] {
+				if v, ok := getInt64Value(valCol, idx); ok {
+					sum += v
+				}
 			}
+			data[i] = sum
 		}
-		data[i] = sum
-	}
+	})
 	return newInt64Series("sum", data)
 }
 
 func (vm *VM) groupSumF(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]float64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		var sum float64
-		for _, idx := range gb.Groups[key] {
-			if v, ok := getFloat64Value(valCol, idx); ok {
-				sum += v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			var sum float64
+			for _, idx := range gb.Groups[key] {
+				if v, ok := getFloat64Value(valCol, idx); ok {
+					sum += v
+				}
 			}
+			data[i] = sum
 		}
-		data[i] = sum
-	}
+	})
 	return newFloat64Series("sum", data)
 }
 
 func (vm *VM) groupMin(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]int64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		indices := gb.Groups[key]
-		if len(indices) > 0 {
-			min, _ := getInt64Value(valCol, indices[0])
-			for _, idx := range indices[1:] {
-				if v, ok := getInt64Value(valCol, idx); ok && v < min {
-					min = v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			indices := gb.Groups[key]
+			if len(indices) > 0 {
+				min, _ := getInt64Value(valCol, indices[0])
+				for _, idx := range indices[1:] {
+					if v, ok := getInt64Value(valCol, idx); ok && v < min {
+						min = v
+					}
 				}
+				data[i] = min
 			}
-			data[i] = min
 		}
-	}
+	})
 	return newInt64Series("min", data)
 }
 
 func (vm *VM) groupMax(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]int64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		indices := gb.Groups[key]
-		if len(indices) > 0 {
-			max, _ := getInt64Value(valCol, indices[0])
-			for _, idx := range indices[1:] {
-				if v, ok := getInt64Value(valCol, idx); ok && v > max {
-					max = v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			indices := gb.Groups[key]
+			if len(indices) > 0 {
+				max, _ := getInt64Value(valCol, indices[0])
+				for _, idx := range indices[1:] {
+					if v, ok := getInt64Value(valCol, idx); ok && v > max {
+						max = v
+					}
 				}
+				data[i] = max
 			}
-			data[i] = max
 		}
-	}
+	})
 	return newInt64Series("max", data)
 }
 
 func (vm *VM) groupMinF(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]float64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		indices := gb.Groups[key]
-		if len(indices) > 0 {
-			min, _ := getFloat64Value(valCol, indices[0])
-			for _, idx := range indices[1:] {
-				if v, ok := getFloat64Value(valCol, idx); ok && v < min {
-					min = v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			indices := gb.Groups[key]
+			if len(indices) > 0 {
+				min, _ := getFloat64Value(valCol, indices[0])
+				for _, idx := range indices[1:] {
+					if v, ok := getFloat64Value(valCol, idx); ok && v < min {
+						min = v
+					}
 				}
+				data[i] = min
 			}
-			data[i] = min
 		}
-	}
+	})
 	return newFloat64Series("min", data)
 }
 
 func (vm *VM) groupMaxF(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
 	data := make([]float64, len(gb.KeyOrder))
-	for i, key := range gb.KeyOrder {
-		indices := gb.Groups[key]
-		if len(indices) > 0 {
-			max, _ := getFloat64Value(valCol, indices[0])
-			for _, idx := range indices[1:] {
-				if v, ok := getFloat64Value(valCol, idx); ok && v > max {
-					max = v
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			indices := gb.Groups[key]
+			if len(indices) > 0 {
+				max, _ := getFloat64Value(valCol, indices[0])
+				for _, idx := range indices[1:] {
+					if v, ok := getFloat64Value(valCol, idx); ok && v > max {
+						max = v
+					}
 				}
+				data[i] = max
 			}
-			data[i] = max
 		}
-	}
+	})
 	return newFloat64Series("max", data)
 }
 
 func (vm *VM) groupMean(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
+	data := make([]float64, len(gb.KeyOrder))
+	vm.forEachGroupChunk(gb, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			key := gb.KeyOrder[i]
+			indices := gb.Groups[key]
+			if len(indices) > 0 {
+				var sum float64
+				for _, idx := range indices {
+					if v, ok := getFloat64Value(valCol, idx); ok {
+						sum += v
+					}
+				}
+				data[i] = sum / float64(len(indices))
+			}
+		}
+	})
+	return newFloat64Series("mean", data)
+}
+
+// groupFirst returns the value at each group's first row, keeping the column type.
+func (vm *VM) groupFirst(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
+	vals := make([]interface{}, len(gb.KeyOrder))
+	for i, key := range gb.KeyOrder {
+		if indices := gb.Groups[key]; len(indices) > 0 {
+			vals[i] = valCol.Value(indices[0])
+		}
+	}
+	return createSeriesWithValues(valCol, vals)
+}
+
+// groupLast returns the value at each group's last row, keeping the column type.
+func (vm *VM) groupLast(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
+	vals := make([]interface{}, len(gb.KeyOrder))
+	for i, key := range gb.KeyOrder {
+		if indices := gb.Groups[key]; len(indices) > 0 {
+			vals[i] = valCol.Value(indices[len(indices)-1])
+		}
+	}
+	return createSeriesWithValues(valCol, vals)
+}
+
+// groupConcat joins each group's non-null values with sep, in row order.
+func (vm *VM) groupConcat(gb *GroupByResult, valCol dataframe.Series, sep string) dataframe.Series {
+	data := make([]string, len(gb.KeyOrder))
+	for i, key := range gb.KeyOrder {
+		parts := make([]string, 0, len(gb.Groups[key]))
+		for _, idx := range gb.Groups[key] {
+			if isNil(valCol, idx) {
+				continue
+			}
+			if v, ok := getStringValue(valCol, idx); ok {
+				parts = append(parts, v)
+			} else {
+				parts = append(parts, fmt.Sprint(valCol.Value(idx)))
+			}
+		}
+		data[i] = strings.Join(parts, sep)
+	}
+	return newStringSeries("group_concat", data)
+}
+
+// groupMedianF returns the median of each group's non-null values as floats.
+func (vm *VM) groupMedianF(gb *GroupByResult, valCol dataframe.Series) dataframe.Series {
+	return newFloat64Series("median", vm.groupReduceF(gb, valCol, median))
+}
+
+// groupQuantileF returns the q-th quantile of each group's non-null values.
+func (vm *VM) groupQuantileF(gb *GroupByResult, valCol dataframe.Series, q float64) dataframe.Series {
+	data := vm.groupReduceF(gb, valCol, func(vals []float64) float64 {
+		return quantile(vals, q)
+	})
+	return newFloat64Series("quantile", data)
+}
+
+// groupReduceF collects each group's non-null values as floats and reduces
+// them with fn, in key order. fn may reorder the slice it is given.
+func (vm *VM) groupReduceF(gb *GroupByResult, valCol dataframe.Series, fn func([]float64) float64) []float64 {
 	data := make([]float64, len(gb.KeyOrder))
 	for i, key := range gb.KeyOrder {
 		indices := gb.Groups[key]
-		if len(indices) > 0 {
-			var sum float64
-			for _, idx := range indices {
-				if v, ok := getFloat64Value(valCol, idx); ok {
-					sum += v
-				}
+		vals := make([]float64, 0, len(indices))
+		for _, idx := range indices {
+			if v, ok := getFloat64Value(valCol, idx); ok {
+				vals = append(vals, v)
 			}
-			data[i] = sum / float64(len(indices))
 		}
+		data[i] = fn(vals)
 	}
-	return newFloat64Series("mean", data)
+	return data
+}
+
+// median returns the middle value of vals, averaging the two middle values
+// when the count is even. It sorts vals in place and returns 0 when empty.
+func median(vals []float64) float64 {
+	return quantile(vals, 0.5)
+}
+
+// quantile returns the q-th quantile of vals using linear interpolation
+// between closest ranks (position q*(n-1)). q is clamped to [0, 1].
+// It sorts vals in place and returns 0 when empty.
+func quantile(vals []float64, q float64) float64 {
+	n := len(vals)
+	if n == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	sort.Float64s(vals)
+	pos := q * float64(n-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return vals[lo] + (pos-float64(lo))*(vals[hi]-vals[lo])
 }
 
 // ===== Join Operations =====
 
-func (vm *VM) joinInner(left, right *dataframe.DataFrame, keyName string) *dataframe.DataFrame {
+func (vm *VM) joinInner(left, right *dataframe.DataFrame, keyName string) (*dataframe.DataFrame, error) {
 	leftKey, _ := getDataFrameColumn(left, keyName)
 	rightKey, _ := getDataFrameColumn(right, keyName)
 
 	// Build right index
-	rightIndex := vm.buildJoinIndex(rightKey)
+	rightIndex, err := vm.buildJoinIndex(rightKey)
+	if err != nil {
+		return nil, err
+	}
 
 	// Find matching rows
 	var leftIndices, rightIndices []int
 	n := getSeriesLength(leftKey)
 	for i := 0; i < n; i++ {
-		key := leftKey.Value(i)
+		if err := vm.checkContext(i); err != nil {
+			return nil, err
+		}
+		key := joinKey(leftKey, i)
+		if matches, ok := rightIndex[key]; ok {
+			for _, j := range matches {
+				leftIndices = append(leftIndices, i)
+				rightIndices = append(rightIndices, j)
+			}
+		}
+	}
+
+	return vm.buildJoinResult(left, right, keyName, leftIndices, rightIndices), nil
+}
+
+// joinInnerSorted is joinInner for frames already sorted ascending on the
+// key. It merges the two key columns instead of indexing the right frame, and
+// produces the same rows in the same order. If either key column is unsorted
+// or holds nulls, NaNs or keys that cannot be ordered against each other, it
+// falls back to the hash join.
+func (vm *VM) joinInnerSorted(left, right *dataframe.DataFrame, keyName string) (*dataframe.DataFrame, error) {
+	leftKey, _ := getDataFrameColumn(left, keyName)
+	rightKey, _ := getDataFrameColumn(right, keyName)
+	if !joinKeysSorted(leftKey) || !joinKeysSorted(rightKey) {
+		return vm.joinInner(left, right, keyName)
+	}
+
+	var leftIndices, rightIndices []int
+	n, m := getSeriesLength(leftKey), getSeriesLength(rightKey)
+	i, j := 0, 0
+	for step := 0; i < n && j < m; step++ {
+		if err := vm.checkContext(step); err != nil {
+			return nil, err
+		}
+		c, ok := compareJoinKeys(joinKey(leftKey, i), joinKey(rightKey, j))
+		if !ok {
+			return vm.joinInner(left, right, keyName)
+		}
+		switch {
+		case c < 0:
+			i++
+		case c > 0:
+			j++
+		default:
+			// Pair every left row of this key with every right row of it
+			jEnd := j + 1
+			for jEnd < m && joinKeysEqual(rightKey, j, jEnd) {
+				jEnd++
+			}
+			iEnd := i + 1
+			for iEnd < n && joinKeysEqual(leftKey, i, iEnd) {
+				iEnd++
+			}
+			for ; i < iEnd; i++ {
+				for k := j; k < jEnd; k++ {
+					if err := vm.checkContext(len(leftIndices)); err != nil {
+						return nil, err
+					}
+					leftIndices = append(leftIndices, i)
+					rightIndices = append(rightIndices, k)
+				}
+			}
+			j = jEnd
+		}
+	}
+
+	return vm.buildJoinResult(left, right, keyName, leftIndices, rightIndices), nil
+}
+
+// joinKeysSorted reports whether col's join keys are in ascending order and
+// can all be ordered against each other. A missing column counts as sorted.
+func joinKeysSorted(col dataframe.Series) bool {
+	n := getSeriesLength(col)
+	if n == 0 {
+		return true
+	}
+	prev := joinKey(col, 0)
+	if _, ok := compareJoinKeys(prev, prev); !ok {
+		return false
+	}
+	for i := 1; i < n; i++ {
+		key := joinKey(col, i)
+		if c, ok := compareJoinKeys(prev, key); !ok || c > 0 {
+			return false
+		}
+		prev = key
+	}
+	return true
+}
+
+// joinKeysEqual reports whether rows a and b of a sorted key column hold the
+// same join key.
+func joinKeysEqual(col dataframe.Series, a, b int) bool {
+	c, ok := compareJoinKeys(joinKey(col, a), joinKey(col, b))
+	return ok && c == 0
+}
+
+// compareJoinKeys orders two keys returned by joinKey, returning -1, 0 or 1,
+// and false if they cannot be ordered: nulls, NaNs, and strings against
+// numbers. Keys compare equal exactly when the hash join would match them.
+func compareJoinKeys(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), true
+		case float64:
+			return compareIntFloat(x, y)
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			if math.IsNaN(x) || math.IsNaN(y) {
+				return 0, false
+			}
+			return cmp.Compare(x, y), true
+		case int64:
+			c, ok := compareIntFloat(y, x)
+			return -c, ok
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+// compareIntFloat orders an int64 key against a float64 key that joinKey
+// left as a float, meaning f is fractional or outside the int64 range, so the
+// two are never equal.
+func compareIntFloat(i int64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case float64(i) < f:
+		return -1, true
+	case float64(i) > f:
+		return 1, true
+	case f > 0:
+		// f is beyond MaxInt64 and float64(i) rounded up to it
+		return -1, true
+	default:
+		return 1, true
+	}
+}
+
+func (vm *VM) joinLeft(left, right *dataframe.DataFrame, keyName string) (*dataframe.DataFrame, error) {
+	leftKey, _ := getDataFrameColumn(left, keyName)
+	rightKey, _ := getDataFrameColumn(right, keyName)
+
+	// Build right index
+	rightIndex, err := vm.buildJoinIndex(rightKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find matching rows, keeping all left rows
+	var leftIndices, rightIndices []int
+	n := getSeriesLength(leftKey)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return nil, err
+		}
+		key := joinKey(leftKey, i)
 		if matches, ok := rightIndex[key]; ok {
 			for _, j := range matches {
 				leftIndices = append(leftIndices, i)
 				rightIndices = append(rightIndices, j)
 			}
+		} else {
+			leftIndices = append(leftIndices, i)
+			rightIndices = append(rightIndices, -1) // null marker
+		}
+	}
+
+	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, true, false), nil
+}
+
+func (vm *VM) joinRight(left, right *dataframe.DataFrame, keyName string) (*dataframe.DataFrame, error) {
+	leftKey, _ := getDataFrameColumn(left, keyName)
+	rightKey, _ := getDataFrameColumn(right, keyName)
+
+	// Build left index
+	leftIndex, err := vm.buildJoinIndex(leftKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find matching rows, keeping all right rows
+	var leftIndices, rightIndices []int
+	n := getSeriesLength(rightKey)
+	for j := 0; j < n; j++ {
+		if err := vm.checkContext(j); err != nil {
+			return nil, err
+		}
+		key := joinKey(rightKey, j)
+		if matches, ok := leftIndex[key]; ok {
+			for _, i := range matches {
+				leftIndices = append(leftIndices, i)
+				rightIndices = append(rightIndices, j)
+			}
+		} else {
+			leftIndices = append(leftIndices, -1) // null marker
+			rightIndices = append(rightIndices, j)
+		}
+	}
+
+	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, false, true), nil
+}
+
+func (vm *VM) joinOuter(left, right *dataframe.DataFrame, keyName string) (*dataframe.DataFrame, error) {
+	leftKey, _ := getDataFrameColumn(left, keyName)
+	rightKey, _ := getDataFrameColumn(right, keyName)
+
+	rightIndex, err := vm.buildJoinIndex(rightKey)
+	if err != nil {
+		return nil, err
+	}
+	matchedRight := make(map[int]bool)
+
+	var leftIndices, rightIndices []int
+
+	// Match from left side
+	n := getSeriesLength(leftKey)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return nil, err
+		}
+		key := joinKey(leftKey, i)
+		if matches, ok := rightIndex[key]; ok {
+			for _, j := range matches {
+				leftIndices = append(leftIndices, i)
+				rightIndices = append(rightIndices, j)
+				matchedRight[j] = true
+			}
+		} else {
+			leftIndices = append(leftIndices, i)
+			rightIndices = append(rightIndices, -1)
+		}
+	}
+
+	// Add unmatched right rows
+	m := getSeriesLength(rightKey)
+	for j := 0; j < m; j++ {
+		if err := vm.checkContext(j); err != nil {
+			return nil, err
+		}
+		if !matchedRight[j] {
+			leftIndices = append(leftIndices, -1)
+			rightIndices = append(rightIndices, j)
+		}
+	}
+
+	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, true, true), nil
+}
+
+// joinCross pairs every left row with every right row, left-major. Right
+// columns are prefixed with right_ as in the keyed joins. With SetMaxAlloc
+// in effect, results estimated above the limit at 8 bytes per cell fail with
+// ErrMemoryLimit before anything is built.
+func (vm *VM) joinCross(left, right *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	if left == nil || right == nil {
+		return nil, ErrFrameNotFound
+	}
+
+	n, m := getDataFrameLength(left), getDataFrameLength(right)
+	rows := crossRows(n, m)
+	if vm.maxAlloc > 0 {
+		cols := int64(len(left.Series) + len(right.Series))
+		if int64(rows) > vm.maxAlloc/8/max(cols, 1) {
+			return nil, fmt.Errorf("%w: cross join of %d x %d rows", ErrMemoryLimit, n, m)
+		}
+	}
+	if rows == math.MaxInt {
+		return nil, fmt.Errorf("%w: cross join of %d x %d rows", ErrMemoryLimit, n, m)
+	}
+
+	leftIndices := make([]int, 0, rows)
+	rightIndices := make([]int, 0, rows)
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			if err := vm.checkContext(len(leftIndices)); err != nil {
+				return nil, err
+			}
+			leftIndices = append(leftIndices, i)
+			rightIndices = append(rightIndices, j)
+		}
+	}
+
+	return vm.buildJoinResult(left, right, "", leftIndices, rightIndices), nil
+}
+
+// crossRows returns n*m, saturating at math.MaxInt.
+func crossRows(n, m int) int {
+	if n > 0 && m > math.MaxInt/n {
+		return math.MaxInt
+	}
+	return n * m
+}
+
+func (vm *VM) buildJoinIndex(col dataframe.Series) (map[any][]int, error) {
+	index := make(map[any][]int)
+	n := getSeriesLength(col)
+	for i := 0; i < n; i++ {
+		if err := vm.checkContext(i); err != nil {
+			return nil, err
+		}
+		key := joinKey(col, i)
+		index[key] = append(index[key], i)
+	}
+	return index, nil
+}
+
+// joinKey returns row i of a join key column as a map key. Values keep their
+// native type, so string keys and int64 keys beyond 2^53 match exactly;
+// only integral floats are converted to int64, letting a float64 key column
+// match an int64 one.
+func joinKey(col dataframe.Series, i int) any {
+	v := col.Value(i)
+	if f, ok := v.(float64); ok {
+		if key, ok := joinKeyInt(f); ok {
+			return key
+		}
+	}
+	return v
+}
+
+// joinKeyInt converts f to int64 if it is integral and in range.
+func joinKeyInt(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// gatherJoinKey builds the key column of an outer or right join. Rows with
+// no left match (index -1) take their key from the right frame, converted to
+// the left column's type when the two differ.
+func (vm *VM) gatherJoinKey(leftKey, rightKey dataframe.Series, leftIndices, rightIndices []int) dataframe.Series {
+	vals := make([]interface{}, len(leftIndices))
+	for k, i := range leftIndices {
+		switch {
+		case i >= 0:
+			vals[k] = leftKey.Value(i)
+		case rightKey != nil && rightIndices[k] >= 0:
+			vals[k] = convertJoinKey(leftKey, rightKey.Value(rightIndices[k]))
+		}
+	}
+	return createSeriesWithValues(leftKey, vals)
+}
+
+// convertJoinKey converts a right-hand key value v to the type of the left
+// key column, or returns nil if it cannot be represented there.
+func convertJoinKey(leftKey dataframe.Series, v any) any {
+	switch leftKey.(type) {
+	case *dataframe.SeriesInt64:
+		switch k := v.(type) {
+		case int64:
+			return k
+		case float64:
+			if key, ok := joinKeyInt(k); ok {
+				return key
+			}
+		}
+		return nil
+	case *dataframe.SeriesFloat64:
+		switch k := v.(type) {
+		case int64:
+			return float64(k)
+		case float64:
+			return k
+		}
+		return nil
+	case *dataframe.SeriesString:
+		if k, ok := v.(string); ok {
+			return k
+		}
+		return nil
+	}
+	return v
+}
+
+func (vm *VM) buildJoinResult(left, right *dataframe.DataFrame, keyName string, leftIndices, rightIndices []int) *dataframe.DataFrame {
+	// Collect all series first, then create DataFrame
+	var allSeries []dataframe.Series
+
+	// Gather columns from left frame
+	for _, s := range left.Series {
+		colName := s.Name()
+		dstCol := vm.gatherSeries(s, leftIndices, colName)
+		allSeries = append(allSeries, dstCol)
+	}
+
+	// Gather columns from right frame (except key column)
+	for _, s := range right.Series {
+		colName := s.Name()
+		if colName == keyName {
+			continue
+		}
+		// Prefix with right_ to avoid collision
+		dstCol := vm.gatherSeries(s, rightIndices, "right_"+colName)
+		allSeries = append(allSeries, dstCol)
+	}
+
+	return dataframe.NewDataFrame(allSeries...)
+}
+
+// buildJoinResultWithNulls gathers the result of a left, right or outer
+// join, where an index of -1 marks a missing row. keepRight is set for joins
+// that keep unmatched right rows, whose key is then taken from the right.
+func (vm *VM) buildJoinResultWithNulls(left, right *dataframe.DataFrame, keyName string, leftIndices, rightIndices []int, keepLeft, keepRight bool) *dataframe.DataFrame {
+	// Collect all series first, then create DataFrame
+	var allSeries []dataframe.Series
+
+	// Gather columns from left frame, filling the key of right-only rows
+	// from the right frame
+	rightKey, _ := getDataFrameColumn(right, keyName)
+	for _, s := range left.Series {
+		colName := s.Name()
+		if colName == keyName && keepRight {
+			allSeries = append(allSeries, vm.gatherJoinKey(s, rightKey, leftIndices, rightIndices))
+			continue
+		}
+		dstCol := vm.gatherSeriesWithNulls(s, leftIndices, colName)
+		allSeries = append(allSeries, dstCol)
+	}
+
+	// Gather columns from right frame (except key column)
+	for _, s := range right.Series {
+		colName := s.Name()
+		if colName == keyName {
+			continue
+		}
+		dstCol := vm.gatherSeriesWithNulls(s, rightIndices, "right_"+colName)
+		allSeries = append(allSeries, dstCol)
+	}
+
+	return dataframe.NewDataFrame(allSeries...)
+}
+
+func (vm *VM) gatherSeries(src dataframe.Series, indices []int, name string) dataframe.Series {
+	n := len(indices)
+	vals := make([]interface{}, n)
+	for i, idx := range indices {
+		vals[i] = src.Value(idx)
+	}
+	s := createSeriesWithValues(src, vals)
+	s.Rename(name)
+	return s
+}
+
+func (vm *VM) gatherSeriesWithNulls(src dataframe.Series, indices []int, name string) dataframe.Series {
+	n := len(indices)
+	vals := make([]interface{}, n)
+	for i, idx := range indices {
+		if idx < 0 {
+			vals[i] = nil
+		} else {
+			vals[i] = src.Value(idx)
+		}
+	}
+	s := createSeriesWithValues(src, vals)
+	s.Rename(name)
+	return s
+}
+
+// ===== String Operations =====
+
+func (vm *VM) strLen(s dataframe.Series) (dataframe.Series, error) {
+	n := getSeriesLength(s)
+	data := make([]int64, n)
+	err := vm.forEachChunk(n, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			if v, ok := getStringValue(s, i); ok {
+				data[i] = int64(len(v))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newInt64Series("strlen", data), nil
+}
+
+// strMap applies fn to each string cell of s, naming the result name. Nil
+// and non-string cells become "". The string operations that map a cell to
+// a new string are all built on it.
+func (vm *VM) strMap(s dataframe.Series, name string, fn func(string) string) (dataframe.Series, error) {
+	n := getSeriesLength(s)
+	data := make([]string, n)
+	err := vm.forEachChunk(n, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			if v, ok := getStringValue(s, i); ok {
+				data[i] = fn(v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newStringSeries(name, data), nil
+}
+
+// strTest is strMap for predicates: nil and non-string cells are false.
+func (vm *VM) strTest(s dataframe.Series, name string, fn func(string) bool) (dataframe.Series, error) {
+	n := getSeriesLength(s)
+	data := make([]bool, n)
+	err := vm.forEachChunk(n, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			if v, ok := getStringValue(s, i); ok {
+				data[i] = fn(v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newBoolSeries(name, data), nil
+}
+
+func (vm *VM) strUpper(s dataframe.Series) (dataframe.Series, error) {
+	return vm.strMap(s, "upper", strings.ToUpper)
+}
+
+func (vm *VM) strLower(s dataframe.Series) (dataframe.Series, error) {
+	return vm.strMap(s, "lower", strings.ToLower)
+}
+
+func (vm *VM) strConcat(a, b dataframe.Series) (dataframe.Series, error) {
+	n := getSeriesLength(a)
+	data := make([]string, n)
+	err := vm.forEachChunk(n, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			av, _ := getStringValue(a, i)
+			bv, _ := getStringValue(b, i)
+			data[i] = av + bv
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newStringSeries("concat", data), nil
+}
+
+func (vm *VM) strContains(s dataframe.Series, pattern string) (dataframe.Series, error) {
+	return vm.strTest(s, "contains", func(v string) bool {
+		return strings.Contains(v, pattern)
+	})
+}
+
+// strContainsCI is strContains with both sides lowercased first.
+func (vm *VM) strContainsCI(s dataframe.Series, pattern string) (dataframe.Series, error) {
+	pattern = strings.ToLower(pattern)
+	return vm.strTest(s, "contains", func(v string) bool {
+		return strings.Contains(strings.ToLower(v), pattern)
+	})
+}
+
+// compileRegex compiles pattern, wrapping failures in ErrInvalidPattern.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+	}
+	return re, nil
+}
+
+func (vm *VM) strRegexMatch(s dataframe.Series, re *regexp.Regexp) (dataframe.Series, error) {
+	return vm.strTest(s, "matches", re.MatchString)
+}
+
+// strRegexExtract returns the first capture group of each match, or the
+// whole match when the pattern has no groups. Cells that don't match yield "".
+func (vm *VM) strRegexExtract(s dataframe.Series, re *regexp.Regexp) (dataframe.Series, error) {
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	return vm.strMap(s, "extract", func(v string) string {
+		if m := re.FindStringSubmatch(v); m != nil {
+			return m[group]
+		}
+		return ""
+	})
+}
+
+// strSubstr returns length runes starting at rune offset start. Indices are
+// counted in runes rather than bytes so multi-byte characters are never split,
+// and out-of-range bounds are clamped to the string.
+func (vm *VM) strSubstr(s dataframe.Series, start, length int64) (dataframe.Series, error) {
+	return vm.strMap(s, "substr", func(v string) string {
+		runes := []rune(v)
+		size := int64(len(runes))
+		from := min(max(start, 0), size)
+		to := min(from+max(length, 0), size)
+		return string(runes[from:to])
+	})
+}
+
+// strPad pads each string with pad until it is width runes long. Strings that
+// are already at least width long are returned unchanged; nil cells become "".
+func (vm *VM) strPad(s dataframe.Series, width int64, pad string, left bool) (dataframe.Series, error) {
+	return vm.strMap(s, "pad", func(v string) string {
+		missing := int(width) - utf8.RuneCountInString(v)
+		if missing <= 0 {
+			return v
+		}
+		fill := strings.Repeat(pad, missing)
+		if left {
+			return fill + v
+		}
+		return v + fill
+	})
+}
+
+func (vm *VM) strStartsWith(s dataframe.Series, pattern string) (dataframe.Series, error) {
+	return vm.strTest(s, "startswith", func(v string) bool {
+		return strings.HasPrefix(v, pattern)
+	})
+}
+
+func (vm *VM) strEndsWith(s dataframe.Series, pattern string) (dataframe.Series, error) {
+	return vm.strTest(s, "endswith", func(v string) bool {
+		return strings.HasSuffix(v, pattern)
+	})
+}
+
+// strTitle uppercases the first letter of each whitespace-separated word and
+// lowercases the rest. Case mapping is per rune via the unicode package, so it
+// handles accented letters but not language-specific rules such as Dutch "ij".
+func (vm *VM) strTitle(s dataframe.Series) (dataframe.Series, error) {
+	return vm.strMap(s, "title", func(v string) string {
+		var sb strings.Builder
+		sb.Grow(len(v))
+		wordStart := true
+		for _, r := range v {
+			switch {
+			case unicode.IsSpace(r):
+				wordStart = true
+			case wordStart:
+				r = unicode.ToTitle(r)
+				wordStart = false
+			default:
+				r = unicode.ToLower(r)
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	})
+}
+
+// strCapitalize uppercases the first rune of each cell and leaves the rest
+// unchanged.
+func (vm *VM) strCapitalize(s dataframe.Series) (dataframe.Series, error) {
+	return vm.strMap(s, "capitalize", func(v string) string {
+		if v == "" {
+			return ""
+		}
+		r, size := utf8.DecodeRuneInString(v)
+		return string(unicode.ToTitle(r)) + v[size:]
+	})
+}
+
+func (vm *VM) strTrim(s dataframe.Series) (dataframe.Series, error) {
+	return vm.strMap(s, "trim", strings.TrimSpace)
+}
+
+func (vm *VM) strSplit(s dataframe.Series, delim string) (dataframe.Series, error) {
+	// Returns first part after split for simplicity
+	return vm.strMap(s, "split", func(v string) string {
+		if parts := strings.Split(v, delim); len(parts) > 0 {
+			return parts[0]
+		}
+		return ""
+	})
+}
+
+func (vm *VM) strReplace(s dataframe.Series, pattern string) (dataframe.Series, error) {
+	// Pattern format: "old|new"
+	parts := strings.SplitN(pattern, "|", 2)
+	if len(parts) != 2 {
+		return s, nil
+	}
+	oldStr, newStr := parts[0], parts[1]
+	return vm.strMap(s, "replace", func(v string) string {
+		return strings.ReplaceAll(v, oldStr, newStr)
+	})
+}
+
+// formatF formats numeric cells as strings. An int64 spec gives that many
+// decimal places; a string spec is a printf format applied to the float64
+// value, e.g. "%.2f" or "$%.0f". Nil cells stay nil.
+func (vm *VM) formatF(s dataframe.Series, spec any) (dataframe.Series, error) {
+	var format func(float64) string
+	switch spec := spec.(type) {
+	case int64:
+		if spec < 0 {
+			return nil, fmt.Errorf("%w: negative decimal count %d", ErrInvalidInstruction, spec)
+		}
+		format = func(f float64) string { return strconv.FormatFloat(f, 'f', int(spec), 64) }
+	case string:
+		format = func(f float64) string { return fmt.Sprintf(spec, f) }
+	default:
+		return nil, fmt.Errorf("%w: FORMAT_F spec must be an int or string, got %T", ErrTypeMismatch, spec)
+	}
+
+	n := getSeriesLength(s)
+	vals := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		if v, ok := getFloat64Value(s, i); ok {
+			vals[i] = format(v)
+		}
+	}
+	return dataframe.NewSeriesString("format", nil, vals...), nil
+}
+
+// ===== Window Operations =====
+
+// shift moves values n positions down (lag) or, for negative n, up (lead).
+// Vacated positions are nil and the result keeps the source column's type.
+func (vm *VM) shift(s dataframe.Series, n int) dataframe.Series {
+	length := getSeriesLength(s)
+	vals := make([]interface{}, length)
+	for i := 0; i < length; i++ {
+		if j := i - n; j >= 0 && j < length {
+			vals[i] = s.Value(j)
+		}
+	}
+	return createSeriesWithValues(s, vals)
+}
+
+// rankF assigns 1-based ranks to the non-null values of s as floats, using
+// the tie strategy and direction in flags. Null cells get a nil rank.
+func (vm *VM) rankF(s dataframe.Series, flags uint8) dataframe.Series {
+	n := getSeriesLength(s)
+	type entry struct {
+		val float64
+		row int
+	}
+	entries := make([]entry, 0, n)
+	for i := 0; i < n; i++ {
+		if v, ok := getFloat64Value(s, i); ok {
+			entries = append(entries, entry{v, i})
 		}
 	}
+	desc := flags&RankDescending != 0
+	sort.SliceStable(entries, func(a, b int) bool {
+		if desc {
+			return entries[a].val > entries[b].val
+		}
+		return entries[a].val < entries[b].val
+	})
 
-	return vm.buildJoinResult(left, right, keyName, leftIndices, rightIndices)
+	data := make([]float64, n)
+	for i := range data {
+		data[i] = math.NaN()
+	}
+	dense := 0.0
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].val == entries[start].val {
+			end++
+		}
+		dense++
+
+		var rank float64
+		switch flags &^ RankDescending {
+		case RankMin:
+			rank = float64(start + 1)
+		case RankDense:
+			rank = dense
+		default:
+			rank = float64(start+1+end) / 2
+		}
+		for _, e := range entries[start:end] {
+			data[e.row] = rank
+		}
+		start = end
+	}
+	return newFloat64Series("rank", data)
 }
 
-func (vm *VM) joinLeft(left, right *dataframe.DataFrame, keyName string) *dataframe.DataFrame {
-	leftKey, _ := getDataFrameColumn(left, keyName)
-	rightKey, _ := getDataFrameColumn(right, keyName)
-
-	// Build right index
-	rightIndex := vm.buildJoinIndex(rightKey)
-
-	// Find matching rows, keeping all left rows
-	var leftIndices, rightIndices []int
-	n := getSeriesLength(leftKey)
-	for i := 0; i < n; i++ {
-		key := leftKey.Value(i)
-		if matches, ok := rightIndex[key]; ok {
-			for _, j := range matches {
-				leftIndices = append(leftIndices, i)
-				rightIndices = append(rightIndices, j)
+// binF assigns each value to one of nbins equal-width bins over [lo, hi] and
+// returns the bin indices. Bins are left-inclusive except the last, which also
+// includes hi. NaN bounds select the range from the data's min and max.
+// Nulls and values outside the range map to nil.
+func (vm *VM) binF(s dataframe.Series, nbins int, lo, hi float64) dataframe.Series {
+	n := getSeriesLength(s)
+	if math.IsNaN(lo) || math.IsNaN(hi) {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for i := 0; i < n; i++ {
+			if v, ok := getFloat64Value(s, i); ok {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
 			}
-		} else {
-			leftIndices = append(leftIndices, i)
-			rightIndices = append(rightIndices, -1) // null marker
 		}
 	}
 
-	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, true, false)
+	width := (hi - lo) / float64(nbins)
+	vals := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		v, ok := getFloat64Value(s, i)
+		if !ok || nbins <= 0 || v < lo || v > hi {
+			continue
+		}
+		bin := 0
+		if width > 0 {
+			bin = int((v - lo) / width)
+		}
+		if bin >= nbins {
+			bin = nbins - 1
+		}
+		vals[i] = int64(bin)
+	}
+	return dataframe.NewSeriesInt64("bin", nil, vals...)
 }
 
-func (vm *VM) joinRight(left, right *dataframe.DataFrame, keyName string) *dataframe.DataFrame {
-	leftKey, _ := getDataFrameColumn(left, keyName)
-	rightKey, _ := getDataFrameColumn(right, keyName)
+// ===== Null Handling =====
 
-	// Build left index
-	leftIndex := vm.buildJoinIndex(leftKey)
+// fillNa replaces nil cells with value, converted to the series type. Numeric
+// values fill int64 and float64 series; strings fill string series.
+func (vm *VM) fillNa(s dataframe.Series, value any) (dataframe.Series, error) {
+	var fill any
+	switch getSeriesType(s) {
+	case TypeInt64:
+		switch v := value.(type) {
+		case int64:
+			fill = v
+		case float64:
+			fill = int64(v)
+		}
+	case TypeFloat64:
+		switch v := value.(type) {
+		case int64:
+			fill = float64(v)
+		case float64:
+			fill = v
+		}
+	case TypeString:
+		if v, ok := value.(string); ok {
+			fill = v
+		}
+	case TypeBool:
+		if v, ok := value.(int64); ok {
+			fill = v != 0
+		}
+	}
+	if fill == nil {
+		return nil, fmt.Errorf("%w: cannot fill %s column with %T", ErrTypeMismatch, s.Type(), value)
+	}
 
-	// Find matching rows, keeping all right rows
-	var leftIndices, rightIndices []int
-	n := getSeriesLength(rightKey)
-	for j := 0; j < n; j++ {
-		key := rightKey.Value(j)
-		if matches, ok := leftIndex[key]; ok {
-			for _, i := range matches {
-				leftIndices = append(leftIndices, i)
-				rightIndices = append(rightIndices, j)
-			}
+	n := getSeriesLength(s)
+	vals := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		if isNil(s, i) {
+			vals[i] = fill
 		} else {
-			leftIndices = append(leftIndices, -1) // null marker
-			rightIndices = append(rightIndices, j)
+			vals[i] = s.Value(i)
 		}
 	}
-
-	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, false, true)
+	return createSeriesWithValues(s, vals), nil
 }
 
-func (vm *VM) joinOuter(left, right *dataframe.DataFrame, keyName string) *dataframe.DataFrame {
-	leftKey, _ := getDataFrameColumn(left, keyName)
-	rightKey, _ := getDataFrameColumn(right, keyName)
-
-	rightIndex := vm.buildJoinIndex(rightKey)
-	matchedRight := make(map[int]bool)
-
-	var leftIndices, rightIndices []int
+// coalesce returns a with its nil cells taken from b at the same row. Int and
+// float columns mix into a float column; any other pair must share a type.
+func (vm *VM) coalesce(a, b dataframe.Series) (dataframe.Series, error) {
+	ta, tb := getSeriesType(a), getSeriesType(b)
+	numeric := func(t DataType) bool { return t == TypeInt64 || t == TypeFloat64 }
+	like := a
+	switch {
+	case ta == tb:
+	case numeric(ta) && numeric(tb):
+		like = dataframe.NewSeriesFloat64(a.Name(), nil)
+	default:
+		return nil, fmt.Errorf("%w: cannot coalesce %s column with %s column", ErrTypeMismatch, ta, tb)
+	}
 
-	// Match from left side
-	n := getSeriesLength(leftKey)
+	n := getSeriesLength(a)
+	vals := make([]interface{}, n)
 	for i := 0; i < n; i++ {
-		key := leftKey.Value(i)
-		if matches, ok := rightIndex[key]; ok {
-			for _, j := range matches {
-				leftIndices = append(leftIndices, i)
-				rightIndices = append(rightIndices, j)
-				matchedRight[j] = true
-			}
-		} else {
-			leftIndices = append(leftIndices, i)
-			rightIndices = append(rightIndices, -1)
+		var v interface{}
+		switch {
+		case !isNil(a, i):
+			v = a.Value(i)
+		case !isNil(b, i):
+			v = b.Value(i)
 		}
-	}
-
-	// Add unmatched right rows
-	m := getSeriesLength(rightKey)
-	for j := 0; j < m; j++ {
-		if !matchedRight[j] {
-			leftIndices = append(leftIndices, -1)
-			rightIndices = append(rightIndices, j)
+		if iv, ok := v.(int64); ok && like != a {
+			v = float64(iv)
 		}
+		vals[i] = v
 	}
+	return createSeriesWithValues(like, vals), nil
+}
 
-	return vm.buildJoinResultWithNulls(left, right, keyName, leftIndices, rightIndices, true, true)
+// isNullMask marks each cell of s that is nil (or, when null is false, each
+// cell that is not).
+func (vm *VM) isNullMask(s dataframe.Series, null bool) dataframe.Series {
+	length := getSeriesLength(s)
+	data := make([]bool, length)
+	for i := 0; i < length; i++ {
+		data[i] = isNil(s, i) == null
+	}
+	return newBoolSeries("result", data)
 }
 
-func (vm *VM) buildJoinIndex(col dataframe.Series) map[any][]int {
-	index := make(map[any][]int)
-	n := getSeriesLength(col)
-	for i := 0; i < n; i++ {
-		key := col.Value(i)
-		index[key] = append(index[key], i)
+// floatMask sets each row of the result to pred applied to the raw float64
+// value of s. Float columns store nulls as NaN, so null cells read as NaN
+// here; rows of non-float columns never match. The values are copied under
+// the series lock like float64Slice, keeping NaN.
+func (vm *VM) floatMask(s dataframe.Series, pred func(float64) bool) dataframe.Series {
+	length := getSeriesLength(s)
+	data := make([]bool, length)
+	if sf, ok := s.(*dataframe.SeriesFloat64); ok {
+		vals := vm.buffers.getFloat64s(length)
+		defer vm.buffers.putFloat64s(vals)
+		sf.Lock()
+		copied := copy(vals, sf.Values)
+		sf.Unlock()
+		for i, v := range vals[:copied] {
+			data[i] = pred(v)
+		}
 	}
-	return index
+	return newBoolSeries("result", data)
 }
 
-func (vm *VM) buildJoinResult(left, right *dataframe.DataFrame, keyName string, leftIndices, rightIndices []int) *dataframe.DataFrame {
-	// Collect all series first, then create DataFrame
-	var allSeries []dataframe.Series
+// dropNa returns df without the rows that hold a nil in any of columns, or
+// in any column at all when columns is empty. Every column is filtered with
+// the same mask so rows stay aligned.
+func (vm *VM) dropNa(df *dataframe.DataFrame, columns []string) (*dataframe.DataFrame, error) {
+	if df == nil {
+		return nil, ErrFrameNotFound
+	}
 
-	// Gather columns from left frame
-	for _, s := range left.Series {
-		colName := s.Name()
-		dstCol := vm.gatherSeries(s, leftIndices, colName)
-		allSeries = append(allSeries, dstCol)
+	checked := df.Series
+	if len(columns) > 0 {
+		checked = make([]dataframe.Series, len(columns))
+		for i, name := range columns {
+			col, ok := getDataFrameColumn(df, name)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, name)
+			}
+			checked[i] = col
+		}
 	}
 
-	// Gather columns from right frame (except key column)
-	for _, s := range right.Series {
-		colName := s.Name()
-		if colName == keyName {
-			continue
+	n := getDataFrameLength(df)
+	keep := NewBitmap(n)
+	for i := 0; i < n; i++ {
+		complete := true
+		for _, col := range checked {
+			if isNil(col, i) {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			keep.Set(i)
 		}
-		// Prefix with right_ to avoid collision
-		dstCol := vm.gatherSeries(s, rightIndices, "right_"+colName)
-		allSeries = append(allSeries, dstCol)
 	}
 
-	return dataframe.NewDataFrame(allSeries...)
+	series := make([]dataframe.Series, len(df.Series))
+	for i, col := range df.Series {
+		series[i] = filterSeries(col, keep)
+	}
+	return dataframe.NewDataFrame(series...), nil
 }
 
-func (vm *VM) buildJoinResultWithNulls(left, right *dataframe.DataFrame, keyName string, leftIndices, rightIndices []int, leftNulls, rightNulls bool) *dataframe.DataFrame {
-	// Collect all series first, then create DataFrame
-	var allSeries []dataframe.Series
-
-	// Gather columns from left frame
-	for _, s := range left.Series {
-		colName := s.Name()
-		dstCol := vm.gatherSeriesWithNulls(s, leftIndices, colName)
-		allSeries = append(allSeries, dstCol)
-	}
+// ===== Hashing =====
 
-	// Gather columns from right frame (except key column)
-	for _, s := range right.Series {
-		colName := s.Name()
-		if colName == keyName {
+// hashStr returns the 64-bit FNV-1a hash of each string cell as an int64, so
+// values are stable across runs and platforms. When buckets > 0 the unsigned
+// hash is reduced modulo buckets, giving values in [0, buckets). Nil and
+// non-string cells hash to nil.
+func (vm *VM) hashStr(s dataframe.Series, buckets uint8) dataframe.Series {
+	n := getSeriesLength(s)
+	vals := make([]interface{}, n)
+	h := fnv.New64a()
+	for i := 0; i < n; i++ {
+		v, ok := getStringValue(s, i)
+		if !ok {
 			continue
 		}
-		dstCol := vm.gatherSeriesWithNulls(s, rightIndices, "right_"+colName)
-		allSeries = append(allSeries, dstCol)
+		h.Reset()
+		h.Write([]byte(v))
+		sum := h.Sum64()
+		if buckets > 0 {
+			sum %= uint64(buckets)
+		}
+		vals[i] = int64(sum)
 	}
-
-	return dataframe.NewDataFrame(allSeries...)
+	return dataframe.NewSeriesInt64("hash", nil, vals...)
 }
 
-func (vm *VM) gatherSeries(src dataframe.Series, indices []int, name string) dataframe.Series {
-	n := len(indices)
+// ===== Date Operations =====
+
+// parseDate parses each string cell with a Go time layout and returns Unix
+// seconds. Layouts without a zone are read as UTC. Nil, non-string and
+// unparseable cells become nil.
+func (vm *VM) parseDate(s dataframe.Series, layout string) dataframe.Series {
+	n := getSeriesLength(s)
 	vals := make([]interface{}, n)
-	for i, idx := range indices {
-		vals[i] = src.Value(idx)
+	for i := 0; i < n; i++ {
+		v, ok := getStringValue(s, i)
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(layout, v); err == nil {
+			vals[i] = t.Unix()
+		}
 	}
-	s := createSeriesWithValues(src, vals)
-	s.Rename(name)
-	return s
+	return dataframe.NewSeriesInt64("date", nil, vals...)
 }
 
-func (vm *VM) gatherSeriesWithNulls(src dataframe.Series, indices []int, name string) dataframe.Series {
-	n := len(indices)
+// datePart extracts a UTC calendar component from Unix-second cells. Nil
+// cells stay nil.
+func (vm *VM) datePart(s dataframe.Series, part uint8) (dataframe.Series, error) {
+	var extract func(time.Time) int
+	switch part {
+	case DatePartYear:
+		extract = time.Time.Year
+	case DatePartMonth:
+		extract = func(t time.Time) int { return int(t.Month()) }
+	case DatePartDay:
+		extract = time.Time.Day
+	case DatePartHour:
+		extract = time.Time.Hour
+	default:
+		return nil, fmt.Errorf("%w: unknown DATE_PART part %d", ErrInvalidInstruction, part)
+	}
+
+	n := getSeriesLength(s)
 	vals := make([]interface{}, n)
-	for i, idx := range indices {
-		if idx < 0 {
-			vals[i] = nil
-		} else {
-			vals[i] = src.Value(idx)
+	for i := 0; i < n; i++ {
+		if v, ok := getInt64Value(s, i); ok {
+			vals[i] = int64(extract(time.Unix(v, 0).UTC()))
 		}
 	}
-	s := createSeriesWithValues(src, vals)
-	s.Rename(name)
-	return s
+	return dataframe.NewSeriesInt64("date_part", nil, vals...), nil
 }
 
-// ===== String Operations =====
+// secondsPerDay converts between day counts and Unix seconds. Days are a fixed
+// 86400 seconds since dates are handled in UTC.
+const secondsPerDay = 24 * 60 * 60
 
-func (vm *VM) strLen(s dataframe.Series) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]int64, n)
+// dateAddDays adds b days to the Unix-second cells of a. A nil in either
+// input gives nil.
+func (vm *VM) dateAddDays(a, b dataframe.Series) dataframe.Series {
+	n := getSeriesLength(a)
+	vals := make([]interface{}, n)
 	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = int64(len(v))
+		t, ok1 := getInt64Value(a, i)
+		days, ok2 := getInt64Value(b, i)
+		if ok1 && ok2 {
+			vals[i] = t + days*secondsPerDay
 		}
 	}
-	return newInt64Series("strlen", data)
+	return dataframe.NewSeriesInt64("date", nil, vals...)
 }
 
-func (vm *VM) strUpper(s dataframe.Series) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]string, n)
+// dateDiffDays returns the whole days from b to a (a - b) for Unix-second
+// cells, truncated toward zero so diff(a, b) == -diff(b, a). A nil in either
+// input gives nil.
+func (vm *VM) dateDiffDays(a, b dataframe.Series) dataframe.Series {
+	n := getSeriesLength(a)
+	vals := make([]interface{}, n)
 	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.ToUpper(v)
+		x, ok1 := getInt64Value(a, i)
+		y, ok2 := getInt64Value(b, i)
+		if ok1 && ok2 {
+			vals[i] = (x - y) / secondsPerDay
 		}
 	}
-	return newStringSeries("upper", data)
+	return dataframe.NewSeriesInt64("days", nil, vals...)
 }
 
-func (vm *VM) strLower(s dataframe.Series) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]string, n)
-	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.ToLower(v)
+// ===== Assertions =====
+
+// assertEq compares two registers of the kind selected by OpAssertEq's imm8
+// and returns an ErrAssertionFailed error describing the first difference.
+func (vm *VM) assertEq(a, b, kind uint8) error {
+	switch kind {
+	case RegFrame:
+		return assertFramesEqual(vm.frames[int(vm.registers.R[a])], vm.frames[int(vm.registers.R[b])])
+	case RegInt:
+		if x, y := vm.registers.R[a], vm.registers.R[b]; x != y {
+			return fmt.Errorf("%w: %d != %d", ErrAssertionFailed, x, y)
+		}
+	case RegFloat:
+		x, y := vm.registers.F[a], vm.registers.F[b]
+		if x != y && !(math.IsNaN(x) && math.IsNaN(y)) {
+			return fmt.Errorf("%w: %g != %g", ErrAssertionFailed, x, y)
+		}
+	case RegVector:
+		if row, x, y, ok := firstDifference(vm.registers.V[a], vm.registers.V[b]); !ok {
+			if row < 0 {
+				return fmt.Errorf("%w: vectors have %d and %d rows", ErrAssertionFailed, getSeriesLength(vm.registers.V[a]), getSeriesLength(vm.registers.V[b]))
+			}
+			return fmt.Errorf("%w: row %d: %v != %v", ErrAssertionFailed, row, x, y)
+		}
+	default:
+		return fmt.Errorf("%w: unknown ASSERT_EQ kind %d", ErrInvalidInstruction, kind)
+	}
+	return nil
+}
+
+// assertFramesEqual compares column names in order, then cells column by
+// column, and names the first differing column and row.
+func assertFramesEqual(a, b *dataframe.DataFrame) error {
+	if a == nil || b == nil {
+		return ErrFrameNotFound
+	}
+	if len(a.Series) != len(b.Series) {
+		return fmt.Errorf("%w: frames have %d and %d columns", ErrAssertionFailed, len(a.Series), len(b.Series))
+	}
+	for i := range a.Series {
+		if an, bn := a.Series[i].Name(), b.Series[i].Name(); an != bn {
+			return fmt.Errorf("%w: column %d is %q vs %q", ErrAssertionFailed, i, an, bn)
+		}
+	}
+	if an, bn := getDataFrameLength(a), getDataFrameLength(b); an != bn {
+		return fmt.Errorf("%w: frames have %d and %d rows", ErrAssertionFailed, an, bn)
+	}
+	for i, col := range a.Series {
+		if row, x, y, ok := firstDifference(col, b.Series[i]); !ok {
+			return fmt.Errorf("%w: column %q row %d: %v != %v", ErrAssertionFailed, col.Name(), row, x, y)
 		}
 	}
-	return newStringSeries("lower", data)
+	return nil
 }
 
-func (vm *VM) strConcat(a, b dataframe.Series) dataframe.Series {
+// firstDifference reports whether a and b hold the same cells. When they do
+// not, it returns the first differing row and its values, or row -1 if the
+// lengths differ.
+func firstDifference(a, b dataframe.Series) (row int, x, y any, ok bool) {
 	n := getSeriesLength(a)
-	data := make([]string, n)
+	if n != getSeriesLength(b) {
+		return -1, nil, nil, false
+	}
 	for i := 0; i < n; i++ {
-		av, _ := getStringValue(a, i)
-		bv, _ := getStringValue(b, i)
-		data[i] = av + bv
+		if x, y := a.Value(i), b.Value(i); x != y {
+			return i, x, y, false
+		}
 	}
-	return newStringSeries("concat", data)
+	return 0, nil, nil, true
 }
 
-func (vm *VM) strContains(s dataframe.Series, pattern string) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]bool, n)
-	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.Contains(v, pattern)
+// ===== Output =====
+
+// printPreview is the number of vector elements PRINT shows.
+const printPreview = 5
+
+// print writes one line describing register src, of the kind selected by
+// OpPrint's imm8, to the configured output writer.
+func (vm *VM) print(src, kind uint8) error {
+	var line string
+	switch kind {
+	case RegFrame:
+		df, ok := vm.frames[int(vm.registers.R[src])]
+		if !ok {
+			return ErrFrameNotFound
 		}
+		line = fmt.Sprintf("R%d = frame %d rows x %d cols [%s]", src, getDataFrameLength(df), len(df.Series), strings.Join(df.Names(), ", "))
+	case RegInt:
+		line = fmt.Sprintf("R%d = %d", src, vm.registers.R[src])
+	case RegFloat:
+		line = fmt.Sprintf("F%d = %g", src, vm.registers.F[src])
+	case RegVector:
+		line = fmt.Sprintf("V%d = %s", src, previewSeries(vm.registers.V[src]))
+	default:
+		return fmt.Errorf("%w: unknown PRINT kind %d", ErrInvalidInstruction, kind)
 	}
-	return newBoolSeries("contains", data)
+
+	_, err := fmt.Fprintln(vm.output, line)
+	return err
 }
 
-func (vm *VM) strStartsWith(s dataframe.Series, pattern string) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]bool, n)
-	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.HasPrefix(v, pattern)
+// setResult stores src, read as the register kind selects, in the results
+// map under name. Frames are stored as the frame itself rather than its
+// register handle, so the result stays usable after the next Load.
+func (vm *VM) setResult(name string, src, kind uint8) error {
+	var value any
+	switch kind {
+	case RegFrame:
+		df, ok := vm.frames[int(vm.registers.R[src])]
+		if !ok {
+			return ErrFrameNotFound
 		}
+		value = df
+	case RegInt:
+		value = vm.registers.R[src]
+	case RegFloat:
+		value = vm.registers.F[src]
+	case RegVector:
+		value = vm.registers.V[src]
+	default:
+		return fmt.Errorf("%w: unknown SET_RESULT kind %d", ErrInvalidInstruction, kind)
+	}
+
+	if vm.results == nil {
+		vm.results = make(map[string]any)
 	}
-	return newBoolSeries("startswith", data)
+	vm.results[name] = value
+	return nil
 }
 
-func (vm *VM) strEndsWith(s dataframe.Series, pattern string) dataframe.Series {
+// previewSeries formats the first printPreview values of s followed by its
+// row count, e.g. "[1, 2, 3, 4, 5, ...] (8 rows)".
+func previewSeries(s dataframe.Series) string {
+	if s == nil {
+		return "nil"
+	}
 	n := getSeriesLength(s)
-	data := make([]bool, n)
-	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.HasSuffix(v, pattern)
+	parts := make([]string, 0, printPreview+1)
+	for i := 0; i < n && i < printPreview; i++ {
+		if v := s.Value(i); v == nil {
+			parts = append(parts, "nil")
+		} else {
+			parts = append(parts, fmt.Sprint(v))
 		}
 	}
-	return newBoolSeries("endswith", data)
+	if n > printPreview {
+		parts = append(parts, "...")
+	}
+	return fmt.Sprintf("[%s] (%d rows)", strings.Join(parts, ", "), n)
 }
 
-func (vm *VM) strTrim(s dataframe.Series) dataframe.Series {
-	n := getSeriesLength(s)
-	data := make([]string, n)
+// ===== Reshaping Operations =====
+
+// pivot reshapes a long frame into a wide one with a row per distinct index
+// value and a column per distinct key value, both in first-seen order. Values
+// sharing an (index, key) pair are summed; missing cells are nil. Int64 value
+// columns stay int64, anything else is summed as float64. Key columns are
+// named by fmt.Sprint, so keys whose names collide, ignoring case, with each
+// other or with the index column are an ErrSchemaMismatch.
+func (vm *VM) pivot(df *dataframe.DataFrame, indexName, keyName, valueName string) (*dataframe.DataFrame, error) {
+	indexCol, ok := getDataFrameColumn(df, indexName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, indexName)
+	}
+	keyCol, ok := getDataFrameColumn(df, keyName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, keyName)
+	}
+	valueCol, ok := getDataFrameColumn(df, valueName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, valueName)
+	}
+
+	n := getDataFrameLength(df)
+	rowPos := make(map[any]int)
+	keyPos := make(map[any]int)
+	var rowOrder, keyOrder []interface{}
 	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.TrimSpace(v)
+		if r := indexCol.Value(i); !hasKey(rowPos, r) {
+			rowPos[r] = len(rowOrder)
+			rowOrder = append(rowOrder, r)
+		}
+		if k := keyCol.Value(i); !hasKey(keyPos, k) {
+			keyPos[k] = len(keyOrder)
+			keyOrder = append(keyOrder, k)
 		}
 	}
-	return newStringSeries("trim", data)
-}
 
-func (vm *VM) strSplit(s dataframe.Series, delim string) dataframe.Series {
-	// Returns first part after split for simplicity
-	n := getSeriesLength(s)
-	data := make([]string, n)
+	isInt := getSeriesType(valueCol) == TypeInt64
+	cells := make([][]interface{}, len(keyOrder))
+	for k := range cells {
+		cells[k] = make([]interface{}, len(rowOrder))
+	}
 	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			parts := strings.Split(v, delim)
-			if len(parts) > 0 {
-				data[i] = parts[0]
+		r, k := rowPos[indexCol.Value(i)], keyPos[keyCol.Value(i)]
+		if isInt {
+			if v, ok := getInt64Value(valueCol, i); ok {
+				sum, _ := cells[k][r].(int64)
+				cells[k][r] = sum + v
 			}
+		} else if v, ok := getFloat64Value(valueCol, i); ok {
+			sum, _ := cells[k][r].(float64)
+			cells[k][r] = sum + v
+		}
+	}
+
+	series := []dataframe.Series{createSeriesWithValues(indexCol, rowOrder)}
+	sources := map[string]string{strings.ToLower(indexCol.Name()): "the index column"}
+	for k, key := range keyOrder {
+		name := fmt.Sprint(key)
+		if prev, dup := sources[strings.ToLower(name)]; dup {
+			return nil, fmt.Errorf("%w: PIVOT column %q from key %#v collides with %s", ErrSchemaMismatch, name, key, prev)
+		}
+		sources[strings.ToLower(name)] = fmt.Sprintf("key %#v", key)
+		if isInt {
+			series = append(series, dataframe.NewSeriesInt64(name, nil, cells[k]...))
+		} else {
+			series = append(series, dataframe.NewSeriesFloat64(name, nil, cells[k]...))
 		}
 	}
-	return newStringSeries("split", data)
+	return dataframe.NewDataFrame(series...), nil
 }
 
-func (vm *VM) strReplace(s dataframe.Series, pattern string) dataframe.Series {
-	// Pattern format: "old|new"
-	parts := strings.SplitN(pattern, "|", 2)
-	if len(parts) != 2 {
-		return s
+// concat stacks the rows of bottom under top. Both frames must have the same
+// column names, in the same order, with the same types.
+func (vm *VM) concat(top, bottom *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+	if top == nil || bottom == nil {
+		return nil, ErrFrameNotFound
+	}
+	if len(top.Series) != len(bottom.Series) {
+		return nil, fmt.Errorf("%w: %d columns vs %d", ErrSchemaMismatch, len(top.Series), len(bottom.Series))
 	}
-	oldStr, newStr := parts[0], parts[1]
 
-	n := getSeriesLength(s)
-	data := make([]string, n)
-	for i := 0; i < n; i++ {
-		if v, ok := getStringValue(s, i); ok {
-			data[i] = strings.ReplaceAll(v, oldStr, newStr)
+	series := make([]dataframe.Series, len(top.Series))
+	for i, a := range top.Series {
+		b := bottom.Series[i]
+		if a.Name() != b.Name() || getSeriesType(a) != getSeriesType(b) {
+			return nil, fmt.Errorf("%w: column %d is %s (%s) vs %s (%s)", ErrSchemaMismatch,
+				i, a.Name(), a.Type(), b.Name(), b.Type())
 		}
+		n, m := a.NRows(), b.NRows()
+		vals := make([]interface{}, 0, n+m)
+		for j := 0; j < n; j++ {
+			vals = append(vals, a.Value(j))
+		}
+		for j := 0; j < m; j++ {
+			vals = append(vals, b.Value(j))
+		}
+		series[i] = createSeriesWithValues(a, vals)
 	}
-	return newStringSeries("replace", data)
+	return dataframe.NewDataFrame(series...), nil
+}
+
+func hasKey(m map[any]int, k any) bool {
+	_, ok := m[k]
+	return ok
 }
@@ -2,6 +2,9 @@ package vm
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
 	dataframe "github.com/rocketlaunchr/dataframe-go"
@@ -27,6 +30,95 @@ func TestVM_SetMemoryLimit(t *testing.T) {
 	// Just verify it doesn't panic
 }
 
+func TestVM_ProgramSizeLimits(t *testing.T) {
+	program := func(n int) *Program {
+		code := make([]Instruction, n)
+		for i := range code {
+			code[i] = EncodeInstruction(OpNop, 0, 0, 0, 0, 0)
+		}
+		code[n-1] = EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)
+		return &Program{Code: code}
+	}
+
+	vm := NewVM()
+	vm.SetMaxProgramSize(100)
+
+	if err := vm.Load(program(100)); err != nil {
+		t.Fatalf("expected program at the limit to load, got %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	err := vm.Load(program(101))
+	if !errors.Is(err, ErrProgramTooLarge) {
+		t.Fatalf("expected ErrProgramTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "101 instructions (limit 100)") {
+		t.Errorf("expected descriptive error, got %v", err)
+	}
+
+	vm = NewVM()
+	vm.SetMaxConstants(2)
+	small := &Program{Code: program(1).Code, Constants: []any{"a", "b"}, FloatConstants: []float64{1, 2}}
+	if err := vm.Load(small); err != nil {
+		t.Errorf("expected pools at the limit to load, got %v", err)
+	}
+	if err := vm.Load(&Program{Code: small.Code, Constants: []any{"a", "b", "c"}}); !errors.Is(err, ErrProgramTooLarge) {
+		t.Errorf("expected ErrProgramTooLarge for constants, got %v", err)
+	}
+	if err := vm.Load(&Program{Code: small.Code, FloatConstants: []float64{1, 2, 3}}); !errors.Is(err, ErrProgramTooLarge) {
+		t.Errorf("expected ErrProgramTooLarge for float constants, got %v", err)
+	}
+}
+
+func TestVM_StepBudget_JoinCost(t *testing.T) {
+	keys := func(n int) *dataframe.DataFrame {
+		ids := make([]int64, n)
+		for i := range ids {
+			ids[i] = int64(i)
+		}
+		return dataframe.NewDataFrame(dataframe.NewSeriesInt64("id", nil, ids))
+	}
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpJoinInner, 0, 2, 0, 1, 2),
+			EncodeInstruction(OpRowCount, 0, 3, 2, 0, 0),
+			EncodeInstruction(OpHalt, 0, 3, 0, 0, 0),
+		},
+		Constants: []any{"left", "right", "id"},
+	}
+
+	run := func(rows int, budget int64) (any, error) {
+		vm := NewVM()
+		vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"left": keys(rows), "right": keys(rows)})
+		vm.SetMaxSteps(budget)
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return vm.Execute()
+	}
+
+	// Five instructions fit a budget of 8 when the join is small...
+	result, err := run(100, 8)
+	if err != nil {
+		t.Fatalf("expected small join within budget, got %v", err)
+	}
+	if result != int64(100) {
+		t.Errorf("expected 100 joined rows, got %v", result)
+	}
+
+	// ...but 2x5000 input rows add 10 steps
+	if _, err := run(5000, 8); !errors.Is(err, ErrInstructionLimit) {
+		t.Errorf("expected ErrInstructionLimit for large join, got %v", err)
+	}
+	if _, err := run(5000, 15); err != nil {
+		t.Errorf("expected large join within a budget of 15, got %v", err)
+	}
+}
+
 func TestVM_SetSandbox(t *testing.T) {
 	vm := NewVM()
 	vm.SetSandbox(true, []string{"/tmp"})
@@ -347,6 +439,388 @@ func TestVM_JoinOuter(t *testing.T) {
 	}
 }
 
+// runJoin executes op on the frames "left" and "right" keyed on "id" and
+// returns the result frame.
+func runJoin(t *testing.T, op Opcode, left, right *dataframe.DataFrame) *dataframe.DataFrame {
+	t.Helper()
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"left": left, "right": right})
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+			EncodeInstruction(op, 0, 2, 0, 1, 2),
+			EncodeInstruction(OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"left", "right", "id"},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return vm.frames[2]
+}
+
+func TestVM_JoinStringKey(t *testing.T) {
+	left := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("id", nil, "a1", "b2", "10", "1e1"),
+		dataframe.NewSeriesInt64("qty", nil, 1, 2, 3, 4),
+	)
+	right := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("id", nil, "1e1", "b2", "A1"),
+		dataframe.NewSeriesString("label", nil, "sci", "bee", "upper"),
+	)
+
+	// "10" and "1e1" are the same number but different strings, and keys are
+	// case-sensitive, so only b2 and 1e1 match
+	result := runJoin(t, OpJoinInner, left, right)
+	if n := getDataFrameLength(result); n != 2 {
+		t.Fatalf("expected 2 rows, got %d", n)
+	}
+	ids, _ := getDataFrameColumn(result, "id")
+	labels, _ := getDataFrameColumn(result, "right_label")
+	want := map[string]string{"b2": "bee", "1e1": "sci"}
+	for i := 0; i < 2; i++ {
+		id, _ := getStringValue(ids, i)
+		label, _ := getStringValue(labels, i)
+		if want[id] != label {
+			t.Errorf("row %d: id %q joined to %q", i, id, label)
+		}
+	}
+	if getSeriesType(ids) != TypeString {
+		t.Errorf("expected string key column, got %s", ids.Type())
+	}
+}
+
+func TestVM_JoinLargeIntKey(t *testing.T) {
+	// 2^53 and 2^53+1 are the same float64, so a float-coerced key would
+	// match both left rows to the single right row
+	big := int64(1) << 53
+	left := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, big, big+1),
+		dataframe.NewSeriesString("name", nil, "even", "odd"),
+	)
+	right := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, big+1, big+3),
+		dataframe.NewSeriesInt64("score", nil, 100, 300),
+	)
+
+	inner := runJoin(t, OpJoinInner, left, right)
+	if n := getDataFrameLength(inner); n != 1 {
+		t.Fatalf("expected 1 row, got %d", n)
+	}
+	names, _ := getDataFrameColumn(inner, "name")
+	if name, _ := getStringValue(names, 0); name != "odd" {
+		t.Errorf("expected odd to match, got %q", name)
+	}
+
+	// Right-only rows keep their exact int64 key
+	outer := runJoin(t, OpJoinOuter, left, right)
+	ids, _ := getDataFrameColumn(outer, "id")
+	if getSeriesType(ids) != TypeInt64 {
+		t.Fatalf("expected int64 key column, got %s", ids.Type())
+	}
+	want := []int64{big, big + 1, big + 3}
+	if n := getSeriesLength(ids); n != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), n)
+	}
+	for i, w := range want {
+		if id, ok := getInt64Value(ids, i); !ok || id != w {
+			t.Errorf("row %d: expected id %d, got %v", i, w, ids.Value(i))
+		}
+	}
+}
+
+func TestVM_JoinMixedNumericKey(t *testing.T) {
+	left := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+	)
+	right := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("id", nil, 2.0, 3.5, 1.0),
+		dataframe.NewSeriesString("tag", nil, "two", "odd", "one"),
+	)
+
+	// Integral floats match int keys; 3.5 matches nothing
+	result := runJoin(t, OpJoinRight, left, right)
+	ids, _ := getDataFrameColumn(result, "id")
+	tags, _ := getDataFrameColumn(result, "right_tag")
+	want := []struct {
+		id  any
+		tag string
+	}{{int64(2), "two"}, {nil, "odd"}, {int64(1), "one"}}
+	for i, w := range want {
+		tag, _ := getStringValue(tags, i)
+		if ids.Value(i) != w.id || tag != w.tag {
+			t.Errorf("row %d: expected (%v, %s), got (%v, %s)", i, w.id, w.tag, ids.Value(i), tag)
+		}
+	}
+}
+
+// assertSameJoin checks that the sorted and hash inner joins of left and
+// right produce the same columns and rows in the same order.
+func assertSameJoin(t *testing.T, left, right *dataframe.DataFrame) *dataframe.DataFrame {
+	t.Helper()
+	want := runJoin(t, OpJoinInner, left, right)
+	got := runJoin(t, OpJoinInnerSorted, left, right)
+	if len(got.Series) != len(want.Series) {
+		t.Fatalf("expected %d columns, got %d", len(want.Series), len(got.Series))
+	}
+	if got.NRows() != want.NRows() {
+		t.Fatalf("expected %d rows, got %d", want.NRows(), got.NRows())
+	}
+	for c, ws := range want.Series {
+		gs := got.Series[c]
+		if gs.Name() != ws.Name() {
+			t.Fatalf("column %d: expected %q, got %q", c, ws.Name(), gs.Name())
+		}
+		for i := 0; i < ws.NRows(); i++ {
+			if gs.Value(i) != ws.Value(i) {
+				t.Fatalf("column %q row %d: expected %v, got %v", ws.Name(), i, ws.Value(i), gs.Value(i))
+			}
+		}
+	}
+	return got
+}
+
+func keysSorted(df *dataframe.DataFrame) bool {
+	key, _ := getDataFrameColumn(df, "id")
+	return joinKeysSorted(key)
+}
+
+func TestVM_JoinInnerSorted(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right *dataframe.DataFrame
+		rows        int
+	}{
+		{
+			name: "int keys with duplicates on both sides",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, 2, 2, 4, 5, 5, 9),
+				dataframe.NewSeriesString("name", nil, "a", "b1", "b2", "d", "e1", "e2", "z"),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 0, 2, 2, 2, 3, 5, 9, 10),
+				dataframe.NewSeriesInt64("score", nil, 0, 20, 21, 22, 30, 50, 90, 100),
+			),
+			rows: 2*3 + 2*1 + 1,
+		},
+		{
+			name: "string keys",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "apple", "kiwi", "pear"),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "banana", "kiwi", "pear", "pear"),
+				dataframe.NewSeriesFloat64("price", nil, 0.5, 1.5, 2.5, 2.75),
+			),
+			rows: 3,
+		},
+		{
+			name: "mixed int and float keys",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesFloat64("id", nil, 1.0, 2.5, 3.0, 1e300),
+				dataframe.NewSeriesString("tag", nil, "one", "half", "three", "huge"),
+			),
+			rows: 2,
+		},
+		{
+			name: "no overlap",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, 2),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 3, 4),
+			),
+			rows: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !keysSorted(tt.left) || !keysSorted(tt.right) {
+				t.Fatal("expected both key columns to take the merge path")
+			}
+			got := assertSameJoin(t, tt.left, tt.right)
+			if got.NRows() != tt.rows {
+				t.Errorf("expected %d rows, got %d", tt.rows, got.NRows())
+			}
+		})
+	}
+}
+
+func TestVM_JoinInnerSorted_FallsBack(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right *dataframe.DataFrame
+	}{
+		{
+			name: "unsorted left",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 3, 1, 2, 1),
+				dataframe.NewSeriesString("name", nil, "c", "a", "b", "a2"),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+			),
+		},
+		{
+			name: "unsorted right",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "a", "b", "c"),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "c", "a", "b"),
+				dataframe.NewSeriesInt64("n", nil, 3, 1, 2),
+			),
+		},
+		{
+			name: "null keys",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, nil, 2),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, nil, 1, 2),
+			),
+		},
+		{
+			name: "string against int keys",
+			left: dataframe.NewDataFrame(
+				dataframe.NewSeriesString("id", nil, "1", "2"),
+			),
+			right: dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("id", nil, 1, 2),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name != "string against int keys" && keysSorted(tt.left) && keysSorted(tt.right) {
+				t.Fatal("expected a key column to be rejected by the merge path")
+			}
+			assertSameJoin(t, tt.left, tt.right)
+		})
+	}
+}
+
+func TestCompareJoinKeys(t *testing.T) {
+	big := float64(math.MaxInt64) // 2^63, one past MaxInt64
+	tests := []struct {
+		a, b any
+		want int
+		ok   bool
+	}{
+		{int64(1), int64(2), -1, true},
+		{int64(2), 1.5, 1, true},
+		{1.5, int64(2), -1, true},
+		{int64(math.MaxInt64), big, -1, true},
+		{big, int64(math.MaxInt64), 1, true},
+		{"a", "b", -1, true},
+		{2.5, 2.5, 0, true},
+		{math.NaN(), 1.0, 0, false},
+		{int64(1), math.NaN(), 0, false},
+		{"1", int64(1), 0, false},
+		{nil, int64(1), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := compareJoinKeys(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("compareJoinKeys(%v, %v) = (%d, %v), want (%d, %v)", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestVM_JoinCross(t *testing.T) {
+	vm := NewVM()
+	left := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("size", nil, "S", "M", "L"),
+	)
+	right := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("size", nil, "red", "blue"),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"left": left, "right": right})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpJoinCross, 0, 2, 0, 1, 0),
+			EncodeInstruction(OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"left", "right"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	result := vm.frames[2]
+	if n := getDataFrameLength(result); n != 6 {
+		t.Fatalf("expected 6 rows, got %d", n)
+	}
+	sizes, ok1 := getDataFrameColumn(result, "size")
+	colors, ok2 := getDataFrameColumn(result, "right_size")
+	if !ok1 || !ok2 {
+		t.Fatalf("expected size and right_size columns, got %v", result.Names())
+	}
+
+	// Left-major: each left row is paired with every right row in turn
+	wantSizes := []string{"S", "S", "M", "M", "L", "L"}
+	wantColors := []string{"red", "blue", "red", "blue", "red", "blue"}
+	for i := range wantSizes {
+		size, _ := getStringValue(sizes, i)
+		color, _ := getStringValue(colors, i)
+		if size != wantSizes[i] || color != wantColors[i] {
+			t.Errorf("row %d: expected (%s, %s), got (%s, %s)", i, wantSizes[i], wantColors[i], size, color)
+		}
+	}
+}
+
+func TestVM_JoinCrossLimits(t *testing.T) {
+	left := dataframe.NewDataFrame(dataframe.NewSeriesInt64("a", nil, make([]interface{}, 3000)...))
+	right := dataframe.NewDataFrame(dataframe.NewSeriesInt64("b", nil, make([]interface{}, 3000)...))
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpJoinCross, 0, 2, 0, 1, 0),
+			EncodeInstruction(OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"left", "right"},
+	}
+
+	// 9M output rows cost 9000 extra steps
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"left": left, "right": right})
+	vm.SetMaxSteps(1000)
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrInstructionLimit) {
+		t.Errorf("expected ErrInstructionLimit, got %v", err)
+	}
+
+	// 9M rows x 2 columns x 8 bytes is well over 1MB
+	vm = NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"left": left, "right": right})
+	vm.SetMemoryLimit(1 << 20)
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("expected ErrMemoryLimit, got %v", err)
+	}
+}
+
 // ===== String Extended Tests =====
 
 func TestVM_StrStartsWith(t *testing.T) {
@@ -610,6 +1084,7 @@ func TestOpcode_String(t *testing.T) {
 		{OpLoadConst, "LOAD_CONST"},
 		{OpLoadConstF, "LOAD_CONST_F"},
 		{OpSelectCol, "SELECT_COL"},
+		{OpWide, "WIDE"},
 		{OpHalt, "HALT"},
 		{OpHaltF, "HALT_F"},
 		{OpVecAddI, "VEC_ADD_I"},
@@ -830,6 +1305,8 @@ func TestOpcodeString_AllCases(t *testing.T) {
 		{OpAnd, "AND"},
 		{OpOr, "OR"},
 		{OpNot, "NOT"},
+		{OpXor, "XOR"},
+		{OpMaskedSelect, "MASKED_SELECT"},
 		{OpFilter, "FILTER"},
 		{OpTake, "TAKE"},
 		{OpReduceSum, "REDUCE_SUM"},
@@ -863,6 +1340,7 @@ func TestOpcodeString_AllCases(t *testing.T) {
 		{OpJoinLeft, "JOIN_LEFT"},
 		{OpJoinRight, "JOIN_RIGHT"},
 		{OpJoinOuter, "JOIN_OUTER"},
+		{OpJoinInnerSorted, "JOIN_INNER_SORTED"},
 		{OpStrLen, "STR_LEN"},
 		{OpStrUpper, "STR_UPPER"},
 		{OpStrLower, "STR_LOWER"},
@@ -905,6 +1383,7 @@ func TestOpcodeFromString_AllCases(t *testing.T) {
 		{"LOAD_CONST", OpLoadConst, true},
 		{"LOAD_CONST_F", OpLoadConstF, true},
 		{"SELECT_COL", OpSelectCol, true},
+		{"WIDE", OpWide, true},
 		{"BROADCAST", OpBroadcast, true},
 		{"BROADCAST_F", OpBroadcastF, true},
 		{"LOAD_FRAME", OpLoadFrame, true},
@@ -926,6 +1405,8 @@ func TestOpcodeFromString_AllCases(t *testing.T) {
 		{"AND", OpAnd, true},
 		{"OR", OpOr, true},
 		{"NOT", OpNot, true},
+		{"XOR", OpXor, true},
+		{"MASKED_SELECT", OpMaskedSelect, true},
 		{"FILTER", OpFilter, true},
 		{"TAKE", OpTake, true},
 		{"REDUCE_SUM", OpReduceSum, true},
@@ -959,6 +1440,7 @@ func TestOpcodeFromString_AllCases(t *testing.T) {
 		{"JOIN_LEFT", OpJoinLeft, true},
 		{"JOIN_RIGHT", OpJoinRight, true},
 		{"JOIN_OUTER", OpJoinOuter, true},
+		{"JOIN_INNER_SORTED", OpJoinInnerSorted, true},
 		{"STR_LEN", OpStrLen, true},
 		{"STR_UPPER", OpStrUpper, true},
 		{"STR_LOWER", OpStrLower, true},
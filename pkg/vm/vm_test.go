@@ -1,9 +1,22 @@
 package vm
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/akhildatla/dasm/pkg/loader"
 	dataframe "github.com/rocketlaunchr/dataframe-go"
 )
 
@@ -57,6 +70,137 @@ func TestVM_LoadConstF(t *testing.T) {
 	}
 }
 
+func TestVM_LoadCSVOpts_Schema(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "zips.csv")
+	if err := os.WriteFile(csvPath, []byte("zip,amount\n02134,10\n90210,20\n"), 0644); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+
+	vm := NewVM()
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadCSVOpts, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 2),
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{csvPath, "schema=zip:string", "zip"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	series, ok := result.(dataframe.Series)
+	if !ok {
+		t.Fatalf("expected Series result, got %T", result)
+	}
+	if got := getSeriesType(series); got != TypeString {
+		t.Errorf("expected %s, got %s", TypeString, got)
+	}
+	if v := series.Value(0); v != "02134" {
+		t.Errorf("expected \"02134\", got %v", v)
+	}
+}
+
+func TestVM_FrameCache(t *testing.T) {
+	loader.ClearCache()
+	t.Cleanup(loader.ClearCache)
+
+	csvPath := filepath.Join(t.TempDir(), "cached.csv")
+	if err := os.WriteFile(csvPath, []byte("a\n1\n2\n"), 0644); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+
+	run := func(code []Instruction) any {
+		t.Helper()
+		vm := NewVM()
+		vm.SetFrameCache(true)
+		if err := vm.Load(&Program{Code: code, Constants: []any{csvPath, "a", "b"}}); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		result, err := vm.Execute()
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return result
+	}
+
+	// Adding a column to the loaded frame must not leak into the cache.
+	got := run([]Instruction{
+		EncodeInstruction(OpLoadCSV, 0, 0, 0, 0, 0),
+		EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+		EncodeInstruction(OpAddCol, 0, 0, 0, 0, 2),
+		EncodeInstruction(OpColCount, 0, 1, 0, 0, 0),
+		EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+	})
+	if got != int64(2) {
+		t.Fatalf("expected 2 columns after ADD_COL, got %v", got)
+	}
+
+	got = run([]Instruction{
+		EncodeInstruction(OpLoadCSV, 0, 0, 0, 0, 0),
+		EncodeInstruction(OpColCount, 0, 1, 0, 0, 0),
+		EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+	})
+	if got != int64(1) {
+		t.Errorf("expected cached frame to keep 1 column, got %v", got)
+	}
+}
+
+func TestVM_Reset(t *testing.T) {
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"data": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("value", nil, 1.0, 2.0, 3.0)),
+	})
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpReduceSumF, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSetResult, RegFloat, 0, 0, 0, 1),
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "value"},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != 6.0 {
+		t.Fatalf("expected 6, got %v", result)
+	}
+
+	vm.Reset()
+	if vm.ip != 0 || vm.stepCount != 0 || vm.registers.F[0] != 0 || len(vm.frames) != 0 || vm.Results() != nil {
+		t.Fatal("expected Reset to clear execution state")
+	}
+	if len(vm.code) != len(program.Code) {
+		t.Fatal("expected Reset to keep the loaded program")
+	}
+
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"data": dataframe.NewDataFrame(dataframe.NewSeriesFloat64("value", nil, 10.0, 20.0)),
+	})
+	result, err = vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute after Reset failed: %v", err)
+	}
+	if result != 30.0 {
+		t.Errorf("expected 30, got %v", result)
+	}
+	if got := vm.Results()["value"]; got != 30.0 {
+		t.Errorf("expected result value 30, got %v", got)
+	}
+}
+
 func TestVM_LoadFrame(t *testing.T) {
 	vm := NewVM()
 	frame := dataframe.NewDataFrame(
@@ -157,6 +301,151 @@ func TestVM_Broadcast(t *testing.T) {
 	}
 }
 
+func TestVM_Fill(t *testing.T) {
+	vm := NewVM()
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpFillI, 0, 0, 0, 0, 0), // V0 = [7, 7, 7, 7, 7]
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(7), int64(5)},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	result := vm.registers.V[0]
+	if n := getSeriesLength(result); n != 5 {
+		t.Fatalf("expected 5 rows, got %d", n)
+	}
+	for i := 0; i < 5; i++ {
+		if v, ok := getInt64Value(result, i); !ok || v != 7 {
+			t.Errorf("row %d: expected 7, got %v", i, result.Value(i))
+		}
+	}
+}
+
+func TestVM_FillLikeVector(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("value", nil, 1, 2, 3),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),            // V0 = data.value
+			EncodeInstruction(OpFillStr, FillLikeVector, 1, 0, 0, 2), // V1 = "n/a" x len(V0)
+			EncodeInstruction(OpFillF, FillLikeVector, 2, 0, 0, 3),   // V2 = 0.5 x len(V0)
+			EncodeInstruction(OpReduceSumF, 0, 0, 2, 0, 0),           // F0 = sum(V2)
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "value", "n/a", 0.5},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if sum := vm.registers.F[0]; sum != 1.5 {
+		t.Errorf("expected sum 1.5, got %v", sum)
+	}
+	for i := 0; i < 3; i++ {
+		if v, ok := getStringValue(vm.registers.V[1], i); !ok || v != "n/a" {
+			t.Errorf("row %d: expected n/a, got %q", i, v)
+		}
+	}
+}
+
+func TestVM_FillNegativeLength(t *testing.T) {
+	vm := NewVM()
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpFillI, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(7), int64(-1)},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestVM_Range(t *testing.T) {
+	tests := []struct {
+		name             string
+		start, end, step int64
+		expected         []int64
+	}{
+		{"ascending", 0, 5, 1, []int64{0, 1, 2, 3, 4}},
+		{"descending", 10, 0, -2, []int64{10, 8, 6, 4, 2}},
+		{"uneven step", 0, 5, 2, []int64{0, 2, 4}},
+		{"empty", 5, 0, 1, []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpRange, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+				},
+				Constants: []any{tt.start, tt.end, tt.step},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if _, err := vm.Execute(); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			result := vm.registers.V[0]
+			if n := getSeriesLength(result); n != len(tt.expected) {
+				t.Fatalf("expected %d rows, got %d", len(tt.expected), n)
+			}
+			for i, want := range tt.expected {
+				if v, ok := getInt64Value(result, i); !ok || v != want {
+					t.Errorf("row %d: expected %d, got %v", i, want, result.Value(i))
+				}
+			}
+		})
+	}
+}
+
+func TestVM_RangeZeroStep(t *testing.T) {
+	vm := NewVM()
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpRange, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpHaltV, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(0), int64(5), int64(0)},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange, got %v", err)
+	}
+}
+
 // ===== Integration Tests: Vector Arithmetic =====
 
 func TestVM_VecAddI(t *testing.T) {
@@ -267,6 +556,148 @@ func TestVM_VecDivF(t *testing.T) {
 	}
 }
 
+func TestVM_VecDivF_DivByZeroMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    DivByZeroMode
+		check   func(float64) bool
+		wantErr bool
+	}{
+		{"inf", DivByZeroInf, func(v float64) bool { return math.IsInf(v, 1) }, false},
+		{"nan", DivByZeroNaN, math.IsNaN, false},
+		{"zero", DivByZeroZero, func(v float64) bool { return v == 0 }, false},
+		{"error", DivByZeroError, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetFloatDivByZeroMode(tt.mode)
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesFloat64("a", nil, 100.0, 5.0, 30.0),
+				dataframe.NewSeriesFloat64("b", nil, 10.0, 0.0, 3.0),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+					EncodeInstruction(OpVecDivF, 0, 2, 0, 1, 0),
+					EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+				},
+				Constants: []any{"data", "a", "b"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if tt.wantErr {
+				if !errors.Is(err, ErrDivisionByZero) {
+					t.Fatalf("expected ErrDivisionByZero, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			values := result.(*dataframe.SeriesFloat64).Values
+			if values[0] != 10 || values[2] != 10 {
+				t.Errorf("expected non-zero denominators to divide normally, got %v", values)
+			}
+			if !tt.check(values[1]) {
+				t.Errorf("unexpected value for zero denominator: %v", values[1])
+			}
+		})
+	}
+}
+
+func TestVM_VecMulI_CheckedOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		checked bool
+	}{
+		{"unchecked", false},
+		{"checked", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetCheckedIntArith(tt.checked)
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("a", nil, 3, math.MaxInt64/2, 7),
+				dataframe.NewSeriesInt64("b", nil, 4, 3, -2),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+					EncodeInstruction(OpVecMulI, 0, 2, 0, 1, 0),
+					EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+				},
+				Constants: []any{"data", "a", "b"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if tt.checked {
+				if !errors.Is(err, ErrIntegerOverflow) {
+					t.Fatalf("expected ErrIntegerOverflow, got %v", err)
+				}
+				if !strings.Contains(err.Error(), "row 1") {
+					t.Errorf("expected error to name row 1, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			big := int64(math.MaxInt64 / 2)
+			want := []int64{12, big * 3, -14} // big * 3 wraps around
+			series := result.(dataframe.Series)
+			for i, w := range want {
+				if got, _ := getInt64Value(series, i); got != w {
+					t.Errorf("row %d: expected %d, got %d", i, w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_CheckedIntArith_AddSub(t *testing.T) {
+	vm := NewVM()
+	vm.SetCheckedIntArith(true)
+
+	a := newInt64Series("a", []int64{math.MaxInt64, math.MinInt64, -5})
+	b := newInt64Series("b", []int64{-1, 1, 5})
+	if _, err := vm.vectorAddInt64(a, b); err != nil {
+		t.Errorf("unexpected add error: %v", err)
+	}
+	if _, err := vm.vectorSubInt64(a, b); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("expected sub overflow, got %v", err)
+	}
+
+	b = newInt64Series("b", []int64{1, 0, 0})
+	if _, err := vm.vectorAddInt64(a, b); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("expected add overflow, got %v", err)
+	}
+	if _, err := vm.vectorMulInt64(newInt64Series("a", []int64{-1}), newInt64Series("b", []int64{math.MinInt64})); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("expected mul overflow for -1 * MinInt64, got %v", err)
+	}
+}
+
 // ===== Integration Tests: Comparisons =====
 
 func TestVM_CmpGT(t *testing.T) {
@@ -305,10 +736,73 @@ func TestVM_CmpGT(t *testing.T) {
 	}
 }
 
-func TestVM_CmpEQ(t *testing.T) {
+func TestVM_CmpInt64_BeyondFloatPrecision(t *testing.T) {
+	const big = int64(1) << 53
+	// big+1 and big are distinct int64s but the same float64
+	if float64(big+1) != float64(big) {
+		t.Fatal("expected big+1 and big to collide as float64")
+	}
+
+	vm := NewVM()
+	a := newInt64Series("a", []int64{big + 1, big, -big - 1})
+	b := newInt64Series("b", []int64{big, big, -big})
+
+	tests := []struct {
+		name string
+		cmp  func(a, b dataframe.Series) (dataframe.Series, error)
+		want []bool
+	}{
+		{"GT", vm.vectorCmpGT, []bool{true, false, false}},
+		{"LT", vm.vectorCmpLT, []bool{false, false, true}},
+		{"EQ", vm.vectorCmpEQ, []bool{false, true, false}},
+		{"NE", vm.vectorCmpNE, []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		result, err := tt.cmp(a, b)
+		if err != nil {
+			t.Fatalf("%s failed: %v", tt.name, err)
+		}
+		for i, want := range tt.want {
+			if got, _ := getBoolValue(result, i); got != want {
+				t.Errorf("%s row %d: expected %v, got %v", tt.name, i, want, got)
+			}
+		}
+	}
+
+	// The float path cannot tell the first row apart
+	if c := vm.compareValues(a, b, 0); c != 0 {
+		t.Errorf("expected float comparison to see equal values, got %d", c)
+	}
+}
+
+func TestVM_CmpString(t *testing.T) {
+	vm := NewVM()
+	a := dataframe.NewSeriesString("a", nil, "apple", "pear", "fig", nil)
+	b := dataframe.NewSeriesString("b", nil, "banana", "pear", "date", "")
+
+	lt, err := vm.vectorCmpLT(a, b)
+	if err != nil {
+		t.Fatalf("LT failed: %v", err)
+	}
+	eq, err := vm.vectorCmpEQ(a, b)
+	if err != nil {
+		t.Fatalf("EQ failed: %v", err)
+	}
+	for i, want := range []struct{ lt, eq bool }{{true, false}, {false, true}, {false, false}, {false, true}} {
+		if got, _ := getBoolValue(lt, i); got != want.lt {
+			t.Errorf("LT row %d: expected %v, got %v", i, want.lt, got)
+		}
+		if got, _ := getBoolValue(eq, i); got != want.eq {
+			t.Errorf("EQ row %d: expected %v, got %v", i, want.eq, got)
+		}
+	}
+}
+
+func TestVM_CmpString_Program(t *testing.T) {
 	vm := NewVM()
 	frame := dataframe.NewDataFrame(
-		dataframe.NewSeriesInt64("value", nil, 10, 20, 10, 30, 10),
+		dataframe.NewSeriesString("region", nil, "east", "north", "west", "east"),
+		dataframe.NewSeriesString("target", nil, "east", "south", "east", "west"),
 	)
 	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
 
@@ -316,41 +810,115 @@ func TestVM_CmpEQ(t *testing.T) {
 		Code: []Instruction{
 			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
 			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
-			EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 2),
-			EncodeInstruction(OpBroadcast, 0, 1, 1, 0, 0),
-			EncodeInstruction(OpCmpEQ, 0, 2, 0, 1, 0),
-			EncodeInstruction(OpReduceCount, 0, 0, 2, 0, 0),
-			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpCmpLT, 0, 2, 0, 1, 0), // V2 = region < target
+			EncodeInstruction(OpCmpEQ, 0, 3, 0, 1, 0), // V3 = region == target
+			EncodeInstruction(OpHaltV, 0, 3, 0, 0, 0),
 		},
-		Constants: []any{"data", "value", int64(10)},
+		Constants: []any{"data", "region", "target"},
 	}
 
 	if err := vm.Load(program); err != nil {
 		t.Fatalf("Load failed: %v", err)
 	}
-
-	result, err := vm.Execute()
-	if err != nil {
+	if _, err := vm.Execute(); err != nil {
 		t.Fatalf("Execute failed: %v", err)
 	}
 
-	// Values == 10: 3 values
-	expected := int64(3)
-	if result != expected {
-		t.Errorf("expected %v, got %v", expected, result)
+	wantLT := []bool{false, true, false, true}
+	wantEQ := []bool{true, false, false, false}
+	for i := range wantLT {
+		if got, _ := getBoolValue(vm.registers.V[2], i); got != wantLT[i] {
+			t.Errorf("CMP_LT row %d: expected %v, got %v", i, wantLT[i], got)
+		}
+		if got, _ := getBoolValue(vm.registers.V[3], i); got != wantEQ[i] {
+			t.Errorf("CMP_EQ row %d: expected %v, got %v", i, wantEQ[i], got)
+		}
 	}
 }
 
-// ===== Integration Tests: Filtering =====
-
-func TestVM_Filter(t *testing.T) {
+func TestVM_CompareValues_Types(t *testing.T) {
 	vm := NewVM()
-	frame := dataframe.NewDataFrame(
-		dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0, 5.0, 30.0),
-		dataframe.NewSeriesInt64("quantity", nil, 5, 15, 3, 20),
-	)
-	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
-
+	tests := []struct {
+		name string
+		a, b dataframe.Series
+		want []int
+	}{
+		{
+			"string",
+			dataframe.NewSeriesString("a", nil, "a", "b", "B"),
+			dataframe.NewSeriesString("b", nil, "b", "b", "a"),
+			[]int{-1, 0, -1},
+		},
+		{
+			"bool",
+			newBoolSeries("a", []bool{false, true, true}),
+			newBoolSeries("b", []bool{true, true, false}),
+			[]int{-1, 0, 1},
+		},
+		{
+			"numeric",
+			dataframe.NewSeriesInt64("a", nil, 1, 5, 3),
+			dataframe.NewSeriesFloat64("b", nil, 1.5, 5.0, 2.5),
+			[]int{-1, 0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := vm.compareValues(tt.a, tt.b, i); got != want {
+				t.Errorf("%s row %d: expected %d, got %d", tt.name, i, want, got)
+			}
+		}
+	}
+}
+
+func TestVM_CmpEQ(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("value", nil, 10, 20, 10, 30, 10),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpBroadcast, 0, 1, 1, 0, 0),
+			EncodeInstruction(OpCmpEQ, 0, 2, 0, 1, 0),
+			EncodeInstruction(OpReduceCount, 0, 0, 2, 0, 0),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "value", int64(10)},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Values == 10: 3 values
+	expected := int64(3)
+	if result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+// ===== Integration Tests: Filtering =====
+
+func TestVM_Filter(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0, 5.0, 30.0),
+		dataframe.NewSeriesInt64("quantity", nil, 5, 15, 3, 20),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
 	program := &Program{
 		Code: []Instruction{
 			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
@@ -416,6 +984,61 @@ func TestVM_ReduceSum(t *testing.T) {
 	}
 }
 
+func TestVM_ReduceProd(t *testing.T) {
+	tests := []struct {
+		name  string
+		col   dataframe.Series
+		op    Opcode
+		wantI int64
+		wantF float64
+	}{
+		{"int", dataframe.NewSeriesInt64("value", nil, 1, 2, 3, 4), OpReduceProd, 24, 0},
+		{"int skips nil", dataframe.NewSeriesInt64("value", nil, 2, nil, 5), OpReduceProd, 10, 0},
+		{"int empty", dataframe.NewSeriesInt64("value", nil), OpReduceProd, 1, 0},
+		{"float", dataframe.NewSeriesFloat64("value", nil, 1.5, 2.0, 0.5), OpReduceProdF, 0, 1.5},
+		{"float empty", dataframe.NewSeriesFloat64("value", nil), OpReduceProdF, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			frame := dataframe.NewDataFrame(tt.col)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			halt := OpHalt
+			if tt.op == OpReduceProdF {
+				halt = OpHaltF
+			}
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+					EncodeInstruction(tt.op, 0, 0, 0, 0, 0),
+					EncodeInstruction(halt, 0, 0, 0, 0, 0),
+				},
+				Constants: []any{"data", "value"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			var expected any = tt.wantI
+			if tt.op == OpReduceProdF {
+				expected = tt.wantF
+			}
+			if result != expected {
+				t.Errorf("expected %v, got %v", expected, result)
+			}
+		})
+	}
+}
+
 func TestVM_ReduceMean(t *testing.T) {
 	vm := NewVM()
 	frame := dataframe.NewDataFrame(
@@ -638,6 +1261,80 @@ func TestVM_LogicalOr(t *testing.T) {
 	}
 }
 
+func TestVM_LogicalXor(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesGeneric("a", false, nil, false, false, true, true, nil),
+		dataframe.NewSeriesGeneric("b", false, nil, false, true, false, true, true),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpXor, 0, 2, 0, 1, 0), // V0 XOR V1
+			EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"data", "a", "b"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Truth table, then a nil cell which reads as false
+	series := result.(dataframe.Series)
+	for i, want := range []bool{false, true, true, false, true} {
+		if got := series.Value(i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_MaskedSelect(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesGeneric("mask", false, nil, true, false, true, true, false),
+		dataframe.NewSeriesGeneric("vals", false, nil, true, true, false, true, true),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpFilter, 0, 1, 1, 0, 0),       // V1 = vals where mask
+			EncodeInstruction(OpMaskedSelect, 0, 2, 0, 1, 0), // spread V1 back over V0
+			EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"data", "mask", "vals"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	series := result.(dataframe.Series)
+	for i, want := range []bool{true, false, false, true, false} {
+		if got := series.Value(i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
 func TestVM_LogicalNot(t *testing.T) {
 	vm := NewVM()
 	frame := dataframe.NewDataFrame(
@@ -675,6 +1372,58 @@ func TestVM_LogicalNot(t *testing.T) {
 	}
 }
 
+func TestVM_ReduceAnyAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		mask    []interface{}
+		wantAny int64
+		wantAll int64
+	}{
+		{"one true", []interface{}{false, true, false}, 1, 0},
+		{"all true", []interface{}{true, true, true}, 1, 1},
+		{"none true", []interface{}{false, false}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, op := range []Opcode{OpReduceAny, OpReduceAll} {
+				vm := NewVM()
+				frame := dataframe.NewDataFrame(
+					dataframe.NewSeriesGeneric("flag", false, nil, tt.mask...),
+				)
+				vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+				program := &Program{
+					Code: []Instruction{
+						EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+						EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+						EncodeInstruction(op, 0, 1, 0, 0, 0), // R1 = any/all(V0)
+						EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+					},
+					Constants: []any{"data", "flag"},
+				}
+
+				if err := vm.Load(program); err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+
+				result, err := vm.Execute()
+				if err != nil {
+					t.Fatalf("Execute failed: %v", err)
+				}
+
+				expected := tt.wantAny
+				if op == OpReduceAll {
+					expected = tt.wantAll
+				}
+				if result != expected {
+					t.Errorf("%s: expected %v, got %v", op, expected, result)
+				}
+			}
+		})
+	}
+}
+
 // ===== Integration Tests: Frame Operations =====
 
 func TestVM_ColCount(t *testing.T) {
@@ -795,6 +1544,93 @@ func TestVM_Context_Cancellation(t *testing.T) {
 	}
 }
 
+func TestVM_Context_CancelInsideVectorOp(t *testing.T) {
+	rows := cancelCheckRows * 64
+	a := newInt64Series("a", make([]int64, rows))
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			vm := NewVM()
+			vm.SetParallelism(workers)
+			vm.SetContext(ctx)
+
+			// Cancel part way through the first chunk
+			var calls atomic.Int32
+			pred := func(c int) bool {
+				if calls.Add(1) == cancelCheckRows/2 {
+					cancel()
+				}
+				return c == 0
+			}
+			result, err := vm.vectorCompare(a, a, pred)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v (result %T)", err, result)
+			}
+			// The serial loop stops at its next check after the cancellation
+			if n := int(calls.Load()); workers == 1 && n > cancelCheckRows {
+				t.Errorf("expected the loop to stop after cancellation, got %d of %d rows", n, rows)
+			}
+		})
+	}
+}
+
+func TestVM_Context_CancelInsideRowLoops(t *testing.T) {
+	rows := cancelCheckRows * 2
+	ints := make([]int64, rows)
+	floats := make([]float64, rows)
+	strs := make([]string, rows)
+	mask := make([]bool, rows)
+	for i := range ints {
+		ints[i] = int64(i)
+		floats[i] = float64(i)
+		strs[i] = fmt.Sprint(i)
+		mask[i] = i%2 == 0
+	}
+	is, fs, ss := newInt64Series("k", ints), newFloat64Series("f", floats), newStringSeries("s", strs)
+	frame := dataframe.NewDataFrame(newInt64Series("k", ints))
+	small := dataframe.NewDataFrame(newInt64Series("k", ints[:200]))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	vm := NewVM()
+	vm.SetContext(ctx)
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"arithmetic", func() error { _, err := vm.vectorMulFloat64(fs, fs); return err }},
+		{"compare", func() error { _, err := vm.vectorCmpLT(is, is); return err }},
+		{"compare strings", func() error { _, err := vm.vectorCmpEQ(ss, ss); return err }},
+		{"compare mixed", func() error { _, err := vm.vectorCmpGE(is, fs); return err }},
+		{"cmp_eq_eps_f", func() error { _, err := vm.vectorCmpEqEpsF(fs, fs, 0.5); return err }},
+		{"in_set", func() error { _, err := vm.inSet(is, []any{int64(1)}); return err }},
+		{"filter", func() error { _, err := vm.filterSeriesWithMask(is, newBoolSeries("m", mask)); return err }},
+		{"str_upper", func() error { _, err := vm.strUpper(ss); return err }},
+		{"str_contains", func() error { _, err := vm.strContains(ss, "1"); return err }},
+		{"str_len", func() error { _, err := vm.strLen(ss); return err }},
+		{"str_concat", func() error { _, err := vm.strConcat(ss, ss); return err }},
+		{"reduce_sum", func() error { _, err := vm.reduceSum(is); return err }},
+		{"reduce_max_f", func() error { _, err := vm.reduceMaxF(fs); return err }},
+		{"reduce_any", func() error { _, err := vm.reduceAny(newBoolSeries("m", mask)); return err }},
+		{"corr_f", func() error { _, err := vm.corrF(fs, fs); return err }},
+		{"join_inner", func() error { _, err := vm.joinInner(frame, frame, "k"); return err }},
+		{"join_inner_sorted", func() error { _, err := vm.joinInnerSorted(frame, frame, "k"); return err }},
+		{"join_left", func() error { _, err := vm.joinLeft(frame, frame, "k"); return err }},
+		{"join_outer", func() error { _, err := vm.joinOuter(frame, frame, "k"); return err }},
+		{"join_cross", func() error { _, err := vm.joinCross(small, small); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
+
 // ===== Integration Tests: End-to-End Workflow =====
 
 func TestVM_CompleteWorkflow_FilterAggregate(t *testing.T) {
@@ -1469,6 +2305,70 @@ func TestVM_GroupBy(t *testing.T) {
 	}
 }
 
+func TestVM_GroupSizes(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "B", "A", "B", "C", "B", "A"),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),  // V0 = category
+			EncodeInstruction(OpGroupBy, 0, 1, 0, 0, 0),    // R1 = groupby(V0)
+			EncodeInstruction(OpGroupSizes, 0, 2, 1, 0, 0), // R2 = (key, size) frame
+			EncodeInstruction(OpHaltFrame, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"data", "category"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := vm.ExecuteResult()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	sizes, err := result.AsFrame()
+	if err != nil {
+		t.Fatalf("AsFrame failed: %v", err)
+	}
+
+	if names := sizes.Names(); !reflect.DeepEqual(names, []string{"key", "size"}) {
+		t.Fatalf("columns = %v, want [key size]", names)
+	}
+	gb := vm.groupbys[1]
+	want := map[any]int64{"B": 3, "A": 2, "C": 1}
+	if sizes.NRows() != len(gb.KeyOrder) {
+		t.Fatalf("rows = %d, want %d", sizes.NRows(), len(gb.KeyOrder))
+	}
+	for i, key := range gb.KeyOrder {
+		if got := sizes.Series[0].Value(i); got != key {
+			t.Errorf("row %d: key = %v, want %v (KeyOrder %v)", i, got, key, gb.KeyOrder)
+		}
+		if got := sizes.Series[1].Value(i); got != want[key] {
+			t.Errorf("group %v: size = %v, want %d", key, got, want[key])
+		}
+	}
+}
+
+func TestVM_GroupSizesWithoutGroupBy(t *testing.T) {
+	vm := NewVM()
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpGroupSizes, 0, 2, 1, 0, 0),
+			EncodeInstruction(OpHaltFrame, 0, 2, 0, 0, 0),
+		},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrInvalidRegister) {
+		t.Errorf("expected ErrInvalidRegister, got %v", err)
+	}
+}
+
 func TestVM_GroupSum(t *testing.T) {
 	vm := NewVM()
 	frame := dataframe.NewDataFrame(
@@ -1700,3 +2600,2100 @@ func TestVM_Stats_WithFrames(t *testing.T) {
 		t.Errorf("expected 4 steps, got %d", stats.StepsExecuted)
 	}
 }
+
+func TestVM_Stats_RowsAndRegisters(t *testing.T) {
+	vm := NewVM()
+	vm.EnableStats()
+
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("key", nil, "a", "b", "a", "c"),
+		dataframe.NewSeriesFloat64("value", nil, 1.0, 2.0, 3.0, 4.0),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),  // R0 = frame("data")
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),  // V0 = R0.key
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),  // V1 = R0.value
+			EncodeInstruction(OpCmpGT, 0, 2, 1, 1, 0),      // V2 = V1 > V1 (4 rows)
+			EncodeInstruction(OpFilter, 0, 3, 1, 2, 0),     // V3 = V1[V2] (4 rows)
+			EncodeInstruction(OpGroupBy, 0, 5, 0, 0, 0),    // R5 = group(V0) (4 rows)
+			EncodeInstruction(OpGroupSumF, 0, 4, 5, 1, 0),  // V4 = sum V1 by R5 (4 rows)
+			EncodeInstruction(OpReduceSumF, 0, 0, 1, 0, 0), // F0 = sum(V1) (4 rows)
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "key", "value"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	stats := vm.Stats()
+	if stats.RowsProcessed != 20 {
+		t.Errorf("expected 20 rows processed, got %d", stats.RowsProcessed)
+	}
+	if stats.PeakRegisters != 6 {
+		t.Errorf("expected peak register index 5 (6 registers), got %d", stats.PeakRegisters)
+	}
+
+	// Counters restart on the next run
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if stats := vm.Stats(); stats.RowsProcessed != 20 {
+		t.Errorf("expected RowsProcessed to reset between runs, got %d", stats.RowsProcessed)
+	}
+}
+
+func TestExecutionStats_Hotspots(t *testing.T) {
+	vm := NewVM()
+	vm.EnableStats()
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpAddR, 0, 2, 0, 1, 0),
+			EncodeInstruction(OpAddR, 0, 2, 2, 1, 0),
+			EncodeInstruction(OpAddR, 0, 2, 2, 1, 0),
+			EncodeInstruction(OpMoveR, 0, 3, 2, 0, 0),
+			EncodeInstruction(OpNop, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpHalt, 0, 3, 0, 0, 0),
+		},
+		Constants: []any{int64(1), int64(2)},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	stats := vm.Stats()
+
+	got := stats.Hotspots(3)
+	want := []OpCount{{"ADD_R", 3}, {"LOAD_CONST", 2}, {"HALT", 1}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d hotspots, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hotspot %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	if all := stats.Hotspots(0); len(all) != 5 {
+		t.Errorf("expected all 5 opcodes, got %v", all)
+	}
+
+	summary := stats.String()
+	for _, part := range []string{"steps=8", "peak_registers=4", "top=ADD_R:3,LOAD_CONST:2,HALT:1,MOVE_R:1,NOP:1"} {
+		if !strings.Contains(summary, part) {
+			t.Errorf("expected %q in summary %q", part, summary)
+		}
+	}
+}
+
+func TestVM_Shift(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int8
+		expected []interface{}
+	}{
+		{"lag", 1, []interface{}{nil, int64(10), int64(20)}},
+		{"lead", -1, []interface{}{int64(20), int64(30), nil}},
+		{"zero", 0, []interface{}{int64(10), int64(20), int64(30)}},
+		{"past end", 5, []interface{}{nil, nil, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("value", nil, 10, 20, 30),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),               // V1 = value
+					EncodeInstruction(OpShift, 0, 2, 1, 0, uint16(uint8(tt.n))), // V2 = shift(V1, n)
+					EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+				},
+				Constants: []any{"data", "value"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			series, ok := result.(dataframe.Series)
+			if !ok {
+				t.Fatalf("expected Series result, got %T", result)
+			}
+			if getSeriesType(series) != TypeInt64 {
+				t.Errorf("expected int64 series, got type %v", getSeriesType(series))
+			}
+			if series.NRows() != len(tt.expected) {
+				t.Fatalf("expected %d rows, got %d", len(tt.expected), series.NRows())
+			}
+			for i, want := range tt.expected {
+				if got := series.Value(i); got != want {
+					t.Errorf("row %d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_ReduceQuantileF(t *testing.T) {
+	tests := []struct {
+		name     string
+		q        float64
+		expected float64
+	}{
+		{"p0 is min", 0.0, 10.0},
+		{"p25", 0.25, 17.5},
+		{"p50 is median", 0.5, 25.0},
+		{"p100 is max", 1.0, 40.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesFloat64("value", nil, 40.0, 10.0, 30.0, 20.0),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),       // V1 = value
+					EncodeInstruction(OpReduceQuantileF, 0, 0, 1, 0, 0), // F0 = quantile(V1, floatConsts[0])
+					EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+				},
+				Constants:      []any{"data", "value"},
+				FloatConstants: []float64{tt.q},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			got, ok := result.(float64)
+			if !ok {
+				t.Fatalf("expected float64, got %T", result)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// p50 must agree with the median helper
+	if got, want := quantile([]float64{3, 1, 2, 4}, 0.5), median([]float64{3, 1, 2, 4}); got != want {
+		t.Errorf("expected p50 %v to equal median %v", got, want)
+	}
+}
+
+func TestVM_ReduceWMeanF(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		mode    DivByZeroMode
+		check   func(float64) bool
+		wantErr bool
+	}{
+		// (10*1 + 20*2 + 30*7) / (1 + 2 + 7) = 260 / 10 = 26
+		{"weighted", []float64{1, 2, 7}, DivByZeroInf, func(v float64) bool { return math.Abs(v-26) < 1e-9 }, false},
+		{"zero weight nan", []float64{0, 0, 0}, DivByZeroInf, math.IsNaN, false},
+		{"zero weight zero", []float64{0, 0, 0}, DivByZeroZero, func(v float64) bool { return v == 0 }, false},
+		{"zero weight error", []float64{0, 0, 0}, DivByZeroError, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetFloatDivByZeroMode(tt.mode)
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesFloat64("price", nil, 10.0, 20.0, 30.0),
+				dataframe.NewSeriesFloat64("qty", nil, tt.weights[0], tt.weights[1], tt.weights[2]),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),    // V1 = price
+					EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 2),    // V2 = qty
+					EncodeInstruction(OpReduceWMeanF, 0, 0, 1, 2, 0), // F0 = wmean(V1, V2)
+					EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+				},
+				Constants: []any{"data", "price", "qty"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if tt.wantErr {
+				if !errors.Is(err, ErrDivisionByZero) {
+					t.Fatalf("expected ErrDivisionByZero, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got := result.(float64); !tt.check(got) {
+				t.Errorf("unexpected weighted mean %v", got)
+			}
+		})
+	}
+}
+
+func TestVM_CorrCovF(t *testing.T) {
+	tests := []struct {
+		name     string
+		b        []float64
+		wantCorr float64
+		wantCov  float64
+	}{
+		{"perfectly correlated", []float64{2, 4, 6, 8}, 1.0, 3.333333333},
+		{"perfectly anti-correlated", []float64{8, 6, 4, 2}, -1.0, -3.333333333},
+		{"constant column", []float64{5, 5, 5, 5}, math.NaN(), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, op := range []Opcode{OpCorrF, OpCovF} {
+				vm := NewVM()
+				frame := dataframe.NewDataFrame(
+					dataframe.NewSeriesFloat64("a", nil, 1.0, 2.0, 3.0, 4.0),
+					dataframe.NewSeriesFloat64("b", nil, tt.b[0], tt.b[1], tt.b[2], tt.b[3]),
+				)
+				vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+				program := &Program{
+					Code: []Instruction{
+						EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+						EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = a
+						EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 2), // V2 = b
+						EncodeInstruction(op, 0, 0, 1, 2, 0),          // F0 = corr/cov(V1, V2)
+						EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+					},
+					Constants: []any{"data", "a", "b"},
+				}
+
+				if err := vm.Load(program); err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+
+				result, err := vm.Execute()
+				if err != nil {
+					t.Fatalf("Execute failed: %v", err)
+				}
+
+				got := result.(float64)
+				want := tt.wantCorr
+				if op == OpCovF {
+					want = tt.wantCov
+				}
+				if math.IsNaN(want) {
+					if !math.IsNaN(got) {
+						t.Errorf("%s: expected NaN, got %v", op, got)
+					}
+					continue
+				}
+				if math.Abs(got-want) > 1e-6 {
+					t.Errorf("%s: expected %v, got %v", op, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_BinF(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []interface{}
+		consts   []float64 // nbins, min, max
+		expected []interface{}
+	}{
+		{
+			name:     "auto range",
+			values:   []interface{}{0.0, 1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, 9.0},
+			consts:   []float64{5, math.NaN(), math.NaN()},
+			expected: []interface{}{int64(0), int64(0), int64(1), int64(1), int64(2), int64(2), int64(3), int64(3), int64(4), int64(4)},
+		},
+		{
+			name:     "boundaries",
+			values:   []interface{}{0.0, 2.0, 3.999, 4.0, 10.0, 10.5, -1.0, nil},
+			consts:   []float64{5, 0, 10},
+			expected: []interface{}{int64(0), int64(1), int64(1), int64(2), int64(4), nil, nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesFloat64("value", nil, tt.values...),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = value
+					EncodeInstruction(OpBinF, 0, 2, 1, 0, 0),      // V2 = bin(V1)
+					EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+				},
+				Constants:      []any{"data", "value"},
+				FloatConstants: tt.consts,
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			series := result.(dataframe.Series)
+			if series.NRows() != len(tt.expected) {
+				t.Fatalf("expected %d rows, got %d", len(tt.expected), series.NRows())
+			}
+			for i, want := range tt.expected {
+				if got := series.Value(i); got != want {
+					t.Errorf("row %d (%v): expected bin %v, got %v", i, tt.values[i], want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_Pivot(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("region", nil, "north", "north", "south", "north", "south"),
+		dataframe.NewSeriesString("month", nil, "jan", "feb", "jan", "jan", "mar"),
+		dataframe.NewSeriesInt64("sales", nil, 10, 20, 30, 5, 40),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpPivot, 0, 1, 0, 0, 1), // R1 = pivot(R0, "region", "month", "sales")
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "region", "month", "sales"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	wide := vm.frames[1]
+	wantCols := []string{"region", "jan", "feb", "mar"}
+	if got := getDataFrameColumnNames(wide); !reflect.DeepEqual(got, wantCols) {
+		t.Fatalf("expected columns %v, got %v", wantCols, got)
+	}
+
+	// north/jan sums the duplicate rows (10 + 5); missing cells are nil
+	want := [][]interface{}{
+		{"north", int64(15), int64(20), nil},
+		{"south", int64(30), nil, int64(40)},
+	}
+	if getDataFrameLength(wide) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), getDataFrameLength(wide))
+	}
+	for r, row := range want {
+		for c, cell := range row {
+			if got := wide.Series[c].Value(r); got != cell {
+				t.Errorf("row %d, column %s: expected %v, got %v", r, wantCols[c], cell, got)
+			}
+		}
+	}
+}
+
+func TestVM_Pivot_MissingColumn(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("region", nil, "north"),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpPivot, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "region", "month", "sales"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrColumnNotFound) {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestVM_Pivot_ColumnCollision(t *testing.T) {
+	tests := []struct {
+		name string
+		keys dataframe.Series
+	}{
+		{"case", dataframe.NewSeriesString("month", nil, "Jan", "jan")},
+		{"index column", dataframe.NewSeriesString("month", nil, "Jan", "region")},
+		{"nil key", dataframe.NewSeriesString("month", nil, nil, "<nil>")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesString("region", nil, "north", "south"),
+				tt.keys,
+				dataframe.NewSeriesInt64("sales", nil, 1, 2),
+			)
+			_, err := NewVM().pivot(frame, "region", "month", "sales")
+			if !errors.Is(err, ErrSchemaMismatch) {
+				t.Errorf("expected ErrSchemaMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func concatProgram() *Program {
+	return &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0), // R0 = "top"
+			EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1), // R1 = "bottom"
+			EncodeInstruction(OpConcat, 0, 2, 0, 1, 0),    // R2 = concat(R0, R1)
+			EncodeInstruction(OpHalt, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"top", "bottom"},
+	}
+}
+
+func TestVM_Concat(t *testing.T) {
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"top": dataframe.NewDataFrame(
+			dataframe.NewSeriesInt64("id", nil, 1, 2, 3),
+			dataframe.NewSeriesString("name", nil, "a", "b", "c"),
+		),
+		"bottom": dataframe.NewDataFrame(
+			dataframe.NewSeriesInt64("id", nil, 4, 5, 6),
+			dataframe.NewSeriesString("name", nil, "d", "e", "f"),
+		),
+	})
+
+	if err := vm.Load(concatProgram()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	stacked := vm.frames[2]
+	if n := getDataFrameLength(stacked); n != 6 {
+		t.Fatalf("expected 6 rows, got %d", n)
+	}
+	ids, _ := getDataFrameColumn(stacked, "id")
+	names, _ := getDataFrameColumn(stacked, "name")
+	if getSeriesType(ids) != TypeInt64 {
+		t.Errorf("expected id to stay int64, got type %v", getSeriesType(ids))
+	}
+	for i, want := range []string{"a", "b", "c", "d", "e", "f"} {
+		if id, _ := getInt64Value(ids, i); id != int64(i+1) {
+			t.Errorf("row %d: expected id %d, got %d", i, i+1, id)
+		}
+		if name, _ := getStringValue(names, i); name != want {
+			t.Errorf("row %d: expected name %q, got %q", i, want, name)
+		}
+	}
+}
+
+func TestVM_Concat_SchemaMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		bottom *dataframe.DataFrame
+	}{
+		{"different name", dataframe.NewDataFrame(
+			dataframe.NewSeriesInt64("key", nil, 4),
+			dataframe.NewSeriesString("name", nil, "d"),
+		)},
+		{"different type", dataframe.NewDataFrame(
+			dataframe.NewSeriesFloat64("id", nil, 4.0),
+			dataframe.NewSeriesString("name", nil, "d"),
+		)},
+		{"different width", dataframe.NewDataFrame(
+			dataframe.NewSeriesInt64("id", nil, 4),
+		)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+				"top": dataframe.NewDataFrame(
+					dataframe.NewSeriesInt64("id", nil, 1),
+					dataframe.NewSeriesString("name", nil, "a"),
+				),
+				"bottom": tt.bottom,
+			})
+
+			if err := vm.Load(concatProgram()); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if _, err := vm.Execute(); !errors.Is(err, ErrSchemaMismatch) {
+				t.Errorf("expected ErrSchemaMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVM_VecMinMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       Opcode
+		a, b     dataframe.Series
+		expected []interface{}
+	}{
+		{"min int", OpVecMinI,
+			dataframe.NewSeriesInt64("a", nil, 1, 5, 3),
+			dataframe.NewSeriesInt64("b", nil, 4, 2, 3),
+			[]interface{}{int64(1), int64(2), int64(3)}},
+		{"max int", OpVecMaxI,
+			dataframe.NewSeriesInt64("a", nil, 1, 5, 3),
+			dataframe.NewSeriesInt64("b", nil, 4, 2, 3),
+			[]interface{}{int64(4), int64(5), int64(3)}},
+		{"min float", OpVecMinF,
+			dataframe.NewSeriesFloat64("a", nil, 1.5, -2.0, 7.25),
+			dataframe.NewSeriesFloat64("b", nil, 0.5, 3.0, 7.5),
+			[]interface{}{0.5, -2.0, 7.25}},
+		{"max float", OpVecMaxF,
+			dataframe.NewSeriesFloat64("a", nil, 1.5, -2.0, 7.25),
+			dataframe.NewSeriesFloat64("b", nil, 0.5, 3.0, 7.5),
+			[]interface{}{1.5, 3.0, 7.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+				"data": dataframe.NewDataFrame(tt.a, tt.b),
+			})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = a
+					EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 2), // V2 = b
+					EncodeInstruction(tt.op, 0, 3, 1, 2, 0),       // V3 = op(V1, V2)
+					EncodeInstruction(OpHaltV, 0, 3, 0, 0, 0),
+				},
+				Constants: []any{"data", "a", "b"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			series := result.(dataframe.Series)
+			for i, want := range tt.expected {
+				if got := series.Value(i); got != want {
+					t.Errorf("row %d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVM_ClampF(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("value", nil, -5.0, 0.0, 5.0, 15.0, math.NaN(), nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = value
+			EncodeInstruction(OpClampF, 0, 2, 1, 0, 0),    // V2 = clamp(V1, 0, 10)
+			EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+		},
+		Constants:      []any{"data", "value"},
+		FloatConstants: []float64{0, 10},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	series := result.(dataframe.Series)
+	for i, want := range []float64{0, 0, 5, 10} {
+		if got, _ := getFloat64Value(series, i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+	// Float series store nulls as NaN, so both pass through unchanged
+	for _, i := range []int{4, 5} {
+		if !isNil(series, i) {
+			t.Errorf("row %d: expected NaN/nil to pass through, got %v", i, series.Value(i))
+		}
+	}
+}
+
+func TestVM_CmpEqEpsF(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("a", nil, 0.1, 1.0, nil),
+		dataframe.NewSeriesFloat64("b", nil, 0.2, 2.0, 1.0),
+		dataframe.NewSeriesFloat64("want", nil, 0.3, 3.5, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	run := func(cmp Instruction) dataframe.Series {
+		t.Helper()
+		program := &Program{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = a
+				EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 2), // V2 = b
+				EncodeInstruction(OpSelectCol, 0, 3, 0, 0, 3), // V3 = want
+				EncodeInstruction(OpVecAddF, 0, 4, 1, 2, 0),   // V4 = a + b
+				cmp,
+				EncodeInstruction(OpHaltV, 0, 5, 0, 0, 0),
+			},
+			Constants:      []any{"data", "a", "b", "want"},
+			FloatConstants: []float64{1e-9},
+		}
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		result, err := vm.Execute()
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return result.(dataframe.Series)
+	}
+
+	exact := run(EncodeInstruction(OpCmpEQ, 0, 5, 4, 3, 0))
+	if exact.Value(0) != false {
+		t.Errorf("CMP_EQ: expected 0.1 + 0.2 != 0.3, got %v", exact.Value(0))
+	}
+
+	approx := run(EncodeInstruction(OpCmpEqEpsF, 0, 5, 4, 3, 0))
+	// 0.1+0.2 ~ 0.3, 3.0 is not within eps of 3.5, nil never matches
+	for i, want := range []bool{true, false, false} {
+		if got := approx.Value(i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_FillNa(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 1.5, nil, 3.0),
+		dataframe.NewSeriesString("name", nil, "a", "b", nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),   // V1 = price
+			EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 2),   // V2 = name
+			EncodeInstruction(OpFillNa, 0, 3, 1, 0, 3),      // V3 = fillna(V1, 0.0)
+			EncodeInstruction(OpFillNa, 0, 4, 2, 0, 4),      // V4 = fillna(V2, "")
+			EncodeInstruction(OpReduceCount, 0, 1, 3, 0, 0), // R1 = count(V3)
+			EncodeInstruction(OpReduceCount, 0, 2, 4, 0, 0), // R2 = count(V4)
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", "name", 0.0, ""},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	prices := vm.registers.V[3]
+	for i, want := range []float64{1.5, 0.0, 3.0} {
+		if got, ok := getFloat64Value(prices, i); !ok || got != want {
+			t.Errorf("price row %d: expected %v, got %v", i, want, prices.Value(i))
+		}
+	}
+
+	names := vm.registers.V[4]
+	for i, want := range []string{"a", "b", ""} {
+		if got, ok := getStringValue(names, i); !ok || got != want {
+			t.Errorf("name row %d: expected %q, got %v", i, want, names.Value(i))
+		}
+	}
+
+	if vm.registers.R[1] != 3 {
+		t.Errorf("expected float count 3 after fillna, got %d", vm.registers.R[1])
+	}
+	if vm.registers.R[2] != 3 {
+		t.Errorf("expected string count 3 after fillna, got %d", vm.registers.R[2])
+	}
+}
+
+func TestVM_FillNaTypeMismatch(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 1.5, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpFillNa, 0, 2, 1, 0, 2),
+			EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", "missing"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestVM_DropNa(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", "b", "c", "d"),
+		dataframe.NewSeriesFloat64("price", nil, 1.0, nil, 3.0, 4.0),
+		dataframe.NewSeriesInt64("qty", nil, 10, 20, 30, nil),
+	)
+
+	tests := []struct {
+		name     string
+		run      []any
+		expected []string
+	}{
+		{"all columns", []any{int64(0)}, []string{"a", "c"}},
+		{"selected column", []any{int64(1), "price"}, []string{"a", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpDropNa, 0, 1, 0, 0, 1), // R1 = dropna(R0)
+					EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+				},
+				Constants: append([]any{"data"}, tt.run...),
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			if _, err := vm.Execute(); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			df := vm.frames[1]
+			if got := getDataFrameLength(df); got != len(tt.expected) {
+				t.Fatalf("expected %d rows, got %d", len(tt.expected), got)
+			}
+
+			names, _ := getDataFrameColumn(df, "name")
+			qty, _ := getDataFrameColumn(df, "qty")
+			for i, want := range tt.expected {
+				if got, _ := getStringValue(names, i); got != want {
+					t.Errorf("row %d: expected name %q, got %q", i, want, got)
+				}
+			}
+			// Rows stay aligned: "c" keeps its quantity of 30
+			if got, _ := getInt64Value(qty, 1); got != 30 {
+				t.Errorf("expected qty 30 next to \"c\", got %d", got)
+			}
+		})
+	}
+}
+
+func TestVM_DropNaColumnNotFound(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("qty", nil, 1, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpDropNa, 0, 1, 0, 0, 1),
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", int64(1), "missing"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Execute(); !errors.Is(err, ErrColumnNotFound) {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestVM_IsNull(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", nil, "c", nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = name
+			EncodeInstruction(OpIsNull, 0, 2, 1, 0, 0),    // V2 = is_null(V1)
+			EncodeInstruction(OpIsNotNull, 0, 3, 1, 0, 0), // V3 = is_not_null(V1)
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "name"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := []bool{false, true, false, true}
+	for i, w := range want {
+		if got, _ := getBoolValue(vm.registers.V[2], i); got != w {
+			t.Errorf("is_null row %d: expected %v, got %v", i, w, got)
+		}
+		if got, _ := getBoolValue(vm.registers.V[3], i); got != !w {
+			t.Errorf("is_not_null row %d: expected %v, got %v", i, !w, got)
+		}
+	}
+}
+
+func TestVM_AssertEq(t *testing.T) {
+	frames := map[string]*dataframe.DataFrame{
+		"got": dataframe.NewDataFrame(
+			dataframe.NewSeriesString("name", nil, "a", "b", "c"),
+			dataframe.NewSeriesInt64("qty", nil, 1, 2, 3),
+		),
+		"same": dataframe.NewDataFrame(
+			dataframe.NewSeriesString("name", nil, "a", "b", "c"),
+			dataframe.NewSeriesInt64("qty", nil, 1, 2, 3),
+		),
+		"diff": dataframe.NewDataFrame(
+			dataframe.NewSeriesString("name", nil, "a", "b", "c"),
+			dataframe.NewSeriesInt64("qty", nil, 1, 2, 4),
+		),
+		"renamed": dataframe.NewDataFrame(
+			dataframe.NewSeriesString("name", nil, "a", "b", "c"),
+			dataframe.NewSeriesInt64("count", nil, 1, 2, 3),
+		),
+	}
+
+	tests := []struct {
+		name    string
+		code    []Instruction
+		consts  []any
+		wantErr string
+	}{
+		{
+			name: "equal frames",
+			code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+				EncodeInstruction(OpAssertEq, 0, 0, 0, 1, uint16(RegFrame)),
+			},
+			consts: []any{"got", "same"},
+		},
+		{
+			name: "different cell",
+			code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+				EncodeInstruction(OpAssertEq, 0, 0, 0, 1, uint16(RegFrame)),
+			},
+			consts:  []any{"got", "diff"},
+			wantErr: `column "qty" row 2: 3 != 4`,
+		},
+		{
+			name: "different column name",
+			code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+				EncodeInstruction(OpAssertEq, 0, 0, 0, 1, uint16(RegFrame)),
+			},
+			consts:  []any{"got", "renamed"},
+			wantErr: `column 1 is "qty" vs "count"`,
+		},
+		{
+			name: "different vectors",
+			code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpLoadFrame, 0, 1, 0, 0, 1),
+				EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 2),
+				EncodeInstruction(OpSelectCol, 0, 1, 1, 0, 2),
+				EncodeInstruction(OpAssertEq, 0, 0, 0, 1, uint16(RegVector)),
+			},
+			consts:  []any{"got", "diff", "qty"},
+			wantErr: "row 2: 3 != 4",
+		},
+		{
+			name: "different ints",
+			code: []Instruction{
+				EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 1),
+				EncodeInstruction(OpAssertEq, 0, 0, 0, 1, uint16(RegInt)),
+			},
+			consts:  []any{int64(3), int64(4)},
+			wantErr: "3 != 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			vm.SetPredeclaredFrames(frames)
+			code := append(tt.code, EncodeInstruction(OpHalt, 0, 0, 0, 0, 0))
+			if err := vm.Load(&Program{Code: code, Constants: tt.consts}); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			_, err := vm.Execute()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected assertion to pass, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrAssertionFailed) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected assertion failure containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVM_Print(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", "b", "c", "d", "e", "f", "g"),
+		dataframe.NewSeriesInt64("qty", nil, 1, 2, nil, 4, 5, 6, 7),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+	var out bytes.Buffer
+	vm.SetOutput(&out)
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = qty
+			EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 2), // R1 = 42
+			EncodeInstruction(OpPrint, 0, 0, 1, 0, uint16(RegInt)),
+			EncodeInstruction(OpPrint, 0, 0, 0, 0, uint16(RegVector)),
+			EncodeInstruction(OpPrint, 0, 0, 0, 0, uint16(RegFrame)),
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "qty", int64(42)},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := "R1 = 42\n" +
+		"V0 = [1, 2, nil, 4, 5, ...] (7 rows)\n" +
+		"R0 = frame 7 rows x 2 cols [name, qty]\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestVM_OutputDefaultsToDiscard(t *testing.T) {
+	vm := NewVM()
+	if vm.output != io.Discard {
+		t.Fatalf("default output = %v, want io.Discard", vm.output)
+	}
+
+	var out bytes.Buffer
+	vm.SetOutput(&out)
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpPrint, 0, 0, 0, 0, uint16(RegInt)),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{int64(7)},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := out.String(); got != "R0 = 7\n" {
+		t.Errorf("output = %q, want %q", got, "R0 = 7\n")
+	}
+
+	// A nil writer restores the default
+	vm.SetOutput(nil)
+	if vm.output != io.Discard {
+		t.Errorf("output after SetOutput(nil) = %v, want io.Discard", vm.output)
+	}
+	vm.Reset()
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute after SetOutput(nil) failed: %v", err)
+	}
+	if got := out.String(); got != "R0 = 7\n" {
+		t.Errorf("output after SetOutput(nil) = %q, want nothing new", got)
+	}
+}
+
+func TestVM_SetResult(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5, 4.0),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = price
+			EncodeInstruction(OpReduceSumF, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpReduceMean, 0, 1, 0, 0, 0),
+			EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 2), // R1 = 3
+			EncodeInstruction(OpSetResult, RegFloat, 0, 0, 0, 3),
+			EncodeInstruction(OpSetResult, RegFloat, 0, 1, 0, 4),
+			EncodeInstruction(OpSetResult, RegInt, 0, 1, 0, 5),
+			EncodeInstruction(OpSetResult, RegVector, 0, 0, 0, 6),
+			EncodeInstruction(OpSetResult, RegFrame, 0, 0, 0, 7),
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", int64(3), "total", "avg", "n", "col", "frame"},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if vm.Results() != nil {
+		t.Fatal("expected no results before execution")
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	results := vm.Results()
+	if got := results["total"]; got != 8.0 {
+		t.Errorf("total = %v, want 8", got)
+	}
+	if got := results["avg"]; got != 8.0/3 {
+		t.Errorf("avg = %v, want %v", got, 8.0/3)
+	}
+	if got := results["n"]; got != int64(3) {
+		t.Errorf("n = %v, want 3", got)
+	}
+	if col, ok := results["col"].(dataframe.Series); !ok || col.NRows() != 3 {
+		t.Errorf("col = %v, want the price series", results["col"])
+	}
+	if got := results["frame"]; got != frame {
+		t.Errorf("frame = %v, want the loaded frame", got)
+	}
+
+	// Load clears results from the previous run
+	if err := vm.Load(&Program{Code: []Instruction{EncodeInstruction(OpHalt, 0, 0, 0, 0, 0)}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if vm.Results() != nil {
+		t.Errorf("expected results cleared by Load, got %v", vm.Results())
+	}
+}
+
+func TestVM_SetResultMissingFrame(t *testing.T) {
+	vm := NewVM()
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpSetResult, RegFrame, 0, 3, 0, 0),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"df"},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrFrameNotFound) {
+		t.Errorf("expected ErrFrameNotFound, got %v", err)
+	}
+}
+
+func TestVM_VecIndex(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("qty", nil, 5, 6, 7, nil),
+		dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5, 4.25, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	run := func(op Opcode, col, idx int) (any, error) {
+		t.Helper()
+		halt := EncodeInstruction(OpHalt, 0, 2, 0, 0, 0)
+		if op == OpVecIndexF {
+			halt = EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0)
+		}
+		program := &Program{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpSelectCol, 0, 0, 0, 0, uint16(col)),
+				EncodeInstruction(OpLoadConst, 0, 1, 0, 0, 3), // R1 = index
+				EncodeInstruction(op, 0, 2, 0, 1, 0),          // R2 or F2 = V0[R1]
+				EncodeInstruction(OpMoveF, 0, 0, 2, 0, 0),
+				halt,
+			},
+			Constants: []any{"data", "qty", "price", int64(idx)},
+		}
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return vm.Execute()
+	}
+
+	// Third element of each column
+	if got, err := run(OpVecIndex, 1, 2); err != nil || got != int64(7) {
+		t.Errorf("VEC_INDEX qty[2] = %v, %v; want 7", got, err)
+	}
+	if got, err := run(OpVecIndexF, 2, 2); err != nil || got != 4.25 {
+		t.Errorf("VEC_INDEX_F price[2] = %v, %v; want 4.25", got, err)
+	}
+	if got, err := run(OpVecIndexF, 1, 2); err != nil || got != 7.0 {
+		t.Errorf("VEC_INDEX_F qty[2] = %v, %v; want 7", got, err)
+	}
+
+	// Nulls are NaN in F registers and an error in R registers
+	if got, err := run(OpVecIndexF, 2, 3); err != nil || !math.IsNaN(got.(float64)) {
+		t.Errorf("VEC_INDEX_F price[3] = %v, %v; want NaN", got, err)
+	}
+	if _, err := run(OpVecIndex, 1, 3); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("VEC_INDEX qty[3]: expected ErrTypeMismatch, got %v", err)
+	}
+
+	for _, idx := range []int{4, -1} {
+		if _, err := run(OpVecIndex, 1, idx); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("VEC_INDEX qty[%d]: expected ErrIndexOutOfRange, got %v", idx, err)
+		}
+		if _, err := run(OpVecIndexF, 2, idx); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("VEC_INDEX_F price[%d]: expected ErrIndexOutOfRange, got %v", idx, err)
+		}
+	}
+}
+
+func TestVM_Negate(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("qty", nil, 5, nil, -7),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 2),  // R0 = 42
+			EncodeInstruction(OpNegI, 0, 1, 0, 0, 0),       // R1 = -R0
+			EncodeInstruction(OpLoadConstF, 0, 0, 0, 0, 0), // F0 = 2.5
+			EncodeInstruction(OpNegF, 0, 1, 0, 0, 0),       // F1 = -F0
+			EncodeInstruction(OpLoadFrame, 0, 2, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 2, 0, 1),
+			EncodeInstruction(OpVecNegF, 0, 1, 0, 0, 0), // V1 = -V0
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants:      []any{"data", "qty", int64(42)},
+		FloatConstants: []float64{2.5},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got != int64(-42) {
+		t.Errorf("NEG_I: expected -42, got %v", got)
+	}
+	if f := vm.registers.F[1]; f != -2.5 {
+		t.Errorf("NEG_F: expected -2.5, got %v", f)
+	}
+
+	result := vm.registers.V[1]
+	want := []any{-5.0, nil, 7.0}
+	for i, w := range want {
+		if v := result.Value(i); v != w {
+			t.Errorf("VEC_NEG_F row %d: expected %v, got %v", i, w, v)
+		}
+	}
+}
+
+func TestVM_LoadConstStr(t *testing.T) {
+	run := func(constants []any) (any, error) {
+		t.Helper()
+		vm := NewVM()
+		program := &Program{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadConstStr, 0, 0, 0, 0, 1), // R0 = handle of constants[1]
+				EncodeInstruction(OpMoveR, 0, 1, 0, 0, 0),
+				EncodeInstruction(OpHaltStr, 0, 1, 0, 0, 0),
+			},
+			Constants: constants,
+		}
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return vm.Execute()
+	}
+
+	got, err := run([]any{int64(3), "hello"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("expected \"hello\", got %#v", got)
+	}
+
+	if _, err := run([]any{"x", int64(3)}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch for an int constant, got %v", err)
+	}
+}
+
+func TestVM_Wide(t *testing.T) {
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{
+		"data": dataframe.NewDataFrame(
+			dataframe.NewSeriesFloat64("price", nil, 1.5, 2.5),
+			dataframe.NewSeriesFloat64("qty", nil, 10.0, 20.0),
+		),
+	})
+
+	constants := make([]any, 301)
+	for i := range constants {
+		constants[i] = int64(i)
+	}
+	constants[0], constants[44], constants[300] = "data", "qty", "price"
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpWide, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 44), // constants[1<<8 | 44]
+			EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 44), // WIDE applies to one instruction
+			EncodeInstruction(OpReduceSumF, 0, 0, 1, 0, 0),
+			EncodeInstruction(OpReduceSumF, 0, 1, 2, 0, 0),
+			EncodeInstruction(OpHaltF, 0, 0, 0, 0, 0),
+		},
+		Constants: constants,
+	}
+	if err := ValidateProgram(program); err != nil {
+		t.Fatalf("ValidateProgram failed: %v", err)
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got != 4.0 {
+		t.Errorf("expected sum of price = 4, got %v", got)
+	}
+	if f := vm.registers.F[1]; f != 30.0 {
+		t.Errorf("expected sum of qty = 30, got %v", f)
+	}
+}
+
+func TestVM_Resume(t *testing.T) {
+	vm := NewVM()
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadConst, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpAddR, 0, 1, 0, 0, 0), // R1 = R0 + R0, R0 kept from before the HALT
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{int64(21)},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := vm.Resume(); !errors.Is(err, ErrNotHalted) {
+		t.Fatalf("expected ErrNotHalted before Execute, got %v", err)
+	}
+	if got, err := vm.Execute(); err != nil || got != int64(21) {
+		t.Fatalf("Execute: expected 21, got %v, %v", got, err)
+	}
+	if got, err := vm.Resume(); err != nil || got != int64(42) {
+		t.Fatalf("Resume: expected 42, got %v, %v", got, err)
+	}
+	if _, err := vm.Resume(); !errors.Is(err, ErrNoHalt) {
+		t.Errorf("expected ErrNoHalt after the last HALT, got %v", err)
+	}
+	if _, err := vm.Resume(); !errors.Is(err, ErrNotHalted) {
+		t.Errorf("expected ErrNotHalted once execution has ended, got %v", err)
+	}
+}
+
+func TestVM_ParseDate(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("when", nil, "2024-03-15T10:30:00Z", "1999-12-31T23:00:00Z", "15/03/2024", nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),                    // V0 = when
+			EncodeInstruction(OpParseDate, 0, 1, 0, 0, 2),                    // V1 = parse_date(when)
+			EncodeInstruction(OpDatePart, 0, 2, 1, 0, uint16(DatePartYear)),  // V2 = year
+			EncodeInstruction(OpDatePart, 0, 3, 1, 0, uint16(DatePartMonth)), // V3 = month
+			EncodeInstruction(OpDatePart, 0, 4, 1, 0, uint16(DatePartDay)),   // V4 = day
+			EncodeInstruction(OpDatePart, 0, 5, 1, 0, uint16(DatePartHour)),  // V5 = hour
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "when", "2006-01-02T15:04:05Z07:00"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// The bad-format cell and the nil cell parse to nil
+	wants := map[int][]any{
+		1: {int64(1710498600), int64(946681200), nil, nil},
+		2: {int64(2024), int64(1999), nil, nil},
+		3: {int64(3), int64(12), nil, nil},
+		4: {int64(15), int64(31), nil, nil},
+		5: {int64(10), int64(23), nil, nil},
+	}
+	for reg, want := range wants {
+		for i, w := range want {
+			if got := vm.registers.V[reg].Value(i); got != w {
+				t.Errorf("V%d row %d: expected %v, got %v", reg, i, w, got)
+			}
+		}
+	}
+}
+
+func TestVM_DateArithmetic(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("start", nil, "2024-01-15", "2024-02-20", "2024-03-01", nil),
+		dataframe.NewSeriesString("end", nil, "2024-02-14", "2024-02-10", "2024-03-01", "2024-03-05"),
+		dataframe.NewSeriesInt64("days", nil, 30, 30, 30, 30),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),    // V0 = start
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),    // V1 = end
+			EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 3),    // V2 = days
+			EncodeInstruction(OpParseDate, 0, 0, 0, 0, 4),    // V0 = parse_date(start)
+			EncodeInstruction(OpParseDate, 0, 1, 1, 0, 4),    // V1 = parse_date(end)
+			EncodeInstruction(OpDateAddDays, 0, 3, 0, 2, 0),  // V3 = start + 30 days
+			EncodeInstruction(OpDateDiffDays, 0, 4, 1, 0, 0), // V4 = end - start in days
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "start", "end", "days", "2006-01-02"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// 2024-01-15 + 30 days = 2024-02-14; 2024 is a leap year
+	feb14, mar21 := int64(1707868800), int64(1710979200)
+	for i, want := range []any{feb14, mar21} {
+		if got := vm.registers.V[3].Value(i); got != want {
+			t.Errorf("date_add row %d: expected %v, got %v", i, want, got)
+		}
+	}
+	if got := vm.registers.V[3].Value(3); got != nil {
+		t.Errorf("date_add of nil: expected nil, got %v", got)
+	}
+
+	for i, want := range []any{int64(30), int64(-10), int64(0), nil} {
+		if got := vm.registers.V[4].Value(i); got != want {
+			t.Errorf("date_diff row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_Coalesce(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("a", nil, "a0", nil, nil, nil),
+		dataframe.NewSeriesString("b", nil, "b0", "b1", nil, nil),
+		dataframe.NewSeriesString("c", nil, "c0", "c1", "c2", nil),
+		dataframe.NewSeriesInt64("n", nil, 1, nil, 3, nil),
+		dataframe.NewSeriesFloat64("f", nil, 0.5, 1.5, nil, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = a
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2), // V1 = b
+			EncodeInstruction(OpSelectCol, 0, 2, 0, 0, 3), // V2 = c
+			EncodeInstruction(OpCoalesce, 0, 3, 0, 1, 0),  // V3 = coalesce(a, b)
+			EncodeInstruction(OpCoalesce, 0, 3, 3, 2, 0),  // V3 = coalesce(V3, c)
+			EncodeInstruction(OpSelectCol, 0, 4, 0, 0, 4), // V4 = n
+			EncodeInstruction(OpSelectCol, 0, 5, 0, 0, 5), // V5 = f
+			EncodeInstruction(OpCoalesce, 0, 6, 4, 5, 0),  // V6 = coalesce(n, f)
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "a", "b", "c", "n", "f"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	strs := vm.registers.V[3]
+	for i, want := range []any{"a0", "b1", "c2", nil} {
+		if got := strs.Value(i); got != want {
+			t.Errorf("string row %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	// Mixed int and float inputs produce a float column
+	nums := vm.registers.V[6]
+	if _, ok := nums.(*dataframe.SeriesFloat64); !ok {
+		t.Fatalf("expected float column, got %T", nums)
+	}
+	for i, want := range []any{1.0, 1.5, 3.0, nil} {
+		if got := nums.Value(i); got != want {
+			t.Errorf("numeric row %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	mismatch := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 2),
+			EncodeInstruction(OpCoalesce, 0, 2, 0, 1, 0),
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "a", "n"},
+	}
+	if err := vm.Load(mismatch); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestVM_FormatF(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("price", nil, 1.5, 2.345, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = price
+			EncodeInstruction(OpFormatF, 0, 1, 0, 0, 2),   // V1 = format(price, 2)
+			EncodeInstruction(OpFormatF, 0, 2, 0, 0, 3),   // V2 = format(price, "$%.1f")
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "price", int64(2), "$%.1f"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for i, want := range []any{"1.50", "2.35", nil} {
+		if got := vm.registers.V[1].Value(i); got != want {
+			t.Errorf("decimals row %d: expected %v, got %v", i, want, got)
+		}
+	}
+	for i, want := range []any{"$1.5", "$2.3", nil} {
+		if got := vm.registers.V[2].Value(i); got != want {
+			t.Errorf("format row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_HashStr(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("key", nil, "", "a", "foobar", nil, "apple"),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 0, 0, 0, 1), // V0 = key
+			EncodeInstruction(OpHashStr, 0, 1, 0, 0, 0),   // V1 = hash(key)
+			EncodeInstruction(OpHashStr, 0, 2, 0, 0, 16),  // V2 = hash(key) % 16
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "key"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Published 64-bit FNV-1a values, reinterpreted as int64
+	asInt := func(h uint64) int64 { return int64(h) }
+	hashes := vm.registers.V[1]
+	for i, want := range []any{asInt(0xcbf29ce484222325), asInt(0xaf63dc4c8601ec8c), asInt(0x85944171f73967e8), nil} {
+		if got := hashes.Value(i); got != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	buckets := vm.registers.V[2]
+	for i, want := range []any{int64(5), int64(12), int64(8), nil, int64(15)} {
+		if got := buckets.Value(i); got != want {
+			t.Errorf("bucket row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_IsNaNInfF(t *testing.T) {
+	zero := 0.0
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesFloat64("x", nil, 1.5, 1/zero, zero/zero, -1/zero, 0.0),
+		dataframe.NewSeriesInt64("n", nil, 1, 2, 3, 4, 5),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1), // V1 = x
+			EncodeInstruction(OpIsNaNF, 0, 2, 1, 0, 0),    // V2 = is_nan(V1)
+			EncodeInstruction(OpIsInfF, 0, 3, 1, 0, 0),    // V3 = is_inf(V1)
+			EncodeInstruction(OpSelectCol, 0, 4, 0, 0, 2), // V4 = n
+			EncodeInstruction(OpIsNaNF, 0, 5, 4, 0, 0),    // V5 = is_nan(V4)
+			EncodeInstruction(OpHalt, 0, 0, 0, 0, 0),
+		},
+		Constants: []any{"data", "x", "n"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	wantNaN := []bool{false, false, true, false, false}
+	wantInf := []bool{false, true, false, true, false}
+	for i := range wantNaN {
+		if got, _ := getBoolValue(vm.registers.V[2], i); got != wantNaN[i] {
+			t.Errorf("is_nan row %d: expected %v, got %v", i, wantNaN[i], got)
+		}
+		if got, _ := getBoolValue(vm.registers.V[3], i); got != wantInf[i] {
+			t.Errorf("is_inf row %d: expected %v, got %v", i, wantInf[i], got)
+		}
+		if got, _ := getBoolValue(vm.registers.V[5], i); got {
+			t.Errorf("is_nan on int column row %d: expected false", i)
+		}
+	}
+}
+
+func TestVM_InSet(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("category", nil, "A", "B", "C", "A", nil, "D"),
+		dataframe.NewSeriesInt64("qty", nil, 1, 2, 3, 4, 5, nil),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),   // V1 = category
+			EncodeInstruction(OpInSet, 0, 2, 1, 0, 3),       // V2 = category in ("A", "C")
+			EncodeInstruction(OpFilter, 0, 3, 1, 2, 0),      // V3 = filter(V1, V2)
+			EncodeInstruction(OpReduceCount, 0, 1, 3, 0, 0), // R1 = count(V3)
+			EncodeInstruction(OpSelectCol, 0, 4, 0, 0, 2),   // V4 = qty
+			EncodeInstruction(OpInSet, 0, 5, 4, 0, 6),       // V5 = qty in (2, 5.0)
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "category", "qty", int64(2), "A", "C", int64(2), int64(2), 5.0},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != int64(3) {
+		t.Errorf("expected 3 rows in {A, C}, got %v", result)
+	}
+
+	for i, want := range []bool{false, true, false, false, true, false} {
+		if got, _ := getBoolValue(vm.registers.V[5], i); got != want {
+			t.Errorf("qty row %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestVM_InSet_LargeInt64(t *testing.T) {
+	vm := NewVM()
+	// 2^53 and 2^53+1 are the same float64
+	col := newInt64Series("id", []int64{1 << 53, 1<<53 + 1, math.MaxInt64})
+	got, err := vm.inSet(col, []any{int64(1<<53 + 1), int64(math.MaxInt64)})
+	if err != nil {
+		t.Fatalf("inSet failed: %v", err)
+	}
+
+	for i, want := range []bool{false, true, true} {
+		if v, _ := getBoolValue(got, i); v != want {
+			t.Errorf("row %d: expected %v, got %v", i, want, v)
+		}
+	}
+}
+
+func TestVM_RowIndex(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", "b", "c", "d"),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpRowIndex, 0, 1, 0, 0, 0), // V1 = row_index(R0)
+			EncodeInstruction(OpRowCount, 0, 1, 0, 0, 0), // R1 = row_count(R0)
+			EncodeInstruction(OpHaltV, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := vm.Execute()
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	series := result.(dataframe.Series)
+	if got := int64(getSeriesLength(series)); got != vm.registers.R[1] {
+		t.Fatalf("expected %d indices, got %d", vm.registers.R[1], got)
+	}
+	for i := 0; i < getSeriesLength(series); i++ {
+		if got, _ := getInt64Value(series, i); got != int64(i) {
+			t.Errorf("row %d: expected index %d, got %d", i, i, got)
+		}
+	}
+}
+
+func TestVM_RankF(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    uint8
+		expected []float64
+	}{
+		{"average ascending", RankAverage, []float64{1, 2.5, 2.5, 4}},
+		{"min ascending", RankMin, []float64{1, 2, 2, 4}},
+		{"dense ascending", RankDense, []float64{1, 2, 2, 3}},
+		{"min descending", RankMin | RankDescending, []float64{4, 2, 2, 1}},
+		{"dense descending", RankDense | RankDescending, []float64{3, 2, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM()
+			frame := dataframe.NewDataFrame(
+				dataframe.NewSeriesInt64("score", nil, 10, 20, 20, 30, nil),
+			)
+			vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+			program := &Program{
+				Code: []Instruction{
+					EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+					EncodeInstruction(OpSelectCol, 0, 1, 0, 0, 1),            // V1 = score
+					EncodeInstruction(OpRankF, 0, 2, 1, 0, uint16(tt.flags)), // V2 = rank(V1)
+					EncodeInstruction(OpHaltV, 0, 2, 0, 0, 0),
+				},
+				Constants: []any{"data", "score"},
+			}
+
+			if err := vm.Load(program); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			result, err := vm.Execute()
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			series := result.(dataframe.Series)
+			for i, want := range tt.expected {
+				if got, _ := getFloat64Value(series, i); got != want {
+					t.Errorf("row %d: expected rank %v, got %v", i, want, got)
+				}
+			}
+			if !isNil(series, 4) {
+				t.Errorf("expected nil rank for nil score, got %v", series.Value(4))
+			}
+		})
+	}
+}
+
+func TestVM_TopN(t *testing.T) {
+	vm := NewVM()
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesString("name", nil, "a", "b", "c", "d", "e"),
+		dataframe.NewSeriesFloat64("score", nil, 3.0, nil, 9.0, 1.0, 9.0),
+	)
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpTopN, 0, 1, 0, 0, 1), // R1 = top 3 of R0 by score, desc
+			EncodeInstruction(OpTopN, 0, 2, 0, 0, 4), // R2 = top 10 of R0 by score, asc
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", "score", int64(3), "desc", "score", int64(10), "asc"},
+	}
+
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	checkNames := func(df *dataframe.DataFrame, want []string) {
+		t.Helper()
+		names := df.Series[0]
+		if got := getSeriesLength(names); got != len(want) {
+			t.Fatalf("expected %d rows, got %d", len(want), got)
+		}
+		for i, w := range want {
+			if got, _ := getStringValue(names, i); got != w {
+				t.Errorf("row %d: expected %q, got %q", i, w, got)
+			}
+		}
+	}
+
+	// Ties keep their original order; nils sort last in either direction
+	checkNames(vm.frames[1], []string{"c", "e", "a"})
+	checkNames(vm.frames[2], []string{"d", "a", "c", "e", "b"})
+}
+
+func TestVM_Sample(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9),
+	)
+
+	run := func(seed int64, n int64) []int64 {
+		t.Helper()
+		vm := NewVM()
+		vm.SetSeed(seed)
+		vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+		program := &Program{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpSample, 0, 1, 0, 0, 1), // R1 = n random rows of R0
+				EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+			},
+			Constants: []any{"data", n},
+		}
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if _, err := vm.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		ids := vm.frames[1].Series[0]
+		rows := make([]int64, getSeriesLength(ids))
+		for i := range rows {
+			rows[i], _ = getInt64Value(ids, i)
+		}
+		return rows
+	}
+
+	first := run(42, 4)
+	if len(first) != 4 {
+		t.Fatalf("expected 4 rows, got %v", first)
+	}
+	seen := make(map[int64]bool)
+	for _, id := range first {
+		if seen[id] {
+			t.Errorf("row %d sampled twice in %v", id, first)
+		}
+		seen[id] = true
+	}
+	if again := run(42, 4); !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed gave different rows: %v and %v", first, again)
+	}
+
+	// n beyond the frame returns every row once
+	all := run(7, 50)
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	if !reflect.DeepEqual(all, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("expected all 10 rows, got %v", all)
+	}
+}
+
+func TestVM_SampleSeededInstruction(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9),
+	)
+	vm := NewVM()
+	vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+
+	// Both instructions carry seed 42, so they draw the same rows even though
+	// the VM itself is unseeded
+	program := &Program{
+		Code: []Instruction{
+			EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+			EncodeInstruction(OpSample, SampleSeeded, 1, 0, 0, 1),
+			EncodeInstruction(OpSample, SampleSeeded, 2, 0, 0, 1),
+			EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+		},
+		Constants: []any{"data", int64(3), int64(42)},
+	}
+	if err := vm.Load(program); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := vm.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	a, b := vm.frames[1].Series[0], vm.frames[2].Series[0]
+	if getSeriesLength(a) != 3 || getSeriesLength(b) != 3 {
+		t.Fatalf("expected 3 rows each, got %d and %d", getSeriesLength(a), getSeriesLength(b))
+	}
+	for i := 0; i < 3; i++ {
+		if a.Value(i) != b.Value(i) {
+			t.Errorf("row %d: %v != %v", i, a.Value(i), b.Value(i))
+		}
+	}
+}
+
+func TestVM_Shuffle(t *testing.T) {
+	frame := dataframe.NewDataFrame(
+		dataframe.NewSeriesInt64("id", nil, 0, 1, 2, 3, 4, 5, 6, 7),
+		dataframe.NewSeriesString("name", nil, "a", "b", "c", "d", "e", "f", "g", "h"),
+	)
+
+	run := func(seed int64) *dataframe.DataFrame {
+		t.Helper()
+		vm := NewVM()
+		vm.SetSeed(seed)
+		vm.SetPredeclaredFrames(map[string]*dataframe.DataFrame{"data": frame})
+		program := &Program{
+			Code: []Instruction{
+				EncodeInstruction(OpLoadFrame, 0, 0, 0, 0, 0),
+				EncodeInstruction(OpShuffle, 0, 1, 0, 0, 0), // R1 = shuffle(R0)
+				EncodeInstruction(OpHalt, 0, 1, 0, 0, 0),
+			},
+			Constants: []any{"data"},
+		}
+		if err := vm.Load(program); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if _, err := vm.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return vm.frames[1]
+	}
+
+	rows := func(df *dataframe.DataFrame) []string {
+		out := make([]string, getDataFrameLength(df))
+		for i := range out {
+			id, _ := getInt64Value(df.Series[0], i)
+			name, _ := getStringValue(df.Series[1], i)
+			out[i] = fmt.Sprintf("%d:%s", id, name)
+		}
+		return out
+	}
+
+	first := rows(run(42))
+	if again := rows(run(42)); !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed gave different orders: %v and %v", first, again)
+	}
+
+	// Columns move together, so every original row appears exactly once
+	sorted := append([]string(nil), first...)
+	sort.Strings(sorted)
+	want := []string{"0:a", "1:b", "2:c", "3:d", "4:e", "5:f", "6:g", "7:h"}
+	if !reflect.DeepEqual(sorted, want) {
+		t.Errorf("expected rows %v, got %v", want, first)
+	}
+}
+
+func TestVM_TypedSlices_MatchGetters(t *testing.T) {
+	vm := NewVM()
+	columns := []dataframe.Series{
+		dataframe.NewSeriesInt64("i", nil, 1, nil, -7, math.MaxInt64),
+		dataframe.NewSeriesFloat64("f", nil, 1.5, nil, -2.25, math.NaN()),
+		dataframe.NewSeriesString("s", nil, "a", "b", "c", "d"),
+	}
+
+	// Read past the end to check that missing cells read as 0
+	n := 6
+	for _, s := range columns {
+		ints := vm.int64Slice(s, n)
+		floats := vm.float64Slice(s, n)
+		for i := 0; i < n; i++ {
+			if want, _ := getInt64Value(s, i); ints[i] != want {
+				t.Errorf("%s[%d]: expected int %d, got %d", s.Name(), i, want, ints[i])
+			}
+			if want, _ := getFloat64Value(s, i); floats[i] != want {
+				t.Errorf("%s[%d]: expected float %v, got %v", s.Name(), i, want, floats[i])
+			}
+		}
+		vm.buffers.putInt64s(ints)
+		vm.buffers.putFloat64s(floats)
+	}
+}
+
+func TestVM_TypedSlices_WaitForSeriesLock(t *testing.T) {
+	si := dataframe.NewSeriesInt64("i", nil, 1, 2)
+	sf := dataframe.NewSeriesFloat64("f", nil, 1.5, 2.5)
+
+	// Both readers must wait for a writer holding the series lock
+	si.Lock()
+	sf.Lock()
+	done := make(chan struct{}, 2)
+	// Separate VMs since a buffer pool is not safe for concurrent use
+	go func() { NewVM().int64Slice(si, 2); done <- struct{}{} }()
+	go func() { NewVM().float64Slice(sf, 2); done <- struct{}{} }()
+
+	select {
+	case <-done:
+		t.Fatal("typed slice read a series while it was locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+	si.Unlock()
+	sf.Unlock()
+	<-done
+	<-done
+}
+
+func largeInt64Column(n int) dataframe.Series {
+	data := make([]int64, n)
+	for i := range data {
+		data[i] = int64(i*7919) - 1_000_000
+	}
+	return newInt64Series("x", data)
+}
+
+func BenchmarkInt64Read_Generic(b *testing.B) {
+	s := largeInt64Column(1_000_000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		for j := 0; j < 1_000_000; j++ {
+			v, _ := getInt64Value(s, j)
+			sum += v
+		}
+	}
+}
+
+func BenchmarkInt64Read_Typed(b *testing.B) {
+	s := largeInt64Column(1_000_000)
+	vm := NewVM()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		vals := vm.int64Slice(s, 1_000_000)
+		for _, v := range vals {
+			sum += v
+		}
+		vm.buffers.putInt64s(vals)
+	}
+}